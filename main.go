@@ -1,108 +1,1017 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
-	"gopkg.in/yaml.v2"
-
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/archive"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/compat"
 	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/dashboard"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/demo"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/health"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/logging"
 	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/store"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/version"
 	"github.com/jimohabdol/k8s-resource-watcher/pkg/watcher"
 
 	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 func main() {
+	defer exitcode.Recover()
+
+	// Subcommands run instead of the watcher
+	if len(os.Args) > 1 && os.Args[1] == "migrate-config" {
+		os.Exit(runMigrateConfig(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "tail" {
+		os.Exit(runTail(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		os.Exit(runRestore(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+
 	// Parse command line flags
 	configFile := flag.String("config", "config.yaml", "Path to configuration file")
+	kubeconfigFile := flag.String("kubeconfig", "", "Path to a kubeconfig file; defaults to the in-cluster config, then KUBECONFIG and ~/.kube/config")
+	kubeContext := flag.String("context", "", "Kubeconfig context to use; defaults to the current context")
+	demoMode := flag.Bool("demo", false, "Watch an in-memory fake cluster with synthetic changes instead of a real one")
 	flag.Parse()
 
 	// Load configuration
 	cfg, err := loadConfig(*configFile)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		exitcode.Exit(exitcode.Config, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	cfg.LocalKubeconfig = *kubeconfigFile
+	cfg.LocalContext = *kubeContext
+
+	// Demo mode swaps the cluster for a fake one whose objects change on their own
+	var demoCluster *demo.Cluster
+	if *demoMode {
+		slog.Info("Demo mode: watching a fake cluster", "interval", demo.DefaultInterval.String())
+		cfg.Clusters = nil
+		if cfg.Storage.GetDriver() == config.StorageDriverConfigMap {
+			slog.Info("Demo mode: keeping state in memory instead of a ConfigMap")
+			cfg.Storage = config.StorageConfig{}
+		}
+		demoCluster = demo.NewCluster(cfg, demo.DefaultInterval)
+	}
+	if err := notifier.ValidateRoutingEventTypes(cfg.Routing); err != nil {
+		exitcode.Exit(exitcode.Config, fmt.Errorf("invalid routing configuration: %w", err))
+	}
+	if err := notifier.ValidateSeverityEventTypes(cfg.Severity); err != nil {
+		exitcode.Exit(exitcode.Config, fmt.Errorf("invalid severity configuration: %w", err))
+	}
+
+	// Refuse configs and state written for a newer release unless safe mode is allowed
+	compatibility := compat.Check(cfg)
+	if !compatibility.Compatible() {
+		for _, problem := range compatibility.Problems {
+			slog.Error("Incompatible: " + problem)
+		}
+		if cfg.Compatibility.SafeMode {
+			compatibility.EnterSafeMode(cfg)
+		}
+	}
+
+	slog.Info("Starting Kubernetes Resource Watcher (Informer-based)", "version", version.Version,
+		"config", *configFile, "cluster", cfg.ClusterName, "resources", len(cfg.Resources))
+	if len(cfg.Clusters) > 0 {
+		slog.Info("Watching multiple clusters", "clusters", len(cfg.Clusters))
+	}
+
+	// Create email notifier, plus the other notifiers that are configured
+	notifiers := map[string]notifier.Notifier{}
+	if cfg.Email.IsEnabled() {
+		notifiers["email"] = notifier.NewEmailNotifier(cfg)
+	}
+	if cfg.Teams.Enabled {
+		notifiers["teams"] = notifier.NewTeamsNotifier(cfg)
+		slog.Info("Teams notifications enabled", "webhooks", len(cfg.Teams.Webhooks))
+	}
+	if cfg.Webhook.Enabled {
+		notifiers["webhook"] = notifier.NewWebhookNotifier(cfg)
+		slog.Info("Webhook notifications enabled", "oauth2", cfg.Webhook.OAuth2 != nil)
+	}
+	if cfg.LogSink.Enabled {
+		logSink, err := notifier.NewLogSinkNotifier(cfg)
+		if err != nil {
+			exitcode.Fail(err, exitcode.Failure)
+		}
+		notifiers["logSink"] = logSink
+		slog.Info("Log sink enabled", "path", cfg.LogSink.Path)
+	}
+	if cfg.Publisher.Enabled {
+		notifiers["publisher"] = notifier.NewPublisherNotifier(cfg)
+		slog.Info("Event publishing enabled", "broker", cfg.Publisher.GetBroker(), "format", cfg.Publisher.GetFormat())
+	}
+	if cfg.SNS.Enabled {
+		notifiers["sns"] = notifier.NewSNSNotifier(cfg)
+		slog.Info("SNS publishing enabled", "topic", cfg.SNS.TopicARN, "format", cfg.SNS.GetFormat())
+	}
+	if cfg.PubSub.Enabled {
+		notifiers["pubsub"] = notifier.NewPubSubNotifier(cfg)
+		slog.Info("Pub/Sub publishing enabled", "project", cfg.PubSub.Project, "topic", cfg.PubSub.Topic, "format", cfg.PubSub.GetFormat())
+	}
+	if cfg.Opsgenie.Enabled {
+		notifiers["opsgenie"] = notifier.NewOpsgenieNotifier(cfg)
+		slog.Info("Opsgenie alerts enabled", "endpoint", cfg.Opsgenie.GetEndpoint())
+	}
+	if cfg.SplunkOnCall.Enabled {
+		notifiers["splunkOnCall"] = notifier.NewSplunkOnCallNotifier(cfg)
+		slog.Info("Splunk On-Call alerts enabled", "routingKey", cfg.SplunkOnCall.RoutingKey)
+	}
+
+	// Route events to notifiers according to the configured rulesets
+	notificationRouter := notifier.NewRouter(cfg.Routing, notifiers, cfg.NotifierNames())
+	var eventNotifier notifier.Notifier = notificationRouter
+	if len(cfg.Routing.Rulesets) > 0 {
+		slog.Info("Notification routing enabled", "rulesets", len(cfg.Routing.Rulesets))
 	}
 
-	// Load logging configuration
-	if err := cfg.LoadLoggingConfig(); err != nil {
-		log.Printf("Warning: Failed to load logging config: %v", err)
+	// Summaries go to every notifier, bypassing routing
+	broadcast := notifier.NewRouter(config.RoutingConfig{}, notifiers, cfg.NotifierNames())
+
+	// Scrub what the redaction rules name before it reaches a notifier
+	var redactor *notifier.Redactor
+	if len(cfg.Redaction.Rules) > 0 {
+		redactor = notifier.NewRedactor(cfg.Redaction)
+		notificationRouter.SetRedactor(redactor)
+		broadcast.SetRedactor(redactor)
+		slog.Info("Notification redaction enabled", "rules", len(cfg.Redaction.Rules))
+	}
+
+	// Tell operators about the incompatibility before refusing to start or continuing in safe mode
+	if !compatibility.Compatible() {
+		alertCtx, cancelAlert := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := broadcast.SendNotification(alertCtx, compatibility.Alert()); err != nil {
+			slog.Error("Failed to send compatibility self-alert", "error", err)
+		}
+		cancelAlert()
+		if !compatibility.SafeMode {
+			exitcode.Exit(exitcode.Config, errors.New("refusing to start: config or persisted state is incompatible with this binary (set compatibility.safeMode to run in safe mode)"))
+		}
+		slog.Warn("Running in safe mode")
+	}
+
+	// Cap the total notification volume
+	var burstGuard *notifier.BurstGuardNotifier
+	if burst := cfg.Watcher.BurstProtection; burst.Enabled {
+		burstGuard = notifier.NewBurstGuardNotifier(eventNotifier, broadcast, burst.GetMaxPerMinute())
+		burstGuard.SetRoute(notificationRouter.Route)
+		eventNotifier = burstGuard
+		slog.Info("Burst protection enabled", "maxPerMinute", burst.GetMaxPerMinute())
+	}
+
+	// Throttle flapping resources before they reach the notifier
+	if rateLimit := cfg.Watcher.RateLimit; rateLimit.Enabled {
+		eventNotifier = notifier.NewRateLimitedNotifier(eventNotifier, rateLimit.GetMaxNotifications(), rateLimit.GetPeriod())
+		slog.Info("Notification rate limiting enabled", "maxNotifications", rateLimit.GetMaxNotifications(), "period", rateLimit.GetPeriod().String())
 	}
 
-	log.Printf("Starting Kubernetes Resource Watcher (Informer-based)")
-	log.Printf("Configuration loaded from: %s", *configFile)
-	log.Printf("Cluster: %s", cfg.ClusterName)
-	log.Printf("Watching %d resource types", len(cfg.Resources))
+	// Drop notifications matching an active silence; expired silences are summarized
+	silences := notifier.NewSilencingNotifier(eventNotifier, broadcast, notifiers["email"], cfg.Watcher.Silences.GetWarnBefore())
+	eventNotifier = silences
+
+	// Annotate events with the deployment markers pushed to /api/markers
+	markers := notifier.NewMarkerNotifier(eventNotifier, cfg.Watcher.Markers.GetWindow())
+	eventNotifier = markers
 
-	// Create email notifier
-	emailNotifier := notifier.NewEmailNotifier(cfg)
+	// Count every change, including those silenced or throttled further down
+	if anomaly := cfg.Watcher.AnomalyDetection; anomaly.Enabled {
+		eventNotifier = notifier.NewAnomalyDetector(eventNotifier, anomaly.GetMinEvents(), anomaly.GetFactor(), anomaly.GetBaseline())
+		slog.Info("Anomaly detection enabled", "minEvents", anomaly.GetMinEvents(), "factor", anomaly.GetFactor(), "baseline", anomaly.GetBaseline().String())
+	}
 
-	// Create Informer-based watcher
-	resourceWatcher, err := watcher.NewInformerWatcher(cfg, emailNotifier)
+	// Keep silences across restarts in the configured state storage
+	stateStorage, err := store.OpenStorage(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create resource watcher: %v", err)
+		exitcode.Fail(fmt.Errorf("failed to open state storage: %w", err), exitcode.Failure)
+	}
+	restoreCtx, cancelRestore := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := silences.Restore(restoreCtx, stateStorage); err != nil {
+		slog.Warn("Failed to restore silences", "error", err)
 	}
+	cancelRestore()
 
-	// Start the watcher
-	if err := resourceWatcher.Start(); err != nil {
-		log.Fatalf("Failed to start resource watcher: %v", err)
+	background, stopBackground := context.WithCancel(context.Background())
+	go silences.Run(background)
+
+	// Create Informer-based watcher; with clusters configured it watches the first
+	clusterConfigs := cfg.ClusterConfigs()
+	var resourceWatcher *watcher.InformerWatcher
+	if demoCluster != nil {
+		resourceWatcher, err = watcher.NewInformerWatcherWithClients(clusterConfigs[0], eventNotifier, demoCluster.Clients())
+	} else {
+		resourceWatcher, err = watcher.NewInformerWatcher(clusterConfigs[0], eventNotifier)
+	}
+	// Without an API error, the kubeconfig or its Secret reference is at fault
+	if err != nil {
+		exitcode.Fail(fmt.Errorf("failed to create resource watcher: %w", err), exitcode.Config)
 	}
 
-	log.Printf("Resource watcher started successfully")
+	// The other clusters share the notification pipeline and event history
+	watchers := []*watcher.InformerWatcher{resourceWatcher}
+	for _, clusterCfg := range clusterConfigs[1:] {
+		clusterWatcher, err := watcher.NewClusterWatcher(clusterCfg, eventNotifier, resourceWatcher.GetEventStore())
+		if err != nil {
+			exitcode.Fail(fmt.Errorf("failed to create resource watcher for cluster %s: %w", clusterCfg.ClusterName, err), exitcode.Config)
+		}
+		watchers = append(watchers, clusterWatcher)
+	}
+
+	// Copy every change to event stream clients such as the tail subcommand
+	stream := notifier.NewEventStream()
+	for _, clusterWatcher := range watchers {
+		clusterWatcher.SetRedactor(redactor)
+		clusterWatcher.Subscribe("stream", stream.Publish)
+	}
+
+	// Report notifications dropped in the current window before exiting
+	if burstGuard != nil {
+		resourceWatcher.OnStopping("burst-summary", burstGuard.Flush)
+	}
+
+	// Keep the latest suppression counts for the expiry summaries
+	resourceWatcher.OnStopping("silences", silences.Save)
+
+	resourceWatcher.OnStopping("background", func(ctx context.Context) error {
+		stopBackground()
+		return nil
+	})
+
+	if demoCluster != nil {
+		if err := demoCluster.Seed(background); err != nil {
+			exitcode.Exit(exitcode.Failure, fmt.Errorf("failed to seed demo cluster: %w", err))
+		}
+	}
+
+	// Read the Secrets whose values are redacted before the first event can be notified
+	if redactor != nil {
+		loadCtx, cancelLoad := context.WithTimeout(background, 30*time.Second)
+		if err := redactor.LoadSecrets(loadCtx, resourceWatcher.GetSecretData); err != nil {
+			slog.Warn("Redacted Secrets unavailable; withholding the diffs their rules apply to", "error", err)
+		}
+		cancelLoad()
+		go redactor.Run(background, resourceWatcher.GetSecretData)
+	}
+
+	// Load the server certificates before anything is started
+	tlsConfig, err := serverTLSConfig(cfg.Server.TLS)
+	if err != nil {
+		exitcode.Fail(fmt.Errorf("health check server: %w", err), exitcode.Config)
+	}
+	adminTLS, err := adminTLSConfig(cfg.Server.Admin)
+	if err != nil {
+		exitcode.Fail(fmt.Errorf("admin server: %w", err), exitcode.Config)
+	}
+
+	// Start the watchers; caches that do not sync in time point at the API server
+	for _, clusterWatcher := range watchers {
+		if err := clusterWatcher.Start(); err != nil {
+			err = fmt.Errorf("failed to start resource watcher for cluster %s: %w", clusterWatcher.ClusterName(), err)
+			if errors.Is(err, watcher.ErrMissingPermissions) {
+				exitcode.Exit(exitcode.RBAC, err)
+			}
+			exitcode.Fail(err, exitcode.Connectivity)
+		}
+	}
+
+	slog.Info("Resource watcher started successfully")
+
+	if demoCluster != nil {
+		go demoCluster.Run(background)
+	}
 
 	// Set up Gin server for health checks
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 	router.Use(gin.Recovery())
 
+	// With an admin port, the API and dashboard move there behind authentication, and
+	// the main port keeps only the health checks and status page, for probes and screens
+	admin := cfg.Server.Admin
+	public := router
+	probeRouters := []*gin.Engine{router}
+	if admin.Enabled {
+		router.Use(adminAuth(admin))
+		public = gin.New()
+		public.Use(gin.Recovery())
+		probeRouters = append(probeRouters, public)
+	}
+
 	// Health check endpoints
-	router.GET("/healthz", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "OK"})
+	for _, probeRouter := range probeRouters {
+		probeRouter.GET("/healthz", func(c *gin.Context) {
+			c.JSON(200, gin.H{"status": "OK"})
+		})
+	}
+
+	// Ready once caches are synced and every notifier accepted a test connection
+	readiness := health.NewHandler()
+	readiness.AddCheck("watcher", func(ctx context.Context) error {
+		for _, clusterWatcher := range watchers {
+			if ready, reason := clusterWatcher.Ready(); !ready {
+				if len(watchers) > 1 {
+					reason = clusterWatcher.ClusterName() + ": " + reason
+				}
+				return errors.New(reason)
+			}
+		}
+		return nil
 	})
+	if !cfg.Watcher.Readiness.SkipNotifierCheck {
+		for _, name := range cfg.NotifierNames() {
+			if prober, ok := notifiers[name].(notifier.Prober); ok {
+				readiness.AddStartupCheck(name, prober.TestConnection)
+			}
+		}
+	}
+	go readiness.Run(background)
+	for _, probeRouter := range probeRouters {
+		probeRouter.GET("/readyz", gin.WrapH(readiness))
+	}
 
-	router.GET("/readyz", func(c *gin.Context) {
-		if resourceWatcher != nil {
-			c.JSON(200, gin.H{"status": "OK"})
+	// Per resource entry: engine, cache sync, last event, reconnects and consecutive failures
+	router.GET("/api/v1/status", func(c *gin.Context) {
+		ready, reason := readiness.Ready(c.Request.Context())
+		var states []watcher.WatcherState
+		for _, clusterWatcher := range watchers {
+			states = append(states, clusterWatcher.GetWatcherState()...)
+		}
+		c.JSON(200, gin.H{"ready": ready, "reason": reason, "version": version.Version, "compatibility": compatibility, "watchers": states})
+	})
+
+	// Processing timeline of a recent event, for "why was this suppressed" questions
+	router.GET("/api/events/:id/trace", func(c *gin.Context) {
+		for _, clusterWatcher := range watchers {
+			if trace, ok := clusterWatcher.GetEventTrace(c.Param("id")); ok {
+				c.JSON(200, trace)
+				return
+			}
+		}
+		c.JSON(404, gin.H{"error": "trace not found (it may have been evicted)"})
+	})
+
+	// Most recent events, newest first, optionally for one namespace.
+	// Here and below, ?cluster= selects a cluster other than the first.
+	router.GET("/api/events/recent", func(c *gin.Context) {
+		clusterWatcher, ok := selectWatcher(c, watchers)
+		if !ok {
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 {
+			c.JSON(400, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		events := clusterWatcher.GetRecentEvents(0)
+		recent := make([]watcher.EventTrace, 0, limit)
+		for _, event := range events {
+			if namespace, ok := c.GetQuery("namespace"); ok && event.Namespace != namespace {
+				continue
+			}
+			if recent = append(recent, event); len(recent) == limit {
+				break
+			}
+		}
+		c.JSON(200, recent)
+	})
+
+	// Read-only dashboard over the endpoints above
+	router.GET("/ui", gin.WrapH(dashboard.Handler()))
+
+	// Health and watched kinds only, safe to show on a NOC screen without authentication
+	if statusPage := cfg.Watcher.StatusPage; statusPage.Enabled {
+		title := statusPage.Title
+		if title == "" {
+			title = cfg.ClusterName
+		}
+		statusHandler := gin.WrapH(dashboard.StatusHandler(func(r *http.Request) dashboard.StatusPage {
+			ready, _ := readiness.Ready(r.Context())
+			page := dashboard.StatusPage{Title: title, Version: version.Version, Ready: ready, MultiCluster: len(watchers) > 1}
+			for _, clusterWatcher := range watchers {
+				for _, state := range clusterWatcher.GetWatcherState() {
+					page.Entries = append(page.Entries, dashboard.StatusEntry{
+						Cluster:       state.Cluster,
+						Resource:      state.Resource,
+						Engine:        state.Engine,
+						CacheSynced:   state.CacheSynced,
+						LastEventTime: state.LastEventTime,
+					})
+				}
+			}
+			return page
+		}))
+		for _, probeRouter := range probeRouters {
+			probeRouter.GET("/statusz", statusHandler)
+		}
+	}
+
+	// Event, notification and process counters
+	router.GET("/api/metrics", func(c *gin.Context) {
+		if clusterWatcher, ok := selectWatcher(c, watchers); ok {
+			c.JSON(200, clusterWatcher.GetMetrics())
+		}
+	})
+
+	// Which watch engine serves each kind, and why a kind was degraded to raw watches
+	router.GET("/api/engines", func(c *gin.Context) {
+		if clusterWatcher, ok := selectWatcher(c, watchers); ok {
+			c.JSON(200, clusterWatcher.GetEngineStatus())
+		}
+	})
+
+	// Render the notifications a change to a live object would produce, without sending them
+	router.POST("/api/preview", func(c *gin.Context) {
+		clusterWatcher, ok := selectWatcher(c, watchers)
+		if !ok {
+			return
+		}
+		var request struct {
+			Kind      string `json:"kind" binding:"required"`
+			Namespace string `json:"namespace"`
+			Name      string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		event, err := clusterWatcher.PreviewEvent(c.Request.Context(), request.Kind, request.Namespace, request.Name)
+		if err != nil {
+			status := 500
+			if apierrors.IsNotFound(err) {
+				status = 404
+			} else if errors.Is(err, watcher.ErrUnsupportedKind) {
+				status = 400
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"event": redactor.RedactAll(event), "notifications": notificationRouter.Preview(event)})
+	})
+
+	// Silences survive restarts when storage.driver is file or configmap
+	router.GET("/api/v1/silences", func(c *gin.Context) {
+		c.JSON(200, silences.List())
+	})
+
+	router.POST("/api/v1/silences", func(c *gin.Context) {
+		var silence notifier.Silence
+		if err := c.ShouldBindJSON(&silence); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		created, err := silences.Add(c.Request.Context(), silence)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(201, created)
+	})
+
+	router.DELETE("/api/v1/silences/:id", func(c *gin.Context) {
+		if err := silences.Expire(c.Request.Context(), c.Param("id")); err != nil {
+			if errors.Is(err, notifier.ErrSilenceNotFound) {
+				c.JSON(404, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(204)
+	})
+
+	// Deployment markers from CI/CD, e.g. {"service": "api", "version": "v1.4.2", "author": "alice"}
+	router.GET("/api/markers", func(c *gin.Context) {
+		c.JSON(200, markers.List())
+	})
+
+	router.POST("/api/markers", func(c *gin.Context) {
+		var marker notifier.Marker
+		if err := c.ShouldBindJSON(&marker); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		created, err := markers.Add(marker)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(201, created)
+	})
+
+	// Routing rulesets and silences as one document, for backup and promotion
+	// between clusters; ?format=yaml exports YAML
+	router.GET("/api/v1/policies", func(c *gin.Context) {
+		policies := notifier.ExportPolicies(notificationRouter, silences)
+		if c.Query("format") == "yaml" {
+			c.YAML(200, policies)
+			return
+		}
+		c.JSON(200, policies)
+	})
+
+	// Imported routing replaces the config's until restart; ?dryRun=true only validates
+	router.POST("/api/v1/policies", func(c *gin.Context) {
+		var policies notifier.Policies
+		var err error
+		if strings.Contains(c.ContentType(), "yaml") {
+			err = c.ShouldBindYAML(&policies)
 		} else {
-			c.JSON(503, gin.H{"status": "Not Ready"})
+			err = c.ShouldBindJSON(&policies)
+		}
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+		result, err := notifier.ImportPolicies(c.Request.Context(), policies, notificationRouter, silences, cfg.NotifierNames(), dryRun)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(200, result)
 	})
 
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{"message": "Kubernetes Resource Watcher is running"})
+	// Recorded event history, e.g. /api/v1/events?namespace=prod&since=24h
+	router.GET("/api/v1/events", eventHistoryHandler(resourceWatcher.GetEventStore()))
+
+	// Every change from now on as server-sent events
+	router.GET("/api/v1/events/stream", eventStreamHandler(stream))
+
+	// Send recorded events again through one notifier, e.g. to try a new webhook on history
+	router.POST("/api/v1/events/replay", eventReplayHandler(resourceWatcher.GetEventStore(), notificationRouter))
+
+	// Archived versions of an object, e.g. /api/v1/archive/versions?kind=ConfigMap&namespace=prod&name=app-config,
+	// and the manifest of one to apply again with &version=<id> or &version=latest
+	router.GET("/api/v1/archive/versions", archiveVersionsHandler(watchers))
+	router.GET("/api/v1/archive/manifest", archiveManifestHandler(watchers))
+
+	// Loaded configuration, with passwords, secrets and webhook URLs redacted
+	router.GET("/api/config", func(c *gin.Context) {
+		redacted, err := cfg.Redacted()
+		if err == nil {
+			var document map[string]interface{}
+			if document, err = redacted.Document(); err == nil {
+				c.JSON(200, document)
+				return
+			}
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
 	})
 
+	// Differences between the config file on disk and the loaded config (both redacted)
+	router.GET("/api/config/diff", func(c *gin.Context) {
+		onDisk, err := readConfig(*configFile, false)
+		if err != nil {
+			c.JSON(422, gin.H{"error": "config file on disk is not loadable: " + err.Error()})
+			return
+		}
+		diffs, err := config.DiffConfigs(cfg, onDisk)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"path": *configFile, "changed": len(diffs) > 0, "differences": diffs})
+	})
+
+	for _, probeRouter := range probeRouters {
+		probeRouter.GET("/", func(c *gin.Context) {
+			c.JSON(200, gin.H{"message": "Kubernetes Resource Watcher is running"})
+		})
+	}
+
+	server := &http.Server{
+		Addr:         cfg.Server.ListenAddress(cfg.Server.GetPort()),
+		Handler:      public,
+		TLSConfig:    tlsConfig,
+		ReadTimeout:  cfg.Server.GetReadTimeout(),
+		WriteTimeout: cfg.Server.GetWriteTimeout(),
+	}
 	go func() {
-		log.Printf("Starting health check server on port 8080")
-		if err := router.Run(":8080"); err != nil {
-			log.Printf("Health check server error: %v", err)
+		defer exitcode.Recover()
+		slog.Info("Starting health check server", "address", server.Addr, "tls", tlsConfig != nil)
+		if err := listenAndServe(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Health check server error", "error", err)
 		}
 	}()
 
+	// Stop gets here after in-flight notifications have drained and before the informers stop
+	resourceWatcher.OnStopping("http-server", server.Shutdown)
+
+	if admin.Enabled {
+		adminServer := &http.Server{
+			Addr:         cfg.Server.ListenAddress(admin.GetPort()),
+			Handler:      router,
+			TLSConfig:    adminTLS,
+			ReadTimeout:  cfg.Server.GetReadTimeout(),
+			WriteTimeout: cfg.Server.GetWriteTimeout(),
+		}
+		adminServer.RegisterOnShutdown(stream.Close)
+		go func() {
+			defer exitcode.Recover()
+			slog.Info("Starting admin server", "address", adminServer.Addr, "tls", adminTLS != nil,
+				"bearerToken", admin.GetBearerToken() != "", "clientCertificates", adminTLS != nil && adminTLS.ClientCAs != nil)
+			if err := listenAndServe(adminServer); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Admin server error", "error", err)
+			}
+		}()
+		resourceWatcher.OnStopping("admin-server", adminServer.Shutdown)
+	} else {
+		server.RegisterOnShutdown(stream.Close)
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	sig := <-sigChan
-	log.Printf("Received shutdown signal: %v", sig)
-
-	log.Printf("Shutting down resource watcher...")
+	slog.Info("Received shutdown signal; shutting down resource watcher", "signal", sig.String())
+	// The first watcher runs the stopping hooks and closes the event store, so it stops last
+	for _, clusterWatcher := range watchers[1:] {
+		clusterWatcher.Stop()
+	}
 	resourceWatcher.Stop()
 
-	log.Printf("Resource watcher shutdown complete")
+	slog.Info("Resource watcher shutdown complete")
+	exitcode.Exit(exitcode.OK, nil)
+}
+
+// selectWatcher returns the watcher of the cluster named by the cluster query
+// parameter, by default the first, and responds 404 for an unknown cluster
+func selectWatcher(c *gin.Context, watchers []*watcher.InformerWatcher) (*watcher.InformerWatcher, bool) {
+	name, ok := c.GetQuery("cluster")
+	if !ok {
+		return watchers[0], true
+	}
+	for _, clusterWatcher := range watchers {
+		if clusterWatcher.ClusterName() == name {
+			return clusterWatcher, true
+		}
+	}
+	c.JSON(404, gin.H{"error": fmt.Sprintf("unknown cluster %q", name)})
+	return nil, false
 }
 
 func loadConfig(configPath string) (*config.Config, error) {
+	cfg, err := readConfig(configPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Log with the configured level and format from here on; invalid settings fall back to info and text
+	loggingErr := cfg.LoadLoggingConfig()
+	logging.Setup(cfg.Logging)
+	if loggingErr != nil {
+		slog.Warn("Failed to load logging config", "error", loggingErr)
+	}
+
+	for _, warning := range cfg.Lint() {
+		slog.Warn("Config warning: " + warning)
+	}
+	return cfg, nil
+}
+
+// streamKeepAlive is how often an idle event stream gets a comment line, so proxies keep it open
+const streamKeepAlive = 30 * time.Second
+
+// eventStreamHandler sends each event published to the stream as a "change" server-sent event
+func eventStreamHandler(stream *notifier.EventStream) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events, unsubscribe := stream.Subscribe()
+		defer unsubscribe()
+		keepAlive := time.NewTicker(streamKeepAlive)
+		defer keepAlive.Stop()
+
+		// The stream outlives the server's write timeout
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			slog.Debug("Failed to clear the event stream's write deadline", "error", err)
+		}
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case event, ok := <-events:
+				if ok {
+					c.SSEvent("change", event)
+				}
+				return ok
+			case <-keepAlive.C:
+				_, err := io.WriteString(w, ": keep-alive\n\n")
+				return err == nil
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	}
+}
+
+// Event history page sizes
+const (
+	defaultEventPageSize = 100
+	maxEventPageSize     = 1000
+)
+
+// eventHistoryHandler serves recorded events newest first. Next-page links pin
+// the time range of the first request, so new events do not shift the pages.
+func eventHistoryHandler(eventStore store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if eventStore == nil {
+			c.JSON(404, gin.H{"error": "event store is not enabled (set store.enabled)"})
+			return
+		}
+
+		filter := store.Filter{
+			ID:        c.Query("id"),
+			Kind:      c.Query("kind"),
+			Namespace: c.Query("namespace"),
+			Name:      c.Query("name"),
+			EventType: c.Query("eventType"),
+			Status:    c.Query("status"),
+			Until:     time.Now().UTC(),
+			Limit:     defaultEventPageSize,
+		}
+
+		var err error
+		if since := c.Query("since"); since != "" {
+			if filter.Since, err = parseSince(since); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if until := c.Query("until"); until != "" {
+			if filter.Until, err = time.Parse(time.RFC3339Nano, until); err != nil {
+				c.JSON(400, gin.H{"error": "until must be an RFC 3339 time"})
+				return
+			}
+		}
+		if limit := c.Query("limit"); limit != "" {
+			if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 || filter.Limit > maxEventPageSize {
+				c.JSON(400, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxEventPageSize)})
+				return
+			}
+		}
+		if offset := c.Query("offset"); offset != "" {
+			if filter.Offset, err = strconv.Atoi(offset); err != nil || filter.Offset < 0 {
+				c.JSON(400, gin.H{"error": "offset must be a non-negative integer"})
+				return
+			}
+		}
+
+		// Ask for one extra record to know whether there is a next page
+		pageSize := filter.Limit
+		filter.Limit++
+		records, err := eventStore.Query(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := gin.H{"until": filter.Until.Format(time.RFC3339Nano)}
+		if len(records) > pageSize {
+			records = records[:pageSize]
+			next := c.Request.URL.Query()
+			next.Set("until", filter.Until.Format(time.RFC3339Nano))
+			if !filter.Since.IsZero() {
+				next.Set("since", filter.Since.UTC().Format(time.RFC3339Nano))
+			}
+			next.Set("offset", strconv.Itoa(filter.Offset+pageSize))
+			next.Set("limit", strconv.Itoa(pageSize))
+			response["next"] = c.Request.URL.Path + "?" + next.Encode()
+		}
+		if records == nil {
+			records = []store.Record{}
+		}
+		response["events"] = records
+		response["count"] = len(records)
+		c.JSON(200, response)
+	}
+}
+
+// eventReplayHandler sends the recorded events selected by the request to one notifier,
+// oldest first, and responds with the outcome of each once all are sent
+func eventReplayHandler(eventStore store.Store, notificationRouter *notifier.Router) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if eventStore == nil {
+			c.JSON(404, gin.H{"error": "event store is not enabled (set store.enabled)"})
+			return
+		}
+		var request struct {
+			Notifier  string `json:"notifier" binding:"required"`
+			ID        string `json:"id"`
+			Kind      string `json:"kind"`
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+			EventType string `json:"eventType"`
+			Status    string `json:"status"`
+			Since     string `json:"since"`
+			Until     string `json:"until"`
+			Limit     int    `json:"limit"`
+			DryRun    bool   `json:"dryRun"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		filter := store.Filter{
+			ID:        request.ID,
+			Kind:      request.Kind,
+			Namespace: request.Namespace,
+			Name:      request.Name,
+			EventType: request.EventType,
+			Status:    request.Status,
+			Limit:     request.Limit,
+		}
+		var err error
+		if request.Since != "" {
+			if filter.Since, err = parseSince(request.Since); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if request.Until != "" {
+			if filter.Until, err = parseSince(request.Until); err != nil {
+				c.JSON(400, gin.H{"error": "until: " + err.Error()})
+				return
+			}
+		}
+		if filter.Limit == 0 {
+			filter.Limit = defaultEventPageSize
+		}
+		if filter.Limit < 1 || filter.Limit > maxEventPageSize {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxEventPageSize)})
+			return
+		}
+
+		// One extra record tells whether the range holds more events than are replayed
+		pageSize := filter.Limit
+		filter.Limit++
+		records, err := eventStore.Query(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		truncated := len(records) > pageSize
+		if truncated {
+			records = records[:pageSize]
+		}
+		slices.Reverse(records)
+
+		// Sending many events can take longer than the server's write timeout
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			slog.Debug("Failed to clear the replay's write deadline", "error", err)
+		}
+		results, err := notificationRouter.Replay(c.Request.Context(), request.Notifier, records, request.DryRun)
+		if errors.Is(err, notifier.ErrUnknownNotifier) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		failed := 0
+		for _, result := range results {
+			if result.Error != "" {
+				failed++
+			}
+		}
+		response := gin.H{
+			"notifier":  request.Notifier,
+			"dryRun":    request.DryRun,
+			"events":    results,
+			"count":     len(results),
+			"failed":    failed,
+			"truncated": truncated,
+		}
+		if err != nil {
+			response["error"] = err.Error()
+		}
+		c.JSON(200, response)
+	}
+}
+
+// archivedVersions lists the archived versions of the object named by the kind, namespace and
+// name query parameters in the selected cluster, responding with the error when it cannot
+func archivedVersions(c *gin.Context, watchers []*watcher.InformerWatcher) (archive.Archive, []archive.Version, bool) {
+	clusterWatcher, ok := selectWatcher(c, watchers)
+	if !ok {
+		return nil, nil, false
+	}
+	manifests := clusterWatcher.GetArchive()
+	if manifests == nil {
+		c.JSON(404, gin.H{"error": "manifest archive is not enabled (set archive.enabled)"})
+		return nil, nil, false
+	}
+	versions, err := archive.Versions(c.Request.Context(), manifests, clusterWatcher.ClusterName(),
+		c.Query("namespace"), c.Query("kind"), c.Query("name"))
+	if errors.Is(err, archive.ErrInvalidObject) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	return manifests, versions, true
+}
+
+// archiveVersionsHandler serves the archived versions of an object, newest first
+func archiveVersionsHandler(watchers []*watcher.InformerWatcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, versions, ok := archivedVersions(c, watchers)
+		if !ok {
+			return
+		}
+		c.JSON(200, gin.H{"versions": versions, "count": len(versions)})
+	}
+}
+
+// archiveManifestHandler serves an archived manifest as YAML for kubectl apply. Secrets
+// archived without their values are refused, as applying them would empty the live Secret.
+func archiveManifestHandler(watchers []*watcher.InformerWatcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Query("version")
+		if id == "" {
+			c.JSON(400, gin.H{"error": "version is required: an ID from /api/v1/archive/versions, or latest"})
+			return
+		}
+		manifests, versions, ok := archivedVersions(c, watchers)
+		if !ok {
+			return
+		}
+		version, ok := archive.Find(versions, id)
+		if !ok {
+			c.JSON(404, gin.H{"error": fmt.Sprintf("no archived version %q", id)})
+			return
+		}
+		manifest, err := manifests.Get(c.Request.Context(), version.Key)
+		if errors.Is(err, archive.ErrNotFound) {
+			c.JSON(404, gin.H{"error": fmt.Sprintf("no archived version %q", id)})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if archive.RedactedSecret(manifest) {
+			c.JSON(409, gin.H{"error": "the Secret was archived without its values (set archive.secretValues to restore Secrets)"})
+			return
+		}
+		c.Header("X-Archive-Version", version.ID)
+		c.Data(200, "application/yaml", manifest)
+	}
+}
+
+// parseSince accepts a duration back from now ("24h", "7d") or an RFC 3339 time
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("since must be a duration such as 24h or 7d, or an RFC 3339 time")
+	}
+	return time.Now().Add(-duration), nil
+}
+
+// readConfig parses, validates and applies environment overrides to the config file at configPath.
+// Lenient accepts configs written for a newer schema version, leaving the decision to the compatibility gate.
+func readConfig(configPath string, lenient bool) (*config.Config, error) {
 	configData, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %v", err)
 	}
 
-	var cfg config.Config
-	if err := yaml.Unmarshal(configData, &cfg); err != nil {
+	parse := config.Parse
+	if lenient {
+		parse = config.ParseLenient
+	}
+	cfg, err := parse(configData)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %v", err)
 	}
 
@@ -114,6 +1023,10 @@ func loadConfig(configPath string) (*config.Config, error) {
 		return nil, fmt.Errorf("failed to load email config: %v", err)
 	}
 
+	if err := cfg.LoadServerConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load server config: %v", err)
+	}
+
 	if clusterName := os.Getenv("CLUSTER_NAME"); clusterName != "" {
 		cfg.ClusterName = clusterName
 	}
@@ -122,5 +1035,27 @@ func loadConfig(configPath string) (*config.Config, error) {
 		return nil, fmt.Errorf("cluster name must be set either in config.yaml or CLUSTER_NAME environment variable")
 	}
 
-	return &cfg, nil
+	return cfg, nil
+}
+
+// runMigrateConfig prints the given config file converted to the current schema version
+func runMigrateConfig(args []string) int {
+	flags := flag.NewFlagSet("migrate-config", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "Path to the configuration file to migrate")
+	flags.Parse(args)
+
+	configData, err := os.ReadFile(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read config file: %v\n", err)
+		return 1
+	}
+
+	migrated, err := config.MigrateToV2(configData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to migrate config: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(string(migrated))
+	return 0
 }
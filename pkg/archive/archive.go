@@ -0,0 +1,222 @@
+// Package archive keeps the manifest of every version of the watched objects in a
+// directory or in an S3-compatible or Google Cloud Storage bucket, so the earlier state of
+// an object can be recovered after someone overwrote or deleted it.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// ErrNotFound is returned by Get for a key without a manifest
+var ErrNotFound = errors.New("manifest not found")
+
+// Archive stores manifests under slash-separated keys
+type Archive interface {
+	Put(ctx context.Context, key string, manifest []byte) error
+
+	// List returns the keys starting with prefix, in lexical order
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Get returns the manifest stored under a key, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Location describes where manifests go, e.g. s3://bucket/prefix, for logs
+	Location() string
+}
+
+// New creates the archive of a validated config; nothing is written until Put
+func New(cfg config.ArchiveConfig) Archive {
+	prefix := strings.Trim(cfg.Prefix, "/")
+	switch cfg.GetDriver() {
+	case config.ArchiveDriverS3:
+		return newS3Archive(cfg, prefix)
+	case config.ArchiveDriverGCS:
+		return newGCSArchive(cfg, prefix)
+	}
+	return &fileArchive{dir: filepath.Join(cfg.Path, filepath.FromSlash(prefix))}
+}
+
+// clusterScopedDir stands in for the namespace of cluster-scoped objects in keys
+const clusterScopedDir = "_cluster"
+
+// Key returns the key of an object version, e.g.
+// production/payments/ConfigMap/app-config/20240507T091244.123Z-modified.yaml
+func Key(cluster, namespace, kind, name string, at time.Time, eventType string) string {
+	file := at.UTC().Format(versionTimeFormat) + "-" + strings.ToLower(eventType) + manifestExt
+	return path.Join(ObjectPrefix(cluster, namespace, kind, name), file)
+}
+
+// ObjectPrefix returns the directory of an object's versions in keys
+func ObjectPrefix(cluster, namespace, kind, name string) string {
+	if namespace == "" {
+		namespace = clusterScopedDir
+	}
+	return path.Join(cluster, namespace, kind, name)
+}
+
+// serverMetadata are the metadata fields the API server sets, which an object applied
+// from the archive must not carry
+var serverMetadata = []string{
+	"managedFields", "uid", "resourceVersion", "generation", "creationTimestamp",
+	"deletionTimestamp", "deletionGracePeriodSeconds", "selfLink",
+}
+
+// lastAppliedAnnotation repeats the object as kubectl last applied it, Secret data included
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Manifest renders an object as YAML that can be applied again: without status and the
+// metadata the API server sets, and, unless secretValues is set, with empty Secret values.
+// The object is not modified.
+func Manifest(object map[string]interface{}, secretValues bool) ([]byte, error) {
+	sanitized := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		sanitized[key] = value
+	}
+	delete(sanitized, "status")
+
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		copied := make(map[string]interface{}, len(metadata))
+		for key, value := range metadata {
+			copied[key] = value
+		}
+		for _, field := range serverMetadata {
+			delete(copied, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			kept := make(map[string]interface{}, len(annotations))
+			for key, value := range annotations {
+				if key != lastAppliedAnnotation {
+					kept[key] = value
+				}
+			}
+			if len(kept) > 0 {
+				copied["annotations"] = kept
+			} else {
+				delete(copied, "annotations")
+			}
+		}
+		sanitized["metadata"] = copied
+	}
+
+	if kind, _ := object["kind"].(string); kind == "Secret" && !secretValues {
+		for _, field := range []string{"data", "stringData"} {
+			if values, ok := object[field].(map[string]interface{}); ok {
+				emptied := make(map[string]interface{}, len(values))
+				for key := range values {
+					emptied[key] = ""
+				}
+				sanitized[field] = emptied
+			}
+		}
+	}
+
+	// apiVersion, kind and metadata lead, as in kubectl output
+	ordered := yaml.MapSlice{}
+	for _, key := range []string{"apiVersion", "kind", "metadata"} {
+		if value, ok := sanitized[key]; ok {
+			ordered = append(ordered, yaml.MapItem{Key: key, Value: value})
+			delete(sanitized, key)
+		}
+	}
+	rest := make([]string, 0, len(sanitized))
+	for key := range sanitized {
+		rest = append(rest, key)
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		ordered = append(ordered, yaml.MapItem{Key: key, Value: sanitized[key]})
+	}
+
+	data, err := yaml.Marshal(ordered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return data, nil
+}
+
+// fileArchive writes manifests below a directory, e.g. on a PersistentVolume
+type fileArchive struct {
+	dir string
+}
+
+// Put writes the manifest to a temporary file first, so readers never see a partial one
+func (a *fileArchive) Put(ctx context.Context, key string, manifest []byte) error {
+	target := filepath.Join(a.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".manifest-*")
+	if err != nil {
+		return fmt.Errorf("failed to create manifest file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(manifest); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// List walks the directory below the prefix, skipping the temporary files of Put
+func (a *fileArchive) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	root := filepath.Join(a.dir, filepath.FromSlash(path.Dir(prefix)))
+	err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".manifest-") {
+			return nil
+		}
+		rel, err := filepath.Rel(a.dir, file)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive directory: %w", err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (a *fileArchive) Get(ctx context.Context, key string) ([]byte, error) {
+	manifest, err := os.ReadFile(filepath.Join(a.dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func (a *fileArchive) Location() string {
+	return a.dir
+}
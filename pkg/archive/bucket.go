@@ -0,0 +1,271 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/cloudauth"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+const manifestContentType = "application/yaml"
+
+// s3Archive uploads manifests to an S3 bucket, or a bucket of an S3-compatible server such
+// as MinIO, authenticating with the AWS credentials of the pod
+type s3Archive struct {
+	config      config.ArchiveConfig
+	prefix      string
+	client      *http.Client
+	credentials *cloudauth.AWSCredentialSource
+}
+
+func newS3Archive(cfg config.ArchiveConfig, prefix string) *s3Archive {
+	client := &http.Client{Timeout: cfg.GetTimeout()}
+	return &s3Archive{
+		config:      cfg,
+		prefix:      prefix,
+		client:      client,
+		credentials: cloudauth.NewAWSCredentialSource(cfg.GetRegion(), client),
+	}
+}
+
+// Put uploads the manifest with a signed PutObject request
+func (a *s3Archive) Put(ctx context.Context, key string, manifest []byte) error {
+	endpoint, err := a.bucketURL()
+	if err != nil {
+		return err
+	}
+	_, err = a.send(ctx, http.MethodPut, endpoint+"/"+escapeKey(joinPrefix(a.prefix, key)), manifest)
+	return err
+}
+
+// List pages through ListObjectsV2 results
+func (a *s3Archive) List(ctx context.Context, prefix string) ([]string, error) {
+	endpoint, err := a.bucketURL()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	query := url.Values{"list-type": {"2"}, "prefix": {joinPrefix(a.prefix, prefix)}}
+	for {
+		// The signature covers the query as sent, which Encode sorts
+		body, err := a.send(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("S3: bucket %s not found", a.config.Bucket)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("S3: failed to parse object list: %w", err)
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, trimPrefix(a.prefix, object.Key))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (a *s3Archive) Get(ctx context.Context, key string) ([]byte, error) {
+	endpoint, err := a.bucketURL()
+	if err != nil {
+		return nil, err
+	}
+	return a.send(ctx, http.MethodGet, endpoint+"/"+escapeKey(joinPrefix(a.prefix, key)), nil)
+}
+
+// send signs and sends a request to the bucket
+func (a *s3Archive) send(ctx context.Context, method, endpoint string, body []byte) ([]byte, error) {
+	credentials, err := a.credentials.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("S3: %w", err)
+	}
+	headers, err := cloudauth.SignAWS(credentials, "s3", a.config.GetRegion(), method, endpoint, body, manifestContentType)
+	if err != nil {
+		return nil, fmt.Errorf("S3: %w", err)
+	}
+	return request(ctx, a.client, method, endpoint, body, headers, "S3")
+}
+
+// bucketURL addresses the bucket in its host name, or in the path with pathStyle
+func (a *s3Archive) bucketURL() (string, error) {
+	endpoint, err := url.Parse(a.config.GetEndpoint())
+	if err != nil {
+		return "", fmt.Errorf("S3: invalid endpoint: %w", err)
+	}
+	if a.config.PathStyle {
+		return a.config.GetEndpoint() + "/" + url.PathEscape(a.config.Bucket), nil
+	}
+	return fmt.Sprintf("%s://%s.%s%s", endpoint.Scheme, a.config.Bucket, endpoint.Host, strings.TrimRight(endpoint.Path, "/")), nil
+}
+
+func (a *s3Archive) Location() string {
+	return "s3://" + path.Join(a.config.Bucket, a.prefix)
+}
+
+// gcsArchive uploads manifests to a Google Cloud Storage bucket with the access tokens of
+// the pod's Google service account
+type gcsArchive struct {
+	config config.ArchiveConfig
+	prefix string
+	client *http.Client
+	tokens *cloudauth.MetadataTokenSource
+}
+
+func newGCSArchive(cfg config.ArchiveConfig, prefix string) *gcsArchive {
+	client := &http.Client{Timeout: cfg.GetTimeout()}
+	return &gcsArchive{
+		config: cfg,
+		prefix: prefix,
+		client: client,
+		tokens: cloudauth.NewMetadataTokenSource(client),
+	}
+}
+
+// Put uploads the manifest with a JSON API media upload
+func (a *gcsArchive) Put(ctx context.Context, key string, manifest []byte) error {
+	token, err := a.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("GCS: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", a.config.GetEndpoint(),
+		url.PathEscape(a.config.Bucket), url.QueryEscape(joinPrefix(a.prefix, key)))
+	headers := map[string]string{"Authorization": "Bearer " + token, "Content-Type": manifestContentType}
+	_, err = a.send(ctx, http.MethodPost, endpoint, manifest, headers)
+	return err
+}
+
+// List pages through the bucket's objects with the prefix
+func (a *gcsArchive) List(ctx context.Context, prefix string) ([]string, error) {
+	token, err := a.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GCS: %w", err)
+	}
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	var keys []string
+	query := url.Values{"prefix": {joinPrefix(a.prefix, prefix)}, "fields": {"items(name),nextPageToken"}}
+	for {
+		endpoint := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", a.config.GetEndpoint(), url.PathEscape(a.config.Bucket), query.Encode())
+		body, err := a.send(ctx, http.MethodGet, endpoint, nil, headers)
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("GCS: bucket %s not found", a.config.Bucket)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("GCS: failed to parse object list: %w", err)
+		}
+		for _, object := range result.Items {
+			keys = append(keys, trimPrefix(a.prefix, object.Name))
+		}
+		if result.NextPageToken == "" {
+			return keys, nil
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+}
+
+func (a *gcsArchive) Get(ctx context.Context, key string) ([]byte, error) {
+	token, err := a.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GCS: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", a.config.GetEndpoint(),
+		url.PathEscape(a.config.Bucket), url.PathEscape(joinPrefix(a.prefix, key)))
+	return a.send(ctx, http.MethodGet, endpoint, nil, map[string]string{"Authorization": "Bearer " + token})
+}
+
+// send sends a request with the access token in headers
+func (a *gcsArchive) send(ctx context.Context, method, endpoint string, body []byte, headers map[string]string) ([]byte, error) {
+	response, err := request(ctx, a.client, method, endpoint, body, headers, "GCS")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		// Fetch a new token next time, in case this one was revoked
+		a.tokens.Invalidate()
+	}
+	return response, err
+}
+
+func (a *gcsArchive) Location() string {
+	return "gs://" + path.Join(a.config.Bucket, a.prefix)
+}
+
+// request sends a request to a bucket service and returns the response body. It fails on
+// any status but 2xx, with ErrNotFound for 404.
+func request(ctx context.Context, client *http.Client, method, endpoint string, body []byte, headers map[string]string, service string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create request: %w", service, err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s: unexpected status %d: %s", service, resp.StatusCode, bytes.TrimSpace(message))
+	}
+	response, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read response: %w", service, err)
+	}
+	return response, nil
+}
+
+// escapeKey escapes a key for a URL path, keeping its slashes
+func escapeKey(key string) string {
+	return (&url.URL{Path: key}).EscapedPath()
+}
+
+// joinPrefix prepends the configured prefix to a key or key prefix, keeping a trailing slash
+func joinPrefix(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
+
+// trimPrefix removes the configured prefix from a bucket object name
+func trimPrefix(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimPrefix(name, prefix+"/")
+}
@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// versionTimeFormat and manifestExt make up the file names of versions; the times sort
+// lexically in order
+const (
+	versionTimeFormat = "20060102T150405.000Z"
+	manifestExt       = ".yaml"
+)
+
+// LatestVersion selects the newest version in Find
+const LatestVersion = "latest"
+
+// ErrInvalidObject is returned for an object reference that cannot name a key directory
+var ErrInvalidObject = errors.New("invalid object reference")
+
+// Version is an archived manifest of an object
+type Version struct {
+	ID        string    `json:"id"` // File name without .yaml, e.g. 20240507T091244.123Z-modified
+	Time      time.Time `json:"time"`
+	EventType string    `json:"eventType"`
+	Key       string    `json:"key"`
+}
+
+// Versions returns the archived versions of an object, newest first
+func Versions(ctx context.Context, a Archive, cluster, namespace, kind, name string) ([]Version, error) {
+	for _, segment := range []string{cluster, namespace, kind, name} {
+		if segment == "." || segment == ".." || strings.Contains(segment, "/") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidObject, segment)
+		}
+	}
+	if kind == "" || name == "" {
+		return nil, fmt.Errorf("%w: kind and name are required", ErrInvalidObject)
+	}
+
+	keys, err := a.List(ctx, ObjectPrefix(cluster, namespace, kind, name)+"/")
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(keys))
+	for _, key := range keys {
+		if version, ok := parseVersion(key); ok {
+			versions = append(versions, version)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].ID > versions[j].ID })
+	return versions, nil
+}
+
+// parseVersion reads the time and event type from a key's file name
+func parseVersion(key string) (Version, bool) {
+	id, ok := strings.CutSuffix(path.Base(key), manifestExt)
+	if !ok {
+		return Version{}, false
+	}
+	stamp, eventType, ok := strings.Cut(id, "-")
+	if !ok {
+		return Version{}, false
+	}
+	at, err := time.Parse(versionTimeFormat, stamp)
+	if err != nil {
+		return Version{}, false
+	}
+	return Version{ID: id, Time: at, EventType: strings.ToUpper(eventType), Key: key}, true
+}
+
+// Find returns a version by ID, or the newest for LatestVersion, from versions as
+// returned by Versions
+func Find(versions []Version, id string) (Version, bool) {
+	if id == LatestVersion && len(versions) > 0 {
+		return versions[0], true
+	}
+	for _, version := range versions {
+		if version.ID == id {
+			return version, true
+		}
+	}
+	return Version{}, false
+}
+
+// RedactedSecret reports whether a manifest is a Secret archived without its values,
+// which must not be applied over the live Secret
+func RedactedSecret(manifest []byte) bool {
+	var object struct {
+		Kind       string            `yaml:"kind"`
+		Data       map[string]string `yaml:"data"`
+		StringData map[string]string `yaml:"stringData"`
+	}
+	if err := yaml.Unmarshal(manifest, &object); err != nil || object.Kind != "Secret" {
+		return false
+	}
+	if len(object.Data)+len(object.StringData) == 0 {
+		return false
+	}
+	for _, values := range []map[string]string{object.Data, object.StringData} {
+		for _, value := range values {
+			if value != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
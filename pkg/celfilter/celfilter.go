@@ -0,0 +1,146 @@
+// Package celfilter evaluates CEL expressions such as
+// object.metadata.labels["env"] == "prod" && event.type == "DELETED" against resource events.
+//
+// Expressions see three variables:
+//
+//   - event: type, kind, namespace, name, cluster and changedFields of the event
+//   - object: the object as it is now, or as it was when it was deleted
+//   - oldObject: the previous version of a changed object; empty otherwise
+package celfilter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// Event is what the event variable holds
+type Event struct {
+	Type          string
+	Kind          string
+	Namespace     string
+	Name          string
+	Cluster       string
+	ChangedFields []string
+	ChangedBy     string // Field manager of the change, when known
+	Origin        string // argocd, flux or other, when known
+}
+
+// Input is what an expression is evaluated against; nil objects are empty
+type Input struct {
+	Event     Event
+	Object    map[string]interface{}
+	OldObject map[string]interface{}
+}
+
+// Program is a compiled expression
+type Program struct {
+	source  string
+	program cel.Program
+}
+
+var (
+	envOnce sync.Once
+	env     *cel.Env
+	envErr  error
+
+	// programs caches compiled expressions by source; they are safe for concurrent use
+	programs sync.Map
+)
+
+func environment() (*cel.Env, error) {
+	envOnce.Do(func() {
+		env, envErr = cel.NewEnv(
+			cel.Variable("event", cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable("oldObject", cel.MapType(cel.StringType, cel.DynType)),
+			ext.Strings(),
+		)
+	})
+	return env, envErr
+}
+
+// Compile parses and type-checks an expression, which must evaluate to a bool;
+// dynamic fields such as object.spec.paused are checked when evaluated.
+// Programs are cached, so compiling the same expression again is cheap.
+func Compile(expression string) (*Program, error) {
+	if cached, ok := programs.Load(expression); ok {
+		return cached.(*Program), nil
+	}
+
+	env, err := environment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expression, issues.Err())
+	}
+	if !ast.OutputType().IsAssignableType(cel.BoolType) {
+		return nil, fmt.Errorf("invalid expression %q: evaluates to %s, not bool", expression, ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expression, err)
+	}
+
+	compiled := &Program{source: expression, program: program}
+	programs.Store(expression, compiled)
+	return compiled, nil
+}
+
+// Match evaluates the expression. Reading a missing field or map key is an error;
+// guard such reads with has(), e.g. has(object.metadata.labels) && "env" in object.metadata.labels.
+func (p *Program) Match(input Input) (bool, error) {
+	out, _, err := p.program.Eval(map[string]interface{}{
+		"event": map[string]interface{}{
+			"type":          input.Event.Type,
+			"kind":          input.Event.Kind,
+			"namespace":     input.Event.Namespace,
+			"name":          input.Event.Name,
+			"cluster":       input.Event.Cluster,
+			"changedFields": append([]string{}, input.Event.ChangedFields...),
+			"changedBy":     input.Event.ChangedBy,
+			"origin":        input.Event.Origin,
+		},
+		"object":    orEmpty(input.Object),
+		"oldObject": orEmpty(input.OldObject),
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", p.source, err)
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("%s: evaluated to %v, not a bool", p.source, out.Value())
+	}
+	return matched, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// MetadataObject builds an object holding only kind and metadata, for callers that
+// have an event's labels and annotations but not the object itself
+func MetadataObject(kind, namespace, name string, labels, annotations map[string]string) map[string]interface{} {
+	metadata := map[string]interface{}{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+	return map[string]interface{}{"kind": kind, "metadata": metadata}
+}
+
+func orEmpty(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return map[string]interface{}{}
+	}
+	return object
+}
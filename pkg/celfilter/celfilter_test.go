@@ -0,0 +1,70 @@
+package celfilter
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	input := Input{
+		Event: Event{Type: "MODIFIED", Kind: "Deployment", Namespace: "prod", Name: "api", ChangedFields: []string{"spec.replicas"}, Origin: "argocd"},
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"env": "prod"}},
+			"spec":     map[string]interface{}{"replicas": int64(3)},
+		},
+		OldObject: map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(5)},
+		},
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+		wantErr    bool
+	}{
+		{expression: `event.type == "MODIFIED" && event.kind == "Deployment"`, want: true},
+		{expression: `object.metadata.labels["env"] == "prod"`, want: true},
+		{expression: `object.spec.replicas < oldObject.spec.replicas`, want: true},
+		{expression: `"spec.replicas" in event.changedFields`, want: true},
+		{expression: `event.origin == "flux"`, want: false},
+		{expression: `event.name.startsWith("ap")`, want: true},
+		{expression: `has(object.metadata.annotations) && "team" in object.metadata.annotations`, want: false},
+		{expression: `object.metadata.annotations["team"] == "x"`, wantErr: true}, // Missing key
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			program, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := program.Match(input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expression := range []string{
+		`event.type ==`,
+		`"Deployment"`,   // Not a bool
+		`unknown == "x"`, // Undeclared variable
+		`1 + "x" == 2`,   // No such overload
+	} {
+		if _, err := Compile(expression); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", expression)
+		}
+	}
+}
+
+func TestMatchEmptyObjects(t *testing.T) {
+	program, err := Compile(`!has(oldObject.spec)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched, err := program.Match(Input{Event: Event{Type: "ADDED"}}); err != nil || !matched {
+		t.Errorf("Match() = %v, %v; want nil objects to be empty", matched, err)
+	}
+}
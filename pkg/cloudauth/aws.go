@@ -0,0 +1,219 @@
+// Package cloudauth authenticates requests to AWS and Google Cloud APIs with the credentials
+// pods are given: static keys or IRSA for AWS, Workload Identity for Google Cloud.
+package cloudauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AWSCredentials are the keys AWS requests are signed with
+type AWSCredentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string // Set for temporary credentials
+}
+
+// AWSCredentialSource resolves AWS credentials the way the AWS SDKs do for pods: static
+// keys from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or else IRSA, exchanging the
+// service account token in AWS_WEB_IDENTITY_TOKEN_FILE for temporary credentials of
+// AWS_ROLE_ARN. Temporary credentials are cached until shortly before they expire.
+type AWSCredentialSource struct {
+	region string // Selects the regional STS endpoint
+	client *http.Client
+
+	mu      sync.Mutex
+	cached  AWSCredentials
+	expires time.Time
+}
+
+// NewAWSCredentialSource creates a credential source; region selects the STS endpoint
+func NewAWSCredentialSource(region string, client *http.Client) *AWSCredentialSource {
+	return &AWSCredentialSource{region: region, client: client}
+}
+
+// Credentials returns the static credentials if set, or cached or freshly assumed IRSA ones
+func (s *AWSCredentialSource) Credentials(ctx context.Context) (AWSCredentials, error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey != "" && secretKey != "" {
+		return AWSCredentials{accessKey: accessKey, secretKey: secretKey, sessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+	roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN == "" || tokenFile == "" {
+		return AWSCredentials{}, fmt.Errorf("no AWS credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or use IRSA (AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE)")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached.accessKey != "" && time.Now().Add(refreshMargin).Before(s.expires) {
+		return s.cached, nil
+	}
+	credentials, expires, err := s.assumeRole(ctx, roleARN, tokenFile)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	s.cached, s.expires = credentials, expires
+	return credentials, nil
+}
+
+// assumeRoleResponse is the part of an STS AssumeRoleWithWebIdentity response that is used
+type assumeRoleResponse struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+// assumeRole exchanges the projected service account token for temporary role credentials.
+// The request is authenticated by the token itself and is not signed.
+func (s *AWSCredentialSource) assumeRole(ctx context.Context, roleARN, tokenFile string) (AWSCredentials, time.Time, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = "k8s-resource-watcher"
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+
+	// AWS_ENDPOINT_URL_STS is the SDKs' override, e.g. for an STS VPC endpoint
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_STS")
+	if endpoint == "" {
+		endpoint = "https://sts.amazonaws.com"
+		if s.region != "" {
+			endpoint = "https://sts." + s.region + ".amazonaws.com"
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("failed to create STS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("STS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("failed to read STS response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(body) > 512 {
+			body = body[:512]
+		}
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("STS AssumeRoleWithWebIdentity for %s: unexpected status %d: %s", roleARN, resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var result assumeRoleResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("invalid STS response: %w", err)
+	}
+	if result.Credentials.AccessKeyID == "" {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("STS response has no credentials")
+	}
+	return AWSCredentials{
+		accessKey:    result.Credentials.AccessKeyID,
+		secretKey:    result.Credentials.SecretAccessKey,
+		sessionToken: result.Credentials.SessionToken,
+	}, result.Credentials.Expiration, nil
+}
+
+// SignAWS returns the headers authenticating a request to service in region with
+// AWS Signature Version 4. contentType is signed along when the request has a body.
+func SignAWS(credentials AWSCredentials, service, region, method, endpoint string, payload []byte, contentType string) (map[string]string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	payloadHash := SHA256Hex(payload)
+
+	headers := map[string]string{
+		"host":                 parsed.Host,
+		"x-amz-date":           amzDate,
+		"x-amz-content-sha256": payloadHash,
+	}
+	if payload != nil {
+		headers["content-type"] = contentType
+	}
+	if credentials.sessionToken != "" {
+		headers["x-amz-security-token"] = credentials.sessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		parsed.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, SHA256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.secretKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	// The Host header is set by the HTTP client from the URL
+	delete(headers, "host")
+	headers["Authorization"] = fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.accessKey, scope, signedHeaders, signature)
+	return headers, nil
+}
+
+// SHA256Hex returns the lowercase hex SHA-256 digest of data
+func SHA256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
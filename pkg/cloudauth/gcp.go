@@ -0,0 +1,83 @@
+package cloudauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// refreshMargin renews credentials this long before they expire, so they never run out
+// while a request is in flight
+const refreshMargin = 30 * time.Second
+
+// MetadataTokenSource fetches access tokens of the pod's Google service account from the
+// metadata server, which GKE Workload Identity serves for the Kubernetes service account,
+// and caches them until shortly before they expire. GCE_METADATA_HOST overrides the server.
+type MetadataTokenSource struct {
+	client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewMetadataTokenSource creates a token source; tokens are fetched on first use
+func NewMetadataTokenSource(client *http.Client) *MetadataTokenSource {
+	return &MetadataTokenSource{client: client}
+}
+
+// Token returns a cached access token, requesting a new one when it is missing or about to expire
+func (s *MetadataTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Add(refreshMargin).Before(s.expires) {
+		return s.token, nil
+	}
+
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	endpoint := "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("metadata server token request failed (is Workload Identity enabled?): %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("metadata server token request: unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access_token")
+	}
+	s.token = token.AccessToken
+	s.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// Invalidate drops the cached token
+func (s *MetadataTokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
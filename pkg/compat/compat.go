@@ -0,0 +1,104 @@
+// Package compat checks at startup that the config and persisted state were
+// not written for a newer release than the running binary, which happens
+// when a fleet is upgraded in stages or an upgrade is rolled back.
+package compat
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/store"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/version"
+)
+
+// Report lists the incompatibilities between the binary and its config or persisted state
+type Report struct {
+	BinaryVersion  string   `json:"binaryVersion"`
+	ConfigVersion  int      `json:"configVersion"`
+	StateVersion   int      `json:"stateVersion,omitempty"`
+	StateWrittenBy string   `json:"stateWrittenBy,omitempty"`
+	Problems       []string `json:"problems,omitempty"`
+	SafeMode       bool     `json:"safeMode"`
+
+	stateIncompatible bool
+}
+
+// Check compares the config schema version and the persisted state version with
+// the versions this binary supports
+func Check(cfg *config.Config) Report {
+	report := Report{
+		BinaryVersion: version.Version,
+		ConfigVersion: cfg.SchemaVersion,
+	}
+
+	if cfg.SchemaVersion > config.CurrentVersion {
+		report.Problems = append(report.Problems, fmt.Sprintf(
+			"config schema version %d is newer than the newest this binary supports (%d)",
+			cfg.SchemaVersion, config.CurrentVersion))
+	}
+
+	if cfg.Store.Enabled {
+		info, err := store.ReadStateInfo(cfg.Store)
+		switch {
+		case err != nil:
+			report.stateIncompatible = true
+			report.Problems = append(report.Problems, fmt.Sprintf("persisted state version is unreadable: %v", err))
+		case info.StateVersion > store.StateVersion:
+			report.StateVersion, report.StateWrittenBy = info.StateVersion, info.BinaryVersion
+			report.stateIncompatible = true
+			report.Problems = append(report.Problems, fmt.Sprintf(
+				"persisted state version %d (written by %s) is newer than the newest this binary supports (%d)",
+				info.StateVersion, describeBinary(info.BinaryVersion), store.StateVersion))
+		default:
+			report.StateVersion, report.StateWrittenBy = info.StateVersion, info.BinaryVersion
+		}
+	}
+
+	return report
+}
+
+// Compatible reports whether the binary can run with its config and state as they are
+func (r *Report) Compatible() bool {
+	return len(r.Problems) == 0
+}
+
+// EnterSafeMode adjusts cfg so nothing this binary does not understand is
+// written: the event store is disabled when its state is incompatible
+func (r *Report) EnterSafeMode(cfg *config.Config) {
+	r.SafeMode = true
+	if r.stateIncompatible {
+		slog.Warn("Safe mode: event store disabled to leave the persisted state untouched")
+		cfg.Store.Enabled = false
+	}
+}
+
+// Alert returns the self-alert describing the incompatibilities
+func (r *Report) Alert() notifier.NotificationEvent {
+	action := "The watcher refused to start."
+	if r.SafeMode {
+		action = "The watcher is running in safe mode: newer config keys are ignored and newer persisted state is not written."
+	}
+	summary := []string{fmt.Sprintf("Binary %s is incompatible with its config or persisted state. %s",
+		describeBinary(r.BinaryVersion), action)}
+	for _, problem := range r.Problems {
+		summary = append(summary, "- "+problem)
+	}
+	summary = append(summary, "Upgrade the binary, or restore a config and state written for this release.")
+
+	return notifier.NotificationEvent{
+		EventType:    notifier.EventSelfAlert,
+		Severity:     config.SeverityCritical,
+		ResourceKind: "Watcher",
+		ResourceName: "compatibility",
+		Summary:      summary,
+	}
+}
+
+func describeBinary(binaryVersion string) string {
+	if binaryVersion == "" {
+		return "an unknown version"
+	}
+	return "version " + binaryVersion
+}
@@ -2,9 +2,19 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/celfilter"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/fieldpath"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/templates"
 )
 
 type WatcherConfig struct {
@@ -13,241 +23,2783 @@ type WatcherConfig struct {
 	EventDeduplicationWindow  time.Duration `yaml:"eventDeduplicationWindow,omitempty"`
 	ResourceVersionCheck      bool          `yaml:"resourceVersionCheck,omitempty"`
 	MetricsEnabled            bool          `yaml:"metricsEnabled,omitempty"`
+
+	// Field paths whose changes never trigger MODIFIED notifications, keyed by
+	// kind ("*" applies to every kind), e.g. Service: ["status.*"]
+	IgnoreFields map[string][]string `yaml:"ignoreFields,omitempty"`
+
+	// Field paths that make a MODIFIED event notification-worthy, keyed by kind.
+	// Kinds without an entry notify on any change, except Deployments, which
+	// fall back to deploymentImportantFields.
+	SignificantFields map[string][]string `yaml:"significantFields,omitempty"`
+
+	// Skip objects generated by controllers, such as Helm release Secrets
+	Ownership OwnershipConfig `yaml:"ownership,omitempty"`
+
+	// Run basic semantic checks on added/changed objects and include warnings in notifications
+	ValidateObjects bool `yaml:"validateObjects,omitempty"`
+
+	// List the workloads referencing a changed or deleted ConfigMap or Secret in its notification
+	BlastRadius bool `yaml:"blastRadius,omitempty"`
+
+	// Follow Namespaces for deletion summaries and silence-until annotations. By default only
+	// when something else is watched cluster-wide, since it needs to list and watch namespaces.
+	NamespaceTracking *bool `yaml:"namespaceTracking,omitempty"`
+
+	// Key-level diff in ConfigMap MODIFIED notifications
+	ConfigMapDiff ConfigMapDiffConfig `yaml:"configMapDiff,omitempty"`
+
+	// Values diff in Helm release notifications
+	HelmValuesDiff ConfigMapDiffConfig `yaml:"helmValuesDiff,omitempty"`
+
+	// Add the node and zone of the affected pods to Pod and workload notifications
+	Topology TopologyConfig `yaml:"topology,omitempty"`
+
+	// Compare notified objects with their manifests in a Git repository and report drift
+	GitOps GitOpsConfig `yaml:"gitops,omitempty"`
+
+	// Thresholds for Pod crash-loop, OOMKill and image pull alerts
+	PodAlerts PodAlertsConfig `yaml:"podAlerts,omitempty"`
+
+	// Expiry and failed renewal alerts of watched cert-manager Certificates
+	CertificateAlerts CertificateAlertsConfig `yaml:"certificateAlerts,omitempty"`
+
+	// Severity of Ingress, HTTPRoute and Gateway changes that stop serving a host
+	IngressAlerts IngressAlertsConfig `yaml:"ingressAlerts,omitempty"`
+
+	// Notification throttling configuration
+	RateLimit RateLimitConfig `yaml:"rateLimit,omitempty"`
+
+	// Global notification budget across all resources and notifiers
+	BurstProtection BurstProtectionConfig `yaml:"burstProtection,omitempty"`
+
+	// ANOMALY notifications when a kind's change rate in a namespace spikes
+	AnomalyDetection AnomalyDetectionConfig `yaml:"anomalyDetection,omitempty"`
+
+	// When /readyz reports the watcher as ready
+	Readiness ReadinessConfig `yaml:"readiness,omitempty"`
+
+	// Public read-only status page at /statusz
+	StatusPage StatusPageConfig `yaml:"statusPage,omitempty"`
+
+	// One-time report of the objects each resource entry matches once the caches have synced
+	Baseline BaselineConfig `yaml:"baseline,omitempty"`
+
+	// Scheduled summaries of the watched objects and their changes, e.g. a daily digest
+	Reports []ReportConfig `yaml:"reports,omitempty"`
+
+	// Silences created through /api/v1/silences
+	Silences SilencesConfig `yaml:"silences,omitempty"`
+
+	// Deployment markers pushed to /api/markers
+	Markers MarkersConfig `yaml:"markers,omitempty"`
+
+	// URL this watcher's API is reachable at, linked from notifications cut to fit a size limit
+	DashboardURL string `yaml:"dashboardURL,omitempty"`
+
+	// Number of recent event processing traces kept for /api/events/{id}/trace
+	TraceBufferSize int `yaml:"traceBufferSize,omitempty"`
+
+	// Watch engine configuration
+	Engine               string        `yaml:"engine,omitempty"`               // Engine serving every kind: informer (default) or raw-watch
+	CacheSyncTimeout     time.Duration `yaml:"cacheSyncTimeout,omitempty"`     // Max time to wait for informer caches (default: 2m)
+	DisableWatchFallback bool          `yaml:"disableWatchFallback,omitempty"` // Fail startup instead of falling back to raw watches
+	ResyncPeriod         time.Duration `yaml:"resyncPeriod,omitempty"`         // How often informers replay their cache, only counted in metrics (default: 0, off)
+
+	// What startup does when RBAC denies listing or watching a configured kind: fail (default), warn or off
+	PermissionCheck string `yaml:"permissionCheck,omitempty"`
+
+	// Max time shutdown waits for notifications already being sent (default: 30s)
+	DrainTimeout time.Duration `yaml:"drainTimeout,omitempty"`
+
+	// Queues between event handling and the notifiers, history and other consumers
+	EventBus EventBusConfig `yaml:"eventBus,omitempty"`
+}
+
+// OwnershipConfig skips objects by their owner references or, for Secrets, their type
+type OwnershipConfig struct {
+	// Owner reference kinds whose objects are skipped, keyed by watched kind ("*" applies to
+	// every kind), e.g. ConfigMap: ["Prometheus"]; "*" in a list matches any owner
+	SkipOwnerKinds map[string][]string `yaml:"skipOwnerKinds,omitempty"`
+
+	// Secret types that are skipped, e.g. helm.sh/release.v1
+	SkipSecretTypes []string `yaml:"skipSecretTypes,omitempty"`
+}
+
+// GetSkipOwnerKinds returns the skipped owner kinds for kind, including those configured for every kind
+func (o *OwnershipConfig) GetSkipOwnerKinds(kind string) []string {
+	return append(append([]string(nil), o.SkipOwnerKinds["*"]...), o.SkipOwnerKinds[kind]...)
+}
+
+// RateLimitConfig limits how many notifications a single resource can generate
+type RateLimitConfig struct {
+	Enabled          bool          `yaml:"enabled,omitempty"`
+	MaxNotifications int           `yaml:"maxNotifications,omitempty"` // Notifications allowed per resource per period (default: 5)
+	Period           time.Duration `yaml:"period,omitempty"`           // Refill period for the per-resource budget (default: 10m)
+}
+
+// TopologyConfig enriches Pod and workload notifications with node placement
+type TopologyConfig struct {
+	Enabled bool     `yaml:"enabled,omitempty"`
+	Labels  []string `yaml:"labels,omitempty"` // Node labels shown (default: topology.kubernetes.io/zone and region)
+}
+
+// GitOpsConfig points at the Git repository holding the manifests of the watched objects.
+// The repository is cloned with the git command and pulled every interval.
+type GitOpsConfig struct {
+	Enabled          bool          `yaml:"enabled,omitempty"`
+	URL              string        `yaml:"url,omitempty"`              // HTTPS or SSH clone URL
+	Branch           string        `yaml:"branch,omitempty"`           // Default: main
+	Path             string        `yaml:"path,omitempty"`             // Directory of the manifests within the repository (default: all of it)
+	DefaultNamespace string        `yaml:"defaultNamespace,omitempty"` // Namespace of namespaced manifests that set none (default: default)
+	Interval         time.Duration `yaml:"interval,omitempty"`         // How often the repository is pulled (default: 5m)
+	TokenFile        string        `yaml:"tokenFile,omitempty"`        // File holding an HTTPS access token, e.g. from a mounted Secret
+	Username         string        `yaml:"username,omitempty"`         // Username sent with the token (default: x-access-token)
+	CacheDir         string        `yaml:"cacheDir,omitempty"`         // Where the repository is cloned (default: under the temp dir)
+
+	// IgnoreFields are never reported as drift, e.g. spec.replicas of autoscaled Deployments
+	IgnoreFields []string `yaml:"ignoreFields,omitempty"`
+
+	// ReportUntracked notes objects without a manifest in the repository
+	ReportUntracked bool `yaml:"reportUntracked,omitempty"`
+}
+
+// GetBranch returns the branch compared against, defaulting to main
+func (g *GitOpsConfig) GetBranch() string {
+	if g.Branch == "" {
+		return "main"
+	}
+	return g.Branch
+}
+
+// GetDefaultNamespace returns the namespace of manifests that set none
+func (g *GitOpsConfig) GetDefaultNamespace() string {
+	if g.DefaultNamespace == "" {
+		return "default"
+	}
+	return g.DefaultNamespace
+}
+
+// GetInterval returns how often the repository is pulled with a sensible default
+func (g *GitOpsConfig) GetInterval() time.Duration {
+	if g.Interval > 0 {
+		return g.Interval
+	}
+	return 5 * time.Minute
+}
+
+// GetUsername returns the username sent with the access token
+func (g *GitOpsConfig) GetUsername() string {
+	if g.Username == "" {
+		return "x-access-token"
+	}
+	return g.Username
+}
+
+func (g *GitOpsConfig) Validate() error {
+	if !g.Enabled {
+		return nil
+	}
+	if g.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if g.Interval < 0 {
+		return fmt.Errorf("interval cannot be negative")
+	}
+	if path.IsAbs(g.Path) || strings.HasPrefix(path.Clean(g.Path), "..") {
+		return fmt.Errorf("path must be relative to the repository root")
+	}
+	for _, text := range g.IgnoreFields {
+		if _, err := fieldpath.Parse(text); err != nil {
+			return fmt.Errorf("ignoreFields: %v", err)
+		}
+	}
+	return nil
+}
+
+// PodAlertsConfig tunes the alerts sent for watched Pods
+type PodAlertsConfig struct {
+	MinRestarts int `yaml:"minRestarts,omitempty"` // Restarts before CrashLoopBackOff is notified (default: 3)
+}
+
+// CertificateAlertsConfig tunes the alerts sent for watched cert-manager Certificates
+type CertificateAlertsConfig struct {
+	ExpiryThreshold time.Duration `yaml:"expiryThreshold,omitempty"` // Time before notAfter that CERTIFICATE_EXPIRING is sent (default: 336h)
+	CheckInterval   time.Duration `yaml:"checkInterval,omitempty"`   // How often expiry is checked (default: 1h)
+}
+
+// IngressAlertsConfig raises the severity of routing changes that may cause an outage
+type IngressAlertsConfig struct {
+	HostRemovedSeverity string `yaml:"hostRemovedSeverity,omitempty"` // Severity of updates and deletions removing a host; empty leaves it to severity rules
+}
+
+// ReadinessConfig tunes the readiness probe
+type ReadinessConfig struct {
+	SkipNotifierCheck bool          `yaml:"skipNotifierCheck,omitempty"` // Don't wait for a test connection to each notifier
+	DisconnectTimeout time.Duration `yaml:"disconnectTimeout,omitempty"` // How long all watches may fail before going unready (default: 5m)
+}
+
+// StatusPageConfig enables the unauthenticated /statusz page for wall displays
+type StatusPageConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Title   string `yaml:"title,omitempty"` // Page heading (default: the cluster name)
+}
+
+// BaselineConfig sends or records a BASELINE report at startup listing the objects each
+// resource entry matches, to check filters and keep an inventory
+type BaselineConfig struct {
+	Notify     bool `yaml:"notify,omitempty"`     // Send the report through the notifiers
+	Store      bool `yaml:"store,omitempty"`      // Record the report in the event store without notifying it
+	MaxObjects int  `yaml:"maxObjects,omitempty"` // Objects listed per entry, the rest only counted (default: 100)
+}
+
+// GetMaxObjects returns how many objects the report lists per entry with a sensible default
+func (b *BaselineConfig) GetMaxObjects() int {
+	if b.MaxObjects > 0 {
+		return b.MaxObjects
+	}
+	return 100
+}
+
+// ReportConfig sends a REPORT on a cron schedule: the objects each resource entry matches,
+// those that appeared or disappeared, and the notifications sent since the previous report
+type ReportConfig struct {
+	Name       string   `yaml:"name"`
+	Schedule   string   `yaml:"schedule"`             // Cron expression, e.g. "0 8 * * 1-5"; prefix CRON_TZ=Europe/Berlin for a time zone
+	ToEmails   []string `yaml:"toEmails,omitempty"`   // Email recipients instead of the configured ones
+	MaxChanges int      `yaml:"maxChanges,omitempty"` // Changes listed per section, the rest only counted (default: 100)
+}
+
+// GetMaxChanges returns how many changes a report lists per section with a sensible default
+func (r *ReportConfig) GetMaxChanges() int {
+	if r.MaxChanges > 0 {
+		return r.MaxChanges
+	}
+	return 100
+}
+
+// ConfigMapDiffConfig controls a key-level diff: of modified ConfigMaps, or of Helm release values
+type ConfigMapDiffConfig struct {
+	HideValues bool `yaml:"hideValues,omitempty"` // Only name the added, removed and changed keys
+	MaxSize    int  `yaml:"maxSize,omitempty"`    // Max bytes of diff lines per notification (default: 4096)
+}
+
+// EventBusConfig sizes the queues handing events to their consumers
+type EventBusConfig struct {
+	NotifierWorkers int `yaml:"notifierWorkers,omitempty"` // Events notified at once; one object's events stay in order (default: 16)
+	QueueSize       int `yaml:"queueSize,omitempty"`       // Events each consumer may fall behind (default: 1000)
+}
+
+// SilencesConfig controls how silences are expired
+type SilencesConfig struct {
+	WarnBefore time.Duration `yaml:"warnBefore,omitempty"` // How long before expiry the creator is emailed (default: 15m)
+}
+
+// MarkersConfig controls deployment markers pushed to /api/markers
+type MarkersConfig struct {
+	Window time.Duration `yaml:"window,omitempty"` // How long after a marker events are annotated with it (default: 15m)
+}
+
+// BurstProtectionConfig caps the total notifications sent per minute; overflow is
+// reported in a single summary message
+type BurstProtectionConfig struct {
+	Enabled      bool `yaml:"enabled,omitempty"`
+	MaxPerMinute int  `yaml:"maxPerMinute,omitempty"` // Notifications allowed per minute in total (default: 60)
+}
+
+// AnomalyDetectionConfig flags change rates far above a learned per kind and
+// namespace baseline, e.g. a compromised credential rewriting Secrets
+type AnomalyDetectionConfig struct {
+	Enabled   bool          `yaml:"enabled,omitempty"`
+	MinEvents int           `yaml:"minEvents,omitempty"` // Changes per minute below which nothing is flagged (default: 20)
+	Factor    float64       `yaml:"factor,omitempty"`    // How many times the baseline rate counts as a spike (default: 10)
+	Baseline  time.Duration `yaml:"baseline,omitempty"`  // Period the baseline rate is averaged over (default: 1h)
+}
+
+func (a *AnomalyDetectionConfig) Validate() error {
+	if a.MinEvents < 0 {
+		return fmt.Errorf("minEvents cannot be negative")
+	}
+	if a.Factor < 0 || (a.Factor > 0 && a.Factor <= 1) {
+		return fmt.Errorf("factor must be greater than 1")
+	}
+	if a.Baseline != 0 && a.Baseline < time.Minute {
+		return fmt.Errorf("baseline must be at least 1m")
+	}
+	return nil
+}
+
+type ResourceConfig struct {
+	Kind         string `yaml:"kind"`
+	Namespace    string `yaml:"namespace"`
+	ResourceName string `yaml:"resourceName,omitempty"`
+
+	// ResourceNames selects several objects by name; it cannot be combined with ResourceName
+	ResourceNames []string `yaml:"resourceNames,omitempty"`
+
+	// Namespaces and ExcludeNamespaces select several namespaces with glob patterns
+	// (e.g. "team-*"); they cannot be combined with Namespace
+	Namespaces        []string `yaml:"namespaces,omitempty"`
+	ExcludeNamespaces []string `yaml:"excludeNamespaces,omitempty"`
+
+	// EventTypes limits notifications to some of ADDED, MODIFIED and DELETED; empty means all
+	EventTypes []string `yaml:"eventTypes,omitempty"`
+
+	// EventFilter selects which core Events are notified; only valid for kind "Event".
+	// For Events, resourceName and resourceNames match the involved object's name.
+	EventFilter *EventFilterConfig `yaml:"eventFilter,omitempty"`
+
+	// HelmReleases notifies Helm release installs, upgrades and rollbacks instead of
+	// Secret events; only valid for kind "Secret". resourceName and resourceNames match release names.
+	HelmReleases bool `yaml:"helmReleases,omitempty"`
+
+	// ServiceAccountTokens limits a Secret entry to ServiceAccount token Secrets, notifying
+	// tokens being issued, regenerated or moved to another ServiceAccount; only valid for kind "Secret"
+	ServiceAccountTokens bool `yaml:"serviceAccountTokens,omitempty"`
+
+	// WatchExpressions limits MODIFIED notifications to changes of these field paths, e.g.
+	// spec.replicas or data["feature-flags.yaml"]; they take precedence over watcher.significantFields
+	WatchExpressions []string `yaml:"watchExpressions,omitempty"`
+
+	// Filter is a CEL expression over event, object and oldObject; events it is false for
+	// are not notified, e.g. object.metadata.labels["env"] == "prod" && event.type == "DELETED"
+	Filter string `yaml:"filter,omitempty"`
+
+	// ResyncPeriod overrides watcher.resyncPeriod for this entry
+	ResyncPeriod time.Duration `yaml:"resyncPeriod,omitempty"`
+}
+
+// EventFilterConfig narrows watched Events; every list accepts glob patterns and empty lists match all
+type EventFilterConfig struct {
+	Reasons       []string `yaml:"reasons,omitempty"`       // e.g. FailedScheduling, FailedMount
+	Types         []string `yaml:"types,omitempty"`         // Normal or Warning
+	InvolvedKinds []string `yaml:"involvedKinds,omitempty"` // Kind of the involved object, e.g. Pod
+}
+
+type EmailConfig struct {
+	// Enabled defaults to true; set it to false to notify only through the other notifiers
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	Provider string         `yaml:"provider,omitempty"` // smtp (default), ses or sendgrid
+	SES      SESConfig      `yaml:"ses,omitempty"`
+	SendGrid SendGridConfig `yaml:"sendgrid,omitempty"`
+
+	SMTPHost     string   `yaml:"smtpHost"`
+	SMTPPort     int      `yaml:"smtpPort"`
+	UseAuth      bool     `yaml:"useAuth"`
+	SMTPUsername string   `yaml:"smtpUsername,omitempty"`
+	SMTPPassword string   `yaml:"smtpPassword,omitempty"`
+	FromEmail    string   `yaml:"fromEmail"`
+	ToEmails     []string `yaml:"toEmails"`
+
+	// Authenticate with XOAUTH2, using client credentials tokens, instead of the SMTP password
+	OAuth2 *OAuth2Config `yaml:"oauth2,omitempty"`
+
+	// Recipients routes events from matching namespaces or labels to their own lists instead of toEmails
+	Recipients []EmailRecipientsConfig `yaml:"recipients,omitempty"`
+
+	// TLS Configuration
+	EnableTLS   bool `yaml:"enableTLS,omitempty"`
+	InsecureTLS bool `yaml:"insecureTLS,omitempty"`
+	ForceSSL    bool `yaml:"forceSSL,omitempty"`
+
+	// Message templates (Go text/template); the built-in format is used when empty
+	SubjectTemplate string `yaml:"subjectTemplate,omitempty"`
+	BodyTemplate    string `yaml:"bodyTemplate,omitempty"`
+
+	// HTML part sent alongside the plain-text body (Go html/template), inline or from a mounted file
+	HTMLTemplate     string `yaml:"htmlTemplate,omitempty"`
+	HTMLTemplateFile string `yaml:"htmlTemplateFile,omitempty"`
+
+	// Network Configuration
+	ConnectTimeout time.Duration `yaml:"connectTimeout,omitempty"` // Max time to establish the SMTP connection (default: 10s)
+	SendTimeout    time.Duration `yaml:"sendTimeout,omitempty"`    // Max time for a single delivery attempt (default: 30s)
+	SourceAddress  string        `yaml:"sourceAddress,omitempty"`  // Local IP to bind outbound connections to (egress gateway setups)
+
+	// Max bytes of subject and body; larger messages drop diff sections (default: the provider's limit)
+	MaxMessageSize int `yaml:"maxMessageSize,omitempty"`
+
+	Concurrency ConcurrencyConfig `yaml:"concurrency,omitempty"` // Destinations are recipient lists
+}
+
+// ConcurrencyConfig limits how many messages a notifier sends at once
+type ConcurrencyConfig struct {
+	MaxInFlight int `yaml:"maxInFlight,omitempty"` // Messages being sent at once; 0 means unlimited
+
+	// Send to each destination (recipient list, Teams webhook, webhook URL) one message at a
+	// time, while different destinations are still sent to in parallel
+	SerializePerDestination bool `yaml:"serializePerDestination,omitempty"`
+}
+
+// Email delivery providers
+const (
+	EmailProviderSMTP     = "smtp"
+	EmailProviderSES      = "ses"
+	EmailProviderSendGrid = "sendgrid"
+)
+
+// SESConfig sends email through the Amazon SES v2 API. Credentials come from
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and, for temporary ones, AWS_SESSION_TOKEN,
+// or from IRSA (AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE).
+type SESConfig struct {
+	Region           string `yaml:"region,omitempty"`           // Defaults to AWS_REGION
+	ConfigurationSet string `yaml:"configurationSet,omitempty"` // For SES event publishing
+	Endpoint         string `yaml:"endpoint,omitempty"`         // e.g. a VPC endpoint; default: https://email.<region>.amazonaws.com
+}
+
+// SendGridConfig sends email through the SendGrid v3 API
+type SendGridConfig struct {
+	APIKey    string `yaml:"apiKey,omitempty"`
+	APIKeyEnv string `yaml:"apiKeyEnv,omitempty"` // Environment variable holding the API key
+	Endpoint  string `yaml:"endpoint,omitempty"`  // Default: https://api.sendgrid.com (EU: https://api.eu.sendgrid.com)
+}
+
+// EmailRecipientsConfig is a recipient list for events whose namespace or labels match
+type EmailRecipientsConfig struct {
+	Namespaces []string          `yaml:"namespaces,omitempty"` // Namespaces (glob patterns allowed); any may match
+	Labels     map[string]string `yaml:"labels,omitempty"`     // Label values (glob patterns allowed); all must match
+	ToEmails   []string          `yaml:"toEmails"`
+}
+
+// Matches reports whether an event with the namespace and labels goes to this list
+func (r *EmailRecipientsConfig) Matches(namespace string, labels map[string]string) bool {
+	if len(r.Namespaces) > 0 && !MatchAny(r.Namespaces, namespace) {
+		return false
+	}
+	for key, pattern := range r.Labels {
+		value, ok := labels[key]
+		if !ok || !MatchAny([]string{pattern}, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// TeamsConfig represents configuration for Microsoft Teams incoming webhook notifications
+type TeamsConfig struct {
+	Enabled  bool                 `yaml:"enabled,omitempty"`
+	Webhooks []TeamsWebhookConfig `yaml:"webhooks,omitempty"`
+	Timeout  time.Duration        `yaml:"timeout,omitempty"` // Per-request timeout (default: 10s)
+
+	// Max bytes of a card payload; larger cards drop diff sections (default: 28000, the Teams webhook limit)
+	MaxMessageSize int `yaml:"maxMessageSize,omitempty"`
+
+	Concurrency ConcurrencyConfig `yaml:"concurrency,omitempty"` // Destinations are the webhooks
+}
+
+// TeamsWebhookConfig is a single Teams channel webhook and the namespaces routed to it
+type TeamsWebhookConfig struct {
+	Name       string   `yaml:"name"`
+	URL        string   `yaml:"url,omitempty"`
+	URLEnv     string   `yaml:"urlEnv,omitempty"`     // Environment variable holding the webhook URL
+	Namespaces []string `yaml:"namespaces,omitempty"` // Namespaces (glob patterns allowed) routed to this webhook; empty means all
+}
+
+// WebhookConfig posts every event as JSON to an HTTP endpoint
+type WebhookConfig struct {
+	Enabled bool              `yaml:"enabled,omitempty"`
+	URL     string            `yaml:"url,omitempty"`
+	URLEnv  string            `yaml:"urlEnv,omitempty"` // Environment variable holding the URL
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Timeout time.Duration     `yaml:"timeout,omitempty"` // Per-request timeout (default: 10s)
+
+	// Authenticate with OAuth2 client credentials instead of static headers
+	OAuth2 *OAuth2Config `yaml:"oauth2,omitempty"`
+
+	// Max bytes of a JSON payload; larger payloads drop diff sections (default: 1MiB)
+	MaxMessageSize int `yaml:"maxMessageSize,omitempty"`
+
+	Concurrency ConcurrencyConfig `yaml:"concurrency,omitempty"` // The destination is the URL
+}
+
+// LogSinkConfig writes every event as one JSON line, for log collectors such as Loki or Fluentd
+type LogSinkConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Path    string `yaml:"path,omitempty"` // File to append to; empty writes to stdout
+}
+
+// OpsgenieConfig raises and closes Opsgenie alerts through the Alert API
+type OpsgenieConfig struct {
+	Enabled   bool          `yaml:"enabled,omitempty"`
+	APIKey    string        `yaml:"apiKey,omitempty"`    // API integration key
+	APIKeyEnv string        `yaml:"apiKeyEnv,omitempty"` // Environment variable holding the API key
+	Endpoint  string        `yaml:"endpoint,omitempty"`  // Default: https://api.opsgenie.com (EU: https://api.eu.opsgenie.com)
+	Tags      []string      `yaml:"tags,omitempty"`      // Added to every alert
+	Timeout   time.Duration `yaml:"timeout,omitempty"`   // Per-request timeout (default: 10s)
+}
+
+// SplunkOnCallConfig raises and recovers Splunk On-Call (VictorOps) incidents through the REST endpoint integration
+type SplunkOnCallConfig struct {
+	Enabled    bool          `yaml:"enabled,omitempty"`
+	APIKey     string        `yaml:"apiKey,omitempty"`     // REST endpoint integration key
+	APIKeyEnv  string        `yaml:"apiKeyEnv,omitempty"`  // Environment variable holding the API key
+	RoutingKey string        `yaml:"routingKey,omitempty"` // Routes incidents to a team
+	Endpoint   string        `yaml:"endpoint,omitempty"`   // Default: https://alert.victorops.com/integrations/generic/20131114/alert
+	Timeout    time.Duration `yaml:"timeout,omitempty"`    // Per-request timeout (default: 10s)
+}
+
+// Publisher brokers and serialization formats
+const (
+	PublisherBrokerNATS  = "nats"
+	PublisherBrokerKafka = "kafka"
+
+	PublisherFormatJSON        = "json"
+	PublisherFormatCloudEvents = "cloudevents"
+)
+
+// PublisherConfig publishes every event, with its full diff, to a NATS subject or Kafka topic
+type PublisherConfig struct {
+	Enabled bool          `yaml:"enabled,omitempty"`
+	Broker  string        `yaml:"broker,omitempty"`  // nats (default) or kafka
+	Format  string        `yaml:"format,omitempty"`  // json (default): the webhook payload; cloudevents: a structured CloudEvent wrapping it
+	Timeout time.Duration `yaml:"timeout,omitempty"` // Per-publish timeout (default: 10s)
+	NATS    NATSConfig    `yaml:"nats,omitempty"`
+	Kafka   KafkaConfig   `yaml:"kafka,omitempty"`
+}
+
+// NATSConfig is the NATS server and subject events are published to
+type NATSConfig struct {
+	URL      string `yaml:"url,omitempty"`      // nats://host:4222, or tls://host:4222 to require TLS
+	Subject  string `yaml:"subject,omitempty"`  // e.g. "k8s.changes"
+	Token    string `yaml:"token,omitempty"`    // Authentication token, if the server requires one
+	TokenEnv string `yaml:"tokenEnv,omitempty"` // Environment variable holding the token
+}
+
+// KafkaConfig is the Kafka REST Proxy and topic events are produced to
+type KafkaConfig struct {
+	RESTProxyURL string            `yaml:"restProxyURL,omitempty"` // e.g. http://kafka-rest:8082 (REST Proxy v2 API)
+	Topic        string            `yaml:"topic,omitempty"`
+	Headers      map[string]string `yaml:"headers,omitempty"` // e.g. an Authorization header
+}
+
+// SNSConfig publishes every event to an Amazon SNS topic. Credentials come from IRSA
+// (AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE) or from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+type SNSConfig struct {
+	Enabled  bool          `yaml:"enabled,omitempty"`
+	TopicARN string        `yaml:"topicARN,omitempty"` // e.g. arn:aws:sns:eu-west-1:123456789012:k8s-changes; the region is taken from it
+	Format   string        `yaml:"format,omitempty"`   // json (default) or cloudevents, as for the publisher
+	Endpoint string        `yaml:"endpoint,omitempty"` // e.g. a VPC endpoint; default: https://sns.<region>.amazonaws.com
+	Timeout  time.Duration `yaml:"timeout,omitempty"`  // Per-request timeout (default: 10s)
+}
+
+// PubSubConfig publishes every event to a Google Cloud Pub/Sub topic. Access tokens come
+// from the metadata server, i.e. Workload Identity on GKE.
+type PubSubConfig struct {
+	Enabled  bool          `yaml:"enabled,omitempty"`
+	Project  string        `yaml:"project,omitempty"`  // Project ID of the topic
+	Topic    string        `yaml:"topic,omitempty"`    // Topic ID, e.g. "k8s-changes"
+	Format   string        `yaml:"format,omitempty"`   // json (default) or cloudevents, as for the publisher
+	Ordered  bool          `yaml:"ordered,omitempty"`  // Set an ordering key per object, for subscriptions with message ordering
+	Endpoint string        `yaml:"endpoint,omitempty"` // e.g. a regional endpoint; default: https://pubsub.googleapis.com
+	Timeout  time.Duration `yaml:"timeout,omitempty"`  // Per-request timeout (default: 10s)
+}
+
+// OAuth2Config holds client credentials for the OAuth2 client credentials grant
+type OAuth2Config struct {
+	TokenURL        string   `yaml:"tokenURL"`
+	ClientID        string   `yaml:"clientID"`
+	ClientSecret    string   `yaml:"clientSecret,omitempty"`
+	ClientSecretEnv string   `yaml:"clientSecretEnv,omitempty"` // Environment variable holding the client secret
+	Scopes          []string `yaml:"scopes,omitempty"`
+	Audience        string   `yaml:"audience,omitempty"` // Sent as the "audience" parameter, for providers that require it
+}
+
+// RoutingConfig decides which notifiers receive each event
+type RoutingConfig struct {
+	Rulesets []RuleSetConfig `yaml:"rulesets,omitempty" json:"rulesets,omitempty"`
+}
+
+// RuleSetConfig is an ordered group of routing rules evaluated independently of other rulesets
+type RuleSetConfig struct {
+	Name             string       `yaml:"name" json:"name"`
+	Mode             string       `yaml:"mode,omitempty" json:"mode,omitempty"`                         // first-match (default) or all-match
+	DefaultNotifiers []string     `yaml:"defaultNotifiers,omitempty" json:"defaultNotifiers,omitempty"` // Used when no rule in the ruleset matches
+	Rules            []RuleConfig `yaml:"rules" json:"rules"`
+}
+
+// RuleConfig routes matching events to notifiers, or drops them
+type RuleConfig struct {
+	Name      string      `yaml:"name" json:"name"`
+	Priority  int         `yaml:"priority,omitempty" json:"priority,omitempty"` // Higher priorities are evaluated first; ties keep config order
+	Match     MatchConfig `yaml:"match,omitempty" json:"match,omitempty"`
+	Notifiers []string    `yaml:"notifiers,omitempty" json:"notifiers,omitempty"`
+	Action    string      `yaml:"action,omitempty" json:"action,omitempty"` // notify (default) or drop
+}
+
+// MatchConfig selects events; empty lists match everything, names and namespaces accept glob patterns
+type MatchConfig struct {
+	Kinds      []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+	Namespaces []string `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+	Names      []string `yaml:"names,omitempty" json:"names,omitempty"`
+	EventTypes []string `yaml:"eventTypes,omitempty" json:"eventTypes,omitempty"`
+	Clusters   []string `yaml:"clusters,omitempty" json:"clusters,omitempty"` // With clusters configured
+
+	// ChangedBy matches the field manager that made the change, e.g. kubectl-edit, and Origins
+	// whether it was argocd, flux or other; events whose origin is unknown, such as
+	// deletions, match neither
+	ChangedBy []string `yaml:"changedBy,omitempty" json:"changedBy,omitempty"`
+	Origins   []string `yaml:"origins,omitempty" json:"origins,omitempty"`
+
+	// Expression is a CEL expression over event and object that must also be true. Only the
+	// object's kind and metadata are available, e.g. object.metadata.labels["team"] == "payments".
+	Expression string `yaml:"expression,omitempty" json:"expression,omitempty"`
+}
+
+// SeverityConfig assigns a severity to each event from the first matching rule
+type SeverityConfig struct {
+	Default string               `yaml:"default,omitempty"` // Severity of events no rule matches (default: info)
+	Rules   []SeverityRuleConfig `yaml:"rules,omitempty"`
+}
+
+// SeverityRuleConfig gives matching events a severity
+type SeverityRuleConfig struct {
+	Name     string      `yaml:"name"`
+	Match    MatchConfig `yaml:"match,omitempty"`
+	Severity string      `yaml:"severity"` // info, warning or critical
+}
+
+// RedactionConfig scrubs sensitive content from notifications before they are sent
+type RedactionConfig struct {
+	Replacement string                `yaml:"replacement,omitempty"` // Replaces redacted content (default: REDACTED)
+	Rules       []RedactionRuleConfig `yaml:"rules,omitempty"`
+}
+
+// RedactionRuleConfig names content to scrub from the notifications of some or all notifiers
+type RedactionRuleConfig struct {
+	Name        string               `yaml:"name"`
+	Patterns    []string             `yaml:"patterns,omitempty"`    // Regular expressions; only the capture groups are replaced if there are any
+	Secrets     []RedactionSecretRef `yaml:"secrets,omitempty"`     // Secrets whose data values are replaced wherever they appear
+	Annotations []string             `yaml:"annotations,omitempty"` // Glob patterns of annotation keys whose values are replaced
+	Notifiers   []string             `yaml:"notifiers,omitempty"`   // Notifiers the rule applies to; empty for all
+}
+
+// RedactionSecretRef is a Secret, in the first watched cluster, whose values are redacted
+type RedactionSecretRef struct {
+	Namespace string   `yaml:"namespace"`
+	Name      string   `yaml:"name"`
+	Keys      []string `yaml:"keys,omitempty"` // Glob patterns of the data keys to redact; empty for all
+}
+
+// Notification severities, least severe first
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Watch engines
+const (
+	WatchEngineInformer = "informer"
+	WatchEngineRawWatch = "raw-watch"
+)
+
+// Permission check modes
+const (
+	PermissionCheckFail = "fail"
+	PermissionCheckWarn = "warn"
+	PermissionCheckOff  = "off"
+)
+
+// Routing modes and rule actions
+const (
+	RoutingModeFirstMatch = "first-match"
+	RoutingModeAllMatch   = "all-match"
+
+	RuleActionNotify = "notify"
+	RuleActionDrop   = "drop"
+)
+
+// Change origins: made by Argo CD, by Flux, or by anyone else
+const (
+	ChangeOriginArgoCD = "argocd"
+	ChangeOriginFlux   = "flux"
+	ChangeOriginOther  = "other"
+)
+
+// LoggingConfig represents configuration for logging behavior
+type LoggingConfig struct {
+	Level      string `yaml:"level,omitempty"`      // Log level: debug, info, warn, error (default: info)
+	Format     string `yaml:"format,omitempty"`     // Log format: text, json (default: text)
+	EnableJSON bool   `yaml:"enableJSON,omitempty"` // Enable JSON logging format
+}
+
+// Config represents the application configuration
+type Config struct {
+	ClusterName string           `yaml:"clusterName"`
+	Resources   []ResourceConfig `yaml:"resources"`
+	Email       EmailConfig      `yaml:"email"`
+	Teams       TeamsConfig      `yaml:"teams,omitempty"`
+	Webhook     WebhookConfig    `yaml:"webhook,omitempty"`
+	LogSink     LogSinkConfig    `yaml:"logSink,omitempty"`
+	Publisher   PublisherConfig  `yaml:"publisher,omitempty"`
+	SNS         SNSConfig        `yaml:"sns,omitempty"`
+	PubSub      PubSubConfig     `yaml:"pubsub,omitempty"`
+	Routing     RoutingConfig    `yaml:"routing,omitempty"`
+	Severity    SeverityConfig   `yaml:"severity,omitempty"`
+	Redaction   RedactionConfig  `yaml:"redaction,omitempty"`
+	Watcher     WatcherConfig    `yaml:"watcher,omitempty"`
+	Logging     LoggingConfig    `yaml:"logging,omitempty"`
+
+	// Incident management notifiers, which raise alerts and close them on resolving events
+	Opsgenie     OpsgenieConfig     `yaml:"opsgenie,omitempty"`
+	SplunkOnCall SplunkOnCallConfig `yaml:"splunkOnCall,omitempty"`
+
+	// Watch a remote cluster using a kubeconfig stored in a Secret of the local cluster
+	KubeconfigSecret *KubeconfigSecretRef `yaml:"kubeconfigSecret,omitempty"`
+
+	// Watch several clusters from this instance instead of a single one
+	Clusters []ClusterConfig `yaml:"clusters,omitempty"`
+
+	// Kubeconfig file and context of a cluster entry, set by ClusterConfigs
+	Kubeconfig        string `yaml:"-"`
+	KubeconfigContext string `yaml:"-"`
+
+	// Kubeconfig file and context of the local cluster, from the --kubeconfig
+	// and --context flags; empty uses the in-cluster config
+	LocalKubeconfig string `yaml:"-"`
+	LocalContext    string `yaml:"-"`
+
+	// Identity the watcher presents to the API server
+	Client ClientConfig `yaml:"client,omitempty"`
+
+	// Ports and authentication of the health check, API and dashboard server
+	Server ServerConfig `yaml:"server,omitempty"`
+
+	// Persistent history of processed events
+	Store StoreConfig `yaml:"store,omitempty"`
+
+	// Copies of the manifest of every version of the watched objects
+	Archive ArchiveConfig `yaml:"archive,omitempty"`
+
+	// Where watcher state such as silences is kept
+	Storage StorageConfig `yaml:"storage,omitempty"`
+
+	// Look of this cluster's chat notifications
+	Theme ThemeConfig `yaml:"theme,omitempty"`
+
+	// What to do when the config or persisted state is newer than the binary
+	Compatibility CompatibilityConfig `yaml:"compatibility,omitempty"`
+
+	// SchemaVersion is the schema version the file was written in, set by Parse
+	SchemaVersion int `yaml:"-"`
+}
+
+// ThemeConfig makes chat notifications from this cluster visually distinct,
+// e.g. production from staging in a shared channel
+type ThemeConfig struct {
+	Color  string `yaml:"color,omitempty"`  // Title banner color: default, emphasis, good, attention, warning or accent
+	Emoji  string `yaml:"emoji,omitempty"`  // Shown before every title, e.g. "🔴"
+	Prefix string `yaml:"prefix,omitempty"` // Shown before every title after the emoji, e.g. "PROD"
+}
+
+func (t *ThemeConfig) Validate() error {
+	switch t.Color {
+	case "", "default", "emphasis", "good", "attention", "warning", "accent":
+		return nil
+	default:
+		return fmt.Errorf("unknown color %q (use default, emphasis, good, attention, warning or accent)", t.Color)
+	}
+}
+
+// Title decorates a notification title with the theme's emoji and prefix
+func (t *ThemeConfig) Title(title string) string {
+	for _, decoration := range []string{t.Prefix, t.Emoji} {
+		if decoration != "" {
+			title = decoration + " " + title
+		}
+	}
+	return title
+}
+
+// CompatibilityConfig controls the startup version compatibility gate
+type CompatibilityConfig struct {
+	// Start in safe mode instead of refusing to start: a newer config is read
+	// as far as this binary understands it and newer persisted state is left alone
+	SafeMode bool `yaml:"safeMode,omitempty"`
+}
+
+// Event store drivers
+const (
+	StoreDriverFile = "file"
+)
+
+// StoreConfig selects where processed events are recorded
+type StoreConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Driver  string `yaml:"driver,omitempty"` // Default: file
+	Path    string `yaml:"path,omitempty"`   // File driver: JSON lines file, e.g. on a PersistentVolume
+
+	// File driver retention. The file is rotated to <path>.1, <path>.2, ... once it reaches
+	// maxSize bytes or holds records older than maxAge; rotated files past maxFiles, or with
+	// only records older than maxAge, are deleted. By default the file grows forever.
+	MaxSize  int           `yaml:"maxSize,omitempty"`
+	MaxAge   time.Duration `yaml:"maxAge,omitempty"`
+	MaxFiles int           `yaml:"maxFiles,omitempty"` // Default: 5
+}
+
+// Manifest archive drivers
+const (
+	ArchiveDriverFile = "file"
+	ArchiveDriverS3   = "s3"
+	ArchiveDriverGCS  = "gcs"
+)
+
+// ArchiveConfig selects where the manifests of the watched objects' versions are archived, under
+// <prefix>/<cluster>/<namespace>/<kind>/<name>/<time>-<event>.yaml
+type ArchiveConfig struct {
+	Enabled   bool          `yaml:"enabled,omitempty"`
+	Driver    string        `yaml:"driver,omitempty"`    // file (default), s3 or gcs
+	Path      string        `yaml:"path,omitempty"`      // File driver: directory, e.g. on a PersistentVolume
+	Bucket    string        `yaml:"bucket,omitempty"`    // S3 and GCS drivers
+	Prefix    string        `yaml:"prefix,omitempty"`    // Leading path of every key, e.g. "manifests"
+	Region    string        `yaml:"region,omitempty"`    // S3 driver: bucket region (default: AWS_REGION, or us-east-1 with an endpoint)
+	Endpoint  string        `yaml:"endpoint,omitempty"`  // e.g. a MinIO server; default: the AWS or Google Cloud endpoint
+	PathStyle bool          `yaml:"pathStyle,omitempty"` // S3 driver: address the bucket in the path, as MinIO expects
+	Kinds     []string      `yaml:"kinds,omitempty"`     // Kinds archived (default: all watched kinds)
+	Timeout   time.Duration `yaml:"timeout,omitempty"`   // Per-upload timeout (default: 10s)
+
+	// SecretValues archives the data of Secrets; by default only their keys are kept
+	SecretValues bool `yaml:"secretValues,omitempty"`
+}
+
+// State storage drivers
+const (
+	StorageDriverMemory    = "memory"
+	StorageDriverFile      = "file"
+	StorageDriverConfigMap = "configmap"
+)
+
+// StorageConfig selects where watcher state is kept between restarts
+type StorageConfig struct {
+	Driver    string `yaml:"driver,omitempty"`    // Default: memory (lost on restart)
+	Path      string `yaml:"path,omitempty"`      // File driver: directory, e.g. on a PersistentVolume
+	ConfigMap string `yaml:"configMap,omitempty"` // ConfigMap driver: name of the ConfigMap holding the state
+	Namespace string `yaml:"namespace,omitempty"` // ConfigMap driver: defaults to POD_NAMESPACE
+}
+
+// ClientConfig controls how the watcher's API traffic appears in audit logs
+type ClientConfig struct {
+	UserAgent   string            `yaml:"userAgent,omitempty"` // Default: k8s-resource-watcher
+	AuditTag    string            `yaml:"auditTag,omitempty"`  // Appended to the user agent, e.g. "team=platform"
+	Impersonate ImpersonateConfig `yaml:"impersonate,omitempty"`
+}
+
+// ServerConfig sets the ports, bind address, timeouts and TLS of the HTTP server of health
+// checks, the API and the dashboard; SERVER_* environment variables override them
+type ServerConfig struct {
+	Port         int               `yaml:"port,omitempty"`         // Default: 8080
+	Address      string            `yaml:"address,omitempty"`      // Bind address of both ports; default: all interfaces
+	ReadTimeout  time.Duration     `yaml:"readTimeout,omitempty"`  // Default: 30s
+	WriteTimeout time.Duration     `yaml:"writeTimeout,omitempty"` // Default: 60s; event streams are exempt
+	TLS          *ServerTLSConfig  `yaml:"tls,omitempty"`          // Serves the main port over HTTPS
+	Admin        AdminServerConfig `yaml:"admin,omitempty"`
+}
+
+// ServerTLSConfig is a certificate and key pair, reloaded when the files change
+type ServerTLSConfig struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// AdminServerConfig moves the API, dashboard and metrics to their own port with optional
+// authentication; the main port then only serves health checks and the status page
+type AdminServerConfig struct {
+	Enabled        bool            `yaml:"enabled,omitempty"`
+	Port           int             `yaml:"port,omitempty"`           // Default: 9090
+	BearerToken    string          `yaml:"bearerToken,omitempty"`    // Required as "Authorization: Bearer <token>"
+	BearerTokenEnv string          `yaml:"bearerTokenEnv,omitempty"` // Environment variable holding the token
+	TLS            *AdminTLSConfig `yaml:"tls,omitempty"`
+}
+
+// AdminTLSConfig serves the admin port over TLS, requiring client certificates when clientCAFile is set
+type AdminTLSConfig struct {
+	ServerTLSConfig `yaml:",inline"`
+	ClientCAFile    string `yaml:"clientCAFile,omitempty"` // CA bundle client certificates must chain to (mTLS)
+}
+
+// ImpersonateConfig makes every request act as another user and/or groups
+type ImpersonateConfig struct {
+	User   string              `yaml:"user,omitempty"`
+	Groups []string            `yaml:"groups,omitempty"`
+	Extra  map[string][]string `yaml:"extra,omitempty"`
+}
+
+// KubeconfigSecretRef points at a kubeconfig stored in a Secret of the local cluster
+type KubeconfigSecretRef struct {
+	Namespace string `yaml:"namespace,omitempty"` // Defaults to POD_NAMESPACE, then "default"
+	Name      string `yaml:"name"`
+	Key       string `yaml:"key,omitempty"` // Defaults to "kubeconfig"
+}
+
+// ClusterConfig is one of several clusters watched by a single instance.
+// Without kubeconfigSecret or kubeconfig it is the cluster the watcher runs in.
+type ClusterConfig struct {
+	Name             string               `yaml:"name"`
+	KubeconfigSecret *KubeconfigSecretRef `yaml:"kubeconfigSecret,omitempty"`
+	Kubeconfig       string               `yaml:"kubeconfig,omitempty"` // Path of a mounted kubeconfig file
+	Context          string               `yaml:"context,omitempty"`    // Kubeconfig context; defaults to its current context
+}
+
+func (c *ClusterConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.KubeconfigSecret != nil && c.Kubeconfig != "" {
+		return fmt.Errorf("kubeconfigSecret and kubeconfig cannot both be set")
+	}
+	if c.KubeconfigSecret != nil && c.KubeconfigSecret.Name == "" {
+		return fmt.Errorf("kubeconfigSecret: name is required")
+	}
+	return nil
+}
+
+// local reports whether the entry is the cluster the watcher runs in
+func (c *ClusterConfig) local() bool {
+	return c.KubeconfigSecret == nil && c.Kubeconfig == "" && c.Context == ""
+}
+
+// ClusterConfigs returns one configuration per watched cluster, the first
+// being the primary, named after its entry. Without clusters that is the
+// configuration itself.
+func (c *Config) ClusterConfigs() []*Config {
+	if len(c.Clusters) == 0 {
+		return []*Config{c}
+	}
+	configs := make([]*Config, 0, len(c.Clusters))
+	for _, cluster := range c.Clusters {
+		clusterCfg := *c
+		clusterCfg.ClusterName = cluster.Name
+		clusterCfg.KubeconfigSecret = cluster.KubeconfigSecret
+		clusterCfg.Kubeconfig = cluster.Kubeconfig
+		clusterCfg.KubeconfigContext = cluster.Context
+		clusterCfg.Clusters = nil
+		configs = append(configs, &clusterCfg)
+	}
+	return configs
+}
+
+func (c *Config) Validate() error {
+	if c.ClusterName == "" {
+		return fmt.Errorf("cluster name is required")
+	}
+
+	if len(c.Clusters) > 0 && c.KubeconfigSecret != nil {
+		return fmt.Errorf("kubeconfigSecret cannot be set with clusters; set it on the cluster entry")
+	}
+	names := make(map[string]bool)
+	locals := 0
+	for i, cluster := range c.Clusters {
+		if err := cluster.Validate(); err != nil {
+			return fmt.Errorf("clusters[%d]: %v", i, err)
+		}
+		if names[cluster.Name] {
+			return fmt.Errorf("clusters[%d]: duplicate cluster name %q", i, cluster.Name)
+		}
+		names[cluster.Name] = true
+		if cluster.local() {
+			locals++
+		}
+	}
+	if locals > 1 {
+		return fmt.Errorf("clusters: only one entry can be the local cluster")
+	}
+
+	if len(c.Resources) == 0 {
+		return fmt.Errorf("at least one resource must be configured")
+	}
+
+	for i, resource := range c.Resources {
+		if err := resource.Validate(); err != nil {
+			return fmt.Errorf("resource[%d]: %v", i, err)
+		}
+	}
+
+	if err := c.Email.Validate(); err != nil {
+		return fmt.Errorf("email configuration: %v", err)
+	}
+
+	if err := c.Teams.Validate(); err != nil {
+		return fmt.Errorf("teams configuration: %v", err)
+	}
+
+	if err := c.Webhook.Validate(); err != nil {
+		return fmt.Errorf("webhook configuration: %v", err)
+	}
+
+	if err := c.Publisher.Validate(); err != nil {
+		return fmt.Errorf("publisher configuration: %v", err)
+	}
+
+	if err := c.SNS.Validate(); err != nil {
+		return fmt.Errorf("sns configuration: %v", err)
+	}
+
+	if err := c.PubSub.Validate(); err != nil {
+		return fmt.Errorf("pubsub configuration: %v", err)
+	}
+
+	if err := c.Opsgenie.Validate(); err != nil {
+		return fmt.Errorf("opsgenie configuration: %v", err)
+	}
+
+	if err := c.SplunkOnCall.Validate(); err != nil {
+		return fmt.Errorf("splunkOnCall configuration: %v", err)
+	}
+
+	if err := c.Server.Validate(); err != nil {
+		return fmt.Errorf("server configuration: %v", err)
+	}
+
+	if err := c.Store.Validate(); err != nil {
+		return fmt.Errorf("store configuration: %v", err)
+	}
+
+	if err := c.Archive.Validate(); err != nil {
+		return fmt.Errorf("archive configuration: %v", err)
+	}
+
+	if err := c.Storage.Validate(); err != nil {
+		return fmt.Errorf("storage configuration: %v", err)
+	}
+
+	if err := c.Theme.Validate(); err != nil {
+		return fmt.Errorf("theme configuration: %v", err)
+	}
+
+	if err := c.Routing.Validate(c.NotifierNames()); err != nil {
+		return fmt.Errorf("routing configuration: %v", err)
+	}
+
+	if err := c.Severity.Validate(); err != nil {
+		return fmt.Errorf("severity configuration: %v", err)
+	}
+
+	if err := c.Redaction.Validate(c.NotifierNames()); err != nil {
+		return fmt.Errorf("redaction configuration: %v", err)
+	}
+
+	for kind, paths := range c.Watcher.IgnoreFields {
+		for _, text := range paths {
+			if _, err := fieldpath.Parse(text); err != nil {
+				return fmt.Errorf("watcher.ignoreFields[%s]: %v", kind, err)
+			}
+		}
+	}
+	for kind, paths := range c.Watcher.SignificantFields {
+		for _, text := range paths {
+			if _, err := fieldpath.Parse(text); err != nil {
+				return fmt.Errorf("watcher.significantFields[%s]: %v", kind, err)
+			}
+		}
+	}
+
+	if imp := c.Client.Impersonate; imp.User == "" && (len(imp.Groups) > 0 || len(imp.Extra) > 0) {
+		return fmt.Errorf("client.impersonate: user is required when groups or extra are set")
+	}
+
+	if c.KubeconfigSecret != nil && c.KubeconfigSecret.Name == "" {
+		return fmt.Errorf("kubeconfigSecret: name is required")
+	}
+
+	if c.Watcher.DrainTimeout < 0 {
+		return fmt.Errorf("watcher.drainTimeout cannot be negative")
+	}
+
+	if err := validateResyncPeriod(c.Watcher.ResyncPeriod); err != nil {
+		return fmt.Errorf("watcher.resyncPeriod %v", err)
+	}
+
+	if err := c.Watcher.GitOps.Validate(); err != nil {
+		return fmt.Errorf("watcher.gitops: %v", err)
+	}
+
+	if c.Watcher.Baseline.MaxObjects < 0 {
+		return fmt.Errorf("watcher.baseline.maxObjects cannot be negative")
+	}
+	if c.Watcher.Baseline.Store && !c.Store.Enabled {
+		return fmt.Errorf("watcher.baseline.store requires store.enabled")
+	}
+
+	reportNames := make(map[string]bool)
+	for _, report := range c.Watcher.Reports {
+		if report.Name == "" {
+			return fmt.Errorf("watcher.reports: name is required")
+		}
+		if reportNames[report.Name] {
+			return fmt.Errorf("watcher.reports: duplicate name %q", report.Name)
+		}
+		reportNames[report.Name] = true
+		if _, err := cron.ParseStandard(report.Schedule); err != nil {
+			return fmt.Errorf("watcher.reports %s: invalid schedule %q: %v", report.Name, report.Schedule, err)
+		}
+		if report.MaxChanges < 0 {
+			return fmt.Errorf("watcher.reports %s: maxChanges cannot be negative", report.Name)
+		}
+	}
+
+	switch c.Watcher.GetEngine() {
+	case WatchEngineInformer, WatchEngineRawWatch:
+	default:
+		return fmt.Errorf("watcher.engine: unsupported engine %q (supported: %s, %s)",
+			c.Watcher.Engine, WatchEngineInformer, WatchEngineRawWatch)
+	}
+
+	switch c.Watcher.GetPermissionCheck() {
+	case PermissionCheckFail, PermissionCheckWarn, PermissionCheckOff:
+	default:
+		return fmt.Errorf("watcher.permissionCheck: unsupported mode %q (supported: %s, %s, %s)",
+			c.Watcher.PermissionCheck, PermissionCheckFail, PermissionCheckWarn, PermissionCheckOff)
+	}
+
+	if c.Watcher.EventBus.NotifierWorkers < 0 || c.Watcher.EventBus.QueueSize < 0 {
+		return fmt.Errorf("eventBus.notifierWorkers and eventBus.queueSize cannot be negative")
+	}
+
+	if c.Watcher.Readiness.DisconnectTimeout < 0 {
+		return fmt.Errorf("watcher.readiness.disconnectTimeout cannot be negative")
+	}
+
+	if c.Watcher.Silences.WarnBefore < 0 {
+		return fmt.Errorf("watcher.silences.warnBefore cannot be negative")
+	}
+
+	if c.Watcher.Markers.Window < 0 {
+		return fmt.Errorf("watcher.markers.window cannot be negative")
+	}
+
+	if err := c.Watcher.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("rate limit configuration: %v", err)
+	}
+
+	if c.Watcher.PodAlerts.MinRestarts < 0 {
+		return fmt.Errorf("podAlerts.minRestarts cannot be negative")
+	}
+
+	if c.Watcher.CertificateAlerts.ExpiryThreshold < 0 || c.Watcher.CertificateAlerts.CheckInterval < 0 {
+		return fmt.Errorf("certificateAlerts.expiryThreshold and checkInterval cannot be negative")
+	}
+
+	if severity := c.Watcher.IngressAlerts.HostRemovedSeverity; severity != "" {
+		if err := validateSeverity(severity); err != nil {
+			return fmt.Errorf("ingressAlerts.hostRemovedSeverity: %w", err)
+		}
+	}
+
+	if c.Watcher.ConfigMapDiff.MaxSize < 0 {
+		return fmt.Errorf("configMapDiff.maxSize cannot be negative")
+	}
+
+	if c.Watcher.HelmValuesDiff.MaxSize < 0 {
+		return fmt.Errorf("helmValuesDiff.maxSize cannot be negative")
+	}
+
+	if c.Watcher.BurstProtection.MaxPerMinute < 0 {
+		return fmt.Errorf("burst protection configuration: maxPerMinute cannot be negative")
+	}
+
+	if err := c.Watcher.AnomalyDetection.Validate(); err != nil {
+		return fmt.Errorf("anomaly detection configuration: %v", err)
+	}
+
+	return nil
+}
+
+// ClusterScopedKinds lists the supported kinds that have no namespace
+var ClusterScopedKinds = map[string]bool{
+	"ClusterRole":               true,
+	"ClusterRoleBinding":        true,
+	"Node":                      true,
+	"PersistentVolume":          true,
+	"CertificateSigningRequest": true,
+	"CustomResourceDefinition":  true,
+}
+
+func (r *ResourceConfig) Validate() error {
+	if r.Kind == "" {
+		return fmt.Errorf("kind is required")
+	}
+	// Namespace can be empty to watch all namespaces
+	if ClusterScopedKinds[r.Kind] && (r.Namespace != "" || len(r.Namespaces) > 0 || len(r.ExcludeNamespaces) > 0) {
+		return fmt.Errorf("%s is cluster-scoped and cannot be filtered by namespace", r.Kind)
+	}
+	if r.Namespace != "" && len(r.Namespaces) > 0 {
+		return fmt.Errorf("namespace and namespaces cannot both be set")
+	}
+	if r.ResourceName != "" && len(r.ResourceNames) > 0 {
+		return fmt.Errorf("resourceName and resourceNames cannot both be set")
+	}
+	for _, name := range r.ResourceNames {
+		if name == "" {
+			return fmt.Errorf("resourceNames cannot contain an empty name")
+		}
+	}
+	for _, pattern := range append(append([]string(nil), r.Namespaces...), r.ExcludeNamespaces...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid namespace pattern %q: %v", pattern, err)
+		}
+	}
+	if len(r.EventTypes) > 0 && (r.Kind == "Pod" || r.Kind == "Event") {
+		return fmt.Errorf("eventTypes cannot be set for %s, which has its own alert types", r.Kind)
+	}
+	if r.HelmReleases && r.Kind != "Secret" {
+		return fmt.Errorf("helmReleases is only supported for kind Secret")
+	}
+	if r.HelmReleases && len(r.EventTypes) > 0 {
+		return fmt.Errorf("eventTypes cannot be set with helmReleases, which has its own event types")
+	}
+	if r.ServiceAccountTokens && r.Kind != "Secret" {
+		return fmt.Errorf("serviceAccountTokens is only supported for kind Secret")
+	}
+	if r.ServiceAccountTokens && r.HelmReleases {
+		return fmt.Errorf("serviceAccountTokens and helmReleases cannot both be set")
+	}
+	if len(r.WatchExpressions) > 0 && (r.Kind == "Pod" || r.Kind == "Event") {
+		return fmt.Errorf("watchExpressions cannot be set for %s, which has its own alert types", r.Kind)
+	}
+	if len(r.WatchExpressions) > 0 && r.HelmReleases {
+		return fmt.Errorf("watchExpressions cannot be set with helmReleases, which has its own event types")
+	}
+	for _, text := range r.WatchExpressions {
+		if _, err := fieldpath.Parse(text); err != nil {
+			return fmt.Errorf("watchExpressions: %v", err)
+		}
+	}
+	if r.Filter != "" && (r.Kind == "Pod" || r.Kind == "Event") {
+		return fmt.Errorf("filter cannot be set for %s, which has its own alert types", r.Kind)
+	}
+	if r.Filter != "" && r.HelmReleases {
+		return fmt.Errorf("filter cannot be set with helmReleases, which has its own event types")
+	}
+	if r.Filter != "" {
+		if _, err := celfilter.Compile(r.Filter); err != nil {
+			return fmt.Errorf("filter: %v", err)
+		}
+	}
+	if err := validateResyncPeriod(r.ResyncPeriod); err != nil {
+		return fmt.Errorf("resyncPeriod %v", err)
+	}
+	for _, eventType := range r.EventTypes {
+		switch eventType {
+		case "ADDED", "MODIFIED", "DELETED":
+		default:
+			return fmt.Errorf("invalid event type %q (allowed: ADDED, MODIFIED, DELETED)", eventType)
+		}
+	}
+	if r.EventFilter != nil {
+		if r.Kind != "Event" {
+			return fmt.Errorf("eventFilter is only supported for kind Event")
+		}
+		filter := r.EventFilter
+		for _, pattern := range append(append(append([]string(nil), filter.Reasons...), filter.Types...), filter.InvolvedKinds...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("eventFilter: invalid pattern %q: %v", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// NotifiesEventType reports whether this entry wants ADDED, MODIFIED or DELETED events
+func (r *ResourceConfig) NotifiesEventType(eventType string) bool {
+	if len(r.EventTypes) == 0 {
+		return true
+	}
+	for _, wanted := range r.EventTypes {
+		if wanted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the object names this entry selects; empty means all
+func (r *ResourceConfig) Names() []string {
+	if r.ResourceName != "" {
+		return []string{r.ResourceName}
+	}
+	return r.ResourceNames
+}
+
+// MatchesName reports whether objects named name are selected by this entry
+func (r *ResourceConfig) MatchesName(name string) bool {
+	names := r.Names()
+	if len(names) == 0 {
+		return true
+	}
+	for _, selected := range names {
+		if selected == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesNamespace reports whether objects in namespace are selected by this entry
+func (r *ResourceConfig) MatchesNamespace(namespace string) bool {
+	if MatchAny(r.ExcludeNamespaces, namespace) {
+		return false
+	}
+	if r.Namespace != "" {
+		return namespace == r.Namespace
+	}
+	if len(r.Namespaces) > 0 {
+		return MatchAny(r.Namespaces, namespace)
+	}
+	return true
+}
+
+func (r *RateLimitConfig) Validate() error {
+	if r.MaxNotifications < 0 {
+		return fmt.Errorf("maxNotifications cannot be negative")
+	}
+	if r.Period < 0 {
+		return fmt.Errorf("period cannot be negative")
+	}
+	return nil
+}
+
+// NotifierNames returns the names of the enabled notifiers that routing rules can target
+func (c *Config) NotifierNames() []string {
+	var names []string
+	if c.Email.IsEnabled() {
+		names = append(names, "email")
+	}
+	if c.Teams.Enabled {
+		names = append(names, "teams")
+	}
+	if c.Webhook.Enabled {
+		names = append(names, "webhook")
+	}
+	if c.LogSink.Enabled {
+		names = append(names, "logSink")
+	}
+	if c.Publisher.Enabled {
+		names = append(names, "publisher")
+	}
+	if c.SNS.Enabled {
+		names = append(names, "sns")
+	}
+	if c.PubSub.Enabled {
+		names = append(names, "pubsub")
+	}
+	if c.Opsgenie.Enabled {
+		names = append(names, "opsgenie")
+	}
+	if c.SplunkOnCall.Enabled {
+		names = append(names, "splunkOnCall")
+	}
+	return names
+}
+
+func (r *RoutingConfig) Validate(notifiers []string) error {
+	known := make(map[string]bool, len(notifiers))
+	for _, name := range notifiers {
+		known[name] = true
+	}
+	checkNotifiers := func(names []string) error {
+		for _, name := range names {
+			if !known[name] {
+				return fmt.Errorf("unknown notifier %q (enabled notifiers: %s)", name, strings.Join(notifiers, ", "))
+			}
+		}
+		return nil
+	}
+
+	for i, ruleset := range r.Rulesets {
+		if ruleset.Name == "" {
+			return fmt.Errorf("rulesets[%d]: name is required", i)
+		}
+		switch ruleset.Mode {
+		case "", RoutingModeFirstMatch, RoutingModeAllMatch:
+		default:
+			return fmt.Errorf("ruleset %s: invalid mode %q (valid modes: %s, %s)", ruleset.Name, ruleset.Mode, RoutingModeFirstMatch, RoutingModeAllMatch)
+		}
+		if err := checkNotifiers(ruleset.DefaultNotifiers); err != nil {
+			return fmt.Errorf("ruleset %s: defaultNotifiers: %v", ruleset.Name, err)
+		}
+
+		for j, rule := range ruleset.Rules {
+			if rule.Name == "" {
+				return fmt.Errorf("ruleset %s: rules[%d]: name is required", ruleset.Name, j)
+			}
+			switch rule.Action {
+			case "", RuleActionNotify:
+				if len(rule.Notifiers) == 0 {
+					return fmt.Errorf("ruleset %s: rule %s: at least one notifier is required", ruleset.Name, rule.Name)
+				}
+			case RuleActionDrop:
+			default:
+				return fmt.Errorf("ruleset %s: rule %s: invalid action %q (valid actions: %s, %s)", ruleset.Name, rule.Name, rule.Action, RuleActionNotify, RuleActionDrop)
+			}
+			if err := checkNotifiers(rule.Notifiers); err != nil {
+				return fmt.Errorf("ruleset %s: rule %s: %v", ruleset.Name, rule.Name, err)
+			}
+			if err := rule.Match.Validate(); err != nil {
+				return fmt.Errorf("ruleset %s: rule %s: %v", ruleset.Name, rule.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetDefault returns the severity of events no rule matches, defaulting to info
+func (s *SeverityConfig) GetDefault() string {
+	if s.Default == "" {
+		return SeverityInfo
+	}
+	return s.Default
+}
+
+func (s *SeverityConfig) Validate() error {
+	if err := validateSeverity(s.GetDefault()); err != nil {
+		return fmt.Errorf("default: %v", err)
+	}
+	for i, rule := range s.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if err := validateSeverity(rule.Severity); err != nil {
+			return fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+		if err := rule.Match.Validate(); err != nil {
+			return fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// GetReplacement returns the text redacted content is replaced with
+func (r *RedactionConfig) GetReplacement() string {
+	if r.Replacement != "" {
+		return r.Replacement
+	}
+	return RedactedValue
+}
+
+func (r *RedactionConfig) Validate(notifiers []string) error {
+	known := make(map[string]bool, len(notifiers))
+	for _, name := range notifiers {
+		known[name] = true
+	}
+	for i, rule := range r.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if len(rule.Patterns) == 0 && len(rule.Secrets) == 0 && len(rule.Annotations) == 0 {
+			return fmt.Errorf("rule %s: at least one of patterns, secrets or annotations is required", rule.Name)
+		}
+		for _, pattern := range rule.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("rule %s: invalid pattern %q: %v", rule.Name, pattern, err)
+			}
+		}
+		for _, secret := range rule.Secrets {
+			if secret.Namespace == "" || secret.Name == "" {
+				return fmt.Errorf("rule %s: secrets need a namespace and a name", rule.Name)
+			}
+		}
+		for _, pattern := range rule.Annotations {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %s: invalid annotation pattern %q: %v", rule.Name, pattern, err)
+			}
+		}
+		for _, name := range rule.Notifiers {
+			if !known[name] {
+				return fmt.Errorf("rule %s: unknown notifier %q (enabled notifiers: %s)", rule.Name, name, strings.Join(notifiers, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func validateSeverity(severity string) error {
+	switch severity {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return nil
+	default:
+		return fmt.Errorf("invalid severity %q (valid severities: %s, %s, %s)", severity, SeverityInfo, SeverityWarning, SeverityCritical)
+	}
+}
+
+func (m *MatchConfig) Validate() error {
+	for _, pattern := range append(append(append(append([]string(nil), m.Namespaces...), m.Names...), m.Clusters...), m.ChangedBy...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	for _, origin := range m.Origins {
+		switch origin {
+		case ChangeOriginArgoCD, ChangeOriginFlux, ChangeOriginOther:
+		default:
+			return fmt.Errorf("invalid origin %q (valid origins: %s, %s, %s)", origin, ChangeOriginArgoCD, ChangeOriginFlux, ChangeOriginOther)
+		}
+	}
+	if m.Expression != "" {
+		if _, err := celfilter.Compile(m.Expression); err != nil {
+			return fmt.Errorf("expression: %v", err)
+		}
+	}
+	return nil
+}
+
+func (t *TeamsConfig) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+	if len(t.Webhooks) == 0 {
+		return fmt.Errorf("at least one webhook is required when Teams notifications are enabled")
+	}
+	if t.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if t.MaxMessageSize < 0 {
+		return fmt.Errorf("maxMessageSize cannot be negative")
+	}
+	if t.Concurrency.MaxInFlight < 0 {
+		return fmt.Errorf("concurrency.maxInFlight cannot be negative")
+	}
+
+	for i, webhook := range t.Webhooks {
+		if webhook.Name == "" {
+			return fmt.Errorf("webhooks[%d]: name is required", i)
+		}
+		if webhook.URL == "" && webhook.URLEnv == "" {
+			return fmt.Errorf("webhooks[%d]: url or urlEnv is required", i)
+		}
+		for _, pattern := range webhook.Namespaces {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("webhooks[%d]: invalid namespace pattern %q: %v", i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ServerConfig) Validate() error {
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid port %d", s.Port)
+	}
+	if _, _, err := net.SplitHostPort(s.Address); err == nil {
+		return fmt.Errorf("address %q must not include a port; set port instead", s.Address)
+	}
+	if s.ReadTimeout < 0 || s.WriteTimeout < 0 {
+		return fmt.Errorf("readTimeout and writeTimeout must not be negative")
+	}
+	if s.TLS != nil && (s.TLS.CertFile == "" || s.TLS.KeyFile == "") {
+		return fmt.Errorf("tls.certFile and tls.keyFile are required")
+	}
+	admin := s.Admin
+	if !admin.Enabled {
+		return nil
+	}
+	if admin.Port < 0 || admin.Port > 65535 {
+		return fmt.Errorf("admin: invalid port %d", admin.Port)
+	}
+	if admin.GetPort() == s.GetPort() {
+		return fmt.Errorf("admin: port %d is also the main port", admin.GetPort())
+	}
+	if admin.TLS != nil && (admin.TLS.CertFile == "" || admin.TLS.KeyFile == "") {
+		return fmt.Errorf("admin: tls.certFile and tls.keyFile are required")
+	}
+	// An unset variable would otherwise leave the admin port open
+	if admin.BearerTokenEnv != "" && admin.GetBearerToken() == "" {
+		return fmt.Errorf("admin: bearerTokenEnv %s is unset or empty", admin.BearerTokenEnv)
+	}
+	return nil
+}
+
+func (s *StoreConfig) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	switch s.GetDriver() {
+	case StoreDriverFile:
+		if s.Path == "" {
+			return fmt.Errorf("path is required for the file driver")
+		}
+	default:
+		return fmt.Errorf("unsupported driver %q (supported: %s)", s.Driver, StoreDriverFile)
+	}
+	if s.MaxSize < 0 || s.MaxAge < 0 || s.MaxFiles < 0 {
+		return fmt.Errorf("maxSize, maxAge and maxFiles cannot be negative")
+	}
+	return nil
+}
+
+func (a *ArchiveConfig) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	switch a.GetDriver() {
+	case ArchiveDriverFile:
+		if a.Path == "" {
+			return fmt.Errorf("path is required for the file driver")
+		}
+	case ArchiveDriverS3, ArchiveDriverGCS:
+		if a.Bucket == "" {
+			return fmt.Errorf("bucket is required for the %s driver", a.GetDriver())
+		}
+		if a.GetDriver() == ArchiveDriverS3 && a.GetRegion() == "" {
+			return fmt.Errorf("region is required for the s3 driver")
+		}
+	default:
+		return fmt.Errorf("unsupported driver %q (supported: %s, %s, %s)", a.Driver, ArchiveDriverFile, ArchiveDriverS3, ArchiveDriverGCS)
+	}
+	if a.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+func (s *StorageConfig) Validate() error {
+	switch s.GetDriver() {
+	case StorageDriverMemory:
+	case StorageDriverFile:
+		if s.Path == "" {
+			return fmt.Errorf("path is required for the file driver")
+		}
+	case StorageDriverConfigMap:
+		if s.ConfigMap == "" {
+			return fmt.Errorf("configMap is required for the configmap driver")
+		}
+	default:
+		return fmt.Errorf("unsupported driver %q (supported: %s, %s, %s)",
+			s.Driver, StorageDriverMemory, StorageDriverFile, StorageDriverConfigMap)
+	}
+	return nil
+}
+
+func (w *WebhookConfig) Validate() error {
+	if !w.Enabled {
+		return nil
+	}
+	if w.URL == "" && w.URLEnv == "" {
+		return fmt.Errorf("url or urlEnv is required")
+	}
+	// An unset variable would otherwise leave every event posted to an empty URL
+	if w.GetURL() == "" {
+		return fmt.Errorf("urlEnv %s is unset or empty", w.URLEnv)
+	}
+	if w.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if w.MaxMessageSize < 0 {
+		return fmt.Errorf("maxMessageSize cannot be negative")
+	}
+	if w.Concurrency.MaxInFlight < 0 {
+		return fmt.Errorf("concurrency.maxInFlight cannot be negative")
+	}
+	if w.OAuth2 != nil {
+		if err := w.OAuth2.Validate(); err != nil {
+			return err
+		}
+		if _, ok := w.Headers["Authorization"]; ok {
+			return fmt.Errorf("headers.Authorization cannot be combined with oauth2")
+		}
+	}
+	return nil
+}
+
+func (o *OpsgenieConfig) Validate() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.APIKey == "" && o.APIKeyEnv == "" {
+		return fmt.Errorf("apiKey or apiKeyEnv is required")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+func (s *SplunkOnCallConfig) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.APIKey == "" && s.APIKeyEnv == "" {
+		return fmt.Errorf("apiKey or apiKeyEnv is required")
+	}
+	if s.RoutingKey == "" {
+		return fmt.Errorf("routingKey is required")
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+func (p *PublisherConfig) Validate() error {
+	if !p.Enabled {
+		return nil
+	}
+	switch p.GetBroker() {
+	case PublisherBrokerNATS:
+		if p.NATS.URL == "" {
+			return fmt.Errorf("nats.url is required")
+		}
+		if p.NATS.Subject == "" {
+			return fmt.Errorf("nats.subject is required")
+		}
+	case PublisherBrokerKafka:
+		if p.Kafka.RESTProxyURL == "" {
+			return fmt.Errorf("kafka.restProxyURL is required")
+		}
+		if p.Kafka.Topic == "" {
+			return fmt.Errorf("kafka.topic is required")
+		}
+	default:
+		return fmt.Errorf("unsupported broker %q (supported: %s, %s)", p.Broker, PublisherBrokerNATS, PublisherBrokerKafka)
+	}
+	if err := validatePublishFormat(p.GetFormat()); err != nil {
+		return err
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+func (s *SNSConfig) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.TopicARN == "" {
+		return fmt.Errorf("topicARN is required")
+	}
+	if parts := strings.Split(s.TopicARN, ":"); len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" || parts[3] == "" {
+		return fmt.Errorf("invalid topicARN %q (expected arn:aws:sns:<region>:<account>:<topic>)", s.TopicARN)
+	}
+	if err := validatePublishFormat(s.GetFormat()); err != nil {
+		return err
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+func (p *PubSubConfig) Validate() error {
+	if !p.Enabled {
+		return nil
+	}
+	if p.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if p.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	if strings.Contains(p.Topic, "/") {
+		return fmt.Errorf("topic must be a topic ID, not %q", p.Topic)
+	}
+	if err := validatePublishFormat(p.GetFormat()); err != nil {
+		return err
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+func validatePublishFormat(format string) error {
+	switch format {
+	case PublisherFormatJSON, PublisherFormatCloudEvents:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q (supported: %s, %s)", format, PublisherFormatJSON, PublisherFormatCloudEvents)
+	}
+}
+
+func (o *OAuth2Config) Validate() error {
+	if o.TokenURL == "" {
+		return fmt.Errorf("oauth2.tokenURL is required")
+	}
+	if o.ClientID == "" {
+		return fmt.Errorf("oauth2.clientID is required")
+	}
+	if o.ClientSecret == "" && o.ClientSecretEnv == "" {
+		return fmt.Errorf("oauth2.clientSecret or oauth2.clientSecretEnv is required")
+	}
+	return nil
+}
+
+func (e *EmailConfig) Validate() error {
+	if !e.IsEnabled() {
+		return nil
+	}
+	switch e.GetProvider() {
+	case EmailProviderSMTP:
+		if e.SMTPHost == "" {
+			return fmt.Errorf("SMTP host is required")
+		}
+		if e.SMTPPort <= 0 || e.SMTPPort > 65535 {
+			return fmt.Errorf("SMTP port must be between 1 and 65535")
+		}
+	case EmailProviderSES:
+		if e.SES.GetRegion() == "" {
+			return fmt.Errorf("ses.region (or AWS_REGION) is required")
+		}
+	case EmailProviderSendGrid:
+		if e.SendGrid.APIKey == "" && e.SendGrid.APIKeyEnv == "" {
+			return fmt.Errorf("sendgrid.apiKey or sendgrid.apiKeyEnv is required")
+		}
+	default:
+		return fmt.Errorf("unsupported provider %q (supported: %s, %s, %s)",
+			e.Provider, EmailProviderSMTP, EmailProviderSES, EmailProviderSendGrid)
+	}
+	if e.FromEmail == "" {
+		return fmt.Errorf("from email is required")
+	}
+	if e.MaxMessageSize < 0 {
+		return fmt.Errorf("maxMessageSize cannot be negative")
+	}
+	if e.Concurrency.MaxInFlight < 0 {
+		return fmt.Errorf("concurrency.maxInFlight cannot be negative")
+	}
+	if len(e.ToEmails) == 0 {
+		return fmt.Errorf("at least one recipient email is required")
+	}
+
+	for i, email := range e.ToEmails {
+		if strings.TrimSpace(email) == "" {
+			return fmt.Errorf("toEmails[%d]: email address cannot be empty", i)
+		}
+	}
+
+	for i, recipients := range e.Recipients {
+		if len(recipients.Namespaces) == 0 && len(recipients.Labels) == 0 {
+			return fmt.Errorf("recipients[%d]: namespaces or labels are required", i)
+		}
+		if len(recipients.ToEmails) == 0 {
+			return fmt.Errorf("recipients[%d]: at least one recipient email is required", i)
+		}
+		for j, email := range recipients.ToEmails {
+			if strings.TrimSpace(email) == "" {
+				return fmt.Errorf("recipients[%d].toEmails[%d]: email address cannot be empty", i, j)
+			}
+		}
+		for _, pattern := range recipients.Namespaces {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("recipients[%d]: invalid namespace pattern %q: %v", i, pattern, err)
+			}
+		}
+		for key, pattern := range recipients.Labels {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("recipients[%d]: invalid pattern %q for label %s: %v", i, pattern, key, err)
+			}
+		}
+	}
+
+	if _, err := templates.ParseText("subject", e.SubjectTemplate); err != nil {
+		return fmt.Errorf("invalid subject template: %v", err)
+	}
+	if _, err := templates.ParseText("body", e.BodyTemplate); err != nil {
+		return fmt.Errorf("invalid body template: %v", err)
+	}
+	if e.HTMLTemplate != "" && e.HTMLTemplateFile != "" {
+		return fmt.Errorf("htmlTemplate and htmlTemplateFile are mutually exclusive")
+	}
+	if _, err := templates.ParseHTML("html", e.HTMLTemplate); err != nil {
+		return fmt.Errorf("invalid HTML template: %v", err)
+	}
+
+	if e.ConnectTimeout < 0 || e.SendTimeout < 0 {
+		return fmt.Errorf("SMTP timeouts cannot be negative")
+	}
+	if e.SourceAddress != "" && net.ParseIP(e.SourceAddress) == nil {
+		return fmt.Errorf("source address %q is not a valid IP address", e.SourceAddress)
+	}
+
+	// Authentication settings only apply to SMTP relays
+	if e.GetProvider() != EmailProviderSMTP {
+		return nil
+	}
+	if e.OAuth2 != nil {
+		if err := e.OAuth2.Validate(); err != nil {
+			return err
+		}
+		if e.SMTPUsername == "" {
+			return fmt.Errorf("SMTP username (the mailbox to send as) is required with oauth2")
+		}
+	} else if e.UseAuth {
+		if e.SMTPUsername == "" {
+			return fmt.Errorf("SMTP username is required when authentication is enabled")
+		}
+		if e.SMTPPassword == "" {
+			return fmt.Errorf("SMTP password is required when authentication is enabled")
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) LoadEmailConfig() error {
+	if !c.Email.IsEnabled() {
+		return nil
+	}
+	if c.Email.HTMLTemplateFile != "" {
+		htmlTemplate, err := os.ReadFile(c.Email.HTMLTemplateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read HTML template: %v", err)
+		}
+		if _, err := templates.ParseHTML("html", string(htmlTemplate)); err != nil {
+			return fmt.Errorf("invalid HTML template %s: %v", c.Email.HTMLTemplateFile, err)
+		}
+		c.Email.HTMLTemplate = string(htmlTemplate)
+	}
+
+	if secretUsername, err := os.ReadFile("/etc/resource-watcher/secrets/smtp-username"); err == nil {
+		c.Email.SMTPUsername = strings.TrimSpace(string(secretUsername))
+		c.Email.UseAuth = true
+	}
+	if secretPassword, err := os.ReadFile("/etc/resource-watcher/secrets/smtp-password"); err == nil {
+		c.Email.SMTPPassword = strings.TrimSpace(string(secretPassword))
+		c.Email.UseAuth = true
+	}
+	if secretFromEmail, err := os.ReadFile("/etc/resource-watcher/secrets/from-email"); err == nil {
+		c.Email.FromEmail = strings.TrimSpace(string(secretFromEmail))
+	}
+	if secretToEmails, err := os.ReadFile("/etc/resource-watcher/secrets/to-emails"); err == nil {
+		emails := strings.Split(strings.TrimSpace(string(secretToEmails)), ",")
+		for i, email := range emails {
+			emails[i] = strings.TrimSpace(email)
+		}
+		c.Email.ToEmails = emails
+	}
+
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		c.Email.SMTPUsername = strings.TrimSpace(username)
+		c.Email.UseAuth = true
+	}
+	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
+		c.Email.SMTPPassword = strings.TrimSpace(password)
+		c.Email.UseAuth = true
+	}
+	if fromEmail := os.Getenv("FROM_EMAIL"); fromEmail != "" {
+		c.Email.FromEmail = strings.TrimSpace(fromEmail)
+	}
+	if toEmails := os.Getenv("TO_EMAILS"); toEmails != "" {
+		emails := strings.Split(strings.TrimSpace(toEmails), ",")
+		for i, email := range emails {
+			emails[i] = strings.TrimSpace(email)
+		}
+		c.Email.ToEmails = emails
+	}
+	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
+		c.Email.SMTPHost = strings.TrimSpace(smtpHost)
+	}
+	if smtpPort := os.Getenv("SMTP_PORT"); smtpPort != "" {
+		var port int
+		if _, err := fmt.Sscanf(smtpPort, "%d", &port); err == nil {
+			c.Email.SMTPPort = port
+		}
+	}
+	if connectTimeout := os.Getenv("SMTP_CONNECT_TIMEOUT"); connectTimeout != "" {
+		if d, err := time.ParseDuration(strings.TrimSpace(connectTimeout)); err == nil {
+			c.Email.ConnectTimeout = d
+		}
+	}
+	if sendTimeout := os.Getenv("SMTP_SEND_TIMEOUT"); sendTimeout != "" {
+		if d, err := time.ParseDuration(strings.TrimSpace(sendTimeout)); err == nil {
+			c.Email.SendTimeout = d
+		}
+	}
+	if sourceAddress := os.Getenv("SMTP_SOURCE_ADDRESS"); sourceAddress != "" {
+		c.Email.SourceAddress = strings.TrimSpace(sourceAddress)
+	}
+	if useAuth := os.Getenv("SMTP_USE_AUTH"); useAuth != "" {
+		c.Email.UseAuth = useAuth == "true"
+	}
+
+	if c.Email.SMTPPort == 0 {
+		if c.Email.UseAuth {
+			c.Email.SMTPPort = 587
+		} else {
+			c.Email.SMTPPort = 25
+		}
+	}
+
+	// Validate the final configuration
+	return c.Email.Validate()
+}
+
+// LoadServerConfig applies the SERVER_* environment variables to the server configuration,
+// e.g. to give instances sharing the host network their own ports, and validates the result
+func (c *Config) LoadServerConfig() error {
+	if port := os.Getenv("SERVER_PORT"); port != "" {
+		if _, err := fmt.Sscanf(port, "%d", &c.Server.Port); err != nil {
+			return fmt.Errorf("invalid SERVER_PORT %q", port)
+		}
+	}
+	if port := os.Getenv("SERVER_ADMIN_PORT"); port != "" {
+		if _, err := fmt.Sscanf(port, "%d", &c.Server.Admin.Port); err != nil {
+			return fmt.Errorf("invalid SERVER_ADMIN_PORT %q", port)
+		}
+	}
+	if address := os.Getenv("SERVER_ADDRESS"); address != "" {
+		c.Server.Address = strings.TrimSpace(address)
+	}
+	if readTimeout := os.Getenv("SERVER_READ_TIMEOUT"); readTimeout != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(readTimeout))
+		if err != nil {
+			return fmt.Errorf("invalid SERVER_READ_TIMEOUT: %w", err)
+		}
+		c.Server.ReadTimeout = d
+	}
+	if writeTimeout := os.Getenv("SERVER_WRITE_TIMEOUT"); writeTimeout != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(writeTimeout))
+		if err != nil {
+			return fmt.Errorf("invalid SERVER_WRITE_TIMEOUT: %w", err)
+		}
+		c.Server.WriteTimeout = d
+	}
+	certFile, keyFile := os.Getenv("SERVER_TLS_CERT_FILE"), os.Getenv("SERVER_TLS_KEY_FILE")
+	if certFile != "" || keyFile != "" {
+		c.Server.TLS = &ServerTLSConfig{CertFile: strings.TrimSpace(certFile), KeyFile: strings.TrimSpace(keyFile)}
+	}
+	return c.Server.Validate()
+}
+
+// LoadLoggingConfig loads logging configuration from environment variables
+func (c *Config) LoadLoggingConfig() error {
+	// Set defaults
+	if c.Logging.Level == "" {
+		c.Logging.Level = "info"
+	}
+	if c.Logging.Format == "" {
+		c.Logging.Format = "text"
+	}
+
+	// Override with environment variables
+	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
+		c.Logging.Level = strings.ToLower(strings.TrimSpace(logLevel))
+	}
+	if logFormat := os.Getenv("LOG_FORMAT"); logFormat != "" {
+		c.Logging.Format = strings.ToLower(strings.TrimSpace(logFormat))
+	}
+	if enableJSON := os.Getenv("LOG_JSON"); enableJSON != "" {
+		c.Logging.EnableJSON = enableJSON == "true"
+	}
+
+	// Validate log level
+	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	if !validLevels[c.Logging.Level] {
+		return fmt.Errorf("invalid log level: %s (valid levels: debug, info, warn, error)", c.Logging.Level)
+	}
+
+	// Validate log format
+	validFormats := map[string]bool{"text": true, "json": true}
+	if !validFormats[c.Logging.Format] {
+		return fmt.Errorf("invalid log format: %s (valid formats: text, json)", c.Logging.Format)
+	}
+
+	return nil
+}
+
+// GetDeploymentImportantFields returns the important pod template fields, with defaults if not configured.
+// They apply to every workload kind (Deployment, StatefulSet, DaemonSet, Job, CronJob).
+func (w *WatcherConfig) GetDeploymentImportantFields() []string {
+	if len(w.DeploymentImportantFields) > 0 {
+		return w.DeploymentImportantFields
+	}
+
+	// Default important fields for deployments - production-ready defaults
+	return []string{
+		"containers",         // Image, env vars, resources, ports
+		"volumes",            // Volume mounts and configurations
+		"serviceAccountName", // Service account changes
+		"nodeSelector",       // Node selection rules
+		"affinity",           // Pod affinity/anti-affinity
+		"tolerations",        // Node tolerations
+		"securityContext",    // Security settings
+		"imagePullSecrets",   // Image pull secrets
+		"hostAliases",        // Host alias configurations
+		"initContainers",     // Init container changes
+	}
+}
+
+// GetIgnoreFields returns the ignored field paths for kind, including those configured for every kind
+func (w *WatcherConfig) GetIgnoreFields(kind string) []string {
+	return append(append([]string(nil), w.IgnoreFields["*"]...), w.IgnoreFields[kind]...)
+}
+
+// GetEventDeduplicationWindow returns the deduplication window with a sensible default
+func (w *WatcherConfig) GetEventDeduplicationWindow() time.Duration {
+	if w.EventDeduplicationWindow > 0 {
+		return w.EventDeduplicationWindow
+	}
+	return 30 * time.Second // Default 30 seconds
+}
+
+// GetMaxSize returns the ConfigMap diff size limit in bytes with a sensible default
+func (c *ConfigMapDiffConfig) GetMaxSize() int {
+	if c.MaxSize > 0 {
+		return c.MaxSize
+	}
+	return 4096
+}
+
+// GetTraceBufferSize returns the number of event traces to retain with a sensible default
+func (w *WatcherConfig) GetTraceBufferSize() int {
+	if w.TraceBufferSize > 0 {
+		return w.TraceBufferSize
+	}
+	return 200
+}
+
+// GetCacheSyncTimeout returns the informer cache sync timeout with a sensible default
+func (w *WatcherConfig) GetCacheSyncTimeout() time.Duration {
+	if w.CacheSyncTimeout > 0 {
+		return w.CacheSyncTimeout
+	}
+	return 2 * time.Minute
+}
+
+// GetResyncPeriod returns how often the informers of an entry replay their cache: the
+// entry's resyncPeriod, or watcher.resyncPeriod; 0 means never
+func (w *WatcherConfig) GetResyncPeriod(resourceConfig ResourceConfig) time.Duration {
+	if resourceConfig.ResyncPeriod > 0 {
+		return resourceConfig.ResyncPeriod
+	}
+	return w.ResyncPeriod
+}
+
+// validateResyncPeriod rejects periods informers cannot resync at; 0 is off
+func validateResyncPeriod(period time.Duration) error {
+	if period < 0 {
+		return fmt.Errorf("cannot be negative")
+	}
+	if period > 0 && period < time.Second {
+		return fmt.Errorf("must be at least 1s")
+	}
+	return nil
+}
+
+// GetEngine returns the watch engine serving every kind, defaulting to informer
+func (w *WatcherConfig) GetEngine() string {
+	if w.Engine == "" {
+		return WatchEngineInformer
+	}
+	return w.Engine
+}
+
+// GetPermissionCheck returns the startup permission check mode, defaulting to fail
+func (w *WatcherConfig) GetPermissionCheck() string {
+	if w.PermissionCheck == "" {
+		return PermissionCheckFail
+	}
+	return w.PermissionCheck
+}
+
+// GetDrainTimeout returns how long shutdown waits for in-flight notifications
+func (w *WatcherConfig) GetDrainTimeout() time.Duration {
+	if w.DrainTimeout > 0 {
+		return w.DrainTimeout
+	}
+	return 30 * time.Second
+}
+
+// GetNotifierWorkers returns how many events are notified at once with a sensible default
+func (b *EventBusConfig) GetNotifierWorkers() int {
+	if b.NotifierWorkers > 0 {
+		return b.NotifierWorkers
+	}
+	return 16
+}
+
+// GetQueueSize returns how many events each event bus consumer may fall behind with a sensible default
+func (b *EventBusConfig) GetQueueSize() int {
+	if b.QueueSize > 0 {
+		return b.QueueSize
+	}
+	return 1000
+}
+
+// IsResourceVersionCheckEnabled returns whether resource version checking is enabled
+func (w *WatcherConfig) IsResourceVersionCheckEnabled() bool {
+	return w.ResourceVersionCheck
+}
+
+// IsMetricsEnabled returns whether metrics collection is enabled
+func (w *WatcherConfig) IsMetricsEnabled() bool {
+	return w.MetricsEnabled
+}
+
+// IsEnabled returns whether the email notifier is enabled, which it is unless turned off
+func (e *EmailConfig) IsEnabled() bool {
+	return e.Enabled == nil || *e.Enabled
+}
+
+// GetConnectTimeout returns the SMTP connect timeout with a sensible default
+func (e *EmailConfig) GetConnectTimeout() time.Duration {
+	if e.ConnectTimeout > 0 {
+		return e.ConnectTimeout
+	}
+	return 10 * time.Second
+}
+
+// GetProvider returns the email delivery provider, defaulting to SMTP
+func (e *EmailConfig) GetProvider() string {
+	if e.Provider != "" {
+		return e.Provider
+	}
+	return EmailProviderSMTP
+}
+
+// GetMaxMessageSize returns the message size limit in bytes, defaulting to the provider's limit
+func (e *EmailConfig) GetMaxMessageSize() int {
+	if e.MaxMessageSize > 0 {
+		return e.MaxMessageSize
+	}
+	switch e.GetProvider() {
+	case EmailProviderSendGrid:
+		return 30 << 20
+	default:
+		// SES, and the common default of SMTP relays such as Exchange and Postfix
+		return 10 << 20
+	}
+}
+
+// GetRegion returns the SES region, falling back to the standard AWS environment variables
+func (s *SESConfig) GetRegion() string {
+	if s.Region != "" {
+		return s.Region
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// GetEndpoint returns the SES API endpoint, defaulting to the region's public one
+func (s *SESConfig) GetEndpoint() string {
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/")
+	}
+	return "https://email." + s.GetRegion() + ".amazonaws.com"
+}
+
+// GetAPIKey returns the SendGrid API key, resolving it from the environment when apiKeyEnv is set
+func (s *SendGridConfig) GetAPIKey() string {
+	if s.APIKeyEnv != "" {
+		if key := strings.TrimSpace(os.Getenv(s.APIKeyEnv)); key != "" {
+			return key
+		}
+	}
+	return s.APIKey
+}
+
+// GetEndpoint returns the SendGrid API base URL with a sensible default
+func (s *SendGridConfig) GetEndpoint() string {
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/")
+	}
+	return "https://api.sendgrid.com"
+}
+
+// GetSendTimeout returns the per-attempt send timeout with a sensible default
+func (e *EmailConfig) GetSendTimeout() time.Duration {
+	if e.SendTimeout > 0 {
+		return e.SendTimeout
+	}
+	return 30 * time.Second
 }
 
-type ResourceConfig struct {
-	Kind         string `yaml:"kind"`
-	Namespace    string `yaml:"namespace"`
-	ResourceName string `yaml:"resourceName,omitempty"`
+// GetLabels returns the node labels shown for topology enrichment with sensible defaults
+func (t *TopologyConfig) GetLabels() []string {
+	if len(t.Labels) > 0 {
+		return t.Labels
+	}
+	return []string{"topology.kubernetes.io/zone", "topology.kubernetes.io/region"}
 }
 
-type EmailConfig struct {
-	SMTPHost     string   `yaml:"smtpHost"`
-	SMTPPort     int      `yaml:"smtpPort"`
-	UseAuth      bool     `yaml:"useAuth"`
-	SMTPUsername string   `yaml:"smtpUsername,omitempty"`
-	SMTPPassword string   `yaml:"smtpPassword,omitempty"`
-	FromEmail    string   `yaml:"fromEmail"`
-	ToEmails     []string `yaml:"toEmails"`
+// GetMaxNotifications returns the per-resource notification budget with a sensible default
+func (r *RateLimitConfig) GetMaxNotifications() int {
+	if r.MaxNotifications > 0 {
+		return r.MaxNotifications
+	}
+	return 5
+}
 
-	// TLS Configuration
-	EnableTLS   bool `yaml:"enableTLS,omitempty"`
-	InsecureTLS bool `yaml:"insecureTLS,omitempty"`
-	ForceSSL    bool `yaml:"forceSSL,omitempty"`
+// GetPeriod returns the rate limit refill period with a sensible default
+func (r *RateLimitConfig) GetPeriod() time.Duration {
+	if r.Period > 0 {
+		return r.Period
+	}
+	return 10 * time.Minute
 }
 
-// LoggingConfig represents configuration for logging behavior
-type LoggingConfig struct {
-	Level      string `yaml:"level,omitempty"`      // Log level: debug, info, warn, error (default: info)
-	Format     string `yaml:"format,omitempty"`     // Log format: text, json (default: text)
-	EnableJSON bool   `yaml:"enableJSON,omitempty"` // Enable JSON logging format
+// GetMinRestarts returns the CrashLoopBackOff restart threshold with a sensible default
+func (p *PodAlertsConfig) GetMinRestarts() int {
+	if p.MinRestarts > 0 {
+		return p.MinRestarts
+	}
+	return 3
 }
 
-// Config represents the application configuration
-type Config struct {
-	ClusterName string           `yaml:"clusterName"`
-	Resources   []ResourceConfig `yaml:"resources"`
-	Email       EmailConfig      `yaml:"email"`
-	Watcher     WatcherConfig    `yaml:"watcher,omitempty"`
-	Logging     LoggingConfig    `yaml:"logging,omitempty"`
+// GetExpiryThreshold returns how long before expiry Certificates are alerted, defaulting to 14 days
+func (c *CertificateAlertsConfig) GetExpiryThreshold() time.Duration {
+	if c.ExpiryThreshold > 0 {
+		return c.ExpiryThreshold
+	}
+	return 14 * 24 * time.Hour
 }
 
-func (c *Config) Validate() error {
-	if c.ClusterName == "" {
-		return fmt.Errorf("cluster name is required")
+// GetCheckInterval returns how often Certificate expiry is checked, defaulting to an hour
+func (c *CertificateAlertsConfig) GetCheckInterval() time.Duration {
+	if c.CheckInterval > 0 {
+		return c.CheckInterval
 	}
+	return time.Hour
+}
 
-	if len(c.Resources) == 0 {
-		return fmt.Errorf("at least one resource must be configured")
+// GetDisconnectTimeout returns how long all watches may fail before the watcher is unready
+func (r *ReadinessConfig) GetDisconnectTimeout() time.Duration {
+	if r.DisconnectTimeout > 0 {
+		return r.DisconnectTimeout
 	}
+	return 5 * time.Minute
+}
 
-	for i, resource := range c.Resources {
-		if err := resource.Validate(); err != nil {
-			return fmt.Errorf("resource[%d]: %v", i, err)
-		}
+// GetWarnBefore returns how long before a silence expires its creator is warned
+func (s *SilencesConfig) GetWarnBefore() time.Duration {
+	if s.WarnBefore > 0 {
+		return s.WarnBefore
 	}
+	return 15 * time.Minute
+}
 
-	if err := c.Email.Validate(); err != nil {
-		return fmt.Errorf("email configuration: %v", err)
+// GetMaxPerMinute returns the global notification budget with a sensible default
+func (b *BurstProtectionConfig) GetMaxPerMinute() int {
+	if b.MaxPerMinute > 0 {
+		return b.MaxPerMinute
 	}
+	return 60
+}
 
-	return nil
+// GetWindow returns how long markers annotate events with a sensible default
+func (m *MarkersConfig) GetWindow() time.Duration {
+	if m.Window > 0 {
+		return m.Window
+	}
+	return 15 * time.Minute
 }
 
-func (r *ResourceConfig) Validate() error {
-	if r.Kind == "" {
-		return fmt.Errorf("kind is required")
+// GetMinEvents returns the per-minute change count an anomaly needs with a sensible default
+func (a *AnomalyDetectionConfig) GetMinEvents() int {
+	if a.MinEvents > 0 {
+		return a.MinEvents
 	}
-	// Namespace can be empty to watch all namespaces
-	return nil
+	return 20
 }
 
-func (e *EmailConfig) Validate() error {
-	if e.SMTPHost == "" {
-		return fmt.Errorf("SMTP host is required")
+// GetFactor returns the spike factor over the baseline with a sensible default
+func (a *AnomalyDetectionConfig) GetFactor() float64 {
+	if a.Factor > 0 {
+		return a.Factor
 	}
-	if e.SMTPPort <= 0 || e.SMTPPort > 65535 {
-		return fmt.Errorf("SMTP port must be between 1 and 65535")
+	return 10
+}
+
+// GetBaseline returns the baseline averaging period with a sensible default
+func (a *AnomalyDetectionConfig) GetBaseline() time.Duration {
+	if a.Baseline > 0 {
+		return a.Baseline
 	}
-	if e.FromEmail == "" {
-		return fmt.Errorf("from email is required")
+	return time.Hour
+}
+
+// GetPort returns the main HTTP port, defaulting to 8080
+func (s *ServerConfig) GetPort() int {
+	if s.Port > 0 {
+		return s.Port
 	}
-	if len(e.ToEmails) == 0 {
-		return fmt.Errorf("at least one recipient email is required")
+	return 8080
+}
+
+// ListenAddress returns the address to listen on for port, on the configured bind address
+func (s *ServerConfig) ListenAddress(port int) string {
+	return net.JoinHostPort(s.Address, strconv.Itoa(port))
+}
+
+// GetReadTimeout returns how long reading a request may take, defaulting to 30 seconds
+func (s *ServerConfig) GetReadTimeout() time.Duration {
+	if s.ReadTimeout > 0 {
+		return s.ReadTimeout
 	}
+	return 30 * time.Second
+}
 
-	for i, email := range e.ToEmails {
-		if strings.TrimSpace(email) == "" {
-			return fmt.Errorf("toEmails[%d]: email address cannot be empty", i)
-		}
+// GetWriteTimeout returns how long writing a response may take, defaulting to 60 seconds
+func (s *ServerConfig) GetWriteTimeout() time.Duration {
+	if s.WriteTimeout > 0 {
+		return s.WriteTimeout
 	}
+	return time.Minute
+}
 
-	if e.UseAuth {
-		if e.SMTPUsername == "" {
-			return fmt.Errorf("SMTP username is required when authentication is enabled")
-		}
-		if e.SMTPPassword == "" {
-			return fmt.Errorf("SMTP password is required when authentication is enabled")
+// GetPort returns the admin HTTP port, defaulting to 9090
+func (a *AdminServerConfig) GetPort() int {
+	if a.Port > 0 {
+		return a.Port
+	}
+	return 9090
+}
+
+// GetBearerToken returns the admin bearer token, resolving it from the environment when bearerTokenEnv is set
+func (a *AdminServerConfig) GetBearerToken() string {
+	if a.BearerTokenEnv != "" {
+		if token := strings.TrimSpace(os.Getenv(a.BearerTokenEnv)); token != "" {
+			return token
 		}
 	}
+	return a.BearerToken
+}
 
-	return nil
+// GetUserAgent returns the user agent sent to the API server, including the audit tag
+func (c *ClientConfig) GetUserAgent() string {
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = "k8s-resource-watcher"
+	}
+	if c.AuditTag != "" {
+		userAgent += " (" + c.AuditTag + ")"
+	}
+	return userAgent
 }
 
-func (c *Config) LoadEmailConfig() error {
-	if secretUsername, err := os.ReadFile("/etc/resource-watcher/secrets/smtp-username"); err == nil {
-		c.Email.SMTPUsername = strings.TrimSpace(string(secretUsername))
-		c.Email.UseAuth = true
+// GetNamespace returns the namespace of the kubeconfig secret, defaulting to the pod's own namespace
+func (k *KubeconfigSecretRef) GetNamespace() string {
+	if k.Namespace != "" {
+		return k.Namespace
 	}
-	if secretPassword, err := os.ReadFile("/etc/resource-watcher/secrets/smtp-password"); err == nil {
-		c.Email.SMTPPassword = strings.TrimSpace(string(secretPassword))
-		c.Email.UseAuth = true
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
 	}
-	if secretFromEmail, err := os.ReadFile("/etc/resource-watcher/secrets/from-email"); err == nil {
-		c.Email.FromEmail = strings.TrimSpace(string(secretFromEmail))
+	return "default"
+}
+
+// GetKey returns the secret key holding the kubeconfig with a sensible default
+func (k *KubeconfigSecretRef) GetKey() string {
+	if k.Key != "" {
+		return k.Key
 	}
-	if secretToEmails, err := os.ReadFile("/etc/resource-watcher/secrets/to-emails"); err == nil {
-		emails := strings.Split(strings.TrimSpace(string(secretToEmails)), ",")
-		for i, email := range emails {
-			emails[i] = strings.TrimSpace(email)
+	return "kubeconfig"
+}
+
+// GetTimeout returns the Teams request timeout with a sensible default
+func (t *TeamsConfig) GetTimeout() time.Duration {
+	if t.Timeout > 0 {
+		return t.Timeout
+	}
+	return 10 * time.Second
+}
+
+// GetMaxMessageSize returns the card payload size limit in bytes with a sensible default
+func (t *TeamsConfig) GetMaxMessageSize() int {
+	if t.MaxMessageSize > 0 {
+		return t.MaxMessageSize
+	}
+	return 28000
+}
+
+// GetURL returns the webhook URL, resolving it from the environment when urlEnv is set
+func (w *TeamsWebhookConfig) GetURL() string {
+	if w.URLEnv != "" {
+		if url := strings.TrimSpace(os.Getenv(w.URLEnv)); url != "" {
+			return url
 		}
-		c.Email.ToEmails = emails
 	}
+	return w.URL
+}
 
-	if username := os.Getenv("SMTP_USERNAME"); username != "" {
-		c.Email.SMTPUsername = strings.TrimSpace(username)
-		c.Email.UseAuth = true
+// GetDriver returns the store driver with a sensible default
+func (s *StoreConfig) GetDriver() string {
+	if s.Driver != "" {
+		return s.Driver
 	}
-	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
-		c.Email.SMTPPassword = strings.TrimSpace(password)
-		c.Email.UseAuth = true
+	return StoreDriverFile
+}
+
+// GetMaxFiles returns how many rotated event store files are kept with a sensible default
+func (s *StoreConfig) GetMaxFiles() int {
+	if s.MaxFiles > 0 {
+		return s.MaxFiles
 	}
-	if fromEmail := os.Getenv("FROM_EMAIL"); fromEmail != "" {
-		c.Email.FromEmail = strings.TrimSpace(fromEmail)
+	return 5
+}
+
+// GetDriver returns the archive driver, defaulting to file
+func (a *ArchiveConfig) GetDriver() string {
+	if a.Driver != "" {
+		return a.Driver
 	}
-	if toEmails := os.Getenv("TO_EMAILS"); toEmails != "" {
-		emails := strings.Split(strings.TrimSpace(toEmails), ",")
-		for i, email := range emails {
-			emails[i] = strings.TrimSpace(email)
+	return ArchiveDriverFile
+}
+
+// GetRegion returns the S3 bucket region: the configured one, AWS_REGION, or us-east-1
+// for S3-compatible endpoints such as MinIO
+func (a *ArchiveConfig) GetRegion() string {
+	switch {
+	case a.Region != "":
+		return a.Region
+	case os.Getenv("AWS_REGION") != "":
+		return os.Getenv("AWS_REGION")
+	case a.Endpoint != "":
+		return "us-east-1"
+	}
+	return ""
+}
+
+// GetEndpoint returns the base URL of the bucket service
+func (a *ArchiveConfig) GetEndpoint() string {
+	switch {
+	case a.Endpoint != "":
+		return strings.TrimRight(a.Endpoint, "/")
+	case a.GetDriver() == ArchiveDriverGCS:
+		return "https://storage.googleapis.com"
+	}
+	return "https://s3." + a.GetRegion() + ".amazonaws.com"
+}
+
+// GetTimeout returns the per-upload timeout with a sensible default
+func (a *ArchiveConfig) GetTimeout() time.Duration {
+	if a.Timeout > 0 {
+		return a.Timeout
+	}
+	return 10 * time.Second
+}
+
+// Archives reports whether the manifests of kind are archived
+func (a *ArchiveConfig) Archives(kind string) bool {
+	if !a.Enabled {
+		return false
+	}
+	if len(a.Kinds) == 0 {
+		return true
+	}
+	for _, archived := range a.Kinds {
+		if archived == kind {
+			return true
 		}
-		c.Email.ToEmails = emails
 	}
-	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
-		c.Email.SMTPHost = strings.TrimSpace(smtpHost)
+	return false
+}
+
+// GetDriver returns the state storage driver, defaulting to memory
+func (s *StorageConfig) GetDriver() string {
+	if s.Driver != "" {
+		return s.Driver
 	}
-	if smtpPort := os.Getenv("SMTP_PORT"); smtpPort != "" {
-		var port int
-		if _, err := fmt.Sscanf(smtpPort, "%d", &port); err == nil {
-			c.Email.SMTPPort = port
+	return StorageDriverMemory
+}
+
+// GetNamespace returns the namespace of the state ConfigMap, defaulting to the watcher's own
+func (s *StorageConfig) GetNamespace() string {
+	if s.Namespace != "" {
+		return s.Namespace
+	}
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// GetTimeout returns the webhook request timeout with a sensible default
+func (w *WebhookConfig) GetTimeout() time.Duration {
+	if w.Timeout > 0 {
+		return w.Timeout
+	}
+	return 10 * time.Second
+}
+
+// GetMaxMessageSize returns the payload size limit in bytes with a sensible default
+func (w *WebhookConfig) GetMaxMessageSize() int {
+	if w.MaxMessageSize > 0 {
+		return w.MaxMessageSize
+	}
+	return 1 << 20
+}
+
+// GetAPIKey returns the Opsgenie API key, resolving it from the environment when apiKeyEnv is set
+func (o *OpsgenieConfig) GetAPIKey() string {
+	if o.APIKeyEnv != "" {
+		if key := strings.TrimSpace(os.Getenv(o.APIKeyEnv)); key != "" {
+			return key
 		}
 	}
-	if useAuth := os.Getenv("SMTP_USE_AUTH"); useAuth != "" {
-		c.Email.UseAuth = useAuth == "true"
+	return o.APIKey
+}
+
+// GetEndpoint returns the Opsgenie API base URL
+func (o *OpsgenieConfig) GetEndpoint() string {
+	if o.Endpoint != "" {
+		return strings.TrimRight(o.Endpoint, "/")
 	}
+	return "https://api.opsgenie.com"
+}
 
-	if c.Email.SMTPPort == 0 {
-		if c.Email.UseAuth {
-			c.Email.SMTPPort = 587
-		} else {
-			c.Email.SMTPPort = 25
+// GetTimeout returns the Opsgenie request timeout with a sensible default
+func (o *OpsgenieConfig) GetTimeout() time.Duration {
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+	return 10 * time.Second
+}
+
+// GetAPIKey returns the Splunk On-Call API key, resolving it from the environment when apiKeyEnv is set
+func (s *SplunkOnCallConfig) GetAPIKey() string {
+	if s.APIKeyEnv != "" {
+		if key := strings.TrimSpace(os.Getenv(s.APIKeyEnv)); key != "" {
+			return key
 		}
 	}
+	return s.APIKey
+}
 
-	// Validate the final configuration
-	return c.Email.Validate()
+// GetEndpoint returns the Splunk On-Call REST endpoint base URL
+func (s *SplunkOnCallConfig) GetEndpoint() string {
+	if s.Endpoint != "" {
+		return strings.TrimRight(s.Endpoint, "/")
+	}
+	return "https://alert.victorops.com/integrations/generic/20131114/alert"
 }
 
-// LoadLoggingConfig loads logging configuration from environment variables
-func (c *Config) LoadLoggingConfig() error {
-	// Set defaults
-	if c.Logging.Level == "" {
-		c.Logging.Level = "info"
+// GetTimeout returns the Splunk On-Call request timeout with a sensible default
+func (s *SplunkOnCallConfig) GetTimeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
 	}
-	if c.Logging.Format == "" {
-		c.Logging.Format = "text"
+	return 10 * time.Second
+}
+
+// GetBroker returns the publisher's broker, defaulting to nats
+func (p *PublisherConfig) GetBroker() string {
+	if p.Broker == "" {
+		return PublisherBrokerNATS
 	}
+	return p.Broker
+}
 
-	// Override with environment variables
-	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
-		c.Logging.Level = strings.ToLower(strings.TrimSpace(logLevel))
+// GetFormat returns the publisher's serialization format, defaulting to json
+func (p *PublisherConfig) GetFormat() string {
+	if p.Format == "" {
+		return PublisherFormatJSON
 	}
-	if logFormat := os.Getenv("LOG_FORMAT"); logFormat != "" {
-		c.Logging.Format = strings.ToLower(strings.TrimSpace(logFormat))
+	return p.Format
+}
+
+// GetTimeout returns the per-publish timeout with a sensible default
+func (p *PublisherConfig) GetTimeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
 	}
-	if enableJSON := os.Getenv("LOG_JSON"); enableJSON != "" {
-		c.Logging.EnableJSON = enableJSON == "true"
+	return 10 * time.Second
+}
+
+// GetRegion returns the region of the SNS topic, from its ARN
+func (s *SNSConfig) GetRegion() string {
+	if parts := strings.Split(s.TopicARN, ":"); len(parts) == 6 {
+		return parts[3]
 	}
+	return ""
+}
 
-	// Validate log level
-	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
-	if !validLevels[c.Logging.Level] {
-		return fmt.Errorf("invalid log level: %s (valid levels: debug, info, warn, error)", c.Logging.Level)
+// GetEndpoint returns the SNS API URL, in the China partition for aws-cn topics
+func (s *SNSConfig) GetEndpoint() string {
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/")
+	}
+	if strings.HasPrefix(s.TopicARN, "arn:aws-cn:") {
+		return "https://sns." + s.GetRegion() + ".amazonaws.com.cn"
 	}
+	return "https://sns." + s.GetRegion() + ".amazonaws.com"
+}
 
-	// Validate log format
-	validFormats := map[string]bool{"text": true, "json": true}
-	if !validFormats[c.Logging.Format] {
-		return fmt.Errorf("invalid log format: %s (valid formats: text, json)", c.Logging.Format)
+// GetFormat returns the SNS message format, defaulting to json
+func (s *SNSConfig) GetFormat() string {
+	if s.Format == "" {
+		return PublisherFormatJSON
 	}
+	return s.Format
+}
 
-	return nil
+// GetTimeout returns the SNS request timeout with a sensible default
+func (s *SNSConfig) GetTimeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return 10 * time.Second
 }
 
-// GetDeploymentImportantFields returns the deployment important fields, with defaults if not configured
-func (w *WatcherConfig) GetDeploymentImportantFields() []string {
-	if len(w.DeploymentImportantFields) > 0 {
-		return w.DeploymentImportantFields
+// GetEndpoint returns the Pub/Sub API base URL
+func (p *PubSubConfig) GetEndpoint() string {
+	if p.Endpoint != "" {
+		return strings.TrimRight(p.Endpoint, "/")
 	}
+	return "https://pubsub.googleapis.com"
+}
 
-	// Default important fields for deployments - production-ready defaults
-	return []string{
-		"containers",         // Image, env vars, resources, ports
-		"volumes",            // Volume mounts and configurations
-		"serviceAccountName", // Service account changes
-		"nodeSelector",       // Node selection rules
-		"affinity",           // Pod affinity/anti-affinity
-		"tolerations",        // Node tolerations
-		"securityContext",    // Security settings
-		"imagePullSecrets",   // Image pull secrets
-		"hostAliases",        // Host alias configurations
-		"initContainers",     // Init container changes
+// GetFormat returns the Pub/Sub message format, defaulting to json
+func (p *PubSubConfig) GetFormat() string {
+	if p.Format == "" {
+		return PublisherFormatJSON
 	}
+	return p.Format
 }
 
-// GetEventDeduplicationWindow returns the deduplication window with a sensible default
-func (w *WatcherConfig) GetEventDeduplicationWindow() time.Duration {
-	if w.EventDeduplicationWindow > 0 {
-		return w.EventDeduplicationWindow
+// GetTimeout returns the Pub/Sub request timeout with a sensible default
+func (p *PubSubConfig) GetTimeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
 	}
-	return 30 * time.Second // Default 30 seconds
+	return 10 * time.Second
 }
 
-// IsResourceVersionCheckEnabled returns whether resource version checking is enabled
-func (w *WatcherConfig) IsResourceVersionCheckEnabled() bool {
-	return w.ResourceVersionCheck
+// GetToken returns the NATS token, resolving it from the environment when tokenEnv is set
+func (n *NATSConfig) GetToken() string {
+	if n.TokenEnv != "" {
+		if token := strings.TrimSpace(os.Getenv(n.TokenEnv)); token != "" {
+			return token
+		}
+	}
+	return n.Token
 }
 
-// IsMetricsEnabled returns whether metrics collection is enabled
-func (w *WatcherConfig) IsMetricsEnabled() bool {
-	return w.MetricsEnabled
+// GetURL returns the webhook URL, resolving it from the environment when urlEnv is set
+func (w *WebhookConfig) GetURL() string {
+	if w.URLEnv != "" {
+		if url := strings.TrimSpace(os.Getenv(w.URLEnv)); url != "" {
+			return url
+		}
+	}
+	return w.URL
+}
+
+// GetClientSecret returns the client secret, resolving it from the environment when clientSecretEnv is set
+func (o *OAuth2Config) GetClientSecret() string {
+	if o.ClientSecretEnv != "" {
+		if secret := strings.TrimSpace(os.Getenv(o.ClientSecretEnv)); secret != "" {
+			return secret
+		}
+	}
+	return o.ClientSecret
+}
+
+// MatchAny reports whether value matches any of the glob patterns (path.Match syntax)
+func MatchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMode returns the ruleset mode, defaulting to first-match
+func (r *RuleSetConfig) GetMode() string {
+	if r.Mode == "" {
+		return RoutingModeFirstMatch
+	}
+	return r.Mode
 }
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResourceConfigValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource ResourceConfig
+		wantErr  string
+	}{
+		{name: "all namespaces", resource: ResourceConfig{Kind: "Deployment"}},
+		{name: "single namespace", resource: ResourceConfig{Kind: "ConfigMap", Namespace: "prod"}},
+		{name: "namespace patterns", resource: ResourceConfig{Kind: "ConfigMap", Namespaces: []string{"team-*"}, ExcludeNamespaces: []string{"team-sandbox"}}},
+		{name: "event types", resource: ResourceConfig{Kind: "Secret", EventTypes: []string{"ADDED", "DELETED"}}},
+		{name: "watch expression", resource: ResourceConfig{Kind: "Deployment", WatchExpressions: []string{"spec.replicas"}}},
+		{name: "missing kind", resource: ResourceConfig{Namespace: "prod"}, wantErr: "kind is required"},
+		{name: "cluster-scoped with namespace", resource: ResourceConfig{Kind: "ClusterRole", Namespace: "prod"}, wantErr: "cluster-scoped"},
+		{name: "namespace and namespaces", resource: ResourceConfig{Kind: "ConfigMap", Namespace: "prod", Namespaces: []string{"dev"}}, wantErr: "cannot both be set"},
+		{name: "resourceName and resourceNames", resource: ResourceConfig{Kind: "ConfigMap", ResourceName: "a", ResourceNames: []string{"b"}}, wantErr: "cannot both be set"},
+		{name: "empty resource name", resource: ResourceConfig{Kind: "ConfigMap", ResourceNames: []string{"a", ""}}, wantErr: "empty name"},
+		{name: "invalid namespace pattern", resource: ResourceConfig{Kind: "ConfigMap", Namespaces: []string{"team-["}}, wantErr: "invalid namespace pattern"},
+		{name: "unknown event type", resource: ResourceConfig{Kind: "Secret", EventTypes: []string{"UPDATED"}}, wantErr: "invalid event type"},
+		{name: "event types for pods", resource: ResourceConfig{Kind: "Pod", EventTypes: []string{"ADDED"}}, wantErr: "own alert types"},
+		{name: "helm releases of configmaps", resource: ResourceConfig{Kind: "ConfigMap", HelmReleases: true}, wantErr: "only supported for kind Secret"},
+		{name: "invalid filter", resource: ResourceConfig{Kind: "Deployment", Filter: "object.metadata.name =="}, wantErr: "filter"},
+		{name: "event filter on another kind", resource: ResourceConfig{Kind: "Deployment", EventFilter: &EventFilterConfig{}}, wantErr: "only supported for kind Event"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.resource.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Validate() = %v, want no error", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("Validate() = nil, want an error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceConfigMatchesNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		resource  ResourceConfig
+		namespace string
+		want      bool
+	}{
+		{name: "all namespaces", resource: ResourceConfig{}, namespace: "prod", want: true},
+		{name: "same namespace", resource: ResourceConfig{Namespace: "prod"}, namespace: "prod", want: true},
+		{name: "other namespace", resource: ResourceConfig{Namespace: "prod"}, namespace: "dev", want: false},
+		{name: "matching pattern", resource: ResourceConfig{Namespaces: []string{"team-*"}}, namespace: "team-a", want: true},
+		{name: "pattern mismatch", resource: ResourceConfig{Namespaces: []string{"team-*"}}, namespace: "kube-system", want: false},
+		{name: "excluded", resource: ResourceConfig{Namespaces: []string{"team-*"}, ExcludeNamespaces: []string{"team-sandbox"}}, namespace: "team-sandbox", want: false},
+		{name: "excluded from all", resource: ResourceConfig{ExcludeNamespaces: []string{"kube-*"}}, namespace: "kube-system", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resource.MatchesNamespace(tt.namespace); got != tt.want {
+				t.Errorf("MatchesNamespace(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceConfigMatchesName(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource ResourceConfig
+		object   string
+		want     bool
+	}{
+		{name: "all names", resource: ResourceConfig{}, object: "web", want: true},
+		{name: "resourceName", resource: ResourceConfig{ResourceName: "web"}, object: "web", want: true},
+		{name: "resourceName mismatch", resource: ResourceConfig{ResourceName: "web"}, object: "api", want: false},
+		{name: "resourceNames", resource: ResourceConfig{ResourceNames: []string{"web", "api"}}, object: "api", want: true},
+		{name: "resourceNames mismatch", resource: ResourceConfig{ResourceNames: []string{"web", "api"}}, object: "db", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resource.MatchesName(tt.object); got != tt.want {
+				t.Errorf("MatchesName(%q) = %v, want %v", tt.object, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceConfigNotifiesEventType(t *testing.T) {
+	all := ResourceConfig{Kind: "Secret"}
+	deletions := ResourceConfig{Kind: "Secret", EventTypes: []string{"DELETED"}}
+
+	for _, eventType := range []string{"ADDED", "MODIFIED", "DELETED"} {
+		if !all.NotifiesEventType(eventType) {
+			t.Errorf("entry without eventTypes does not notify %s", eventType)
+		}
+	}
+	if !deletions.NotifiesEventType("DELETED") {
+		t.Error("entry with eventTypes [DELETED] does not notify DELETED")
+	}
+	if deletions.NotifiesEventType("MODIFIED") {
+		t.Error("entry with eventTypes [DELETED] notifies MODIFIED")
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		value    string
+		want     bool
+	}{
+		{patterns: nil, value: "prod", want: false},
+		{patterns: []string{"prod"}, value: "prod", want: true},
+		{patterns: []string{"team-*"}, value: "team-a", want: true},
+		{patterns: []string{"team-?"}, value: "team-ab", want: false},
+		{patterns: []string{"dev", "prod-*"}, value: "prod-eu", want: true},
+		{patterns: []string{"["}, value: "[", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchAny(tt.patterns, tt.value); got != tt.want {
+			t.Errorf("MatchAny(%q, %q) = %v, want %v", tt.patterns, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestServerConfigValidateAdminToken(t *testing.T) {
+	t.Setenv("TEST_ADMIN_TOKEN", "secret")
+	t.Setenv("TEST_EMPTY_TOKEN", "")
+
+	tests := []struct {
+		name    string
+		admin   AdminServerConfig
+		wantErr bool
+	}{
+		{name: "token from environment", admin: AdminServerConfig{Enabled: true, BearerTokenEnv: "TEST_ADMIN_TOKEN"}},
+		{name: "inline token", admin: AdminServerConfig{Enabled: true, BearerToken: "secret"}},
+		{name: "empty variable with inline fallback", admin: AdminServerConfig{Enabled: true, BearerToken: "secret", BearerTokenEnv: "TEST_EMPTY_TOKEN"}},
+		{name: "empty variable", admin: AdminServerConfig{Enabled: true, BearerTokenEnv: "TEST_EMPTY_TOKEN"}, wantErr: true},
+		{name: "unset variable", admin: AdminServerConfig{Enabled: true, BearerTokenEnv: "TEST_UNSET_TOKEN"}, wantErr: true},
+		{name: "disabled", admin: AdminServerConfig{BearerTokenEnv: "TEST_UNSET_TOKEN"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := ServerConfig{Admin: tt.admin}
+			if err := server.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookConfigValidateURL(t *testing.T) {
+	t.Setenv("TEST_WEBHOOK_URL", "https://hooks.example.com/events")
+	t.Setenv("TEST_EMPTY_URL", " ")
+
+	tests := []struct {
+		name    string
+		webhook WebhookConfig
+		wantErr bool
+	}{
+		{name: "URL from environment", webhook: WebhookConfig{Enabled: true, URLEnv: "TEST_WEBHOOK_URL"}},
+		{name: "inline URL", webhook: WebhookConfig{Enabled: true, URL: "https://hooks.example.com/events"}},
+		{name: "empty variable with inline fallback", webhook: WebhookConfig{Enabled: true, URL: "https://hooks.example.com/events", URLEnv: "TEST_EMPTY_URL"}},
+		{name: "empty variable", webhook: WebhookConfig{Enabled: true, URLEnv: "TEST_EMPTY_URL"}, wantErr: true},
+		{name: "unset variable", webhook: WebhookConfig{Enabled: true, URLEnv: "TEST_UNSET_URL"}, wantErr: true},
+		{name: "no URL", webhook: WebhookConfig{Enabled: true}, wantErr: true},
+		{name: "disabled", webhook: WebhookConfig{URLEnv: "TEST_UNSET_URL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.webhook.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLintOverlappingResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []ResourceConfig
+		warnings  int
+	}{
+		{
+			name:      "same scope",
+			resources: []ResourceConfig{{Kind: "ConfigMap"}, {Kind: "ConfigMap"}},
+			warnings:  1,
+		},
+		{
+			name:      "namespace inside all namespaces",
+			resources: []ResourceConfig{{Kind: "ConfigMap"}, {Kind: "ConfigMap", Namespace: "prod"}},
+			warnings:  1,
+		},
+		{
+			name:      "different namespaces",
+			resources: []ResourceConfig{{Kind: "ConfigMap", Namespace: "dev"}, {Kind: "ConfigMap", Namespace: "prod"}},
+		},
+		{
+			name:      "different kinds",
+			resources: []ResourceConfig{{Kind: "ConfigMap"}, {Kind: "Secret"}},
+		},
+		{
+			name:      "broader event types",
+			resources: []ResourceConfig{{Kind: "Secret"}, {Kind: "Secret", Namespace: "prod", EventTypes: []string{"DELETED"}}},
+			warnings:  1,
+		},
+		{
+			name:      "narrower entry notifies other event types",
+			resources: []ResourceConfig{{Kind: "Secret", EventTypes: []string{"DELETED"}}, {Kind: "Secret", Namespace: "prod"}},
+			warnings:  0,
+		},
+		{
+			name:      "narrower entry watches other fields",
+			resources: []ResourceConfig{{Kind: "Deployment", WatchExpressions: []string{"spec.replicas"}}, {Kind: "Deployment", Namespace: "prod", WatchExpressions: []string{"spec.template"}}},
+			warnings:  0,
+		},
+		{
+			name:      "broader entry watches every field",
+			resources: []ResourceConfig{{Kind: "Deployment"}, {Kind: "Deployment", Namespace: "prod", WatchExpressions: []string{"spec.replicas"}}},
+			warnings:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Resources: tt.resources}
+			if got := cfg.lintOverlappingResources(); len(got) != tt.warnings {
+				t.Errorf("lintOverlappingResources() = %q, want %d warnings", got, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseV2Archive(t *testing.T) {
+	v1 := []byte(`clusterName: prod
+email:
+  smtpHost: smtp.example.com
+  fromEmail: watcher@example.com
+  toEmails: [ops@example.com]
+archive:
+  enabled: true
+  driver: s3
+  bucket: manifests
+  kinds: [ConfigMap]
+`)
+
+	migrated, err := MigrateToV2(v1)
+	if err != nil {
+		t.Fatalf("MigrateToV2() = %v", err)
+	}
+	if !strings.Contains(string(migrated), "archive:") {
+		t.Fatalf("migrated config dropped the archive section:\n%s", migrated)
+	}
+
+	for name, data := range map[string][]byte{"v1": v1, "v2": migrated} {
+		cfg, err := Parse(data)
+		if err != nil {
+			t.Fatalf("Parse(%s) = %v", name, err)
+		}
+		archive := cfg.Archive
+		if !archive.Enabled || archive.GetDriver() != ArchiveDriverS3 || archive.Bucket != "manifests" || !archive.Archives("ConfigMap") || archive.Archives("Secret") {
+			t.Errorf("Parse(%s).Archive = %+v, want the archive section as written", name, archive)
+		}
+	}
+}
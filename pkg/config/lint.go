@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Lint reports configuration problems that are legal but probably mistakes,
+// such as resources watched twice or routing rules that can never match.
+// Unlike Validate it never fails; callers should log the warnings.
+func (c *Config) Lint() []string {
+	var warnings []string
+	warnings = append(warnings, c.lintOverlappingResources()...)
+	warnings = append(warnings, c.lintRoutingRules()...)
+	warnings = append(warnings, c.lintAdminServer()...)
+	if len(c.NotifierNames()) == 0 {
+		warnings = append(warnings, "no notifier is enabled; events are only recorded")
+	}
+	return warnings
+}
+
+// lintAdminServer flags an admin port that anyone who can reach it may use
+func (c *Config) lintAdminServer() []string {
+	admin := c.Server.Admin
+	if !admin.Enabled || admin.GetBearerToken() != "" || (admin.TLS != nil && admin.TLS.ClientCAFile != "") {
+		return nil
+	}
+	return []string{fmt.Sprintf("server.admin serves the API on port %d without authentication; set bearerToken or tls.clientCAFile", admin.GetPort())}
+}
+
+// lintOverlappingResources finds resource entries whose scopes overlap, which
+// produces duplicate informer handlers and duplicate notifications
+func (c *Config) lintOverlappingResources() []string {
+	var warnings []string
+
+	for i := 0; i < len(c.Resources); i++ {
+		for j := i + 1; j < len(c.Resources); j++ {
+			a, b := c.Resources[i], c.Resources[j]
+			// Helm release and token entries notify a subset of Secrets differently from other entries
+			if a.Kind != b.Kind || a.HelmReleases != b.HelmReleases || a.ServiceAccountTokens != b.ServiceAccountTokens {
+				continue
+			}
+
+			switch {
+			case covers(a, b) && covers(b, a):
+				warnings = append(warnings, fmt.Sprintf("resources[%d] and resources[%d] both watch %s", i, j, a.Describe()))
+			case covers(a, b):
+				warnings = append(warnings, fmt.Sprintf("resources[%d] (%s) already covers resources[%d] (%s)", i, a.Describe(), j, b.Describe()))
+			case covers(b, a):
+				warnings = append(warnings, fmt.Sprintf("resources[%d] (%s) already covers resources[%d] (%s)", j, b.Describe(), i, a.Describe()))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// covers reports whether every object matched by b is also matched by a.
+// Entries using namespace patterns are only compared when their patterns are identical.
+// An entry notifying event types or watch expressions the other does not is never covered.
+func covers(a, b ResourceConfig) bool {
+	if usesNamespacePatterns(a) || usesNamespacePatterns(b) {
+		if !equalStrings(a.Namespaces, b.Namespaces) || !equalStrings(a.ExcludeNamespaces, b.ExcludeNamespaces) {
+			return false
+		}
+	} else if a.Namespace != "" && a.Namespace != b.Namespace {
+		return false
+	}
+	if !listCovers(a.Names(), b.Names(), false) {
+		return false
+	}
+	if a.Filter != "" && a.Filter != b.Filter {
+		return false
+	}
+	if !listCovers(a.EventTypes, b.EventTypes, false) {
+		return false
+	}
+	if len(a.WatchExpressions) > 0 && !equalStrings(a.WatchExpressions, b.WatchExpressions) {
+		return false
+	}
+	return true
+}
+
+func usesNamespacePatterns(r ResourceConfig) bool {
+	return len(r.Namespaces) > 0 || len(r.ExcludeNamespaces) > 0
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe renders a resource entry for log and lint messages
+func (r ResourceConfig) Describe() string {
+	desc := r.Kind
+	names := "[" + strings.Join(r.ResourceNames, ", ") + "]"
+	switch {
+	case r.HelmReleases && r.ResourceName != "":
+		desc = "Helm release '" + r.ResourceName + "'"
+	case r.HelmReleases && len(r.ResourceNames) > 0:
+		desc = "Helm releases " + names
+	case r.HelmReleases:
+		desc = "all Helm releases"
+	case r.ServiceAccountTokens && r.ResourceName != "":
+		desc = "ServiceAccount token Secret '" + r.ResourceName + "'"
+	case r.ServiceAccountTokens && len(r.ResourceNames) > 0:
+		desc = "ServiceAccount token Secrets " + names
+	case r.ServiceAccountTokens:
+		desc = "all ServiceAccount token Secrets"
+	case r.ResourceName != "":
+		desc += " '" + r.ResourceName + "'"
+	case len(r.ResourceNames) > 0:
+		desc += " " + names
+	default:
+		desc = "all " + desc + " resources"
+	}
+
+	switch {
+	case r.Namespace != "":
+		desc += " in namespace '" + r.Namespace + "'"
+	case len(r.Namespaces) > 0:
+		desc += " in namespaces [" + strings.Join(r.Namespaces, ", ") + "]"
+	default:
+		desc += " across all namespaces"
+	}
+	if len(r.ExcludeNamespaces) > 0 {
+		desc += " excluding [" + strings.Join(r.ExcludeNamespaces, ", ") + "]"
+	}
+	if r.Filter != "" {
+		desc += " where " + r.Filter
+	}
+	return desc
+}
+
+// lintRoutingRules finds routing rules that can never take effect
+func (c *Config) lintRoutingRules() []string {
+	var warnings []string
+
+	watchedKinds := make(map[string]bool)
+	for _, resource := range c.Resources {
+		watchedKinds[resource.Kind] = true
+	}
+
+	for _, ruleset := range c.Routing.Rulesets {
+		rules := append([]RuleConfig(nil), ruleset.Rules...)
+		sort.SliceStable(rules, func(i, j int) bool {
+			return rules[i].Priority > rules[j].Priority
+		})
+
+		for i, rule := range rules {
+			if len(rule.Match.Kinds) > 0 {
+				reachable := false
+				for _, kind := range rule.Match.Kinds {
+					if watchedKinds[kind] {
+						reachable = true
+						break
+					}
+				}
+				if !reachable {
+					warnings = append(warnings, fmt.Sprintf("ruleset %s: rule %s only matches kinds that are not watched", ruleset.Name, rule.Name))
+					continue
+				}
+			}
+
+			for _, earlier := range rules[:i] {
+				// In all-match mode only a drop rule stops evaluation
+				if ruleset.GetMode() == RoutingModeAllMatch && earlier.Action != RuleActionDrop {
+					continue
+				}
+				if matchCovers(earlier.Match, rule.Match) {
+					warnings = append(warnings, fmt.Sprintf("ruleset %s: rule %s is unreachable, rule %s matches every event it would", ruleset.Name, rule.Name, earlier.Name))
+					break
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// matchCovers reports whether a matches at least every event b matches.
+// Glob patterns are compared literally except for the catch-all "*", and
+// expressions only cover identical expressions.
+func matchCovers(a, b MatchConfig) bool {
+	return listCovers(a.Kinds, b.Kinds, false) &&
+		listCovers(a.EventTypes, b.EventTypes, false) &&
+		listCovers(a.Namespaces, b.Namespaces, true) &&
+		listCovers(a.Names, b.Names, true) &&
+		(a.Expression == "" || a.Expression == b.Expression)
+}
+
+func listCovers(a, b []string, glob bool) bool {
+	if len(a) == 0 {
+		return true
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		if glob && v == "*" {
+			return true
+		}
+		set[v] = true
+	}
+	if len(b) == 0 {
+		return false
+	}
+	for _, v := range b {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
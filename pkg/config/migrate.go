@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CurrentVersion is the newest configuration schema version
+const CurrentVersion = 2
+
+// ConfigV2 is the structured configuration layout: notifiers are grouped under
+// a single key and tenants map namespaces to the notifiers that serve them.
+type ConfigV2 struct {
+	Version     int              `yaml:"version"`
+	ClusterName string           `yaml:"clusterName"`
+	Resources   []ResourceConfig `yaml:"resources"`
+	Notifiers   NotifiersConfig  `yaml:"notifiers"`
+	Routing     RoutingConfig    `yaml:"routing,omitempty"`
+	Severity    SeverityConfig   `yaml:"severity,omitempty"`
+	Redaction   RedactionConfig  `yaml:"redaction,omitempty"`
+	Tenants     []TenantConfig   `yaml:"tenants,omitempty"`
+	Watcher     WatcherConfig    `yaml:"watcher,omitempty"`
+	Logging     LoggingConfig    `yaml:"logging,omitempty"`
+
+	KubeconfigSecret *KubeconfigSecretRef `yaml:"kubeconfigSecret,omitempty"`
+	Clusters         []ClusterConfig      `yaml:"clusters,omitempty"`
+	Client           ClientConfig         `yaml:"client,omitempty"`
+	Server           ServerConfig         `yaml:"server,omitempty"`
+	Store            StoreConfig          `yaml:"store,omitempty"`
+	Archive          ArchiveConfig        `yaml:"archive,omitempty"`
+	Storage          StorageConfig        `yaml:"storage,omitempty"`
+	Compatibility    CompatibilityConfig  `yaml:"compatibility,omitempty"`
+	Theme            ThemeConfig          `yaml:"theme,omitempty"`
+}
+
+// NotifiersConfig groups the configuration of every notifier backend
+type NotifiersConfig struct {
+	Email     EmailConfig     `yaml:"email"`
+	Teams     TeamsConfig     `yaml:"teams,omitempty"`
+	Webhook   WebhookConfig   `yaml:"webhook,omitempty"`
+	LogSink   LogSinkConfig   `yaml:"logSink,omitempty"`
+	Publisher PublisherConfig `yaml:"publisher,omitempty"`
+	SNS       SNSConfig       `yaml:"sns,omitempty"`
+	PubSub    PubSubConfig    `yaml:"pubsub,omitempty"`
+
+	Opsgenie     OpsgenieConfig     `yaml:"opsgenie,omitempty"`
+	SplunkOnCall SplunkOnCallConfig `yaml:"splunkOnCall,omitempty"`
+}
+
+// TenantConfig routes events from a tenant's namespaces to its notifiers
+type TenantConfig struct {
+	Name       string   `yaml:"name"`
+	Namespaces []string `yaml:"namespaces"` // Glob patterns allowed
+	Notifiers  []string `yaml:"notifiers"`
+}
+
+// tenantRulesetName is the routing ruleset generated from tenants
+const tenantRulesetName = "tenants"
+
+// UnsupportedVersionError reports a config written for a schema version this binary does not know
+type UnsupportedVersionError struct {
+	Version int
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("unsupported config version %d (newest supported: %d)", e.Version, CurrentVersion)
+}
+
+// Parse decodes a configuration file of any supported schema version
+func Parse(data []byte) (*Config, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch version {
+	case 1:
+		var cfg Config
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, err
+		}
+		cfg.SchemaVersion = version
+		return &cfg, nil
+	case 2:
+		return parseV2(data, version)
+	default:
+		return nil, &UnsupportedVersionError{Version: version}
+	}
+}
+
+// ParseLenient is Parse, except that a config written for a newer schema
+// version is decoded with the newest layout this binary knows, ignoring keys
+// it does not. It is meant for running in safe mode during upgrades.
+func ParseLenient(data []byte) (*Config, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentVersion {
+		return parseV2(data, version)
+	}
+	return Parse(data)
+}
+
+// schemaVersion returns the document's schema version; unversioned documents are version 1
+func schemaVersion(data []byte) (int, error) {
+	var header struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+	if header.Version == 0 {
+		return 1, nil
+	}
+	return header.Version, nil
+}
+
+func parseV2(data []byte, version int) (*Config, error) {
+	var v2 ConfigV2
+	if err := yaml.Unmarshal(data, &v2); err != nil {
+		return nil, err
+	}
+	cfg := v2.ToConfig()
+	cfg.SchemaVersion = version
+	return cfg, nil
+}
+
+// ToConfig flattens the structured layout into the runtime configuration.
+// Tenants become an all-match routing ruleset; events outside every tenant
+// still reach all enabled notifiers.
+func (v *ConfigV2) ToConfig() *Config {
+	cfg := &Config{
+		ClusterName: v.ClusterName,
+		Resources:   v.Resources,
+		Email:       v.Notifiers.Email,
+		Teams:       v.Notifiers.Teams,
+		Webhook:     v.Notifiers.Webhook,
+		LogSink:     v.Notifiers.LogSink,
+		Publisher:   v.Notifiers.Publisher,
+		SNS:         v.Notifiers.SNS,
+		PubSub:      v.Notifiers.PubSub,
+		Opsgenie:    v.Notifiers.Opsgenie,
+		Routing:     v.Routing,
+		Severity:    v.Severity,
+		Redaction:   v.Redaction,
+		Watcher:     v.Watcher,
+		Logging:     v.Logging,
+
+		SplunkOnCall:     v.Notifiers.SplunkOnCall,
+		KubeconfigSecret: v.KubeconfigSecret,
+		Clusters:         v.Clusters,
+		Client:           v.Client,
+		Server:           v.Server,
+		Store:            v.Store,
+		Archive:          v.Archive,
+		Storage:          v.Storage,
+		Compatibility:    v.Compatibility,
+		Theme:            v.Theme,
+	}
+
+	if len(v.Tenants) > 0 {
+		ruleset := RuleSetConfig{
+			Name:             tenantRulesetName,
+			Mode:             RoutingModeAllMatch,
+			DefaultNotifiers: cfg.NotifierNames(),
+		}
+		for _, tenant := range v.Tenants {
+			ruleset.Rules = append(ruleset.Rules, RuleConfig{
+				Name:      "tenant-" + tenant.Name,
+				Match:     MatchConfig{Namespaces: tenant.Namespaces},
+				Notifiers: tenant.Notifiers,
+			})
+		}
+		cfg.Routing.Rulesets = append(cfg.Routing.Rulesets, ruleset)
+	}
+
+	return cfg
+}
+
+// MigrateToV2 rewrites a flat (version 1) configuration document into the
+// structured version 2 layout. The document is transformed key by key so
+// values, ordering and unknown keys are preserved exactly as written.
+func MigrateToV2(data []byte) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	for _, item := range doc {
+		if item.Key == "version" {
+			if version, ok := item.Value.(int); ok && version >= 2 {
+				return nil, fmt.Errorf("config is already version %d", version)
+			}
+		}
+	}
+
+	migrated := yaml.MapSlice{{Key: "version", Value: CurrentVersion}}
+	var notifiers yaml.MapSlice
+	notifiersIndex := -1
+
+	for _, item := range doc {
+		switch item.Key {
+		case "version":
+			continue
+		case "email", "teams", "webhook", "logSink", "publisher", "sns", "pubsub", "opsgenie", "splunkOnCall":
+			notifiers = append(notifiers, item)
+			if notifiersIndex < 0 {
+				// Keep the notifiers where the first notifier section used to be
+				notifiersIndex = len(migrated)
+				migrated = append(migrated, yaml.MapItem{Key: "notifiers"})
+			}
+		default:
+			migrated = append(migrated, item)
+		}
+	}
+
+	if notifiersIndex < 0 {
+		return nil, fmt.Errorf("config has no email section to migrate")
+	}
+	migrated[notifiersIndex].Value = notifiers
+
+	out, err := yaml.Marshal(migrated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render migrated config: %v", err)
+	}
+
+	// Make sure the result loads before handing it back
+	if _, err := Parse(out); err != nil {
+		return nil, fmt.Errorf("migrated config does not parse: %v", err)
+	}
+	return out, nil
+}
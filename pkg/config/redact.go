@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RedactedValue replaces secrets in configuration served over the API
+const RedactedValue = "REDACTED"
+
+// FieldDiff is a configuration value that differs between two configs
+type FieldDiff struct {
+	Path   string      `json:"path"`
+	Loaded interface{} `json:"loaded,omitempty"`
+	OnDisk interface{} `json:"onDisk,omitempty"`
+}
+
+// Redacted returns a copy of the config with passwords, secrets and secret-bearing URLs replaced
+func (c *Config) Redacted() (*Config, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+	var redacted Config
+	if err := yaml.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+
+	redact(&redacted.Email.SMTPPassword)
+	redact(&redacted.Email.SendGrid.APIKey)
+	if redacted.Email.OAuth2 != nil {
+		redact(&redacted.Email.OAuth2.ClientSecret)
+	}
+	// Incoming webhook URLs embed their credentials
+	for i := range redacted.Teams.Webhooks {
+		redact(&redacted.Teams.Webhooks[i].URL)
+	}
+	redact(&redacted.Webhook.URL)
+	for key := range redacted.Webhook.Headers {
+		redacted.Webhook.Headers[key] = RedactedValue
+	}
+	if redacted.Webhook.OAuth2 != nil {
+		redact(&redacted.Webhook.OAuth2.ClientSecret)
+	}
+	redact(&redacted.Opsgenie.APIKey)
+	redact(&redacted.SplunkOnCall.APIKey)
+	redact(&redacted.Publisher.NATS.Token)
+	redact(&redacted.Server.Admin.BearerToken)
+	for key := range redacted.Publisher.Kafka.Headers {
+		redacted.Publisher.Kafka.Headers[key] = RedactedValue
+	}
+	return &redacted, nil
+}
+
+func redact(value *string) {
+	if *value != "" {
+		*value = RedactedValue
+	}
+}
+
+// Document returns the config as a JSON-friendly tree keyed by YAML field names
+func (c *Config) Document() (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	document, _ := jsonValue(tree).(map[string]interface{})
+	return document, nil
+}
+
+// DiffConfigs lists the values that differ between the loaded config and the one on disk, sorted by path.
+// Changed secrets are reported with both values redacted.
+func DiffConfigs(loaded, onDisk *Config) ([]FieldDiff, error) {
+	var raw, redacted [2]map[string]interface{}
+	for i, cfg := range []*Config{loaded, onDisk} {
+		var err error
+		if raw[i], err = flatDocument(cfg); err != nil {
+			return nil, err
+		}
+		redactedConfig, err := cfg.Redacted()
+		if err != nil {
+			return nil, err
+		}
+		if redacted[i], err = flatDocument(redactedConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make(map[string]bool)
+	for _, values := range raw {
+		for path := range values {
+			paths[path] = true
+		}
+	}
+
+	diffs := make([]FieldDiff, 0)
+	for path := range paths {
+		if !reflect.DeepEqual(raw[0][path], raw[1][path]) {
+			diffs = append(diffs, FieldDiff{Path: path, Loaded: redacted[0][path], OnDisk: redacted[1][path]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+func flatDocument(cfg *Config) (map[string]interface{}, error) {
+	document, err := cfg.Document()
+	if err != nil {
+		return nil, err
+	}
+	flat := make(map[string]interface{})
+	flatten("", document, flat)
+	return flat, nil
+}
+
+// flatten records every leaf value of tree under its dotted path, with [i] for list elements
+func flatten(prefix string, tree interface{}, out map[string]interface{}) {
+	switch value := tree.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if strings.ContainsAny(key, ".[]") {
+				key = fmt.Sprintf("[%q]", key)
+			} else if prefix != "" {
+				key = "." + key
+			}
+			flatten(prefix+key, child, out)
+		}
+	case []interface{}:
+		for i, child := range value {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), child, out)
+		}
+	default:
+		out[prefix] = value
+	}
+}
+
+// jsonValue converts YAML-decoded maps, which have interface{} keys, to string-keyed maps
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			converted[fmt.Sprint(key)] = jsonValue(child)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, child := range v {
+			converted[i] = jsonValue(child)
+		}
+		return converted
+	default:
+		return v
+	}
+}
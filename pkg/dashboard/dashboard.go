@@ -0,0 +1,19 @@
+// Package dashboard serves the embedded read-only web UI
+package dashboard
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Handler serves the dashboard page; its data comes from the /api endpoints
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(indexHTML)
+	})
+}
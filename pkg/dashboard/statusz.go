@@ -0,0 +1,56 @@
+package dashboard
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+//go:embed statusz.html
+var statusHTML string
+
+var statusTemplate = template.Must(template.New("statusz").Parse(statusHTML))
+
+// StatusPage is what the public status page shows: health and watched kinds,
+// never event contents or error details
+type StatusPage struct {
+	Title        string
+	Version      string
+	Ready        bool
+	MultiCluster bool
+	Entries      []StatusEntry
+	Generated    time.Time
+}
+
+// StatusEntry is one watched resource entry on the status page
+type StatusEntry struct {
+	Cluster       string
+	Resource      string
+	Engine        string
+	CacheSynced   bool
+	LastEventTime *time.Time
+}
+
+// StatusHandler serves the status page for wall displays, rendered server-side from status
+func StatusHandler(status func(r *http.Request) StatusPage) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := status(r)
+		page.Generated = time.Now()
+
+		var buf bytes.Buffer
+		if err := statusTemplate.Execute(&buf, page); err != nil {
+			slog.Error("Failed to render status page", "error", err)
+			http.Error(w, "failed to render status page", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		if !page.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,340 @@
+// Package demo runs the watcher against an in-memory fake cluster that
+// generates synthetic changes, to try notifiers, templates and the dashboard
+// without cluster access
+package demo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/watcher"
+)
+
+// DefaultInterval is how often the fake cluster changes an object
+const DefaultInterval = 5 * time.Second
+
+// defaultNamespace hosts the objects of entries that name no single namespace
+const defaultNamespace = "demo"
+
+// objectsPerKind is how many objects of each kind and namespace the generator keeps at most
+const objectsPerKind = 3
+
+// generatedKinds are the kinds the generator creates through the dynamic client;
+// Deployments go through the typed client, which serves their informer
+var generatedKinds = map[string]bool{"ConfigMap": true, "Secret": true, "Service": true}
+
+// Cluster is a fake cluster whose objects change on their own
+type Cluster struct {
+	kubernetes *kubernetesfake.Clientset
+	dynamic    *dynamicfake.FakeDynamicClient
+	metadata   *metadatafake.FakeMetadataClient
+	resources  map[string]schema.GroupVersionResource
+
+	targets  []target
+	interval time.Duration
+	random   *rand.Rand
+	version  int
+	sequence int
+	objects  map[string][]string // kind/namespace -> names of live objects
+}
+
+// target is a kind and namespace the generator changes objects in
+type target struct {
+	kind      string
+	namespace string
+	name      string // Fixed object name, for entries watching a single resource
+}
+
+// key identifies the target's live objects
+func (t target) key() string {
+	return t.kind + "/" + t.namespace
+}
+
+// NewCluster creates a fake cluster for the configured resources it knows how
+// to generate, changing one object every interval once Run is called
+func NewCluster(cfg *config.Config, interval time.Duration) *Cluster {
+	// The dynamic client's objects stay unstructured, so its scheme has no typed kinds
+	scheme := runtime.NewScheme()
+	metav1.AddMetaToScheme(scheme)
+
+	// Informers for kinds that are not generated still need to list, if only empty results
+	resources := watcher.KindResources()
+	listKinds := make(map[schema.GroupVersionResource]string, len(resources))
+	for kind, gvr := range resources {
+		listKinds[gvr] = kind + "List"
+	}
+
+	c := &Cluster{
+		kubernetes: kubernetesfake.NewSimpleClientset(),
+		dynamic:    dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds),
+		metadata:   metadatafake.NewSimpleMetadataClient(scheme),
+		resources:  resources,
+		interval:   interval,
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		objects:    make(map[string][]string),
+	}
+
+	// The fake cluster grants the watcher everything it asks for
+	c.kubernetes.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = true
+		return true, review, nil
+	})
+
+	seen := make(map[target]bool)
+	for _, resourceConfig := range cfg.Resources {
+		if (!generatedKinds[resourceConfig.Kind] && resourceConfig.Kind != "Deployment") || resourceConfig.HelmReleases || resourceConfig.ServiceAccountTokens {
+			slog.Info("Demo: no synthetic events for " + resourceConfig.Describe())
+			continue
+		}
+		names := resourceConfig.Names()
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		for _, name := range names {
+			t := target{kind: resourceConfig.Kind, namespace: demoNamespace(resourceConfig), name: name}
+			if !seen[t] {
+				seen[t] = true
+				c.targets = append(c.targets, t)
+			}
+		}
+	}
+	return c
+}
+
+// demoNamespace picks a namespace the entry watches, filling in patterns
+func demoNamespace(resourceConfig config.ResourceConfig) string {
+	switch {
+	case resourceConfig.Namespace != "":
+		return resourceConfig.Namespace
+	case len(resourceConfig.Namespaces) > 0:
+		return strings.NewReplacer("*", defaultNamespace, "?", "x").Replace(resourceConfig.Namespaces[0])
+	default:
+		return defaultNamespace
+	}
+}
+
+// Clients returns the fake cluster's clients for the watcher
+func (c *Cluster) Clients() watcher.Clients {
+	return watcher.Clients{Dynamic: c.dynamic, Kubernetes: c.kubernetes, Metadata: c.metadata}
+}
+
+// Seed creates the namespaces and a first object per target; call it before the watcher starts
+func (c *Cluster) Seed(ctx context.Context) error {
+	namespaces := make(map[string]bool)
+	for _, t := range c.targets {
+		if !namespaces[t.namespace] {
+			namespaces[t.namespace] = true
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: t.namespace}}
+			if _, err := c.kubernetes.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create namespace %s: %w", t.namespace, err)
+			}
+		}
+		if len(c.objects[t.key()]) == 0 || t.name != "" {
+			if err := c.create(ctx, t); err != nil {
+				return fmt.Errorf("failed to create %s in %s: %w", t.kind, t.namespace, err)
+			}
+		}
+	}
+	slog.Info("Demo: fake cluster seeded", "targets", len(c.targets), "namespaces", len(namespaces))
+	return nil
+}
+
+// Run changes a random object every interval until ctx is done
+func (c *Cluster) Run(ctx context.Context) {
+	defer exitcode.Recover()
+	if len(c.targets) == 0 {
+		slog.Warn("Demo: none of the configured kinds can be generated (supported: ConfigMap, Deployment, Secret, Service)")
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.step(ctx); err != nil {
+				slog.Warn("Demo: failed to generate a change", "error", err)
+			}
+		}
+	}
+}
+
+// step creates, modifies or deletes one object, mostly modifying
+func (c *Cluster) step(ctx context.Context) error {
+	t := c.targets[c.random.Intn(len(c.targets))]
+	live := c.liveObjects(t)
+	switch roll := c.random.Intn(10); {
+	case len(live) == 0 || (roll < 2 && t.name == "" && len(live) < objectsPerKind):
+		return c.create(ctx, t)
+	case roll == 2 && len(live) > 1:
+		return c.delete(ctx, t, live[c.random.Intn(len(live))])
+	default:
+		return c.modify(ctx, t, live[c.random.Intn(len(live))])
+	}
+}
+
+// liveObjects returns the names of the target's objects; fixed-name targets only see their own
+func (c *Cluster) liveObjects(t target) []string {
+	if t.name == "" {
+		return c.objects[t.key()]
+	}
+	for _, name := range c.objects[t.key()] {
+		if name == t.name {
+			return []string{name}
+		}
+	}
+	return nil
+}
+
+// nextVersion returns a new resource version, which the fake clients do not set
+func (c *Cluster) nextVersion() string {
+	c.version++
+	return strconv.Itoa(c.version)
+}
+
+func (c *Cluster) create(ctx context.Context, t target) error {
+	name := t.name
+	if name == "" {
+		c.sequence++
+		name = fmt.Sprintf("demo-%s-%d", strings.ToLower(t.kind), c.sequence)
+	}
+
+	if t.kind == "Deployment" {
+		deployment := newDeployment(t.namespace, name, c.image())
+		deployment.ResourceVersion = c.nextVersion()
+		if _, err := c.kubernetes.AppsV1().Deployments(t.namespace).Create(ctx, deployment, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	} else {
+		obj := c.newObject(t.kind, t.namespace, name)
+		if _, err := c.dynamic.Resource(c.resources[t.kind]).Namespace(t.namespace).Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+	c.objects[t.key()] = append(c.objects[t.key()], name)
+	return nil
+}
+
+func (c *Cluster) modify(ctx context.Context, t target, name string) error {
+	if t.kind == "Deployment" {
+		deployments := c.kubernetes.AppsV1().Deployments(t.namespace)
+		deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		deployment.Spec.Template.Spec.Containers[0].Image = c.image()
+		deployment.ResourceVersion = c.nextVersion()
+		_, err = deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	}
+
+	resource := c.dynamic.Resource(c.resources[t.kind]).Namespace(t.namespace)
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	c.mutate(obj)
+	obj.SetResourceVersion(c.nextVersion())
+	_, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *Cluster) delete(ctx context.Context, t target, name string) error {
+	var err error
+	if t.kind == "Deployment" {
+		err = c.kubernetes.AppsV1().Deployments(t.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	} else {
+		err = c.dynamic.Resource(c.resources[t.kind]).Namespace(t.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	var live []string
+	for _, existing := range c.objects[t.key()] {
+		if existing != name {
+			live = append(live, existing)
+		}
+	}
+	c.objects[t.key()] = live
+	return nil
+}
+
+// image returns a random version of the demo application image
+func (c *Cluster) image() string {
+	return fmt.Sprintf("registry.example.com/demo-app:1.%d.%d", c.random.Intn(5), c.random.Intn(10))
+}
+
+// newObject builds a ConfigMap, Secret or Service with demo content
+func (c *Cluster) newObject(kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]interface{}{"app.kubernetes.io/part-of": "demo"},
+		},
+	}}
+	if kind == "Service" {
+		obj.Object["spec"] = map[string]interface{}{
+			"selector": map[string]interface{}{"app": name},
+		}
+	}
+	c.mutate(obj)
+	obj.SetResourceVersion(c.nextVersion())
+	return obj
+}
+
+// mutate changes an object's content the way a pipeline or an operator would
+func (c *Cluster) mutate(obj *unstructured.Unstructured) {
+	switch obj.GetKind() {
+	case "Service":
+		unstructured.SetNestedSlice(obj.Object, []interface{}{
+			map[string]interface{}{"port": int64(80), "targetPort": int64(8000 + c.random.Intn(100))},
+		}, "spec", "ports")
+	case "Secret":
+		// Base64 like the API server stores it; the value is not a real credential
+		unstructured.SetNestedField(obj.Object, fmt.Sprintf("ZGVtby0%04d", c.random.Intn(10000)), "data", "token")
+	default:
+		unstructured.SetNestedField(obj.Object, strconv.Itoa(1+c.random.Intn(10)), "data", "workers")
+		unstructured.SetNestedField(obj.Object, []string{"debug", "info", "warn"}[c.random.Intn(3)], "data", "logLevel")
+	}
+}
+
+func newDeployment(namespace, name, image string) *appsv1.Deployment {
+	labels := map[string]string{"app": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: image}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,99 @@
+// Package exitcode defines the exit codes of the watcher and the final
+// structured log line, so supervisors can tell misconfiguration from
+// infrastructure failure without parsing free-form logs
+package exitcode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"runtime/debug"
+	"syscall"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes, following sysexits.h where one fits. 2 is left to the Go
+// runtime, which uses it for flag errors and panics in goroutines not deferring Recover.
+const (
+	OK           = 0
+	Failure      = 1  // Anything not classified below
+	Connectivity = 69 // API server unreachable, timing out or unavailable
+	Panic        = 70 // The watcher panicked
+	RBAC         = 77 // Credentials rejected or access forbidden by the API server
+	Config       = 78 // Invalid or incompatible configuration
+)
+
+var reasons = map[int]string{
+	OK:           "ok",
+	Failure:      "failure",
+	Connectivity: "connectivity",
+	Panic:        "panic",
+	RBAC:         "rbac",
+	Config:       "config",
+}
+
+// Reason returns the name of an exit code as logged in the final line
+func Reason(code int) string {
+	if reason, ok := reasons[code]; ok {
+		return reason
+	}
+	return reasons[Failure]
+}
+
+// Classify returns RBAC or Connectivity when err comes from the API server
+// rejecting or not answering a request, and fallback otherwise
+func Classify(err error, fallback int) int {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return OK
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return RBAC
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err),
+		apierrors.IsServiceUnavailable(err), apierrors.IsTooManyRequests(err):
+		return Connectivity
+	case errors.As(err, &netErr), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, context.DeadlineExceeded):
+		return Connectivity
+	default:
+		return fallback
+	}
+}
+
+// finalLine is the last line written before exiting, as one JSON object on stderr
+type finalLine struct {
+	Msg      string `json:"msg"`
+	ExitCode int    `json:"exitCode"`
+	Reason   string `json:"reason"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Exit logs err and the final line and exits with code
+func Exit(code int, err error) {
+	line := finalLine{Msg: "watcher exiting", ExitCode: code, Reason: Reason(code)}
+	if err != nil {
+		line.Error = err.Error()
+		slog.Error("Fatal error", "error", err)
+	}
+	encoded, _ := json.Marshal(line)
+	fmt.Fprintln(os.Stderr, string(encoded))
+	os.Exit(code)
+}
+
+// Fail exits with the code Classify picks for err
+func Fail(err error, fallback int) {
+	Exit(Classify(err, fallback), err)
+}
+
+// Recover exits with Panic, logging the stack, when the goroutine panics;
+// defer it at the top of main and of every goroutine the watcher starts
+func Recover() {
+	if r := recover(); r != nil {
+		slog.Error("Panic", "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		Exit(Panic, fmt.Errorf("panic: %v", r))
+	}
+}
@@ -0,0 +1,444 @@
+// Package expr evaluates jq-style filter expressions such as
+// .kind == "Deployment" and .namespace =~ "^prod-" against JSON documents
+// decoded into maps, slices, strings, float64s, bools and nil.
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed filter expression
+type Expr struct {
+	source string
+	root   node
+}
+
+// node is one operator or operand of an expression
+type node interface {
+	eval(doc interface{}) interface{}
+}
+
+// Parse parses an expression:
+//
+//   - paths: . (the whole document), .kind, .labels.app, .labels["app.kubernetes.io/name"]
+//   - literals: "strings", numbers, true, false, null
+//   - comparisons: ==, !=, <, <=, >, >=, =~ (regular expression), contains (list element or substring)
+//   - logic: and, or, not, parentheses; a bare operand is true unless false, null, zero or empty
+func Parse(text string) (*Expr, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", text, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", text, err)
+	}
+	return &Expr{source: text, root: root}, nil
+}
+
+// Match reports whether the expression is true for doc
+func (e *Expr) Match(doc interface{}) bool {
+	return truthy(e.root.eval(doc))
+}
+
+func (e *Expr) String() string {
+	return e.source
+}
+
+// token kinds
+const (
+	tokenPath = iota
+	tokenString
+	tokenNumber
+	tokenWord     // true, false, null, and, or, not, contains
+	tokenOperator // comparison operators and parentheses
+)
+
+type token struct {
+	kind  int
+	text  string
+	path  []string    // tokenPath
+	value interface{} // tokenString, tokenNumber
+}
+
+func (t token) String() string {
+	if t.kind == tokenPath {
+		return "path " + t.text
+	}
+	return strconv.Quote(t.text)
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "=~", "<", ">", "(", ")"}
+
+func tokenize(text string) ([]token, error) {
+	var tokens []token
+	rest := text
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return tokens, nil
+		}
+
+		switch c := rest[0]; {
+		case c == '.':
+			path, length, err := scanPath(rest)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenPath, text: rest[:length], path: path})
+			rest = rest[length:]
+		case c == '"':
+			value, length, err := scanString(rest)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: rest[:length], value: value})
+			rest = rest[length:]
+		case c == '-' || (c >= '0' && c <= '9'):
+			length := strings.IndexFunc(rest[1:], func(r rune) bool {
+				return !(r >= '0' && r <= '9' || r == '.' || r == 'e' || r == 'E')
+			}) + 1
+			if length == 0 {
+				length = len(rest)
+			}
+			number, err := strconv.ParseFloat(rest[:length], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", rest[:length])
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: rest[:length], value: number})
+			rest = rest[length:]
+		case unicode.IsLetter(rune(c)):
+			length := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsLetter(r) })
+			if length < 0 {
+				length = len(rest)
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: rest[:length]})
+			rest = rest[length:]
+		default:
+			operator := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(rest, candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator})
+			rest = rest[len(operator):]
+		}
+	}
+}
+
+// scanPath reads a path starting with "." and returns its keys and length
+func scanPath(text string) ([]string, int, error) {
+	var keys []string
+	pos := 1
+	for pos < len(text) {
+		switch {
+		case text[pos] == '[':
+			key, length, err := scanString(text[pos+1:])
+			if err != nil {
+				return nil, 0, fmt.Errorf("expected quoted key after [ in path %q", text[:pos+1])
+			}
+			if !strings.HasPrefix(text[pos+1+length:], "]") {
+				return nil, 0, fmt.Errorf("unterminated bracket in path %q", text[:pos+1+length])
+			}
+			keys = append(keys, key)
+			pos += length + 2
+		case isKeyChar(text[pos]):
+			end := pos
+			for end < len(text) && isKeyChar(text[end]) {
+				end++
+			}
+			keys = append(keys, text[pos:end])
+			pos = end
+		default:
+			return keys, pos, nil
+		}
+
+		if pos < len(text) && text[pos] == '.' {
+			if pos+1 == len(text) || !(isKeyChar(text[pos+1]) || text[pos+1] == '[') {
+				return nil, 0, fmt.Errorf("trailing dot in path %q", text[:pos+1])
+			}
+			pos++
+		}
+	}
+	return keys, pos, nil
+}
+
+func isKeyChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// scanString reads a double-quoted string with Go escapes and returns its value and length
+func scanString(text string) (string, int, error) {
+	if !strings.HasPrefix(text, `"`) {
+		return "", 0, fmt.Errorf("expected string")
+	}
+	for end := 1; end < len(text); end++ {
+		switch text[end] {
+		case '\\':
+			end++
+		case '"':
+			value, err := strconv.Unquote(text[:end+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", text[:end+1])
+			}
+			return value, end + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string %s", text)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// accept consumes the next token if it is the given word or operator
+func (p *parser) accept(text string) bool {
+	if p.pos < len(p.tokens) && (p.tokens[p.pos].kind == tokenWord || p.tokens[p.pos].kind == tokenOperator) && p.tokens[p.pos].text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("or") {
+		var right node
+		if right, err = p.parseAnd(); err == nil {
+			left = orNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	for err == nil && p.accept("and") {
+		var right node
+		if right, err = p.parseNot(); err == nil {
+			left = andNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.accept("not") {
+		operand, err := p.parseNot()
+		return notNode{operand}, err
+	}
+	return p.parseComparison()
+}
+
+var comparisons = []string{"==", "!=", "<=", ">=", "<", ">", "=~", "contains"}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, operator := range comparisons {
+		if !p.accept(operator) {
+			continue
+		}
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if operator != "=~" {
+			return compareNode{operator, left, right}, nil
+		}
+		pattern, ok := right.(literalNode)
+		if _, isString := pattern.value.(string); !ok || !isString {
+			return nil, fmt.Errorf("=~ needs a string pattern")
+		}
+		re, err := regexp.Compile(pattern.value.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		return matchNode{left, re}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	if p.pos == len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case t.kind == tokenPath:
+		return pathNode(t.path), nil
+	case t.kind == tokenString || t.kind == tokenNumber:
+		return literalNode{t.value}, nil
+	case t.kind == tokenWord && t.text == "true":
+		return literalNode{true}, nil
+	case t.kind == tokenWord && t.text == "false":
+		return literalNode{false}, nil
+	case t.kind == tokenWord && t.text == "null":
+		return literalNode{nil}, nil
+	case t.kind == tokenOperator && t.text == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+}
+
+type pathNode []string
+
+func (n pathNode) eval(doc interface{}) interface{} {
+	value := doc
+	for _, key := range n {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) eval(interface{}) interface{} { return n.value }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(doc interface{}) interface{} {
+	return truthy(n.left.eval(doc)) && truthy(n.right.eval(doc))
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(doc interface{}) interface{} {
+	return truthy(n.left.eval(doc)) || truthy(n.right.eval(doc))
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(doc interface{}) interface{} { return !truthy(n.operand.eval(doc)) }
+
+type matchNode struct {
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n matchNode) eval(doc interface{}) interface{} {
+	value, ok := n.operand.eval(doc).(string)
+	return ok && n.re.MatchString(value)
+}
+
+type compareNode struct {
+	operator    string
+	left, right node
+}
+
+func (n compareNode) eval(doc interface{}) interface{} {
+	left, right := n.left.eval(doc), n.right.eval(doc)
+	switch n.operator {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	case "contains":
+		return contains(left, right)
+	}
+
+	// Ordering applies to two numbers or two strings only
+	var order int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false
+		}
+		order = compareFloats(l, r)
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false
+		}
+		order = strings.Compare(l, r)
+	default:
+		return false
+	}
+	switch n.operator {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// contains reports whether a list holds an element equal to value, or a string holds value as a substring
+func contains(container, value interface{}) bool {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, element := range c {
+			if reflect.DeepEqual(element, value) {
+				return true
+			}
+		}
+	case string:
+		s, ok := value.(string)
+		return ok && strings.Contains(c, s)
+	}
+	return false
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
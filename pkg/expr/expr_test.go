@@ -0,0 +1,69 @@
+package expr
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	doc := map[string]interface{}{
+		"kind":      "Deployment",
+		"namespace": "prod-payments",
+		"replicas":  3.0,
+		"paused":    false,
+		"labels":    map[string]interface{}{"app": "api", "app.kubernetes.io/name": "payments"},
+		"changed":   []interface{}{"spec", "metadata"},
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{expression: `.kind == "Deployment"`, want: true},
+		{expression: `.kind != "Deployment"`, want: false},
+		{expression: `.namespace =~ "^prod-"`, want: true},
+		{expression: `.namespace =~ "^dev-"`, want: false},
+		{expression: `.replicas > 2`, want: true},
+		{expression: `.replicas <= 2`, want: false},
+		{expression: `.replicas >= "2"`, want: false}, // Numbers and strings don't order
+		{expression: `.kind < "Service"`, want: true},
+		{expression: `.labels.app == "api"`, want: true},
+		{expression: `.labels["app.kubernetes.io/name"] == "payments"`, want: true},
+		{expression: `.changed contains "spec"`, want: true},
+		{expression: `.namespace contains "pay"`, want: true},
+		{expression: `.changed contains "status"`, want: false},
+		{expression: `.paused`, want: false},
+		{expression: `not .paused`, want: true},
+		{expression: `.missing.field == null`, want: true},
+		{expression: `.labels`, want: true},
+		{expression: `.kind == "Deployment" and .replicas > 5`, want: false},
+		{expression: `.kind == "Service" or .replicas > 2`, want: true},
+		{expression: `not (.kind == "Service" or .paused)`, want: true},
+		{expression: `.`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			e, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := e.Match(doc); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expression := range []string{
+		``,
+		`.kind ==`,
+		`.kind == "Deployment`,
+		`(.kind == "Deployment"`,
+		`.namespace =~ 1`,
+		`.namespace =~ "("`,
+		`.kind "Deployment"`,
+	} {
+		if _, err := Parse(expression); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expression)
+		}
+	}
+}
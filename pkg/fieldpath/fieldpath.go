@@ -0,0 +1,185 @@
+// Package fieldpath parses and applies JSONPath-style field paths such as
+// status.*, spec.rules or metadata.annotations["deployment.kubernetes.io/revision"]
+// against unstructured Kubernetes objects.
+package fieldpath
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Segment is one step of a path: a map key, or a wildcard matching every key or list element
+type Segment struct {
+	Key      string
+	Wildcard bool
+}
+
+// Path is a parsed field path
+type Path []Segment
+
+// Parse parses a dotted path. Keys containing dots are written in brackets,
+// e.g. metadata.annotations["example.com/owner"]; * matches any key or list
+// element at its level. A leading "$." is accepted and ignored.
+func Parse(text string) (Path, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(text), "$.")
+	if rest == "" {
+		return nil, fmt.Errorf("empty field path")
+	}
+
+	var path Path
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			if len(rest) < 2 || (rest[1] != '"' && rest[1] != '\'') {
+				return nil, fmt.Errorf("invalid field path %q: expected quoted key after [", text)
+			}
+			quote := rest[1]
+			end := strings.IndexByte(rest[2:], quote)
+			if end < 0 || !strings.HasPrefix(rest[2+end+1:], "]") {
+				return nil, fmt.Errorf("invalid field path %q: unterminated bracket", text)
+			}
+			path = append(path, Segment{Key: rest[2 : 2+end]})
+			rest = rest[2+end+2:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("invalid field path %q: empty segment", text)
+			}
+			path = append(path, Segment{Key: key, Wildcard: key == "*"})
+			rest = rest[end:]
+		}
+
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("invalid field path %q: trailing dot", text)
+			}
+		}
+	}
+	return path, nil
+}
+
+// MustParse is like Parse but panics on invalid paths; for built-in defaults
+func MustParse(text string) Path {
+	path, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// String renders the path in the syntax accepted by Parse
+func (p Path) String() string {
+	var b strings.Builder
+	for i, segment := range p {
+		switch {
+		case segment.Wildcard:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteByte('*')
+		case strings.ContainsAny(segment.Key, ".[]*"):
+			fmt.Fprintf(&b, "[%q]", segment.Key)
+		default:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(segment.Key)
+		}
+	}
+	return b.String()
+}
+
+// Remove deletes every field the path matches from obj. A trailing wildcard
+// empties the matched map or list instead of deleting it, so "status.*" and
+// "status" differ only in whether an empty status key remains.
+func (p Path) Remove(obj map[string]interface{}) {
+	remove(obj, p)
+}
+
+func remove(value interface{}, path Path) {
+	if len(path) == 0 {
+		return
+	}
+	segment, last := path[0], len(path) == 1
+
+	switch node := value.(type) {
+	case map[string]interface{}:
+		if segment.Wildcard {
+			for key, child := range node {
+				if last {
+					delete(node, key)
+				} else {
+					remove(child, path[1:])
+				}
+			}
+			return
+		}
+		if last {
+			delete(node, segment.Key)
+			return
+		}
+		child, ok := node[segment.Key]
+		if !ok {
+			return
+		}
+		if _, isList := child.([]interface{}); isList && len(path) == 2 && path[1].Wildcard {
+			node[segment.Key] = []interface{}{}
+			return
+		}
+		remove(child, path[1:])
+	case []interface{}:
+		if !segment.Wildcard || last {
+			return
+		}
+		for _, child := range node {
+			remove(child, path[1:])
+		}
+	}
+}
+
+// Values returns the values the path matches in obj. Wildcards visit map keys
+// in sorted order, so results can be compared between two versions of an object.
+func (p Path) Values(obj map[string]interface{}) []interface{} {
+	var values []interface{}
+	collect(obj, p, &values)
+	return values
+}
+
+func collect(value interface{}, path Path, values *[]interface{}) {
+	if len(path) == 0 {
+		*values = append(*values, value)
+		return
+	}
+	segment := path[0]
+
+	switch node := value.(type) {
+	case map[string]interface{}:
+		if segment.Wildcard {
+			keys := make([]string, 0, len(node))
+			for key := range node {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				collect(node[key], path[1:], values)
+			}
+			return
+		}
+		if child, ok := node[segment.Key]; ok {
+			collect(child, path[1:], values)
+		}
+	case []interface{}:
+		if !segment.Wildcard {
+			return
+		}
+		for _, child := range node {
+			collect(child, path[1:], values)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package fieldpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Path
+		wantErr bool
+	}{
+		{text: "spec.replicas", want: Path{{Key: "spec"}, {Key: "replicas"}}},
+		{text: "$.status.*", want: Path{{Key: "status"}, {Key: "*", Wildcard: true}}},
+		{text: `metadata.annotations["deployment.kubernetes.io/revision"]`, want: Path{{Key: "metadata"}, {Key: "annotations"}, {Key: "deployment.kubernetes.io/revision"}}},
+		{text: `data['app.properties'].x`, want: Path{{Key: "data"}, {Key: "app.properties"}, {Key: "x"}}},
+		{text: "", wantErr: true},
+		{text: "spec.", wantErr: true},
+		{text: "spec..replicas", wantErr: true},
+		{text: "data[app]", wantErr: true},
+		{text: `data["app`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathString(t *testing.T) {
+	for _, text := range []string{"spec.replicas", "status.*", `metadata.annotations["example.com/owner"]`, "*.name"} {
+		if got := MustParse(text).String(); got != text {
+			t.Errorf("MustParse(%q).String() = %q", text, got)
+		}
+	}
+}
+
+func object() map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{"example.com/owner": "payments", "note": "x"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:1"},
+				map[string]interface{}{"name": "proxy", "image": "proxy:2"},
+			},
+		},
+		"status": map[string]interface{}{"replicas": 3.0, "ready": 2.0},
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		path string
+		want func(obj map[string]interface{})
+	}{
+		{path: "status", want: func(obj map[string]interface{}) { delete(obj, "status") }},
+		{path: "status.*", want: func(obj map[string]interface{}) { obj["status"] = map[string]interface{}{} }},
+		{path: "spec.containers.*", want: func(obj map[string]interface{}) {
+			obj["spec"] = map[string]interface{}{"containers": []interface{}{}}
+		}},
+		{path: "spec.containers.*.image", want: func(obj map[string]interface{}) {
+			obj["spec"] = map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "app"},
+				map[string]interface{}{"name": "proxy"},
+			}}
+		}},
+		{path: `metadata.annotations["example.com/owner"]`, want: func(obj map[string]interface{}) {
+			delete(obj["metadata"].(map[string]interface{})["annotations"].(map[string]interface{}), "example.com/owner")
+		}},
+		{path: "spec.missing.field", want: func(map[string]interface{}) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, want := object(), object()
+			MustParse(tt.path).Remove(got)
+			tt.want(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Remove() left %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{path: "status.replicas", want: []interface{}{3.0}},
+		{path: "status.*", want: []interface{}{2.0, 3.0}}, // ready, replicas: sorted by key
+		{path: "spec.containers.*.image", want: []interface{}{"app:1", "proxy:2"}},
+		{path: "spec.containers.name", want: nil},
+		{path: "metadata.labels", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := MustParse(tt.path).Values(object()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Values() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package gitops
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxValueLength is how much of a value a drift line shows
+const maxValueLength = 80
+
+// Diff returns a line per field the manifest declares that the live object does not match,
+// e.g. "~ spec.replicas: 3 in Git, 5 live". Fields set only on the live object, such as
+// defaults and status, are not drift; of the metadata only labels and annotations are compared.
+func (r *Repository) Diff(manifest Manifest, live map[string]interface{}) []string {
+	declared := copyMap(manifest.Object)
+	actual := copyMap(live)
+	if kind, _ := declared["kind"].(string); kind == "Secret" {
+		mergeStringData(declared)
+	}
+	for _, path := range r.ignore {
+		path.Remove(declared)
+		path.Remove(actual)
+	}
+
+	var lines []string
+	for _, key := range sortedKeys(declared) {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			gitMetadata, _ := declared[key].(map[string]interface{})
+			liveMetadata, _ := actual[key].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				if value, ok := gitMetadata[field]; ok {
+					compare("metadata."+field, value, liveMetadata[field], liveMetadata != nil && liveMetadata[field] != nil, &lines)
+				}
+			}
+			continue
+		}
+		value, ok := actual[key]
+		compare(key, declared[key], value, ok, &lines)
+	}
+
+	if kind, _ := declared["kind"].(string); kind == "Secret" {
+		// Secret values stay out of notifications
+		for i, line := range lines {
+			field := line[:strings.Index(line, ": ")]
+			switch {
+			case strings.HasPrefix(line, "~ data"):
+				lines[i] = field + ": differs"
+			case strings.HasPrefix(line, "- data."), strings.HasPrefix(line, "- data["):
+				lines[i] = field + ": in Git, not set live"
+			}
+		}
+	}
+	return lines
+}
+
+// compare appends the differences between a declared value and the live one at path
+func compare(path string, declared, live interface{}, present bool, lines *[]string) {
+	if !present {
+		*lines = append(*lines, fmt.Sprintf("- %s: %s in Git, not set live", path, render(declared)))
+		return
+	}
+	switch declared := declared.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(declared) {
+			value, ok := liveMap[key]
+			compare(childPath(path, key), declared[key], value, ok, lines)
+		}
+		return
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			break
+		}
+		if len(declared) != len(liveList) {
+			*lines = append(*lines, fmt.Sprintf("~ %s: %d items in Git, %d live", path, len(declared), len(liveList)))
+			return
+		}
+		for i := range declared {
+			compare(fmt.Sprintf("%s[%d]", path, i), declared[i], liveList[i], true, lines)
+		}
+		return
+	default:
+		if scalar(declared) == scalar(live) {
+			return
+		}
+	}
+	*lines = append(*lines, fmt.Sprintf("~ %s: %s in Git, %s live", path, render(declared), render(live)))
+}
+
+// mergeStringData moves the stringData of a Secret manifest into data, encoded as the API
+// server stores it
+func mergeStringData(secret map[string]interface{}) {
+	stringData, ok := secret["stringData"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	data, _ := secret["data"].(map[string]interface{})
+	if data == nil {
+		data = make(map[string]interface{}, len(stringData))
+	}
+	for key, value := range stringData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(scalar(value)))
+	}
+	secret["data"] = data
+	delete(secret, "stringData")
+}
+
+// scalar renders a scalar for comparison; manifests decode numbers as float64 and typed
+// objects as int64, so integral numbers compare equal either way
+func scalar(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+			return strconv.FormatInt(int64(value), 10)
+		}
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// render shows a value in a drift line, shortened to maxValueLength
+func render(value interface{}) string {
+	var text string
+	switch value := value.(type) {
+	case map[string]interface{}:
+		text = fmt.Sprintf("{%d fields}", len(value))
+	case []interface{}:
+		text = fmt.Sprintf("[%d items]", len(value))
+	case string:
+		text = strconv.Quote(value)
+	default:
+		text = scalar(value)
+	}
+	if len(text) > maxValueLength {
+		text = text[:maxValueLength-3] + "..."
+	}
+	return text
+}
+
+// childPath appends a key to a path in the syntax of ignoreFields
+func childPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]*") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// copyMap deep-copies an object so ignored fields can be removed without touching the
+// cached manifest or the informer's object
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		out[key] = copyValue(value)
+	}
+	return out
+}
+
+func copyValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return copyMap(value)
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, item := range value {
+			out[i] = copyValue(item)
+		}
+		return out
+	}
+	return value
+}
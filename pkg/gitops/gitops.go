@@ -0,0 +1,262 @@
+// Package gitops compares live objects with the manifests declared in a Git repository, so
+// notifications can tell whether a changed object still matches Git or was edited out of band.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/fieldpath"
+)
+
+// syncTimeout bounds one clone or pull of the repository
+const syncTimeout = 2 * time.Minute
+
+// Manifest is an object declared in the repository
+type Manifest struct {
+	File   string // Path of the file declaring it, relative to the repository root
+	Object map[string]interface{}
+}
+
+// Repository is a local clone of the configured repository and the manifests of its last
+// pulled commit. It is safe for concurrent use.
+type Repository struct {
+	config config.GitOpsConfig
+	dir    string
+	ignore []fieldpath.Path
+	logger *slog.Logger
+
+	mu        sync.RWMutex
+	commit    string
+	manifests map[string]Manifest // By kind/namespace/name
+}
+
+// NewRepository prepares a clone of the configured repository for cluster; nothing is
+// fetched until Sync or Run
+func NewRepository(cfg config.GitOpsConfig, cluster string, logger *slog.Logger) *Repository {
+	cacheDir := cfg.CacheDir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(os.TempDir(), "resource-watcher-gitops")
+	}
+	// Each cluster watcher pulls on its own schedule, so each gets its own clone
+	sum := sha256.Sum256([]byte(cluster + "\x00" + cfg.URL + "\x00" + cfg.GetBranch()))
+
+	var ignore []fieldpath.Path
+	for _, text := range cfg.IgnoreFields {
+		if path, err := fieldpath.Parse(text); err == nil {
+			ignore = append(ignore, path)
+		}
+	}
+	return &Repository{
+		config:    cfg,
+		dir:       filepath.Join(cacheDir, hex.EncodeToString(sum[:8])),
+		ignore:    ignore,
+		logger:    logger,
+		manifests: make(map[string]Manifest),
+	}
+}
+
+// Run pulls the repository at once and then every interval until ctx is done
+func (r *Repository) Run(ctx context.Context) {
+	defer exitcode.Recover()
+	ticker := time.NewTicker(r.config.GetInterval())
+	defer ticker.Stop()
+	for {
+		if err := r.Sync(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Warn("Failed to pull GitOps repository", "url", r.config.URL, "branch", r.config.GetBranch(), "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync clones or pulls the branch and, when its commit changed, reloads the manifests.
+// The manifests of the previous commit are kept when it fails.
+func (r *Repository) Sync(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+
+	branch := r.config.GetBranch()
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err != nil {
+		if err := os.RemoveAll(r.dir); err != nil {
+			return fmt.Errorf("failed to clear clone directory: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(r.dir), 0o700); err != nil {
+			return fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		if _, err := r.git(ctx, "clone", "--quiet", "--depth", "1", "--single-branch", "--branch", branch, r.config.URL, r.dir); err != nil {
+			return err
+		}
+	} else {
+		if _, err := r.git(ctx, "-C", r.dir, "fetch", "--quiet", "--depth", "1", "origin", branch); err != nil {
+			return err
+		}
+		if _, err := r.git(ctx, "-C", r.dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return err
+		}
+	}
+
+	commit, err := r.git(ctx, "-C", r.dir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if commit == r.Commit() {
+		return nil
+	}
+
+	manifests, err := r.load()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.commit, r.manifests = commit, manifests
+	r.mu.Unlock()
+	r.logger.Info("Loaded manifests from Git", "url", r.config.URL, "branch", branch, "commit", commit, "manifests", len(manifests))
+	return nil
+}
+
+// git runs a git command without prompting, authenticating HTTPS requests with the token
+// file if set; the token is passed in the environment so it stays out of the process list
+func (r *Repository) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if r.config.TokenFile != "" {
+		token, err := os.ReadFile(r.config.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(r.config.GetUsername() + ":" + strings.TrimSpace(string(token))))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v: %s", args[subcommand(args)], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// subcommand returns the index of the git subcommand in args, after any -C dir
+func subcommand(args []string) int {
+	if len(args) > 2 && args[0] == "-C" {
+		return 2
+	}
+	return 0
+}
+
+// load reads every YAML and JSON manifest under the configured path. Documents that do not
+// parse, such as Helm templates, are skipped; a later file declaring the same object wins.
+func (r *Repository) load() (map[string]Manifest, error) {
+	root := filepath.Join(r.dir, filepath.FromSlash(r.config.Path))
+	manifests := make(map[string]Manifest)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, _ := filepath.Rel(r.dir, path)
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var doc map[string]interface{}
+			if err := decoder.Decode(&doc); err != nil {
+				if err != io.EOF {
+					r.logger.Debug("Skipping unparsable manifest file", "file", file, "error", err)
+				}
+				break
+			}
+			r.add(manifests, filepath.ToSlash(file), doc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests: %w", err)
+	}
+	return manifests, nil
+}
+
+// add indexes a document, and the items of a List
+func (r *Repository) add(manifests map[string]Manifest, file string, doc map[string]interface{}) {
+	kind, _ := doc["kind"].(string)
+	if items, ok := doc["items"].([]interface{}); ok && strings.HasSuffix(kind, "List") {
+		for _, item := range items {
+			if item, ok := item.(map[string]interface{}); ok {
+				r.add(manifests, file, item)
+			}
+		}
+		return
+	}
+
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if kind == "" || name == "" {
+		return
+	}
+	namespace, _ := metadata["namespace"].(string)
+	if namespace == "" && !clusterScoped(kind) {
+		namespace = r.config.GetDefaultNamespace()
+	}
+	manifests[manifestKey(kind, namespace, name)] = Manifest{File: file, Object: doc}
+}
+
+// Commit returns the commit the manifests were loaded from; empty until the first pull
+func (r *Repository) Commit() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.commit
+}
+
+// Lookup returns the manifest declaring an object
+func (r *Repository) Lookup(kind, namespace, name string) (Manifest, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	manifest, ok := r.manifests[manifestKey(kind, namespace, name)]
+	return manifest, ok
+}
+
+func manifestKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+func clusterScoped(kind string) bool {
+	return config.ClusterScopedKinds[kind] || kind == "Namespace"
+}
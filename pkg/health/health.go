@@ -0,0 +1,141 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+)
+
+const (
+	// checkTimeout bounds a single check
+	checkTimeout = 5 * time.Second
+
+	// startupRetryInterval is how often failing startup checks are retried
+	startupRetryInterval = 10 * time.Second
+)
+
+// Check reports whether a dependency is healthy; the error explains why not
+type Check func(ctx context.Context) error
+
+type check struct {
+	name    string
+	fn      Check
+	startup bool
+	passed  bool
+	lastErr error
+}
+
+// Handler serves readiness from a set of checks. Startup checks, such as a test
+// connection to the SMTP server, must pass once and are retried in the background
+// until they do; the other checks run on every probe and must be fast.
+type Handler struct {
+	mu     sync.RWMutex
+	checks []*check
+}
+
+// NewHandler creates a readiness handler without checks
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// AddCheck registers a check evaluated on every probe
+func (h *Handler) AddCheck(name string, fn Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, &check{name: name, fn: fn})
+}
+
+// AddStartupCheck registers a check that must pass once before the service is ready
+func (h *Handler) AddStartupCheck(name string, fn Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, &check{name: name, fn: fn, startup: true, lastErr: fmt.Errorf("not checked yet")})
+}
+
+// Run retries the startup checks until all of them passed or ctx is done
+func (h *Handler) Run(ctx context.Context) {
+	defer exitcode.Recover()
+	ticker := time.NewTicker(startupRetryInterval)
+	defer ticker.Stop()
+	for !h.runStartupChecks(ctx) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runStartupChecks runs the startup checks that have not passed yet, reporting whether all have now
+func (h *Handler) runStartupChecks(ctx context.Context) bool {
+	h.mu.RLock()
+	var pending []*check
+	for _, c := range h.checks {
+		if c.startup && !c.passed {
+			pending = append(pending, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	done := true
+	for _, c := range pending {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := c.fn(checkCtx)
+		cancel()
+
+		h.mu.Lock()
+		c.passed, c.lastErr = err == nil, err
+		h.mu.Unlock()
+
+		if err != nil {
+			slog.Warn("Startup check failed", "check", c.name, "error", err)
+			done = false
+		} else {
+			slog.Info("Startup check passed", "check", c.name)
+		}
+	}
+	return done
+}
+
+// Ready reports whether every check passes; the reason names the first that does not
+func (h *Handler) Ready(ctx context.Context) (bool, string) {
+	h.mu.RLock()
+	checks := make([]check, 0, len(h.checks))
+	for _, c := range h.checks {
+		checks = append(checks, *c)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range checks {
+		if c.startup {
+			if !c.passed {
+				return false, fmt.Sprintf("%s: %v", c.name, c.lastErr)
+			}
+			continue
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := c.fn(checkCtx)
+		cancel()
+		if err != nil {
+			return false, fmt.Sprintf("%s: %v", c.name, err)
+		}
+	}
+	return true, ""
+}
+
+// ServeHTTP answers 200 when ready and 503 with the reason otherwise
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if ready, reason := h.Ready(r.Context()); !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "Not Ready", "reason": reason})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": "OK"})
+}
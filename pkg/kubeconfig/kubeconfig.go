@@ -0,0 +1,49 @@
+// Package kubeconfig locates the client configuration of the clusters the watcher talks to
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Local returns the client configuration for the cluster the watcher runs in.
+// Without an explicit kubeconfig path or context that is the in-cluster
+// service account, falling back to KUBECONFIG and ~/.kube/config when the
+// watcher runs outside a cluster.
+func Local(path, context string) (*rest.Config, error) {
+	if path == "" && context == "" {
+		restConfig, err := rest.InClusterConfig()
+		if err == nil {
+			return restConfig, nil
+		}
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+		}
+	}
+	return File(path, context)
+}
+
+// File returns the client configuration for a context of a kubeconfig file.
+// An empty path uses the default loading rules (KUBECONFIG, then
+// ~/.kube/config); an empty context uses the file's current context.
+func File(path, context string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if path != "" {
+		rules.ExplicitPath = path
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		if path == "" && clientcmd.IsEmptyConfig(err) {
+			return nil, fmt.Errorf("not running in a cluster and no kubeconfig found (set --kubeconfig or KUBECONFIG)")
+		}
+		return nil, fmt.Errorf("failed to load kubeconfig %q (context %q): %w", path, context, err)
+	}
+
+	slog.Info("Using kubeconfig", "path", path, "context", context, "apiServer", restConfig.Host)
+	return restConfig, nil
+}
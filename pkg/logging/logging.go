@@ -0,0 +1,36 @@
+// Package logging configures the process-wide structured logger
+package logging
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// Setup makes a logger with the configured level and format the default for slog and,
+// through slog, for the standard log package. Records go to stderr.
+func Setup(cfg config.LoggingConfig) {
+	options := &slog.HandlerOptions{Level: Level(cfg.Level)}
+	var handler slog.Handler
+	if cfg.Format == "json" || cfg.EnableJSON {
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, options)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// Level converts a configured level name (debug, info, warn or error) to a slog level, defaulting to info
+func Level(name string) slog.Level {
+	switch name {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// alertSource names the watcher as the origin of alerts
+const alertSource = "k8s-resource-watcher"
+
+// Limits of incident management APIs on alert text
+const (
+	maxAlertTitle       = 130
+	maxAlertDescription = 15000
+)
+
+// alertKey identifies the alert of an object across events, deduplicating repeated
+// problems and letting a resolving event close what earlier ones raised
+func alertKey(cfg *config.Config, event NotificationEvent) string {
+	return clusterName(cfg, event) + "/" + event.ResourceKind + "/" + event.Ref()
+}
+
+// resolvesAlert reports whether the event ends the problem an object's alert is about:
+// a Helm revision deployed after a failed one, or a renewed Certificate
+func resolvesAlert(event NotificationEvent) bool {
+	switch event.EventType {
+	case EventHelmInstalled, EventHelmUpgraded, EventHelmRolledBack, EventCertificateRenewed:
+		return true
+	default:
+		return false
+	}
+}
+
+// alertTitle is the one-line summary of an alert, e.g. "[prod] Secret default/db was DELETED"
+func alertTitle(cfg *config.Config, event NotificationEvent) string {
+	title := fmt.Sprintf("[%s] %s %s was %s", clusterName(cfg, event), event.ResourceKind, event.Ref(), event.EventType)
+	return truncateText(title, maxAlertTitle)
+}
+
+// alertDescription renders the event's details as plain text for an alert body,
+// dropping diff sections to fit the APIs' description limit
+func alertDescription(cfg *config.Config, event NotificationEvent) string {
+	return renderAlertDescription(cfg, fitMessage(cfg, event, maxAlertDescription, func(e NotificationEvent) int {
+		return len(renderAlertDescription(cfg, e))
+	}))
+}
+
+func renderAlertDescription(cfg *config.Config, event NotificationEvent) string {
+	lines := []string{
+		"Cluster: " + clusterName(cfg, event),
+		"Resource: " + event.ResourceKind,
+		"Name: " + event.ResourceName,
+		"Namespace: " + displayNamespace(event),
+		"Event: " + string(event.EventType),
+	}
+	if event.ID != "" {
+		lines = append(lines, "Event ID: "+event.ID)
+	}
+	if changedBy := describeChangedBy(event); changedBy != "" {
+		lines = append(lines, "Changed by: "+changedBy)
+	}
+	if len(event.ChangedFields) > 0 {
+		lines = append(lines, "Changed fields: "+strings.Join(event.ChangedFields, ", "))
+	}
+	for _, change := range event.ImageChanges {
+		lines = append(lines, fmt.Sprintf("Image (%s): %s → %s", change.Container, change.Old, change.New))
+	}
+	if len(event.Summary) > 0 {
+		lines = append(append(lines, ""), event.Summary...)
+	}
+	if len(event.Diff) > 0 {
+		lines = append(lines, "", "Changes:")
+		for _, line := range event.Diff {
+			lines = append(lines, "  "+line)
+		}
+	}
+	if len(event.Warnings) > 0 {
+		lines = append(lines, "", "Warnings:")
+		for _, warning := range event.Warnings {
+			lines = append(lines, "  - "+warning)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateText cuts text to at most max bytes without splitting a UTF-8 character
+func truncateText(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := max - len("…")
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "…"
+}
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// anomalyWindow is the period change rates are counted over
+const anomalyWindow = time.Minute
+
+// changeRate tracks the changes of one kind in one namespace
+type changeRate struct {
+	windowStart time.Time
+	count       int     // Changes in the current window
+	baseline    float64 // Moving average of the changes per window
+	flagged     bool    // An ANOMALY was already sent for the current window
+}
+
+// AnomalyDetector learns how often each kind changes per namespace and sends
+// an ANOMALY notification when a minute's changes spike far above that
+// baseline, e.g. runaway automation or a compromised credential rewriting
+// Secrets. Events are always passed on unchanged.
+type AnomalyDetector struct {
+	next      Notifier
+	minEvents int
+	factor    float64
+	decay     float64 // Weight the baseline keeps each window
+
+	mu      sync.Mutex
+	started time.Time
+	rates   map[string]*changeRate // cluster/kind/namespace -> rate
+	now     func() time.Time
+}
+
+// NewAnomalyDetector wraps next so a window with at least minEvents changes and
+// factor times the baseline, averaged over the baseline period, is flagged
+func NewAnomalyDetector(next Notifier, minEvents int, factor float64, baseline time.Duration) *AnomalyDetector {
+	return &AnomalyDetector{
+		next:      next,
+		minEvents: minEvents,
+		factor:    factor,
+		decay:     1 - float64(anomalyWindow)/float64(baseline),
+		started:   time.Now(),
+		rates:     make(map[string]*changeRate),
+		now:       time.Now,
+	}
+}
+
+// SendNotification counts resource changes and forwards the event, preceded by an ANOMALY when the rate spikes
+func (d *AnomalyDetector) SendNotification(ctx context.Context, event NotificationEvent) error {
+	if anomaly, ok := d.observe(event); ok {
+		if err := d.next.SendNotification(ctx, anomaly); err != nil {
+			slog.Error("Failed to send anomaly notification", "error", err)
+		}
+	}
+	return d.next.SendNotification(ctx, event)
+}
+
+// observe counts a resource change and returns the ANOMALY event when it completes a spike
+func (d *AnomalyDetector) observe(event NotificationEvent) (NotificationEvent, bool) {
+	switch event.EventType {
+	case EventAdded, EventModified, EventDeleted:
+	default:
+		return NotificationEvent{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	key := event.Cluster + "/" + event.ResourceKind + "/" + event.Namespace
+	rate, ok := d.rates[key]
+	if !ok {
+		rate = &changeRate{windowStart: now}
+		d.rates[key] = rate
+	}
+	if elapsed := int(now.Sub(rate.windowStart) / anomalyWindow); elapsed > 0 {
+		// Fold the finished window into the baseline, then the empty ones since
+		rate.baseline = rate.baseline*d.decay + float64(rate.count)*(1-d.decay)
+		rate.baseline *= math.Pow(d.decay, float64(elapsed-1))
+		rate.windowStart = rate.windowStart.Add(time.Duration(elapsed) * anomalyWindow)
+		rate.count = 0
+		rate.flagged = false
+	}
+	rate.count++
+
+	// The initial list of every watched object arrives in the first window
+	if rate.flagged || rate.count < d.minEvents || now.Sub(d.started) < anomalyWindow {
+		return NotificationEvent{}, false
+	}
+	if float64(rate.count) < d.factor*math.Max(rate.baseline, 1) {
+		return NotificationEvent{}, false
+	}
+	rate.flagged = true
+
+	slog.Warn("Anomalous change rate within a minute", "kind", event.ResourceKind, "namespace", event.Namespace,
+		"changes", rate.count, "baseline", rate.baseline)
+	return NotificationEvent{
+		Cluster:      event.Cluster,
+		EventType:    EventAnomaly,
+		Severity:     config.SeverityWarning,
+		ResourceKind: event.ResourceKind,
+		ResourceName: "*",
+		Namespace:    event.Namespace,
+		Summary: []string{
+			fmt.Sprintf("%d %s changes in the last minute, against a baseline of %.1f per minute.",
+				rate.count, event.ResourceKind, rate.baseline),
+			fmt.Sprintf("Latest: %s %s was %s.", event.ResourceKind, event.Ref(), event.EventType),
+		},
+	}, true
+}
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+)
+
+// burstWindow is the period the global notification budget applies to
+const burstWindow = time.Minute
+
+// maxSummaryResources caps how many resources are listed in a burst summary
+const maxSummaryResources = 20
+
+// BurstGuardNotifier caps the total number of notifications sent per minute.
+// It is the last line of defence against configuration mistakes: events over
+// the budget are dropped and reported in a single summary when the window ends.
+type BurstGuardNotifier struct {
+	next    Notifier
+	summary Notifier
+	limit   int
+	route   func(NotificationEvent) []string // Notifiers an event is routed to; nil counts every event
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sent        int
+	dropped     map[string]int // kind namespace/name -> dropped events
+	timer       *time.Timer
+	now         func() time.Time
+}
+
+// NewBurstGuardNotifier wraps next so at most maxPerMinute notifications are sent per minute.
+// Summaries of dropped notifications are delivered through summary, which should
+// not be subject to routing so the summary always reaches someone.
+func NewBurstGuardNotifier(next, summary Notifier, maxPerMinute int) *BurstGuardNotifier {
+	return &BurstGuardNotifier{
+		next:    next,
+		summary: summary,
+		limit:   maxPerMinute,
+		dropped: make(map[string]int),
+		now:     time.Now,
+	}
+}
+
+// SetRoute makes the guard ask route which notifiers an event goes to, so events that
+// routing sends nowhere do not spend the budget
+func (g *BurstGuardNotifier) SetRoute(route func(NotificationEvent) []string) {
+	g.route = route
+}
+
+// SendNotification forwards the event while the global budget lasts
+func (g *BurstGuardNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	if g.route != nil && len(g.route(event)) == 0 {
+		return g.next.SendNotification(ctx, event)
+	}
+
+	g.mu.Lock()
+	now := g.now()
+	if now.Sub(g.windowStart) >= burstWindow {
+		g.windowStart = now
+		g.sent = 0
+	}
+
+	if g.sent < g.limit {
+		g.sent++
+		g.mu.Unlock()
+		return g.next.SendNotification(ctx, event)
+	}
+
+	key := fmt.Sprintf("%s %s/%s", event.ResourceKind, event.Namespace, event.ResourceName)
+	g.dropped[key]++
+	if g.timer == nil {
+		slog.Warn("Global notification budget exhausted; dropping until the window ends", "maxPerMinute", g.limit)
+		g.timer = time.AfterFunc(g.windowStart.Add(burstWindow).Sub(now), g.flushOnTimer)
+	}
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *BurstGuardNotifier) flushOnTimer() {
+	defer exitcode.Recover()
+	ctx, cancel := context.WithTimeout(context.Background(), burstWindow)
+	defer cancel()
+	if err := g.Flush(ctx); err != nil {
+		slog.Error("Failed to send burst summary", "error", err)
+	}
+}
+
+// Flush sends the summary of dropped notifications, if any. It runs
+// automatically at the end of each window and should be called on shutdown.
+func (g *BurstGuardNotifier) Flush(ctx context.Context) error {
+	g.mu.Lock()
+	dropped := g.dropped
+	g.dropped = make(map[string]int)
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	g.mu.Unlock()
+
+	if len(dropped) == 0 {
+		return nil
+	}
+	return g.summary.SendNotification(ctx, g.buildSummary(dropped))
+}
+
+// buildSummary lists the resources with the most dropped notifications first
+func (g *BurstGuardNotifier) buildSummary(dropped map[string]int) NotificationEvent {
+	keys := make([]string, 0, len(dropped))
+	total := 0
+	for key, count := range dropped {
+		keys = append(keys, key)
+		total += count
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if dropped[keys[i]] != dropped[keys[j]] {
+			return dropped[keys[i]] > dropped[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var lines []string
+	for i, key := range keys {
+		if i == maxSummaryResources {
+			lines = append(lines, fmt.Sprintf("...and %d more resources", len(keys)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s: %d", key, dropped[key]))
+	}
+
+	slog.Info("Sending burst summary", "dropped", total, "resources", len(keys))
+	return NotificationEvent{
+		EventType:        EventBurstSummary,
+		Severity:         config.SeverityWarning,
+		ResourceKind:     "Notifications",
+		ResourceName:     "burst-protection",
+		SuppressedEvents: total,
+		Summary: append([]string{
+			fmt.Sprintf("%d notifications exceeded the global budget of %d per minute and were dropped:", total, g.limit),
+		}, lines...),
+	}
+}
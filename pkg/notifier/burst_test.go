@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBurstGuardNotifier(t *testing.T) {
+	now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+	next, summary := &recordingNotifier{}, &recordingNotifier{}
+	guard := NewBurstGuardNotifier(next, summary, 3)
+	guard.now = func() time.Time { return now }
+	defer guard.Flush(context.Background())
+
+	send := func(name string) {
+		guard.SendNotification(context.Background(), NotificationEvent{ResourceKind: "ConfigMap", Namespace: "prod", ResourceName: name})
+	}
+	for _, name := range []string{"a", "b", "c", "d", "d", "e"} {
+		send(name)
+	}
+	if got := len(next.sent()); got != 3 {
+		t.Fatalf("%d notifications forwarded, want the budget of 3", got)
+	}
+
+	if err := guard.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	summaries := summary.sent()
+	if len(summaries) != 1 {
+		t.Fatalf("%d summaries sent, want 1", len(summaries))
+	}
+	if summaries[0].EventType != EventBurstSummary || summaries[0].SuppressedEvents != 3 {
+		t.Errorf("summary = %+v, want a burst summary of 3 dropped notifications", summaries[0])
+	}
+	wantLines := []string{
+		"3 notifications exceeded the global budget of 3 per minute and were dropped:",
+		"ConfigMap prod/d: 2",
+		"ConfigMap prod/e: 1",
+	}
+	if !reflect.DeepEqual(summaries[0].Summary, wantLines) {
+		t.Errorf("summary lines = %q, want %q", summaries[0].Summary, wantLines)
+	}
+
+	// A new window has a new budget, and an empty flush sends nothing
+	now = now.Add(time.Minute)
+	send("f")
+	if got := len(next.sent()); got != 4 {
+		t.Errorf("%d notifications forwarded after the window ended, want 4", got)
+	}
+	guard.Flush(context.Background())
+	if got := len(summary.sent()); got != 1 {
+		t.Errorf("%d summaries sent, want no second one without drops", got)
+	}
+}
+
+func TestBurstGuardNotifierSkipsUnroutedEvents(t *testing.T) {
+	next, summary := &recordingNotifier{}, &recordingNotifier{}
+	guard := NewBurstGuardNotifier(next, summary, 2)
+	guard.SetRoute(func(event NotificationEvent) []string {
+		if event.Namespace == "dev" {
+			return nil
+		}
+		return []string{"email"}
+	})
+	defer guard.Flush(context.Background())
+
+	for _, namespace := range []string{"dev", "dev", "dev", "prod", "prod", "prod"} {
+		guard.SendNotification(context.Background(), NotificationEvent{ResourceKind: "ConfigMap", Namespace: namespace, ResourceName: "app"})
+	}
+	// Unrouted events still reach the router, which drops them, but leave the budget to the routed ones
+	if got := len(next.sent()); got != 5 {
+		t.Errorf("%d notifications forwarded, want the 3 unrouted and 2 routed within budget", got)
+	}
+	guard.Flush(context.Background())
+	if summaries := summary.sent(); len(summaries) != 1 || summaries[0].SuppressedEvents != 1 {
+		t.Errorf("summaries = %+v, want one of 1 dropped notification", summaries)
+	}
+}
+
+func TestBurstGuardSummaryListsTopResources(t *testing.T) {
+	guard := NewBurstGuardNotifier(nil, nil, 1)
+	dropped := make(map[string]int)
+	for i := 0; i < maxSummaryResources+5; i++ {
+		dropped[fmt.Sprintf("ConfigMap prod/cm-%02d", i)] = 1
+	}
+	dropped["ConfigMap prod/noisy"] = 10
+
+	lines := guard.buildSummary(dropped).Summary
+	if len(lines) != maxSummaryResources+2 {
+		t.Fatalf("%d summary lines, want the header, %d resources and a remainder line", len(lines), maxSummaryResources)
+	}
+	if lines[1] != "ConfigMap prod/noisy: 10" || lines[len(lines)-1] != "...and 6 more resources" {
+		t.Errorf("summary lines = %q", lines)
+	}
+}
@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// sendLimiter bounds a notifier's concurrent sends and, when configured, sends
+// to each destination one message at a time
+type sendLimiter struct {
+	slots     chan struct{} // nil when unlimited
+	serialize bool
+
+	mu           sync.Mutex
+	destinations map[string]chan struct{}
+}
+
+func newSendLimiter(cfg config.ConcurrencyConfig) *sendLimiter {
+	l := &sendLimiter{
+		serialize:    cfg.SerializePerDestination,
+		destinations: make(map[string]chan struct{}),
+	}
+	if cfg.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, cfg.MaxInFlight)
+	}
+	return l
+}
+
+// do runs send for destination once the limits allow, or returns ctx's error if it is done first.
+// A send waiting for its destination does not hold one of the in-flight slots.
+func (l *sendLimiter) do(ctx context.Context, destination string, send func() error) error {
+	if l.serialize {
+		turn := l.destination(destination)
+		select {
+		case turn <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() { <-turn }()
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() { <-l.slots }()
+	}
+	return send()
+}
+
+// destination returns the one-message turn of a destination
+func (l *sendLimiter) destination(name string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	turn, ok := l.destinations[name]
+	if !ok {
+		turn = make(chan struct{}, 1)
+		l.destinations[name] = turn
+	}
+	return turn
+}
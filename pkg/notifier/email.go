@@ -1,14 +1,18 @@
 package notifier
 
 import (
-	"crypto/tls"
+	"bytes"
+	"context"
 	"fmt"
-	"log"
+	htmltemplate "html/template"
+	"log/slog"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/templates"
 
 	"gopkg.in/gomail.v2"
 )
@@ -20,137 +24,346 @@ type EmailMetrics struct {
 	EmailsSkipped int64
 }
 
+// emailMessage is a rendered email, independent of how it is delivered
+type emailMessage struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string // Optional alternative part
+}
+
+// compose builds the MIME message
+func (msg emailMessage) compose() *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To...)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetBody("text/plain", msg.Text)
+	if msg.HTML != "" {
+		m.AddAlternative("text/html", msg.HTML)
+	}
+	return m
+}
+
+// emailTransport delivers rendered emails over SMTP or a provider's HTTPS API.
+// send makes a single attempt and must return once ctx is done.
+type emailTransport interface {
+	send(ctx context.Context, msg emailMessage) error
+	probe(ctx context.Context) error
+}
+
 // EmailNotifier sends email notifications for resource events
 type EmailNotifier struct {
-	config  *config.Config
-	metrics *EmailMetrics
-	mu      sync.RWMutex
-	dialer  *gomail.Dialer
+	config    *config.Config
+	metrics   *EmailMetrics
+	mu        sync.RWMutex
+	transport emailTransport
+	limiter   *sendLimiter
+
+	subjectTemplate *template.Template
+	bodyTemplate    *template.Template
+	htmlTemplate    *htmltemplate.Template
 }
 
 // NewEmailNotifier creates a new email notifier
 func NewEmailNotifier(cfg *config.Config) *EmailNotifier {
-	// Create dialer with appropriate settings
-	dialer := gomail.NewDialer(
-		cfg.Email.SMTPHost,
-		cfg.Email.SMTPPort,
-		cfg.Email.SMTPUsername,
-		cfg.Email.SMTPPassword,
-	)
-
-	switch cfg.Email.SMTPPort {
-	case 465:
-		dialer.SSL = true
-		dialer.TLSConfig = &tls.Config{
-			InsecureSkipVerify: cfg.Email.InsecureTLS,
-			ServerName:         cfg.Email.SMTPHost,
-		}
-	case 587:
-		dialer.SSL = cfg.Email.ForceSSL
-		dialer.TLSConfig = &tls.Config{
-			InsecureSkipVerify: cfg.Email.InsecureTLS,
-			ServerName:         cfg.Email.SMTPHost,
-		}
-	case 25:
-		dialer.SSL = cfg.Email.ForceSSL
-		dialer.TLSConfig = &tls.Config{
-			InsecureSkipVerify: true,
-			ServerName:         cfg.Email.SMTPHost,
-		}
+	var transport emailTransport
+	switch cfg.Email.GetProvider() {
+	case config.EmailProviderSES:
+		transport = newSESTransport(&cfg.Email)
+	case config.EmailProviderSendGrid:
+		transport = newSendGridTransport(&cfg.Email)
 	default:
-		dialer.SSL = cfg.Email.ForceSSL
-		dialer.TLSConfig = &tls.Config{
-			InsecureSkipVerify: cfg.Email.InsecureTLS,
-			ServerName:         cfg.Email.SMTPHost,
-		}
+		transport = newSMTPTransport(&cfg.Email)
+	}
+
+	notifier := &EmailNotifier{
+		config:    cfg,
+		metrics:   &EmailMetrics{},
+		transport: transport,
+		limiter:   newSendLimiter(cfg.Email.Concurrency),
 	}
 
-	return &EmailNotifier{
-		config:  cfg,
-		metrics: &EmailMetrics{},
-		dialer:  dialer,
+	// Templates are validated with the config; fall back to the built-in format if parsing still fails
+	var err error
+	if notifier.subjectTemplate, err = parseOptionalTemplate("subject", cfg.Email.SubjectTemplate); err != nil {
+		slog.Warn("Ignoring email subject template", "error", err)
 	}
+	if notifier.bodyTemplate, err = parseOptionalTemplate("body", cfg.Email.BodyTemplate); err != nil {
+		slog.Warn("Ignoring email body template", "error", err)
+	}
+	if cfg.Email.HTMLTemplate != "" {
+		if notifier.htmlTemplate, err = templates.ParseHTML("html", cfg.Email.HTMLTemplate); err != nil {
+			slog.Warn("Ignoring email HTML template", "error", err)
+		}
+	}
+
+	return notifier
 }
 
 // SendNotification sends an email notification for a resource event
-func (n *EmailNotifier) SendNotification(event NotificationEvent) error {
+func (n *EmailNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
 	// Skip non-standard events
-	switch event.EventType {
-	case "ADDED", "MODIFIED", "DELETED", "ROLLOUT_COMPLETED":
-		// Process these events
-	default:
-		log.Printf("Skipping notification for event type: %s", event.EventType)
+	if !event.EventType.Valid() {
+		eventLogger(event).Debug("Skipping email for non-standard event type")
+		n.mu.Lock()
 		n.metrics.EmailsSkipped++
+		n.mu.Unlock()
 		return nil
 	}
 
-	// Create email message
-	subject := fmt.Sprintf("[%s] %s %s/%s was %s",
-		n.config.ClusterName,
-		event.ResourceKind,
-		event.Namespace,
-		event.ResourceName,
-		event.EventType)
+	subject, body, html := n.render(event)
 
-	body := fmt.Sprintf(`
-Resource Change Notification
+	to := n.recipients(event)
 
-Cluster: %s
-Resource: %s
-Name: %s
-Namespace: %s
-Event: %s
-Time: %s
-
-This is an automated notification from the Kubernetes Resource Watcher.
-`, n.config.ClusterName, event.ResourceKind, event.ResourceName, event.Namespace, event.EventType, time.Now().Format(time.RFC3339))
-
-	log.Printf("Preparing email: Subject='%s', To='%s', From='%s'",
-		subject, strings.Join(n.config.Email.ToEmails, ", "), n.config.Email.FromEmail)
+	logger := eventLogger(event)
+	logger.Debug("Preparing email", "subject", subject, "to", strings.Join(to, ", "), "from", n.config.Email.FromEmail)
 
-	m := gomail.NewMessage()
-	m.SetHeader("From", n.config.Email.FromEmail)
-
-	recipients := make([]string, len(n.config.Email.ToEmails))
-	for i, email := range n.config.Email.ToEmails {
+	recipients := make([]string, len(to))
+	for i, email := range to {
 		recipients[i] = strings.TrimSpace(email)
 	}
-	m.SetHeader("To", recipients...)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", body)
+	message := emailMessage{
+		From:    n.config.Email.FromEmail,
+		To:      recipients,
+		Subject: subject,
+		Text:    body,
+		HTML:    html,
+	}
 
 	maxRetries := 3
 	backoff := 1 * time.Second
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		// Send the email
-		if err := n.dialer.DialAndSend(m); err != nil {
+		// Send the email; each recipient list is a destination of the concurrency limits
+		err := n.limiter.do(ctx, strings.Join(recipients, ","), func() error {
+			return n.transport.send(ctx, message)
+		})
+		if err != nil {
 			lastErr = err
-			log.Printf("Failed to send email notification (attempt %d/%d): %v", attempt, maxRetries, err)
+			logger.Warn("Failed to send email notification", "attempt", attempt, "maxAttempts", maxRetries, "error", err)
 
-			if attempt < maxRetries {
-				time.Sleep(backoff)
+			if attempt < maxRetries && ctx.Err() == nil {
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+				}
 				backoff *= 2
-				continue
+				if ctx.Err() == nil {
+					continue
+				}
 			}
 			n.mu.Lock()
 			n.metrics.EmailsFailed++
 			n.mu.Unlock()
-			return fmt.Errorf("failed to send email after %d attempts: %v", maxRetries, lastErr)
+			return fmt.Errorf("failed to send email after %d attempts: %v", attempt, lastErr)
 		}
 
 		n.mu.Lock()
 		n.metrics.EmailsSent++
 		n.mu.Unlock()
-		log.Printf("Successfully sent email notification for %s %s in namespace %s to %s",
-			event.ResourceKind, event.ResourceName, event.Namespace, strings.Join(n.config.Email.ToEmails, ", "))
+		logger.Info("Sent email notification", "to", strings.Join(to, ", "))
 		return nil
 	}
 
 	return lastErr
 }
 
+// render builds the subject, body and optional HTML part, dropping diff sections to fit email.maxMessageSize
+func (n *EmailNotifier) render(event NotificationEvent) (string, string, string) {
+	event = fitMessage(n.config, event, n.config.Email.GetMaxMessageSize(), func(e NotificationEvent) int {
+		subject, body, html := n.renderMessage(e)
+		return len(subject) + len(body) + len(html)
+	})
+	return n.renderMessage(event)
+}
+
+func (n *EmailNotifier) renderMessage(event NotificationEvent) (subject, body, html string) {
+	if event.EventType == EventBurstSummary {
+		subject, body = n.buildSummaryMessage(event)
+		return subject, body, ""
+	}
+	subject, body = n.buildMessage(event)
+	return subject, body, n.renderHTML(event)
+}
+
+// buildMessage renders the default subject and body for a resource event, then applies any configured templates
+func (n *EmailNotifier) buildMessage(event NotificationEvent) (string, string) {
+	subject := fmt.Sprintf("%s[%s] %s %s was %s%s",
+		severityPrefix(event),
+		clusterName(n.config, event),
+		event.ResourceKind,
+		event.Ref(),
+		event.EventType,
+		imageHeadline(event))
+
+	body := fmt.Sprintf(`
+Resource Change Notification
+
+Cluster: %s
+Resource: %s
+Name: %s
+Namespace: %s
+Event: %s
+Time: %s
+`, clusterName(n.config, event), event.ResourceKind, event.ResourceName, displayNamespace(event), event.EventType, time.Now().Format(time.RFC3339))
+
+	if event.Severity != "" {
+		body += fmt.Sprintf("Severity: %s\n", event.Severity)
+	}
+
+	// The event ID matches the watcher's log records and /api/events/:id/trace
+	if event.ID != "" {
+		body += fmt.Sprintf("Event ID: %s\n", event.ID)
+	}
+
+	if changedBy := describeChangedBy(event); changedBy != "" {
+		body += fmt.Sprintf("Changed by: %s\n", changedBy)
+	}
+
+	if len(event.ChangedFields) > 0 {
+		body += fmt.Sprintf("Changed fields: %s\n", strings.Join(event.ChangedFields, ", "))
+	}
+
+	if len(event.ImageChanges) > 0 {
+		body += "\nImage changes:\n"
+		for _, change := range event.ImageChanges {
+			body += fmt.Sprintf("  %s: %s → %s\n", change.Container, change.Old, change.New)
+		}
+	}
+
+	if len(event.Summary) > 0 {
+		body += "\n" + strings.Join(event.Summary, "\n") + "\n"
+	}
+
+	if len(event.Diff) > 0 {
+		body += "\nChanges:\n"
+		for _, line := range event.Diff {
+			body += fmt.Sprintf("  %s\n", line)
+		}
+	}
+
+	if len(event.Warnings) > 0 {
+		body += "\nWarnings:\n"
+		for _, warning := range event.Warnings {
+			body += fmt.Sprintf("  - %s\n", warning)
+		}
+	}
+
+	if event.SuppressedEvents > 0 {
+		body += fmt.Sprintf("\nNote: %d further events for this resource were suppressed by rate limiting since the last notification.\n",
+			event.SuppressedEvents)
+	}
+
+	body += "\nThis is an automated notification from the Kubernetes Resource Watcher.\n"
+
+	return n.applyTemplates(event, subject, body)
+}
+
+// buildSummaryMessage renders a summary event; templates are not applied since they describe a single resource
+func (n *EmailNotifier) buildSummaryMessage(event NotificationEvent) (string, string) {
+	subject := fmt.Sprintf("%s[%s] %d notifications suppressed by burst protection", severityPrefix(event), clusterName(n.config, event), event.SuppressedEvents)
+
+	body := fmt.Sprintf(`
+Notification Burst Summary
+
+Cluster: %s
+Time: %s
+
+%s
+`, clusterName(n.config, event), time.Now().Format(time.RFC3339), strings.Join(event.Summary, "\n"))
+
+	body += "\nThis is an automated notification from the Kubernetes Resource Watcher.\n"
+
+	return subject, body
+}
+
+// renderHTML renders the HTML part of the email, or returns "" when no HTML
+// template is configured or it fails, leaving the plain-text body on its own
+func (n *EmailNotifier) renderHTML(event NotificationEvent) string {
+	if n.htmlTemplate == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := n.htmlTemplate.Execute(&buf, newTemplateData(clusterName(n.config, event), event)); err != nil {
+		eventLogger(event).Warn("Failed to render HTML template; sending plain text only", "error", err)
+		return ""
+	}
+	return buf.String()
+}
+
+// applyTemplates renders the configured subject/body templates, keeping the defaults for any that are unset or fail
+func (n *EmailNotifier) applyTemplates(event NotificationEvent, subject, body string) (string, string) {
+	if n.subjectTemplate == nil && n.bodyTemplate == nil {
+		return subject, body
+	}
+
+	data := newTemplateData(clusterName(n.config, event), event)
+	if n.subjectTemplate != nil {
+		if rendered, err := renderTemplate(n.subjectTemplate, data); err != nil {
+			eventLogger(event).Warn("Using default subject", "error", err)
+		} else {
+			subject = strings.TrimSpace(rendered)
+		}
+	}
+	if n.bodyTemplate != nil {
+		if rendered, err := renderTemplate(n.bodyTemplate, data); err != nil {
+			eventLogger(event).Warn("Using default body", "error", err)
+		} else {
+			body = rendered
+		}
+	}
+	return subject, body
+}
+
+// recipients returns the event's explicit recipients, else the combined lists of
+// the matching recipients entries, else toEmails
+func (n *EmailNotifier) recipients(event NotificationEvent) []string {
+	if len(event.Recipients) > 0 {
+		return event.Recipients
+	}
+
+	seen := make(map[string]bool)
+	var to []string
+	for _, recipients := range n.config.Email.Recipients {
+		if !recipients.Matches(event.Namespace, event.Labels) {
+			continue
+		}
+		for _, email := range recipients.ToEmails {
+			if email = strings.TrimSpace(email); !seen[email] {
+				seen[email] = true
+				to = append(to, email)
+			}
+		}
+	}
+	if len(to) == 0 {
+		return n.config.Email.ToEmails
+	}
+	return to
+}
+
+// Preview returns the email that would be sent for the event
+func (n *EmailNotifier) Preview(event NotificationEvent) interface{} {
+	subject, body, html := n.render(event)
+	preview := map[string]interface{}{
+		"from":    n.config.Email.FromEmail,
+		"to":      n.recipients(event),
+		"subject": subject,
+		"body":    body,
+	}
+	if html != "" {
+		preview["html"] = html
+	}
+	return preview
+}
+
+// TestConnection connects and authenticates to the SMTP server without sending anything
+func (n *EmailNotifier) TestConnection(ctx context.Context) error {
+	return n.transport.probe(ctx)
+}
+
 // GetMetrics returns a copy of the current metrics
 func (n *EmailNotifier) GetMetrics() EmailMetrics {
 	n.mu.RLock()
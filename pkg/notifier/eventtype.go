@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// EventType classifies a notification. The string values are part of the
+// public contract: they appear in payloads, templates and routing rules
+// (eventTypes), so existing values must never change.
+type EventType string
+
+// Resource lifecycle events
+const (
+	EventAdded            EventType = "ADDED"
+	EventModified         EventType = "MODIFIED"
+	EventDeleted          EventType = "DELETED"
+	EventNamespaceDeleted EventType = "NAMESPACE_DELETED"
+
+	// An object that differs after a relist from the version cached before the watch disconnected
+	EventChangedWhileDisconnected EventType = "CHANGED_WHILE_DISCONNECTED"
+
+	// Helm release revisions, from the release Secrets Helm stores
+	EventHelmInstalled  EventType = "HELM_INSTALLED"
+	EventHelmUpgraded   EventType = "HELM_UPGRADED"
+	EventHelmRolledBack EventType = "HELM_ROLLED_BACK"
+	EventHelmFailed     EventType = "HELM_FAILED"
+)
+
+// Health and policy events
+const (
+	EventPodOOMKilled        EventType = "POD_OOMKILLED"
+	EventPodCrashLoop        EventType = "POD_CRASHLOOP"
+	EventPodImagePullBackOff EventType = "POD_IMAGE_PULL_BACKOFF"
+	EventKubeEvent           EventType = "K8S_EVENT"
+	EventAnomaly             EventType = "ANOMALY"
+
+	// cert-manager Certificates nearing expiry, failing to be issued, and renewed after either
+	EventCertificateExpiring EventType = "CERTIFICATE_EXPIRING"
+	EventCertificateFailed   EventType = "CERTIFICATE_FAILED"
+	EventCertificateRenewed  EventType = "CERTIFICATE_RENEWED"
+)
+
+// Events about the watcher itself
+const (
+	EventBurstSummary    EventType = "BURST_SUMMARY"
+	EventSelfAlert       EventType = "SELF_ALERT"
+	EventSilenceExpiring EventType = "SILENCE_EXPIRING"
+	EventSilenceExpired  EventType = "SILENCE_EXPIRED"
+	EventBaseline        EventType = "BASELINE"
+	EventReport          EventType = "REPORT"
+)
+
+// eventTypes lists every known type in a stable order
+var eventTypes = []EventType{
+	EventAdded, EventModified, EventDeleted, EventNamespaceDeleted, EventChangedWhileDisconnected,
+	EventHelmInstalled, EventHelmUpgraded, EventHelmRolledBack, EventHelmFailed,
+	EventPodOOMKilled, EventPodCrashLoop, EventPodImagePullBackOff, EventKubeEvent, EventAnomaly,
+	EventCertificateExpiring, EventCertificateFailed, EventCertificateRenewed,
+	EventBurstSummary, EventSelfAlert, EventSilenceExpiring, EventSilenceExpired, EventBaseline, EventReport,
+}
+
+// EventTypes returns every known event type
+func EventTypes() []EventType {
+	return append([]EventType(nil), eventTypes...)
+}
+
+// Valid reports whether t is a known event type
+func (t EventType) Valid() bool {
+	for _, known := range eventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEventType converts a configured event type name, ignoring case and surrounding space
+func ParseEventType(name string) (EventType, error) {
+	t := EventType(strings.ToUpper(strings.TrimSpace(name)))
+	if !t.Valid() {
+		return "", fmt.Errorf("unknown event type %q", name)
+	}
+	return t, nil
+}
+
+// ValidateRoutingEventTypes checks that routing rules only match known event types
+func ValidateRoutingEventTypes(cfg config.RoutingConfig) error {
+	for _, ruleset := range cfg.Rulesets {
+		for _, rule := range ruleset.Rules {
+			for _, name := range rule.Match.EventTypes {
+				if !EventType(name).Valid() {
+					return fmt.Errorf("ruleset %s, rule %s: unknown event type %q", ruleset.Name, rule.Name, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSeverityEventTypes checks that severity rules only match known event types
+func ValidateSeverityEventTypes(cfg config.SeverityConfig) error {
+	for _, rule := range cfg.Rules {
+		for _, name := range rule.Match.EventTypes {
+			if !EventType(name).Valid() {
+				return fmt.Errorf("rule %s: unknown event type %q", rule.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// FromWatchEvent maps a Kubernetes watch event to its notification type
+func FromWatchEvent(t watch.EventType) (EventType, bool) {
+	switch t {
+	case watch.Added:
+		return EventAdded, true
+	case watch.Modified:
+		return EventModified, true
+	case watch.Deleted:
+		return EventDeleted, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// postJSON sends payload to url, retrying transient failures with exponential
+// backoff. Every attempt is bounded by the client timeout and by ctx.
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, headers map[string]string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return postWithRetries(ctx, client, url, data, headers)
+}
+
+// postWithRetries sends an encoded body to url like postJSON; headers should
+// set the Content-Type of bodies that are not JSON
+func postWithRetries(ctx context.Context, client *http.Client, url string, data []byte, headers map[string]string) error {
+	maxRetries := 3
+	backoff := 1 * time.Second
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		retryable, err := doPost(ctx, client, url, data, headers)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		slog.Warn("HTTP notification failed", "attempt", attempt, "maxAttempts", maxRetries, "error", err)
+
+		if !retryable || attempt == maxRetries || ctx.Err() != nil {
+			return fmt.Errorf("failed after %d attempts: %w", attempt, lastErr)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("failed after %d attempts: %w", attempt, ctx.Err())
+		}
+		backoff *= 2
+	}
+
+	return lastErr
+}
+
+// doPost performs a single POST, reporting whether a failure is worth retrying
+func doPost(ctx context.Context, client *http.Client, url string, data []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+}
@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// LogSinkNotifier writes every event as a single JSON line to stdout or a file,
+// for log collectors such as Loki or Fluentd to scrape
+type LogSinkNotifier struct {
+	config *config.Config
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// logSinkLine is the JSON line written for each event
+type logSinkLine struct {
+	Time             time.Time         `json:"time"`
+	Message          string            `json:"msg"`
+	ID               string            `json:"eventID,omitempty"`
+	Cluster          string            `json:"cluster"`
+	EventType        EventType         `json:"eventType"`
+	Severity         string            `json:"severity,omitempty"`
+	Kind             string            `json:"kind"`
+	Name             string            `json:"name"`
+	Namespace        string            `json:"namespace,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+	ChangedFields    []string          `json:"changedFields,omitempty"`
+	ChangedBy        string            `json:"changedBy,omitempty"`
+	Origin           string            `json:"origin,omitempty"`
+	Diff             []string          `json:"diff,omitempty"`
+	ImageChanges     []ImageChange     `json:"imageChanges,omitempty"`
+	Warnings         []string          `json:"warnings,omitempty"`
+	Summary          []string          `json:"summary,omitempty"`
+	SuppressedEvents int               `json:"suppressedEvents,omitempty"`
+}
+
+// NewLogSinkNotifier creates a log sink writing to logSink.path, opened for appending, or to stdout
+func NewLogSinkNotifier(cfg *config.Config) (*LogSinkNotifier, error) {
+	n := &LogSinkNotifier{config: cfg, out: os.Stdout}
+	if path := cfg.LogSink.Path; path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create log sink directory: %w", err)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log sink: %w", err)
+		}
+		n.out = file
+	}
+	return n, nil
+}
+
+// SendNotification writes the event as one line; Encode ends it with a newline
+func (n *LogSinkNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false) // Keep "->" in diff lines readable
+	err := encoder.Encode(logSinkLine{
+		Time:             time.Now().UTC(),
+		Message:          fmt.Sprintf("%s %s was %s", event.ResourceKind, event.Ref(), event.EventType),
+		ID:               event.ID,
+		Cluster:          clusterName(n.config, event),
+		EventType:        event.EventType,
+		Severity:         event.Severity,
+		Kind:             event.ResourceKind,
+		Name:             event.ResourceName,
+		Namespace:        event.Namespace,
+		Labels:           event.Labels,
+		Annotations:      event.Annotations,
+		ChangedFields:    event.ChangedFields,
+		ChangedBy:        event.ChangedBy,
+		Origin:           event.Origin,
+		Diff:             event.Diff,
+		ImageChanges:     event.ImageChanges,
+		Warnings:         event.Warnings,
+		Summary:          event.Summary,
+		SuppressedEvents: event.SuppressedEvents,
+	})
+	if err != nil {
+		return fmt.Errorf("log sink: failed to encode event: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, err := n.out.Write(line.Bytes()); err != nil {
+		return fmt.Errorf("log sink: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// maxMarkers caps how many deployment markers are kept
+const maxMarkers = 100
+
+// Marker records a deployment pushed by a CI/CD system, e.g. a pipeline run
+// rolling out a new version of a service
+type Marker struct {
+	ID         string    `json:"id"`
+	Service    string    `json:"service"`
+	Version    string    `json:"version,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	URL        string    `json:"url,omitempty"`        // Link to the pipeline run
+	Cluster    string    `json:"cluster,omitempty"`    // Only annotate events from this cluster
+	Namespaces []string  `json:"namespaces,omitempty"` // Only annotate events in these namespaces; glob patterns allowed
+	Time       time.Time `json:"time"`                 // Defaults to now
+}
+
+// matches reports whether the marker applies to the event
+func (m *Marker) matches(event NotificationEvent) bool {
+	if m.Cluster != "" && event.Cluster != "" && m.Cluster != event.Cluster {
+		return false
+	}
+	return len(m.Namespaces) == 0 || config.MatchAny(m.Namespaces, event.Namespace)
+}
+
+// describe renders the marker as a notification line
+func (m *Marker) describe(now time.Time) string {
+	line := "Deployment marker: " + m.Service
+	if m.Version != "" {
+		line += " " + m.Version
+	}
+	if m.Author != "" {
+		line += " by " + m.Author
+	}
+	line += fmt.Sprintf(" (%s ago)", now.Sub(m.Time).Round(time.Second))
+	if m.URL != "" {
+		line += " " + m.URL
+	}
+	return line
+}
+
+// MarkerNotifier annotates events with the deployment markers received
+// within the window before them, tying cluster changes to pipeline runs
+type MarkerNotifier struct {
+	next   Notifier
+	window time.Duration
+
+	mu      sync.Mutex
+	markers []Marker // Oldest first
+	now     func() time.Time
+}
+
+// NewMarkerNotifier wraps next so events are annotated with markers up to window old
+func NewMarkerNotifier(next Notifier, window time.Duration) *MarkerNotifier {
+	return &MarkerNotifier{
+		next:   next,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Add records a marker, defaulting its time to now
+func (m *MarkerNotifier) Add(marker Marker) (Marker, error) {
+	if marker.Service == "" {
+		return Marker{}, fmt.Errorf("service is required")
+	}
+	for _, pattern := range marker.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Marker{}, fmt.Errorf("invalid namespace pattern %q: %v", pattern, err)
+		}
+	}
+	now := m.now()
+	if marker.Time.IsZero() {
+		marker.Time = now
+	}
+	if marker.Time.After(now) {
+		return Marker{}, fmt.Errorf("time is in the future")
+	}
+	marker.ID = newID()
+
+	m.mu.Lock()
+	m.markers = append(m.markers, marker)
+	sort.SliceStable(m.markers, func(i, j int) bool { return m.markers[i].Time.Before(m.markers[j].Time) })
+	m.prune(now)
+	m.mu.Unlock()
+
+	slog.Info("Deployment marker recorded", "marker", marker.ID, "service", marker.Service, "version", marker.Version, "author", marker.Author)
+	return marker, nil
+}
+
+// List returns the markers still annotating events, newest first
+func (m *MarkerNotifier) List() []Marker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(m.now())
+
+	markers := make([]Marker, 0, len(m.markers))
+	for i := len(m.markers) - 1; i >= 0; i-- {
+		markers = append(markers, m.markers[i])
+	}
+	return markers
+}
+
+// prune drops markers older than the window, and the oldest beyond maxMarkers
+func (m *MarkerNotifier) prune(now time.Time) {
+	keep := 0
+	for keep < len(m.markers) && now.Sub(m.markers[keep].Time) > m.window {
+		keep++
+	}
+	if len(m.markers)-keep > maxMarkers {
+		keep = len(m.markers) - maxMarkers
+	}
+	m.markers = m.markers[keep:]
+}
+
+// SendNotification adds the matching markers to resource events and forwards them
+func (m *MarkerNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	switch event.EventType {
+	case EventBurstSummary, EventSelfAlert, EventSilenceExpiring, EventSilenceExpired, EventBaseline, EventReport:
+		return m.next.SendNotification(ctx, event)
+	}
+
+	m.mu.Lock()
+	now := m.now()
+	m.prune(now)
+	var markers []Marker
+	var lines []string
+	for i := len(m.markers) - 1; i >= 0; i-- {
+		if marker := m.markers[i]; marker.matches(event) && !marker.Time.After(now) {
+			markers = append(markers, marker)
+			lines = append(lines, marker.describe(now))
+		}
+	}
+	m.mu.Unlock()
+
+	if len(markers) > 0 {
+		event.Markers = markers
+		event.Summary = append(append([]string(nil), event.Summary...), lines...)
+	}
+	return m.next.SendNotification(ctx, event)
+}
@@ -0,0 +1,188 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/version"
+)
+
+// natsConn publishes over a single connection speaking the NATS client protocol.
+// Every publish is followed by a PING, so an error or a closed connection is noticed
+// before the publish is reported as done.
+type natsConn struct {
+	config  *config.NATSConfig
+	timeout time.Duration
+
+	mu         sync.Mutex
+	conn       net.Conn
+	reader     *bufio.Reader
+	maxPayload int
+}
+
+// natsInfo is the part of the server's INFO message the client uses
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+func newNATSConn(cfg *config.NATSConfig, timeout time.Duration) *natsConn {
+	return &natsConn{config: cfg, timeout: timeout}
+}
+
+// publish sends data to subject, connecting first if needed. A connection the server
+// closed while idle is replaced once before giving up.
+func (c *natsConn) publish(ctx context.Context, subject string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		reused := c.conn != nil
+		if !reused {
+			if err := c.connect(ctx); err != nil {
+				return err
+			}
+		}
+		if c.maxPayload > 0 && len(data) > c.maxPayload {
+			return fmt.Errorf("message of %d bytes exceeds the server's max_payload of %d", len(data), c.maxPayload)
+		}
+		err := c.exchange(ctx, fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(data), data))
+		if err == nil {
+			return nil
+		}
+		c.close()
+		if !reused || attempt > 1 {
+			return err
+		}
+	}
+}
+
+// check connects if not connected yet, confirming the server accepts the client
+func (c *natsConn) check(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		return nil
+	}
+	return c.connect(ctx)
+}
+
+// connect dials the server, upgrades to TLS when either side requires it and authenticates
+func (c *natsConn) connect(ctx context.Context) error {
+	server, err := url.Parse(c.config.URL)
+	if err != nil {
+		return fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	address := server.Host
+	if server.Port() == "" {
+		address = net.JoinHostPort(server.Hostname(), "4222")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	conn.SetDeadline(c.deadline(ctx))
+	c.conn, c.reader = conn, bufio.NewReader(conn)
+
+	line, err := c.reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		c.close()
+		return fmt.Errorf("no INFO from NATS server %s: %v", address, err)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "INFO "))), &info); err != nil {
+		c.close()
+		return fmt.Errorf("invalid INFO from NATS server %s: %w", address, err)
+	}
+	c.maxPayload = info.MaxPayload
+
+	if server.Scheme == "tls" || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: server.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			c.close()
+			return fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+		}
+		c.conn, c.reader = tlsConn, bufio.NewReader(tlsConn)
+	}
+
+	options := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "k8s-resource-watcher",
+		"lang":     "go",
+		"version":  version.Version,
+		"protocol": 1,
+	}
+	if token := c.config.GetToken(); token != "" {
+		options["auth_token"] = token
+	}
+	if server.User != nil {
+		options["user"] = server.User.Username()
+		if password, ok := server.User.Password(); ok {
+			options["pass"] = password
+		}
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		c.close()
+		return fmt.Errorf("failed to encode CONNECT: %w", err)
+	}
+	// The server answers an unauthorized CONNECT with -ERR instead of the PONG
+	if err := c.exchange(ctx, "CONNECT "+string(encoded)+"\r\nPING\r\n"); err != nil {
+		c.close()
+		return err
+	}
+	return nil
+}
+
+// exchange writes commands ending in a PING and reads until the server's PONG,
+// answering the server's own PINGs meanwhile
+func (c *natsConn) exchange(ctx context.Context, commands string) error {
+	c.conn.SetDeadline(c.deadline(ctx))
+	if _, err := c.conn.Write([]byte(commands)); err != nil {
+		return fmt.Errorf("failed to write to NATS server: %w", err)
+	}
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read from NATS server: %w", err)
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := c.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("failed to write to NATS server: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// deadline is the earlier of ctx's deadline and the configured timeout from now
+func (c *natsConn) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+func (c *natsConn) close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn, c.reader = nil, nil
+}
@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// fakeNATSServer accepts clients on a local port and answers them with serve
+type fakeNATSServer struct {
+	listener net.Listener
+	commands chan string // Every line clients send
+}
+
+func newFakeNATSServer(t *testing.T, serve func(conn net.Conn, reader *bufio.Reader, commands chan<- string)) *fakeNATSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeNATSServer{listener: listener, commands: make(chan string, 100)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn, bufio.NewReader(conn), server.commands)
+			}()
+		}
+	}()
+	return server
+}
+
+func (s *fakeNATSServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+// natsServe is a well-behaved server: it answers every PING with a PONG, or the CONNECT
+// with connectReply, and closes the connection after closeAfter publishes when set
+func natsServe(info, connectReply string, closeAfter int) func(net.Conn, *bufio.Reader, chan<- string) {
+	return func(conn net.Conn, reader *bufio.Reader, commands chan<- string) {
+		conn.Write([]byte("INFO " + info + "\r\n"))
+		published, connected := 0, false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			commands <- line
+			switch {
+			case strings.HasPrefix(line, "PUB "):
+				published++
+			case line == "PING" && !connected && connectReply != "":
+				conn.Write([]byte(connectReply + "\r\n"))
+				return
+			case line == "PING":
+				connected = true
+				conn.Write([]byte("PONG\r\n"))
+				if closeAfter > 0 && published == closeAfter {
+					return
+				}
+			}
+		}
+	}
+}
+
+// received returns the commands the server got so far
+func (s *fakeNATSServer) received() []string {
+	var commands []string
+	for {
+		select {
+		case command := <-s.commands:
+			commands = append(commands, command)
+		case <-time.After(100 * time.Millisecond):
+			return commands
+		}
+	}
+}
+
+func TestNATSConnPublish(t *testing.T) {
+	tests := []struct {
+		name         string
+		info         string
+		connectReply string
+		closeAfter   int
+		url          func(server *fakeNATSServer) string
+		token        string
+		publishes    int
+		data         string
+		wantErr      string
+		wantCommands []string // Parts of the commands the server must have received, in order
+	}{
+		{
+			name:         "publish",
+			info:         `{"max_payload":1024}`,
+			publishes:    2,
+			data:         `{"kind":"ConfigMap"}`,
+			wantCommands: []string{"CONNECT ", "PING", "PUB k8s.changes 20", `{"kind":"ConfigMap"}`, "PING", "PUB k8s.changes 20"},
+		},
+		{
+			name:         "token and user",
+			info:         `{}`,
+			url:          func(server *fakeNATSServer) string { return "nats://watcher:secret@" + server.listener.Addr().String() },
+			token:        "t0ken",
+			publishes:    1,
+			data:         `{}`,
+			wantCommands: []string{`"auth_token":"t0ken"`, `"pass":"secret"`, `"user":"watcher"`},
+		},
+		{
+			name:      "payload too large",
+			info:      `{"max_payload":4}`,
+			publishes: 1,
+			data:      `{"kind":"ConfigMap"}`,
+			wantErr:   "exceeds the server's max_payload of 4",
+		},
+		{
+			name:         "authorization rejected",
+			info:         `{}`,
+			connectReply: "-ERR 'Authorization Violation'",
+			publishes:    1,
+			data:         `{}`,
+			wantErr:      "NATS server error: 'Authorization Violation'",
+		},
+		{
+			name:         "reconnects after the server closed the connection",
+			info:         `{}`,
+			closeAfter:   1,
+			publishes:    2,
+			data:         `{}`,
+			wantCommands: []string{"CONNECT ", "PING", "PUB k8s.changes 2", "{}", "PING", "CONNECT "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeNATSServer(t, natsServe(tt.info, tt.connectReply, tt.closeAfter))
+			cfg := &config.NATSConfig{URL: server.url(), Subject: "k8s.changes", Token: tt.token}
+			if tt.url != nil {
+				cfg.URL = tt.url(server)
+			}
+			conn := newNATSConn(cfg, 2*time.Second)
+			defer conn.close()
+
+			var err error
+			for i := 0; i < tt.publishes && err == nil; i++ {
+				err = conn.publish(context.Background(), cfg.Subject, []byte(tt.data))
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("publish() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			commands := server.received()
+			next := 0
+			for _, command := range commands {
+				for next < len(tt.wantCommands) && strings.Contains(command, tt.wantCommands[next]) {
+					next++
+				}
+			}
+			if next < len(tt.wantCommands) {
+				t.Errorf("server received %q, missing %q", commands, tt.wantCommands[next])
+			}
+		})
+	}
+}
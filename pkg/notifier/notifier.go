@@ -1,14 +1,138 @@
 package notifier
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
 // NotificationEvent represents a resource event to be notified
 type NotificationEvent struct {
-	EventType    string
+	ID           string // Identifies the event's processing trace
+	Cluster      string // Cluster the event happened in; empty means the configured clusterName
+	EventType    EventType
 	ResourceKind string
 	ResourceName string
 	Namespace    string
+
+	// Severity is info, warning or critical, from the configured severity rules
+	Severity string
+
+	// Labels and Annotations of the changed object, exposed to message templates
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// ChangedFields lists the fields that differ for MODIFIED events, when known
+	ChangedFields []string
+
+	// ChangedBy is the field manager that made the change, e.g. kubectl-edit, and Origin
+	// whether that was argocd, flux or other; both are empty when unknown, as for deletions
+	ChangedBy string
+	Origin    string
+
+	// Diff holds human-readable change lines ("+ rule: ...", "- subject: ..."), when available
+	Diff []string
+
+	// ImageChanges lists the container images a MODIFIED workload now runs instead
+	ImageChanges []ImageChange
+
+	// SuppressedEvents is the number of events for this resource that were
+	// dropped by rate limiting since the previous notification was sent
+	SuppressedEvents int
+
+	// Warnings are validation findings about the changed object, when validation is enabled
+	Warnings []string
+
+	// Recipients overrides the email recipients, e.g. to address a silence's creator
+	Recipients []string
+
+	// Summary holds the message lines of summary events such as EventBurstSummary
+	Summary []string
+
+	// Markers are the recent deployment markers the event falls under, newest first
+	Markers []Marker
+}
+
+// Ref returns namespace/name, or just the name for cluster-scoped resources and namespaces
+func (e NotificationEvent) Ref() string {
+	if e.Namespace == "" || e.ResourceKind == "Namespace" {
+		return e.ResourceName
+	}
+	return e.Namespace + "/" + e.ResourceName
+}
+
+// eventLogger returns the default logger with the event's correlation ID and object
+func eventLogger(event NotificationEvent) *slog.Logger {
+	return slog.With("eventID", event.ID, "kind", event.ResourceKind, "namespace", event.Namespace,
+		"name", event.ResourceName, "eventType", event.EventType)
+}
+
+// clusterName returns the cluster an event happened in, falling back to the configured one
+func clusterName(cfg *config.Config, event NotificationEvent) string {
+	if event.Cluster != "" {
+		return event.Cluster
+	}
+	return cfg.ClusterName
+}
+
+// ImageChange is a container whose image changed in a workload's pod template
+type ImageChange struct {
+	Container string `json:"container"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+}
+
+func (c ImageChange) String() string {
+	return "image: " + c.Old + " → " + c.New
+}
+
+// imageHeadline returns the subject suffix for an event's image changes: the
+// first change, and how many others there are
+func imageHeadline(event NotificationEvent) string {
+	switch len(event.ImageChanges) {
+	case 0:
+		return ""
+	case 1:
+		return " (" + event.ImageChanges[0].String() + ")"
+	default:
+		return fmt.Sprintf(" (%s, +%d more)", event.ImageChanges[0], len(event.ImageChanges)-1)
+	}
+}
+
+// severityPrefix marks subjects of warning and critical events, e.g. "[CRITICAL] "; info events are unmarked
+func severityPrefix(event NotificationEvent) string {
+	switch event.Severity {
+	case config.SeverityWarning, config.SeverityCritical:
+		return "[" + strings.ToUpper(event.Severity) + "] "
+	default:
+		return ""
+	}
+}
+
+// describeChangedBy names who made a change, e.g. "kustomize-controller (flux)"
+func describeChangedBy(event NotificationEvent) string {
+	switch {
+	case event.ChangedBy == "":
+		return event.Origin
+	case event.Origin == "" || event.Origin == config.ChangeOriginOther:
+		return event.ChangedBy
+	}
+	return fmt.Sprintf("%s (%s)", event.ChangedBy, event.Origin)
+}
+
+// displayNamespace returns the namespace for message bodies, marking cluster-scoped resources
+func displayNamespace(event NotificationEvent) string {
+	if event.Namespace == "" {
+		return "(cluster-scoped)"
+	}
+	return event.Namespace
 }
 
-// Notifier defines the interface for sending notifications
+// Notifier defines the interface for sending notifications.
+// Implementations must stop retrying and return once ctx is done.
 type Notifier interface {
-	SendNotification(event NotificationEvent) error
+	SendNotification(ctx context.Context, event NotificationEvent) error
 }
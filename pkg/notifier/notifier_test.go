@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+)
+
+// recordingNotifier keeps the events it is sent, failing with err when set
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []NotificationEvent
+	err    error
+}
+
+func (n *recordingNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *recordingNotifier) sent() []NotificationEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]NotificationEvent(nil), n.events...)
+}
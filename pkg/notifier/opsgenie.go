@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// OpsgenieNotifier raises an Opsgenie alert per object, with a priority from the event's
+// severity, and closes it when a resolving event for the object arrives
+type OpsgenieNotifier struct {
+	config *config.Config
+	client *http.Client
+}
+
+// opsgenieAlert is an Alert API create request
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"` // Deduplicates open alerts of the same object
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Entity      string            `json:"entity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// opsgenieClose is an Alert API close request
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// NewOpsgenieNotifier creates a new Opsgenie notifier
+func NewOpsgenieNotifier(cfg *config.Config) *OpsgenieNotifier {
+	return &OpsgenieNotifier{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.Opsgenie.GetTimeout()},
+	}
+}
+
+// SendNotification creates or, for resolving events, closes the object's alert
+func (n *OpsgenieNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	opsgenie := n.config.Opsgenie
+	headers := map[string]string{"Authorization": "GenieKey " + opsgenie.GetAPIKey()}
+	alias := truncateText(alertKey(n.config, event), 512)
+
+	if resolvesAlert(event) {
+		endpoint := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", opsgenie.GetEndpoint(), url.PathEscape(alias))
+		if err := postJSON(ctx, n.client, endpoint, opsgenieClose{Source: alertSource, Note: alertTitle(n.config, event)}, headers); err != nil {
+			return fmt.Errorf("opsgenie: failed to close alert: %w", err)
+		}
+		eventLogger(event).Info("Closed Opsgenie alert", "alias", alias)
+		return nil
+	}
+
+	tags := append([]string{clusterName(n.config, event), string(event.EventType)}, opsgenie.Tags...)
+	alert := opsgenieAlert{
+		Message:     alertTitle(n.config, event),
+		Alias:       alias,
+		Description: alertDescription(n.config, event),
+		Priority:    opsgeniePriority(event.Severity),
+		Source:      alertSource,
+		Entity:      event.ResourceKind + "/" + event.Ref(),
+		Tags:        tags,
+		Details: map[string]string{
+			"cluster":   clusterName(n.config, event),
+			"kind":      event.ResourceKind,
+			"namespace": event.Namespace,
+			"name":      event.ResourceName,
+			"eventType": string(event.EventType),
+			"eventID":   event.ID,
+		},
+	}
+	if err := postJSON(ctx, n.client, opsgenie.GetEndpoint()+"/v2/alerts", alert, headers); err != nil {
+		return fmt.Errorf("opsgenie: %w", err)
+	}
+	eventLogger(event).Info("Sent Opsgenie alert", "alias", alias, "priority", alert.Priority)
+	return nil
+}
+
+// opsgeniePriority maps a severity to an alert priority
+func opsgeniePriority(severity string) string {
+	switch severity {
+	case config.SeverityCritical:
+		return "P1"
+	case config.SeverityWarning:
+		return "P3"
+	default:
+		return "P5"
+	}
+}
+
+// TestConnection checks that the Opsgenie API is reachable
+func (n *OpsgenieNotifier) TestConnection(ctx context.Context) error {
+	return dialURL(ctx, n.config.Opsgenie.GetEndpoint())
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// PolicyVersion is the version of exported policy documents
+const PolicyVersion = 1
+
+// Policies is the portable form of the notification policies: routing rulesets
+// and silences, for backup and promotion between clusters
+type Policies struct {
+	Version  int                   `json:"version" yaml:"version"`
+	Routing  *config.RoutingConfig `json:"routing,omitempty" yaml:"routing,omitempty"` // Left unchanged on import when absent
+	Silences []Silence             `json:"silences" yaml:"silences"`
+}
+
+// ImportResult describes what an import applied, or would apply for a dry run
+type ImportResult struct {
+	DryRun          bool      `json:"dryRun"`
+	RoutingReplaced bool      `json:"routingReplaced"`
+	Silences        []Silence `json:"silences"`          // Silences created, with their new IDs
+	Skipped         []string  `json:"skipped,omitempty"` // Silences not imported, and why
+}
+
+// ExportPolicies returns the routing rulesets in use and the active and pending silences
+func ExportPolicies(router *Router, silences *SilencingNotifier) Policies {
+	routing := router.Routing()
+	return Policies{
+		Version:  PolicyVersion,
+		Routing:  &routing,
+		Silences: silences.List(),
+	}
+}
+
+// ImportPolicies validates a policy document and applies it: its routing
+// rulesets replace the ones in use and its silences are added with new IDs.
+// Invalid routing rejects the whole document; silences that are invalid, already
+// ended or already active here (a re-imported backup) are skipped. A dry run only validates.
+func ImportPolicies(ctx context.Context, policies Policies, router *Router, silences *SilencingNotifier, notifierNames []string, dryRun bool) (ImportResult, error) {
+	if policies.Version != PolicyVersion {
+		return ImportResult{}, fmt.Errorf("unsupported policy document version %d (expected %d)", policies.Version, PolicyVersion)
+	}
+	if policies.Routing != nil {
+		if err := policies.Routing.Validate(notifierNames); err != nil {
+			return ImportResult{}, fmt.Errorf("routing: %v", err)
+		}
+		if err := ValidateRoutingEventTypes(*policies.Routing); err != nil {
+			return ImportResult{}, fmt.Errorf("routing: %v", err)
+		}
+	}
+
+	existing := make(map[string]bool)
+	for _, silence := range silences.List() {
+		existing[silence.ID] = true
+	}
+
+	result := ImportResult{DryRun: dryRun, RoutingReplaced: policies.Routing != nil, Silences: []Silence{}}
+	for i, silence := range policies.Silences {
+		if existing[silence.ID] {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("silences[%d] (%s): already active", i, silence.ID))
+			continue
+		}
+		if err := silences.Check(&silence); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("silences[%d] (%s): %v", i, silence.ID, err))
+			continue
+		}
+		if dryRun {
+			result.Silences = append(result.Silences, silence)
+			continue
+		}
+		created, err := silences.Add(ctx, silence)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("silences[%d] (%s): %v", i, silence.ID, err))
+			continue
+		}
+		result.Silences = append(result.Silences, created)
+	}
+
+	if policies.Routing != nil && !dryRun {
+		router.SetRouting(*policies.Routing)
+		slog.Info("Imported routing rulesets", "rulesets", len(policies.Routing.Rulesets))
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package notifier
+
+// Previewer is implemented by notifiers that can render their message without sending it
+type Previewer interface {
+	Preview(event NotificationEvent) interface{}
+}
+
+// Preview is the message one notifier would send for an event
+type Preview struct {
+	Notifier string      `json:"notifier"`
+	Selected bool        `json:"selected"` // Whether routing selects this notifier for the event
+	Message  interface{} `json:"message,omitempty"`
+}
+
+// Preview renders the event for every notifier, in delivery order, marking the ones routing selects
+func (r *Router) Preview(event NotificationEvent) []Preview {
+	selected := make(map[string]bool)
+	for _, name := range r.Route(event) {
+		selected[name] = true
+	}
+
+	previews := make([]Preview, 0, len(r.order))
+	for _, name := range r.order {
+		preview := Preview{Notifier: name, Selected: selected[name]}
+		if previewer, ok := r.notifiers[name].(Previewer); ok {
+			preview.Message = previewer.Preview(r.redactor.Redact(name, event))
+		}
+		previews = append(previews, preview)
+	}
+	return previews
+}
@@ -0,0 +1,35 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// Prober is implemented by notifiers that can test their connection without sending a message
+type Prober interface {
+	TestConnection(ctx context.Context) error
+}
+
+// dialURL opens and closes a TCP connection to the host serving rawURL
+func dialURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = "443"
+		if parsed.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(parsed.Hostname(), port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", parsed.Host, err)
+	}
+	return conn.Close()
+}
@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// PublisherNotifier publishes every event, with its full diff, to a NATS subject or,
+// through the Kafka REST Proxy, to a Kafka topic, for other systems to consume
+type PublisherNotifier struct {
+	config *config.Config
+	client *http.Client // Kafka REST Proxy
+	nats   *natsConn
+}
+
+// cloudEvent is an event in the CloudEvents 1.0 structured JSON format
+type cloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id"`
+	Source          string         `json:"source"`
+	Type            string         `json:"type"`
+	Subject         string         `json:"subject"`
+	Time            time.Time      `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            webhookPayload `json:"data"`
+}
+
+// kafkaRecords is a Kafka REST Proxy v2 produce request
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// NewPublisherNotifier creates a publisher for the configured broker; NATS connects on first use
+func NewPublisherNotifier(cfg *config.Config) *PublisherNotifier {
+	timeout := cfg.Publisher.GetTimeout()
+	return &PublisherNotifier{
+		config: cfg,
+		client: &http.Client{Timeout: timeout},
+		nats:   newNATSConn(&cfg.Publisher.NATS, timeout),
+	}
+}
+
+// SendNotification publishes the event in the configured format
+func (n *PublisherNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	message := eventMessage(n.config, event, n.config.Publisher.GetFormat())
+	broker := n.config.Publisher.GetBroker()
+
+	var err error
+	switch broker {
+	case config.PublisherBrokerKafka:
+		err = n.produce(ctx, event, message)
+	default:
+		var data []byte
+		if data, err = json.Marshal(message); err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		err = n.nats.publish(ctx, n.config.Publisher.NATS.Subject, data)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", broker, err)
+	}
+	eventLogger(event).Info("Published event", "broker", broker)
+	return nil
+}
+
+// eventMessage returns the webhook payload of the event, wrapped in a CloudEvent in the
+// cloudevents format. The publisher and the cloud messaging notifiers all send it.
+func eventMessage(cfg *config.Config, event NotificationEvent, format string) interface{} {
+	payload := newWebhookPayload(cfg, event)
+	if format != config.PublisherFormatCloudEvents {
+		return payload
+	}
+
+	id := event.ID
+	if id == "" {
+		id = newID()
+	}
+	return cloudEvent{
+		SpecVersion:     "1.0",
+		ID:              id,
+		Source:          "k8s-resource-watcher/" + payload.Cluster,
+		Type:            "io.resource-watcher." + strings.ToLower(string(event.EventType)),
+		Subject:         event.ResourceKind + "/" + event.Ref(),
+		Time:            payload.Time,
+		DataContentType: "application/json",
+		Data:            payload,
+	}
+}
+
+// messageAttributes returns the event's identifying fields as string attributes, which
+// SNS subscription filter policies and Pub/Sub subscription filters can match on
+func messageAttributes(cfg *config.Config, event NotificationEvent) map[string]string {
+	attributes := map[string]string{
+		"cluster":   clusterName(cfg, event),
+		"eventType": string(event.EventType),
+		"kind":      event.ResourceKind,
+		"namespace": event.Namespace,
+		"severity":  event.Severity,
+	}
+	for name, value := range attributes {
+		if value == "" {
+			delete(attributes, name) // Attributes cannot be empty
+		}
+	}
+	return attributes
+}
+
+// produce sends the message to the Kafka REST Proxy, keyed by object so that the
+// changes of one object land in one partition, in order
+func (n *PublisherNotifier) produce(ctx context.Context, event NotificationEvent, message interface{}) error {
+	kafka := n.config.Publisher.Kafka
+	headers := map[string]string{
+		"Content-Type": "application/vnd.kafka.json.v2+json",
+		"Accept":       "application/vnd.kafka.v2+json",
+	}
+	for key, value := range kafka.Headers {
+		headers[key] = value
+	}
+
+	endpoint := strings.TrimRight(kafka.RESTProxyURL, "/") + "/topics/" + url.PathEscape(kafka.Topic)
+	key := clusterName(n.config, event) + "/" + event.ResourceKind + "/" + event.Ref()
+	return postJSON(ctx, n.client, endpoint, kafkaRecords{Records: []kafkaRecord{{Key: key, Value: message}}}, headers)
+}
+
+// TestConnection connects to the NATS server or the Kafka REST Proxy
+func (n *PublisherNotifier) TestConnection(ctx context.Context) error {
+	if n.config.Publisher.GetBroker() == config.PublisherBrokerKafka {
+		return dialURL(ctx, n.config.Publisher.Kafka.RESTProxyURL)
+	}
+	return n.nats.check(ctx)
+}
@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/cloudauth"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// maxPubSubData bounds the message data of a publish; base64 grows it by a third
+// within the 10MB limit of a publish request
+const maxPubSubData = 7 << 20
+
+// PubSubNotifier publishes every event to a Google Cloud Pub/Sub topic, for Cloud
+// Functions, Cloud Run services and other subscribers to react to
+type PubSubNotifier struct {
+	config *config.Config
+	client *http.Client
+	tokens *cloudauth.MetadataTokenSource
+}
+
+// pubsubPublishRequest is a Pub/Sub REST publish request
+type pubsubPublishRequest struct {
+	Messages []pubsubMessage `json:"messages"`
+}
+
+type pubsubMessage struct {
+	Data        []byte            `json:"data"` // Encoded as base64
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+// NewPubSubNotifier creates a Pub/Sub notifier; access tokens are fetched on first use
+func NewPubSubNotifier(cfg *config.Config) *PubSubNotifier {
+	client := &http.Client{Timeout: cfg.PubSub.GetTimeout()}
+	return &PubSubNotifier{
+		config: cfg,
+		client: client,
+		tokens: cloudauth.NewMetadataTokenSource(client),
+	}
+}
+
+// SendNotification publishes the event with its identifying fields as attributes and,
+// when ordered is set, the object as ordering key
+func (n *PubSubNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	pubsubConfig := n.config.PubSub
+	event = fitMessage(n.config, event, maxPubSubData, func(e NotificationEvent) int {
+		return jsonSize(eventMessage(n.config, e, pubsubConfig.GetFormat()))
+	})
+	data, err := json.Marshal(eventMessage(n.config, event, pubsubConfig.GetFormat()))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	message := pubsubMessage{Data: data, Attributes: messageAttributes(n.config, event)}
+	if pubsubConfig.Ordered {
+		message.OrderingKey = clusterName(n.config, event) + "/" + event.ResourceKind + "/" + event.Ref()
+	}
+
+	token, err := n.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/topics/%s:publish", pubsubConfig.GetEndpoint(),
+		url.PathEscape(pubsubConfig.Project), url.PathEscape(pubsubConfig.Topic))
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	if err := postJSON(ctx, n.client, endpoint, pubsubPublishRequest{Messages: []pubsubMessage{message}}, headers); err != nil {
+		// Fetch a new token next time, in case this one was revoked
+		n.tokens.Invalidate()
+		return err
+	}
+	eventLogger(event).Info("Published event to Pub/Sub", "project", pubsubConfig.Project, "topic", pubsubConfig.Topic)
+	return nil
+}
+
+// TestConnection fetches an access token, which checks Workload Identity, and connects to the endpoint
+func (n *PubSubNotifier) TestConnection(ctx context.Context) error {
+	if _, err := n.tokens.Token(ctx); err != nil {
+		return err
+	}
+	return dialURL(ctx, n.config.PubSub.GetEndpoint())
+}
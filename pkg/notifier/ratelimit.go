@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitedNotifier throttles notifications per resource using a token bucket.
+// Events that exceed the budget are dropped and counted, and the count is
+// attached to the next notification that is allowed through for that resource.
+type RateLimitedNotifier struct {
+	next     Notifier
+	capacity float64
+	rate     float64 // tokens per second
+	period   time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// tokenBucket tracks the remaining budget and suppressed events for one resource
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+	suppressed int
+}
+
+// NewRateLimitedNotifier wraps next so each resource can generate at most
+// maxNotifications notifications per period
+func NewRateLimitedNotifier(next Notifier, maxNotifications int, period time.Duration) *RateLimitedNotifier {
+	now := time.Now()
+	return &RateLimitedNotifier{
+		next:      next,
+		capacity:  float64(maxNotifications),
+		rate:      float64(maxNotifications) / period.Seconds(),
+		period:    period,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: now,
+		now:       time.Now,
+	}
+}
+
+// SendNotification forwards the event if the resource still has budget left
+func (r *RateLimitedNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	key := fmt.Sprintf("%s/%s/%s", event.ResourceKind, event.Namespace, event.ResourceName)
+	if event.Cluster != "" {
+		key = event.Cluster + ":" + key
+	}
+
+	suppressed, allowed := r.take(key)
+	if !allowed {
+		eventLogger(event).Info("Suppressed notification; rate limit budget exhausted", "budget", r.capacity, "period", r.period.String())
+		return nil
+	}
+
+	event.SuppressedEvents += suppressed
+	if err := r.next.SendNotification(ctx, event); err != nil {
+		// Keep the suppressed count so it is reported by the next successful send
+		r.restore(key, suppressed)
+		return err
+	}
+	return nil
+}
+
+// take consumes a token for key, returning the number of events suppressed
+// since the last allowed one and whether this event may be sent
+func (r *RateLimitedNotifier) take(key string) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.sweep(now)
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: r.capacity, lastRefill: now}
+		r.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.lastRefill).Seconds() * r.rate
+	if bucket.tokens > r.capacity {
+		bucket.tokens = r.capacity
+	}
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		bucket.suppressed++
+		return 0, false
+	}
+
+	bucket.tokens--
+	suppressed := bucket.suppressed
+	bucket.suppressed = 0
+	return suppressed, true
+}
+
+// restore puts back a suppressed count that could not be delivered
+func (r *RateLimitedNotifier) restore(key string, suppressed int) {
+	if suppressed == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if bucket, ok := r.buckets[key]; ok {
+		bucket.suppressed += suppressed
+	}
+}
+
+// sweep drops buckets that have fully refilled and have nothing to report,
+// so resources that stopped changing do not accumulate state forever
+func (r *RateLimitedNotifier) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < r.period {
+		return
+	}
+	r.lastSweep = now
+
+	for key, bucket := range r.buckets {
+		if bucket.suppressed == 0 && now.Sub(bucket.lastRefill) >= r.period {
+			delete(r.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedNotifier(t *testing.T) {
+	type send struct {
+		after      time.Duration // Since the previous event
+		name       string
+		fail       bool
+		want       bool // Reaches the next notifier
+		suppressed int  // Suppressed count it carries
+	}
+
+	tests := []struct {
+		name  string
+		sends []send
+	}{
+		{
+			name: "budget per resource",
+			sends: []send{
+				{name: "a", want: true},
+				{name: "a", want: true},
+				{name: "a"},
+				{name: "b", want: true},
+			},
+		},
+		{
+			name: "refills over the period",
+			sends: []send{
+				{name: "a", want: true},
+				{name: "a", want: true},
+				{name: "a"},
+				{name: "a"},
+				{after: 30 * time.Second, name: "a", want: true, suppressed: 2},
+				{name: "a"},
+			},
+		},
+		{
+			name: "suppressed count kept when sending fails",
+			sends: []send{
+				{name: "a", want: true},
+				{name: "a", want: true},
+				{name: "a"},
+				{after: 30 * time.Second, name: "a", fail: true},
+				{after: 30 * time.Second, name: "a", want: true, suppressed: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+			next := &recordingNotifier{}
+			limiter := NewRateLimitedNotifier(next, 2, time.Minute)
+			limiter.now = func() time.Time { return now }
+
+			for i, s := range tt.sends {
+				now = now.Add(s.after)
+				next.err = nil
+				if s.fail {
+					next.err = errors.New("unavailable")
+				}
+				before := len(next.sent())
+				err := limiter.SendNotification(context.Background(), NotificationEvent{ResourceKind: "ConfigMap", Namespace: "prod", ResourceName: s.name})
+				if (err != nil) != s.fail {
+					t.Fatalf("send %d: error = %v, want failure %v", i, err, s.fail)
+				}
+				sent := next.sent()
+				if got := len(sent) > before; got != s.want {
+					t.Fatalf("send %d: forwarded = %v, want %v", i, got, s.want)
+				}
+				if s.want && sent[len(sent)-1].SuppressedEvents != s.suppressed {
+					t.Errorf("send %d: suppressed = %d, want %d", i, sent[len(sent)-1].SuppressedEvents, s.suppressed)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitedNotifierSeparatesClusters(t *testing.T) {
+	next := &recordingNotifier{}
+	limiter := NewRateLimitedNotifier(next, 1, time.Minute)
+	for _, cluster := range []string{"eu-1", "us-1", "eu-1"} {
+		limiter.SendNotification(context.Background(), NotificationEvent{Cluster: cluster, ResourceKind: "ConfigMap", Namespace: "prod", ResourceName: "a"})
+	}
+	if got := len(next.sent()); got != 2 {
+		t.Errorf("%d notifications forwarded, want one per cluster", got)
+	}
+}
@@ -0,0 +1,306 @@
+package notifier
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+)
+
+// redactionSecretRefresh is how often the values of redacted Secrets are read again,
+// so rotated values are redacted too
+const redactionSecretRefresh = time.Minute
+
+// minRedactedSecretValue is the length below which Secret values are not redacted,
+// since short values such as "true" or "1" would be replaced everywhere
+const minRedactedSecretValue = 6
+
+// SecretDataFunc returns the data of a Secret
+type SecretDataFunc func(ctx context.Context, namespace, name string) (map[string][]byte, error)
+
+// Redactor scrubs the content that redaction rules name from events before they
+// leave the process. Each notifier gets the rules that apply to it; the event
+// history, event stream and other consumers get every rule.
+type Redactor struct {
+	replacement string
+	rules       []*redactionRule
+}
+
+type redactionRule struct {
+	config    config.RedactionRuleConfig
+	patterns  []*regexp.Regexp
+	notifiers map[string]bool // Empty for every notifier
+
+	mu      sync.RWMutex
+	secrets map[string][]string // namespace/name -> values read last
+	values  []string            // Secret values and their encoded forms, longest first
+	missing []string            // Secrets whose values could not be read yet
+}
+
+// NewRedactor creates a redactor for validated rules; Secret values are read by LoadSecrets
+func NewRedactor(cfg config.RedactionConfig) *Redactor {
+	r := &Redactor{replacement: cfg.GetReplacement()}
+	for _, ruleConfig := range cfg.Rules {
+		rule := &redactionRule{config: ruleConfig, notifiers: make(map[string]bool), secrets: make(map[string][]string)}
+		for _, pattern := range ruleConfig.Patterns {
+			rule.patterns = append(rule.patterns, regexp.MustCompile(pattern))
+		}
+		for _, name := range ruleConfig.Notifiers {
+			rule.notifiers[name] = true
+		}
+		for _, secret := range ruleConfig.Secrets {
+			rule.missing = append(rule.missing, secret.Namespace+"/"+secret.Name)
+		}
+		r.rules = append(r.rules, rule)
+	}
+	return r
+}
+
+// LoadSecrets reads the values of the Secrets the rules name. A Secret that cannot be read
+// keeps its previous values; until it was read once, the diffs it applies to are withheld.
+func (r *Redactor) LoadSecrets(ctx context.Context, secretData SecretDataFunc) error {
+	var errs []string
+	for _, rule := range r.rules {
+		if len(rule.config.Secrets) == 0 {
+			continue
+		}
+		rule.mu.Lock()
+		var values, missing []string
+		for _, secret := range rule.config.Secrets {
+			ref := secret.Namespace + "/" + secret.Name
+			data, err := secretData(ctx, secret.Namespace, secret.Name)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("rule %s: Secret %s: %v", rule.config.Name, ref, err))
+			} else {
+				var secretValues []string
+				for key, value := range data {
+					if len(secret.Keys) == 0 || config.MatchAny(secret.Keys, key) {
+						secretValues = append(secretValues, redactedForms(string(value))...)
+					}
+				}
+				rule.secrets[ref] = secretValues
+			}
+			if secretValues, ok := rule.secrets[ref]; ok {
+				values = append(values, secretValues...)
+			} else {
+				missing = append(missing, ref)
+			}
+		}
+		sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+		rule.values, rule.missing = values, missing
+		rule.mu.Unlock()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to read redacted Secrets: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Run reads the Secrets again every redactionSecretRefresh until ctx is done
+func (r *Redactor) Run(ctx context.Context, secretData SecretDataFunc) {
+	defer exitcode.Recover()
+	ticker := time.NewTicker(redactionSecretRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.LoadSecrets(ctx, secretData); err != nil {
+				slog.Warn("Failed to refresh redacted Secret values", "error", err)
+			}
+		}
+	}
+}
+
+// redactedForms returns a Secret value as it may appear in notifications: as is, base64-encoded,
+// escaped by the diffs' quoting and, for multi-line values such as keys, line by line
+func redactedForms(value string) []string {
+	forms := []string{value, base64.StdEncoding.EncodeToString([]byte(value))}
+	if quoted := strconv.Quote(value); len(quoted) > 2 {
+		forms = append(forms, quoted[1:len(quoted)-1])
+	}
+	if encoded, err := json.Marshal(value); err == nil && len(encoded) > 2 {
+		forms = append(forms, string(encoded[1:len(encoded)-1]))
+	}
+	if strings.Contains(value, "\n") {
+		forms = append(forms, strings.Split(value, "\n")...)
+	}
+
+	seen := make(map[string]bool)
+	var unique []string
+	for _, form := range forms {
+		form = strings.TrimSpace(form)
+		if len(form) >= minRedactedSecretValue && !seen[form] {
+			seen[form] = true
+			unique = append(unique, form)
+		}
+	}
+	return unique
+}
+
+// Redact returns the event with the content of every rule applying to notifier replaced,
+// in its diff, summary, warnings, labels and annotations
+func (r *Redactor) Redact(notifier string, event NotificationEvent) NotificationEvent {
+	if r == nil {
+		return event
+	}
+	var rules []*redactionRule
+	for _, rule := range r.rules {
+		if len(rule.notifiers) == 0 || rule.notifiers[notifier] {
+			rules = append(rules, rule)
+		}
+	}
+	return r.redact(rules, event)
+}
+
+// RedactAll returns the event with the content of every rule replaced, whichever notifiers
+// the rules are for. It is applied to events recorded or streamed outside the notifiers.
+func (r *Redactor) RedactAll(event NotificationEvent) NotificationEvent {
+	if r == nil {
+		return event
+	}
+	return r.redact(r.rules, event)
+}
+
+func (r *Redactor) redact(rules []*redactionRule, event NotificationEvent) NotificationEvent {
+	if len(rules) == 0 {
+		return event
+	}
+
+	redacted := event
+	redacted.Diff = r.redactDiff(rules, event.Diff)
+	redacted.Summary = r.redactLines(rules, event.Summary)
+	redacted.Warnings = r.redactLines(rules, event.Warnings)
+	redacted.Labels = r.redactMap(rules, event.Labels, false)
+	redacted.Annotations = r.redactMap(rules, event.Annotations, true)
+
+	for _, rule := range rules {
+		rule.mu.RLock()
+		missing := rule.missing
+		rule.mu.RUnlock()
+		if len(missing) > 0 && len(redacted.Diff) > 0 {
+			redacted.Diff = []string{fmt.Sprintf("(diff withheld: redaction rule %s cannot read Secret %s)", rule.config.Name, strings.Join(missing, ", "))}
+		}
+	}
+	return redacted
+}
+
+// redactText replaces the patterns and Secret values of rules in text
+func (r *Redactor) redactText(rules []*redactionRule, text string) string {
+	for _, rule := range rules {
+		for _, pattern := range rule.patterns {
+			text = replaceMatches(pattern, text, r.replacement)
+		}
+		rule.mu.RLock()
+		for _, value := range rule.values {
+			text = strings.ReplaceAll(text, value, r.replacement)
+		}
+		rule.mu.RUnlock()
+	}
+	return text
+}
+
+func (r *Redactor) redactLines(rules []*redactionRule, lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+	redacted := make([]string, len(lines))
+	for i, line := range lines {
+		redacted[i] = r.redactText(rules, line)
+	}
+	return redacted
+}
+
+// redactMap redacts the values of labels or annotations; the values of annotation
+// keys matching a rule's annotation patterns are replaced whole
+func (r *Redactor) redactMap(rules []*redactionRule, values map[string]string, annotations bool) map[string]string {
+	if len(values) == 0 {
+		return values
+	}
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		if annotations && redactsAnnotation(rules, key) {
+			redacted[key] = r.replacement
+		} else {
+			redacted[key] = r.redactText(rules, value)
+		}
+	}
+	return redacted
+}
+
+// redactDiff redacts diff lines; changes of annotations whose keys the rules name keep
+// only their field, e.g. "~ metadata.annotations.vault.hashicorp.com/token: REDACTED"
+func (r *Redactor) redactDiff(rules []*redactionRule, diff []string) []string {
+	if len(diff) == 0 {
+		return diff
+	}
+	const annotationsField = "metadata.annotations"
+	redacted := make([]string, len(diff))
+	for i, line := range diff {
+		sign, rest, _ := strings.Cut(line, " ")
+		field, value, hasValue := strings.Cut(rest, ": ")
+		switch {
+		case !hasValue || (sign != "+" && sign != "-" && sign != "~"):
+		case strings.HasPrefix(field, annotationsField+".") && redactsAnnotation(rules, strings.TrimPrefix(field, annotationsField+".")):
+			line = sign + " " + field + ": " + r.replacement
+		case field == annotationsField:
+			// A whole annotations map, added or removed
+			var annotations map[string]string
+			if err := json.Unmarshal([]byte(value), &annotations); err != nil {
+				line = sign + " " + field + ": " + r.replacement
+				break
+			}
+			encoded, _ := json.Marshal(r.redactMap(rules, annotations, true))
+			line = sign + " " + field + ": " + string(encoded)
+		}
+		redacted[i] = r.redactText(rules, line)
+	}
+	return redacted
+}
+
+func redactsAnnotation(rules []*redactionRule, key string) bool {
+	for _, rule := range rules {
+		for _, pattern := range rule.config.Annotations {
+			if matched, _ := path.Match(pattern, key); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replaceMatches replaces the matches of pattern in text or, when it has capture groups,
+// only what the groups captured, e.g. the value of `password=(\S+)`
+func replaceMatches(pattern *regexp.Regexp, text, replacement string) string {
+	if pattern.NumSubexp() == 0 {
+		return pattern.ReplaceAllLiteralString(text, replacement)
+	}
+	var redacted strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		for group := 1; group <= pattern.NumSubexp(); group++ {
+			start, end := match[2*group], match[2*group+1]
+			// Skip groups that did not take part in the match or lie within a replaced group
+			if start < 0 || start < last {
+				continue
+			}
+			redacted.WriteString(text[last:start])
+			redacted.WriteString(replacement)
+			last = end
+		}
+	}
+	redacted.WriteString(text[last:])
+	return redacted.String()
+}
@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+func TestRedactorRedact(t *testing.T) {
+	redactor := NewRedactor(config.RedactionConfig{Rules: []config.RedactionRuleConfig{
+		{Name: "passwords", Patterns: []string{`password=(\S+)`}},
+		{Name: "tokens", Patterns: []string{`ghp_[A-Za-z0-9]+`}, Notifiers: []string{"teams"}},
+		{Name: "vault", Annotations: []string{"vault.hashicorp.com/*"}},
+		{Name: "db", Secrets: []config.RedactionSecretRef{{Namespace: "prod", Name: "db", Keys: []string{"pass*"}}}},
+	}})
+	err := redactor.LoadSecrets(context.Background(), func(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+		return map[string][]byte{"password": []byte("s3cr3t-value"), "user": []byte("payments-app"), "port": []byte("5432")}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		notifier string
+		event    NotificationEvent
+		want     NotificationEvent
+	}{
+		{
+			name:     "capture group",
+			notifier: "email",
+			event:    NotificationEvent{Diff: []string{"~ data.url: db?user=app password=hunter22 tls=true"}},
+			want:     NotificationEvent{Diff: []string{"~ data.url: db?user=app password=REDACTED tls=true"}},
+		},
+		{
+			name:     "rule for another notifier",
+			notifier: "email",
+			event:    NotificationEvent{Summary: []string{"token ghp_abc123"}},
+			want:     NotificationEvent{Summary: []string{"token ghp_abc123"}},
+		},
+		{
+			name:     "rule for the notifier",
+			notifier: "teams",
+			event:    NotificationEvent{Summary: []string{"token ghp_abc123"}},
+			want:     NotificationEvent{Summary: []string{"token REDACTED"}},
+		},
+		{
+			name:     "annotation keys",
+			notifier: "email",
+			event: NotificationEvent{
+				Annotations: map[string]string{"vault.hashicorp.com/role": "payments", "team": "payments"},
+				Diff: []string{
+					"~ metadata.annotations.vault.hashicorp.com/role: payments",
+					`+ metadata.annotations: {"team":"payments","vault.hashicorp.com/role":"payments"}`,
+				},
+			},
+			want: NotificationEvent{
+				Annotations: map[string]string{"vault.hashicorp.com/role": "REDACTED", "team": "payments"},
+				Diff: []string{
+					"~ metadata.annotations.vault.hashicorp.com/role: REDACTED",
+					`+ metadata.annotations: {"team":"payments","vault.hashicorp.com/role":"REDACTED"}`,
+				},
+			},
+		},
+		{
+			name:     "secret values and their encodings",
+			notifier: "email",
+			event: NotificationEvent{
+				Diff:     []string{"~ data.dsn: postgres://app:s3cr3t-value@db", "~ data.encoded: " + base64.StdEncoding.EncodeToString([]byte("s3cr3t-value"))},
+				Warnings: []string{"user payments-app on port 5432"},
+			},
+			want: NotificationEvent{
+				Diff:     []string{"~ data.dsn: postgres://app:REDACTED@db", "~ data.encoded: REDACTED"},
+				Warnings: []string{"user payments-app on port 5432"}, // Keys not named and values too short to redact
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.Redact(tt.notifier, tt.event); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redact() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorRedactAll(t *testing.T) {
+	redactor := NewRedactor(config.RedactionConfig{Replacement: "***", Rules: []config.RedactionRuleConfig{
+		{Name: "tokens", Patterns: []string{`ghp_[A-Za-z0-9]+`}, Notifiers: []string{"teams"}},
+	}})
+	event := NotificationEvent{Summary: []string{"token ghp_abc123"}}
+	if got := redactor.RedactAll(event).Summary; !reflect.DeepEqual(got, []string{"token ***"}) {
+		t.Errorf("RedactAll() summary = %v, want the rule of every notifier applied", got)
+	}
+
+	var none *Redactor
+	if got := none.RedactAll(event); !reflect.DeepEqual(got, event) {
+		t.Errorf("nil Redactor changed the event: %+v", got)
+	}
+}
+
+func TestRedactorWithholdsDiffUntilSecretRead(t *testing.T) {
+	redactor := NewRedactor(config.RedactionConfig{Rules: []config.RedactionRuleConfig{
+		{Name: "db", Secrets: []config.RedactionSecretRef{{Namespace: "prod", Name: "db"}}},
+	}})
+	failing := func(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+		return nil, fmt.Errorf("forbidden")
+	}
+	if err := redactor.LoadSecrets(context.Background(), failing); err == nil {
+		t.Fatal("LoadSecrets() succeeded with an unreadable Secret")
+	}
+
+	got := redactor.Redact("email", NotificationEvent{Diff: []string{"~ data.password: unknown"}})
+	want := []string{"(diff withheld: redaction rule db cannot read Secret prod/db)"}
+	if !reflect.DeepEqual(got.Diff, want) {
+		t.Errorf("Redact() diff = %v, want %v", got.Diff, want)
+	}
+}
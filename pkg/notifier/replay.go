@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/store"
+)
+
+// ReplayResult is the outcome of replaying a recorded event
+type ReplayResult struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	EventType string    `json:"eventType"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// RecordedEvent rebuilds the notification of a recorded event, marked as a replay. The
+// labels, annotations and image changes of the object are not recorded, so they are missing.
+func RecordedEvent(record store.Record) NotificationEvent {
+	summary := []string{"Replayed from the event history; recorded at " + record.Time.UTC().Format(time.RFC3339)}
+	return NotificationEvent{
+		ID:            record.ID,
+		Cluster:       record.Cluster,
+		EventType:     EventType(record.EventType),
+		ResourceKind:  record.Kind,
+		ResourceName:  record.Name,
+		Namespace:     record.Namespace,
+		Severity:      record.Severity,
+		ChangedFields: record.ChangedFields,
+		ChangedBy:     record.ChangedBy,
+		Origin:        record.Origin,
+		Diff:          record.Diff,
+		Warnings:      record.Warnings,
+		Summary:       append(summary, record.Summary...),
+	}
+}
+
+// Replay sends recorded events to the named notifier in the given order, bypassing routing,
+// silences and rate limits; with dryRun nothing is sent. It stops once ctx is done, returning
+// the results so far.
+func (r *Router) Replay(ctx context.Context, name string, records []store.Record, dryRun bool) ([]ReplayResult, error) {
+	if _, ok := r.notifiers[name]; !ok {
+		return nil, r.unknownNotifier(name)
+	}
+
+	results := make([]ReplayResult, 0, len(records))
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		result := ReplayResult{
+			ID:        record.ID,
+			Time:      record.Time,
+			EventType: record.EventType,
+			Kind:      record.Kind,
+			Namespace: record.Namespace,
+			Name:      record.Name,
+		}
+		if !dryRun {
+			event := RecordedEvent(record)
+			if err := r.Deliver(ctx, name, event); err != nil {
+				result.Error = err.Error()
+				eventLogger(event).Warn("Failed to replay event", "notifier", name, "error", err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
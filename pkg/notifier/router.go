@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/rules"
+)
+
+// Router delivers events to the named notifiers selected by the routing rulesets.
+// Each ruleset is evaluated independently and the selected notifiers are combined.
+type Router struct {
+	notifiers map[string]Notifier
+	order     []string
+	redactor  *Redactor // Applied per notifier; nil redacts nothing
+
+	mu       sync.RWMutex
+	routing  config.RoutingConfig
+	rulesets []*rules.RuleSet
+}
+
+// NewRouter creates a router over the named notifiers; order fixes the delivery order
+func NewRouter(cfg config.RoutingConfig, notifiers map[string]Notifier, order []string) *Router {
+	router := &Router{
+		notifiers: notifiers,
+		order:     order,
+	}
+	router.SetRouting(cfg)
+	return router
+}
+
+// SetRouting replaces the routing rulesets; cfg must already be validated
+func (r *Router) SetRouting(cfg config.RoutingConfig) {
+	rulesets := make([]*rules.RuleSet, 0, len(cfg.Rulesets))
+	for _, rulesetConfig := range cfg.Rulesets {
+		rulesets = append(rulesets, rules.NewRuleSet(rulesetConfig))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routing = cfg
+	r.rulesets = rulesets
+}
+
+// SetRedactor makes the router redact every event for each notifier before handing it over
+func (r *Router) SetRedactor(redactor *Redactor) {
+	r.redactor = redactor
+}
+
+// Routing returns the routing rulesets in use
+func (r *Router) Routing() config.RoutingConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.routing
+}
+
+// SendNotification routes the event and delivers it to each selected notifier once
+func (r *Router) SendNotification(ctx context.Context, event NotificationEvent) error {
+	targets := r.Route(event)
+	if len(targets) == 0 {
+		eventLogger(event).Info("No notifier selected")
+		return nil
+	}
+
+	var errs []error
+	for _, name := range targets {
+		if err := r.notifiers[name].SendNotification(ctx, r.redactor.Redact(name, event)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ErrUnknownNotifier is returned by Deliver and Replay for a notifier that is not configured
+var ErrUnknownNotifier = errors.New("unknown notifier")
+
+// Deliver sends the event to the named notifier, bypassing the routing rulesets
+func (r *Router) Deliver(ctx context.Context, name string, event NotificationEvent) error {
+	target, ok := r.notifiers[name]
+	if !ok {
+		return r.unknownNotifier(name)
+	}
+	return target.SendNotification(ctx, r.redactor.Redact(name, event))
+}
+
+func (r *Router) unknownNotifier(name string) error {
+	return fmt.Errorf("%w %q (configured: %s)", ErrUnknownNotifier, name, strings.Join(r.order, ", "))
+}
+
+// Route returns the notifiers selected for the event, in delivery order
+func (r *Router) Route(event NotificationEvent) []string {
+	r.mu.RLock()
+	rulesets := r.rulesets
+	r.mu.RUnlock()
+
+	if len(rulesets) == 0 {
+		return r.order
+	}
+
+	ruleEvent := rules.Event{
+		Kind:          event.ResourceKind,
+		Namespace:     event.Namespace,
+		Name:          event.ResourceName,
+		EventType:     string(event.EventType),
+		Cluster:       event.Cluster,
+		Labels:        event.Labels,
+		Annotations:   event.Annotations,
+		ChangedFields: event.ChangedFields,
+		ChangedBy:     event.ChangedBy,
+		Origin:        event.Origin,
+	}
+
+	selected := make(map[string]bool)
+	for _, ruleset := range rulesets {
+		decision := ruleset.Evaluate(ruleEvent)
+		if len(decision.MatchedRules) > 0 {
+			eventLogger(event).Debug("Ruleset matched", "ruleset", ruleset.Name, "rules", strings.Join(decision.MatchedRules, ", "))
+		}
+		for _, name := range decision.Notifiers {
+			selected[name] = true
+		}
+	}
+
+	var targets []string
+	for _, name := range r.order {
+		if selected[name] {
+			targets = append(targets, name)
+		}
+	}
+	return targets
+}
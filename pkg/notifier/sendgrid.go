@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// sendgridTransport sends email through the SendGrid v3 API over HTTPS, for
+// clusters where outbound SMTP is blocked
+type sendgridTransport struct {
+	config *config.SendGridConfig
+	client *http.Client
+}
+
+type sendgridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendgridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendgridPersonalization struct {
+	To []sendgridAddress `json:"to"`
+}
+
+type sendgridMail struct {
+	Personalizations []sendgridPersonalization `json:"personalizations"`
+	From             sendgridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendgridContent         `json:"content"`
+}
+
+func newSendGridTransport(cfg *config.EmailConfig) *sendgridTransport {
+	return &sendgridTransport{
+		config: &cfg.SendGrid,
+		client: &http.Client{Timeout: cfg.GetSendTimeout()},
+	}
+}
+
+// send delivers the message with one personalization addressed to every recipient
+func (t *sendgridTransport) send(ctx context.Context, msg emailMessage) error {
+	from, err := parseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid From address %q: %w", msg.From, err)
+	}
+	personalization := sendgridPersonalization{}
+	for _, recipient := range msg.To {
+		to, err := parseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid To address %q: %w", recipient, err)
+		}
+		personalization.To = append(personalization.To, to)
+	}
+
+	payload := sendgridMail{
+		Personalizations: []sendgridPersonalization{personalization},
+		From:             from,
+		Subject:          msg.Subject,
+		Content:          []sendgridContent{{Type: "text/plain", Value: msg.Text}},
+	}
+	if msg.HTML != "" {
+		payload.Content = append(payload.Content, sendgridContent{Type: "text/html", Value: msg.HTML})
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode SendGrid request: %w", err)
+	}
+	headers := map[string]string{"Authorization": "Bearer " + t.config.GetAPIKey()}
+	if _, err := doPost(ctx, t.client, t.config.GetEndpoint()+"/v3/mail/send", data, headers); err != nil {
+		return fmt.Errorf("SendGrid: %w", err)
+	}
+	return nil
+}
+
+// probe lists the API key's scopes, which checks the endpoint and the key
+func (t *sendgridTransport) probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.config.GetEndpoint()+"/v3/scopes", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.config.GetAPIKey())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("SendGrid: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("SendGrid: unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// parseAddress splits "Name <user@example.com>" into its parts
+func parseAddress(value string) (sendgridAddress, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return sendgridAddress{}, err
+	}
+	return sendgridAddress{Email: addr.Address, Name: addr.Name}, nil
+}
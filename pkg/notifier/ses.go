@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/cloudauth"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// sesTransport sends the composed MIME message through the Amazon SES v2 API
+// over HTTPS, for clusters where outbound SMTP is blocked
+type sesTransport struct {
+	config      *config.SESConfig
+	client      *http.Client
+	credentials *cloudauth.AWSCredentialSource
+}
+
+func newSESTransport(cfg *config.EmailConfig) *sesTransport {
+	client := &http.Client{Timeout: cfg.GetSendTimeout()}
+	return &sesTransport{
+		config:      &cfg.SES,
+		client:      client,
+		credentials: cloudauth.NewAWSCredentialSource(cfg.SES.GetRegion(), client),
+	}
+}
+
+// send delivers the message as raw email, so the HTML part and headers arrive exactly as composed
+func (t *sesTransport) send(ctx context.Context, msg emailMessage) error {
+	var raw bytes.Buffer
+	if _, err := msg.compose().WriteTo(&raw); err != nil {
+		return fmt.Errorf("failed to compose message: %w", err)
+	}
+
+	request := map[string]interface{}{
+		"FromEmailAddress": msg.From,
+		"Destination":      map[string]interface{}{"ToAddresses": msg.To},
+		"Content":          map[string]interface{}{"Raw": map[string]interface{}{"Data": raw.Bytes()}},
+	}
+	if t.config.ConfigurationSet != "" {
+		request["ConfigurationSetName"] = t.config.ConfigurationSet
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode SES request: %w", err)
+	}
+
+	endpoint := t.config.GetEndpoint() + "/v2/email/outbound-emails"
+	headers, err := t.sign(ctx, http.MethodPost, endpoint, data)
+	if err != nil {
+		return err
+	}
+	if _, err := doPost(ctx, t.client, endpoint, data, headers); err != nil {
+		return fmt.Errorf("SES: %w", err)
+	}
+	return nil
+}
+
+// probe reads the account's sending status, which checks the endpoint and the credentials
+func (t *sesTransport) probe(ctx context.Context) error {
+	endpoint := t.config.GetEndpoint() + "/v2/email/account"
+	headers, err := t.sign(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("SES: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("SES: unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// sign returns the headers authenticating a request with AWS Signature Version 4
+func (t *sesTransport) sign(ctx context.Context, method, endpoint string, payload []byte) (map[string]string, error) {
+	credentials, err := t.credentials.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SES: %w", err)
+	}
+	headers, err := cloudauth.SignAWS(credentials, "ses", t.config.GetRegion(), method, endpoint, payload, "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("SES: %w", err)
+	}
+	return headers, nil
+}
@@ -0,0 +1,401 @@
+package notifier
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/rules"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/store"
+)
+
+// silenceSweepInterval is how often expired silences are collected and expiry warnings sent
+const silenceSweepInterval = 30 * time.Second
+
+// silencesKey is the storage key of the persisted silences
+const silencesKey = "silences"
+
+// ErrSilenceNotFound is returned for unknown or already expired silence IDs
+var ErrSilenceNotFound = errors.New("silence not found")
+
+// Silence suppresses matching notifications until EndsAt
+type Silence struct {
+	ID        string             `json:"id" yaml:"id,omitempty"`
+	Match     config.MatchConfig `json:"match" yaml:"match"`
+	CreatedBy string             `json:"createdBy" yaml:"createdBy"` // Email address warned before expiry, when it is one
+	Comment   string             `json:"comment,omitempty" yaml:"comment,omitempty"`
+	StartsAt  time.Time          `json:"startsAt" yaml:"startsAt"`
+	EndsAt    time.Time          `json:"endsAt" yaml:"endsAt"`
+
+	Suppressed int `json:"suppressed" yaml:"suppressed,omitempty"` // Notifications suppressed so far
+
+	// Suppressed notifications per resource, for the expiry summary
+	resources map[string]int
+	warned    bool
+}
+
+// storedSilence is a silence as persisted, with the counters its expiry summary needs
+type storedSilence struct {
+	Silence
+	Resources map[string]int `json:"resources,omitempty"`
+	Warned    bool           `json:"warned,omitempty"`
+}
+
+// SilencingNotifier drops notifications matching an active silence. Silences
+// expire on their own: creators are warned shortly before, and a summary of
+// what each silence suppressed is sent when it ends.
+type SilencingNotifier struct {
+	next       Notifier
+	summary    Notifier // Receives expiry summaries; should bypass routing
+	creator    Notifier // Receives expiry warnings addressed to the creator; may be nil
+	warnBefore time.Duration
+
+	mu       sync.Mutex
+	silences map[string]*Silence
+	now      func() time.Time
+	storage  store.Storage // Nil until Restore
+	dirty    bool          // Counters changed since the last save
+
+	saveMu sync.Mutex // Keeps saves in order
+}
+
+// NewSilencingNotifier wraps next with silences. Expiry summaries go to summary,
+// and warnings go to creator (typically the email notifier) warnBefore expiry.
+func NewSilencingNotifier(next, summary, creator Notifier, warnBefore time.Duration) *SilencingNotifier {
+	return &SilencingNotifier{
+		next:       next,
+		summary:    summary,
+		creator:    creator,
+		warnBefore: warnBefore,
+		silences:   make(map[string]*Silence),
+		now:        time.Now,
+	}
+}
+
+// SendNotification forwards the event unless an active silence matches it
+func (s *SilencingNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	ruleEvent := rules.Event{
+		Kind:          event.ResourceKind,
+		Namespace:     event.Namespace,
+		Name:          event.ResourceName,
+		EventType:     string(event.EventType),
+		Cluster:       event.Cluster,
+		Labels:        event.Labels,
+		Annotations:   event.Annotations,
+		ChangedFields: event.ChangedFields,
+		ChangedBy:     event.ChangedBy,
+		Origin:        event.Origin,
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	for _, silence := range s.silences {
+		if now.Before(silence.StartsAt) || !now.Before(silence.EndsAt) || !rules.Matches(silence.Match, ruleEvent) {
+			continue
+		}
+		silence.Suppressed++
+		silence.resources[fmt.Sprintf("%s %s", event.ResourceKind, event.Ref())]++
+		s.dirty = true
+		s.mu.Unlock()
+		eventLogger(event).Info("Suppressed notification", "silence", silence.ID)
+		return nil
+	}
+	s.mu.Unlock()
+
+	return s.next.SendNotification(ctx, event)
+}
+
+// Add registers a silence, assigning its ID and defaulting StartsAt to now
+func (s *SilencingNotifier) Add(ctx context.Context, silence Silence) (Silence, error) {
+	if err := s.Check(&silence); err != nil {
+		return Silence{}, err
+	}
+
+	silence.ID = newID()
+	silence.Suppressed = 0
+	silence.resources = make(map[string]int)
+	silence.warned = false
+
+	s.mu.Lock()
+	s.silences[silence.ID] = &silence
+	s.mu.Unlock()
+
+	slog.Info("Silence created", "silence", silence.ID, "createdBy", silence.CreatedBy, "endsAt", silence.EndsAt.Format(time.RFC3339))
+	s.persist(ctx)
+	return silence, nil
+}
+
+// Check validates a silence before it is added, defaulting StartsAt to now
+func (s *SilencingNotifier) Check(silence *Silence) error {
+	if silence.CreatedBy == "" {
+		return fmt.Errorf("createdBy is required")
+	}
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = s.now()
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return fmt.Errorf("endsAt must be after startsAt")
+	}
+	if silence.EndsAt.Before(s.now()) {
+		return fmt.Errorf("endsAt is in the past")
+	}
+	if err := silence.Match.Validate(); err != nil {
+		return fmt.Errorf("match: %v", err)
+	}
+	return nil
+}
+
+// List returns the active and pending silences, ending soonest first
+func (s *SilencingNotifier) List() []Silence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silences := make([]Silence, 0, len(s.silences))
+	for _, silence := range s.silences {
+		silences = append(silences, *silence)
+	}
+	sort.Slice(silences, func(i, j int) bool { return silences[i].EndsAt.Before(silences[j].EndsAt) })
+	return silences
+}
+
+// Expire ends a silence now and sends its summary; summary failures are only logged
+func (s *SilencingNotifier) Expire(ctx context.Context, id string) error {
+	s.mu.Lock()
+	silence, ok := s.silences[id]
+	if ok {
+		delete(s.silences, id)
+		silence.EndsAt = s.now()
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrSilenceNotFound
+	}
+	s.persist(ctx)
+	if err := s.sendSummary(ctx, silence); err != nil {
+		slog.Error("Failed to send silence summary", "silence", silence.ID, "error", err)
+	}
+	return nil
+}
+
+// Run collects expired silences and sends expiry warnings until ctx is done
+func (s *SilencingNotifier) Run(ctx context.Context) {
+	defer exitcode.Recover()
+	ticker := time.NewTicker(silenceSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep warns creators of silences about to end and removes and summarizes ended ones
+func (s *SilencingNotifier) sweep(ctx context.Context) {
+	var expiring, expired []*Silence
+
+	s.mu.Lock()
+	now := s.now()
+	for id, silence := range s.silences {
+		switch {
+		case !now.Before(silence.EndsAt):
+			delete(s.silences, id)
+			expired = append(expired, silence)
+		case !silence.warned && now.Add(s.warnBefore).After(silence.EndsAt):
+			silence.warned = true
+			copied := *silence
+			expiring = append(expiring, &copied)
+		}
+	}
+	changed := s.dirty || len(expiring) > 0 || len(expired) > 0
+	s.mu.Unlock()
+
+	if changed {
+		s.persist(ctx)
+	}
+	for _, silence := range expiring {
+		if err := s.sendWarning(ctx, silence); err != nil {
+			slog.Error("Failed to warn that silence expires", "silence", silence.ID, "createdBy", silence.CreatedBy, "error", err)
+		}
+	}
+	for _, silence := range expired {
+		if err := s.sendSummary(ctx, silence); err != nil {
+			slog.Error("Failed to send silence summary", "silence", silence.ID, "error", err)
+		}
+	}
+}
+
+// Restore loads the silences persisted in storage and saves every change there
+// from now on. Silences that ended while the watcher was down are summarized by the next sweep.
+func (s *SilencingNotifier) Restore(ctx context.Context, storage store.Storage) error {
+	s.mu.Lock()
+	s.storage = storage
+	s.mu.Unlock()
+
+	data, err := storage.Get(ctx, silencesKey)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read silences: %w", err)
+	}
+	var stored []storedSilence
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to decode silences: %w", err)
+	}
+
+	s.mu.Lock()
+	for i := range stored {
+		silence := stored[i].Silence
+		silence.resources = stored[i].Resources
+		if silence.resources == nil {
+			silence.resources = make(map[string]int)
+		}
+		silence.warned = stored[i].Warned
+		s.silences[silence.ID] = &silence
+	}
+	s.mu.Unlock()
+
+	slog.Info("Restored silences", "silences", len(stored))
+	return nil
+}
+
+// Save writes the silences and their counters to storage, when Restore set one
+func (s *SilencingNotifier) Save(ctx context.Context) error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	storage := s.storage
+	stored := make([]storedSilence, 0, len(s.silences))
+	for _, silence := range s.silences {
+		resources := make(map[string]int, len(silence.resources))
+		for key, count := range silence.resources {
+			resources[key] = count
+		}
+		stored = append(stored, storedSilence{Silence: *silence, Resources: resources, Warned: silence.warned})
+	}
+	s.dirty = false
+	s.mu.Unlock()
+
+	if storage == nil {
+		return nil
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].EndsAt.Before(stored[j].EndsAt) })
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode silences: %w", err)
+	}
+	return storage.Put(ctx, silencesKey, data)
+}
+
+// persist saves the silences, only logging failures: the change still applies in memory
+func (s *SilencingNotifier) persist(ctx context.Context) {
+	if err := s.Save(ctx); err != nil {
+		slog.Error("Failed to persist silences", "error", err)
+	}
+}
+
+// sendWarning tells the creator the silence ends soon, if the creator is an email address
+func (s *SilencingNotifier) sendWarning(ctx context.Context, silence *Silence) error {
+	if s.creator == nil || !strings.Contains(silence.CreatedBy, "@") {
+		return nil
+	}
+	return s.creator.SendNotification(ctx, NotificationEvent{
+		EventType:    EventSilenceExpiring,
+		Severity:     config.SeverityInfo,
+		ResourceKind: "Silence",
+		ResourceName: silence.ID,
+		Recipients:   []string{silence.CreatedBy},
+		Summary: []string{
+			fmt.Sprintf("Your silence %s expires at %s.", silence.ID, silence.EndsAt.Format(time.RFC3339)),
+			describeSilence(silence),
+			fmt.Sprintf("It has suppressed %d notifications so far. Create a new silence to extend it.", silence.Suppressed),
+		},
+	})
+}
+
+// sendSummary reports how many notifications an ended silence suppressed
+func (s *SilencingNotifier) sendSummary(ctx context.Context, silence *Silence) error {
+	keys := make([]string, 0, len(silence.resources))
+	for key := range silence.resources {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if silence.resources[keys[i]] != silence.resources[keys[j]] {
+			return silence.resources[keys[i]] > silence.resources[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	lines := []string{
+		fmt.Sprintf("Silence %s by %s ended at %s and suppressed %d notifications.",
+			silence.ID, silence.CreatedBy, silence.EndsAt.Format(time.RFC3339), silence.Suppressed),
+		describeSilence(silence),
+	}
+	for i, key := range keys {
+		if i == maxSummaryResources {
+			lines = append(lines, fmt.Sprintf("...and %d more resources", len(keys)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s: %d", key, silence.resources[key]))
+	}
+
+	slog.Info("Silence ended", "silence", silence.ID, "suppressed", silence.Suppressed)
+	return s.summary.SendNotification(ctx, NotificationEvent{
+		EventType:    EventSilenceExpired,
+		Severity:     config.SeverityInfo,
+		ResourceKind: "Silence",
+		ResourceName: silence.ID,
+		Summary:      lines,
+	})
+}
+
+// describeSilence renders the silence's comment and matchers
+func describeSilence(silence *Silence) string {
+	var criteria []string
+	for _, part := range []struct {
+		label  string
+		values []string
+	}{
+		{"kinds", silence.Match.Kinds},
+		{"namespaces", silence.Match.Namespaces},
+		{"names", silence.Match.Names},
+		{"eventTypes", silence.Match.EventTypes},
+		{"clusters", silence.Match.Clusters},
+	} {
+		if len(part.values) > 0 {
+			criteria = append(criteria, part.label+"="+strings.Join(part.values, ","))
+		}
+	}
+	description := "Matches: everything"
+	if len(criteria) > 0 {
+		description = "Matches: " + strings.Join(criteria, " ")
+	}
+	if silence.Comment != "" {
+		description += " (" + silence.Comment + ")"
+	}
+	return description
+}
+
+// newID returns a short random identifier
+func newID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/store"
+)
+
+func TestSilencingNotifierMatches(t *testing.T) {
+	now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		silence Silence
+		event   NotificationEvent
+		want    bool // Forwarded
+	}{
+		{
+			name:    "matching namespace",
+			silence: Silence{Match: config.MatchConfig{Namespaces: []string{"prod-*"}}, EndsAt: now.Add(time.Hour)},
+			event:   NotificationEvent{ResourceKind: "ConfigMap", Namespace: "prod-payments", ResourceName: "app"},
+		},
+		{
+			name:    "other namespace",
+			silence: Silence{Match: config.MatchConfig{Namespaces: []string{"prod-*"}}, EndsAt: now.Add(time.Hour)},
+			event:   NotificationEvent{ResourceKind: "ConfigMap", Namespace: "dev", ResourceName: "app"},
+			want:    true,
+		},
+		{
+			name:    "not started yet",
+			silence: Silence{StartsAt: now.Add(time.Minute), EndsAt: now.Add(time.Hour)},
+			event:   NotificationEvent{ResourceKind: "ConfigMap", Namespace: "prod", ResourceName: "app"},
+			want:    true,
+		},
+		{
+			name:    "event type and kind",
+			silence: Silence{Match: config.MatchConfig{Kinds: []string{"Secret"}, EventTypes: []string{"DELETED"}}, EndsAt: now.Add(time.Hour)},
+			event:   NotificationEvent{EventType: EventModified, ResourceKind: "Secret", Namespace: "prod", ResourceName: "db"},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &recordingNotifier{}
+			silences := NewSilencingNotifier(next, &recordingNotifier{}, nil, 10*time.Minute)
+			silences.now = func() time.Time { return now }
+			tt.silence.CreatedBy = "oncall@example.com"
+			if _, err := silences.Add(context.Background(), tt.silence); err != nil {
+				t.Fatal(err)
+			}
+
+			silences.SendNotification(context.Background(), tt.event)
+			if got := len(next.sent()) == 1; got != tt.want {
+				t.Errorf("forwarded = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSilencingNotifierCheck(t *testing.T) {
+	now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+	silences := NewSilencingNotifier(nil, nil, nil, time.Minute)
+	silences.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		silence Silence
+		wantErr string
+	}{
+		{name: "valid", silence: Silence{CreatedBy: "me", EndsAt: now.Add(time.Hour)}},
+		{name: "no creator", silence: Silence{EndsAt: now.Add(time.Hour)}, wantErr: "createdBy is required"},
+		{name: "ends before it starts", silence: Silence{CreatedBy: "me", StartsAt: now.Add(time.Hour), EndsAt: now.Add(time.Minute)}, wantErr: "endsAt must be after startsAt"},
+		{name: "ended", silence: Silence{CreatedBy: "me", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}, wantErr: "endsAt is in the past"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := silences.Check(&tt.silence)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSilencingNotifierExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+	summary, creator := &recordingNotifier{}, &recordingNotifier{}
+	silences := NewSilencingNotifier(&recordingNotifier{}, summary, creator, 10*time.Minute)
+	silences.now = func() time.Time { return now }
+	storage := store.NewMemoryStorage()
+	if err := silences.Restore(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+
+	silence, err := silences.Add(ctx, Silence{CreatedBy: "oncall@example.com", Match: config.MatchConfig{Namespaces: []string{"prod"}}, EndsAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "a", "b"} {
+		silences.SendNotification(ctx, NotificationEvent{ResourceKind: "ConfigMap", Namespace: "prod", ResourceName: name})
+	}
+
+	// Restarting keeps the silence and its counters
+	now = now.Add(55 * time.Minute)
+	silences.sweep(ctx)
+	restored := NewSilencingNotifier(&recordingNotifier{}, summary, creator, 10*time.Minute)
+	restored.now = func() time.Time { return now }
+	if err := restored.Restore(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+	if list := restored.List(); len(list) != 1 || list[0].ID != silence.ID || list[0].Suppressed != 3 {
+		t.Fatalf("restored silences = %+v, want the silence with 3 suppressed", list)
+	}
+	if warnings := creator.sent(); len(warnings) != 1 || warnings[0].Recipients[0] != "oncall@example.com" {
+		t.Errorf("expiry warnings = %+v, want one to the creator", warnings)
+	}
+
+	now = now.Add(5 * time.Minute)
+	restored.sweep(ctx)
+	summaries := summary.sent()
+	if len(summaries) != 1 || summaries[0].EventType != EventSilenceExpired {
+		t.Fatalf("summaries = %+v, want one expiry summary", summaries)
+	}
+	if lines := summaries[0].Summary; len(lines) != 4 || lines[2] != "ConfigMap prod/a: 2" || lines[3] != "ConfigMap prod/b: 1" {
+		t.Errorf("summary lines = %q", lines)
+	}
+	if len(restored.List()) != 0 {
+		t.Error("expired silence still listed")
+	}
+	if err := restored.Expire(ctx, silence.ID); err != ErrSilenceNotFound {
+		t.Errorf("Expire() of an expired silence = %v, want ErrSilenceNotFound", err)
+	}
+}
@@ -0,0 +1,308 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+
+	"gopkg.in/gomail.v2"
+)
+
+// smtpTransport delivers gomail messages with bounded connect and send times.
+// gomail's own Dialer uses a fixed dial timeout and never sets a deadline on
+// the connection, so a black-holed relay can hold a send for minutes.
+type smtpTransport struct {
+	dialer         *gomail.Dialer
+	connectTimeout time.Duration
+	sendTimeout    time.Duration
+	localAddr      net.Addr
+	tokens         *clientCredentialsSource // XOAUTH2 access tokens, when configured
+}
+
+// newSMTPTransport creates the SMTP transport, choosing TLS settings from the port
+func newSMTPTransport(cfg *config.EmailConfig) *smtpTransport {
+	dialer := gomail.NewDialer(
+		cfg.SMTPHost,
+		cfg.SMTPPort,
+		cfg.SMTPUsername,
+		cfg.SMTPPassword,
+	)
+
+	switch cfg.SMTPPort {
+	case 465:
+		dialer.SSL = true
+		dialer.TLSConfig = &tls.Config{
+			InsecureSkipVerify: cfg.InsecureTLS,
+			ServerName:         cfg.SMTPHost,
+		}
+	case 587:
+		dialer.SSL = cfg.ForceSSL
+		dialer.TLSConfig = &tls.Config{
+			InsecureSkipVerify: cfg.InsecureTLS,
+			ServerName:         cfg.SMTPHost,
+		}
+	case 25:
+		dialer.SSL = cfg.ForceSSL
+		dialer.TLSConfig = &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         cfg.SMTPHost,
+		}
+	default:
+		dialer.SSL = cfg.ForceSSL
+		dialer.TLSConfig = &tls.Config{
+			InsecureSkipVerify: cfg.InsecureTLS,
+			ServerName:         cfg.SMTPHost,
+		}
+	}
+
+	transport := &smtpTransport{
+		dialer:         dialer,
+		connectTimeout: cfg.GetConnectTimeout(),
+		sendTimeout:    cfg.GetSendTimeout(),
+	}
+	if cfg.SourceAddress != "" {
+		transport.localAddr = &net.TCPAddr{IP: net.ParseIP(cfg.SourceAddress)}
+	}
+	if cfg.OAuth2 != nil {
+		transport.tokens = newClientCredentialsSource(cfg.OAuth2, &http.Client{Timeout: cfg.GetSendTimeout()})
+	}
+	return transport
+}
+
+// send performs a single delivery attempt, honoring both the configured
+// timeouts and any deadline or cancellation carried by ctx
+func (t *smtpTransport) send(ctx context.Context, msg emailMessage) error {
+	m := msg.compose()
+	return t.session(ctx, func(ctx context.Context, client *smtp.Client) error {
+		from, recipients, err := envelope(m)
+		if err != nil {
+			return err
+		}
+
+		if err := client.Mail(from); err != nil {
+			return t.wrap(ctx, "SMTP MAIL FROM failed", err)
+		}
+		for _, rcpt := range recipients {
+			if err := client.Rcpt(rcpt); err != nil {
+				return t.wrap(ctx, fmt.Sprintf("SMTP RCPT TO %s failed", rcpt), err)
+			}
+		}
+
+		w, err := client.Data()
+		if err != nil {
+			return t.wrap(ctx, "SMTP DATA failed", err)
+		}
+		if _, err := m.WriteTo(w); err != nil {
+			w.Close()
+			return t.wrap(ctx, "failed to write message", err)
+		}
+		if err := w.Close(); err != nil {
+			return t.wrap(ctx, "failed to complete message", err)
+		}
+		return nil
+	})
+}
+
+// probe connects, negotiates TLS and authenticates without sending a message
+func (t *smtpTransport) probe(ctx context.Context) error {
+	return t.session(ctx, func(context.Context, *smtp.Client) error { return nil })
+}
+
+// session connects to the relay, negotiates TLS and authenticates, runs fn on
+// the client and quits
+func (t *smtpTransport) session(ctx context.Context, fn func(ctx context.Context, client *smtp.Client) error) error {
+	ctx, cancel := context.WithTimeout(ctx, t.sendTimeout)
+	defer cancel()
+
+	// Fetch the token first so no connection sits idle while the identity provider answers
+	var token string
+	if t.tokens != nil {
+		var err error
+		if token, err = t.tokens.Token(ctx); err != nil {
+			return fmt.Errorf("failed to obtain SMTP OAuth2 token: %w", err)
+		}
+	}
+
+	netDialer := &net.Dialer{
+		Timeout:   t.connectTimeout,
+		LocalAddr: t.localAddr,
+	}
+
+	address := net.JoinHostPort(t.dialer.Host, strconv.Itoa(t.dialer.Port))
+	conn, err := netDialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	// Bound every read/write on the connection by the attempt deadline and
+	// tear the connection down early if the caller gives up
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("failed to set SMTP connection deadline: %w", err)
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if t.dialer.SSL {
+		conn = tls.Client(conn, t.dialer.TLSConfig)
+	}
+
+	client, err := smtp.NewClient(conn, t.dialer.Host)
+	if err != nil {
+		return t.wrap(ctx, "failed to create SMTP client", err)
+	}
+	defer client.Close()
+
+	if !t.dialer.SSL {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(t.dialer.TLSConfig); err != nil {
+				return t.wrap(ctx, "failed to start TLS", err)
+			}
+		}
+	}
+
+	if auth := t.auth(client, token); auth != nil {
+		if err := client.Auth(auth); err != nil {
+			if t.tokens != nil {
+				// The token may have been revoked early; fetch a fresh one next time
+				t.tokens.Invalidate()
+			}
+			return t.wrap(ctx, "SMTP authentication failed", err)
+		}
+	}
+
+	if err := fn(ctx, client); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// auth selects an authentication mechanism advertised by the server, mirroring
+// gomail's preference order; with OAuth2 configured it always uses XOAUTH2
+func (t *smtpTransport) auth(client *smtp.Client, token string) smtp.Auth {
+	if t.tokens != nil {
+		return &xoauth2Auth{username: t.dialer.Username, token: token}
+	}
+	if t.dialer.Auth != nil {
+		return t.dialer.Auth
+	}
+	if t.dialer.Username == "" {
+		return nil
+	}
+
+	ok, mechanisms := client.Extension("AUTH")
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case strings.Contains(mechanisms, "CRAM-MD5"):
+		return smtp.CRAMMD5Auth(t.dialer.Username, t.dialer.Password)
+	case strings.Contains(mechanisms, "LOGIN") && !strings.Contains(mechanisms, "PLAIN"):
+		return &loginAuth{username: t.dialer.Username, password: t.dialer.Password}
+	default:
+		return smtp.PlainAuth("", t.dialer.Username, t.dialer.Password, t.dialer.Host)
+	}
+}
+
+// wrap annotates err, reporting the context error instead when the attempt timed out or was cancelled
+func (t *smtpTransport) wrap(ctx context.Context, msg string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return fmt.Errorf("%s: send timed out after %s: %w", msg, t.sendTimeout, ctxErr)
+		}
+		return fmt.Errorf("%s: %w", msg, ctxErr)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// envelope extracts the sender and recipients from the message headers
+func envelope(m *gomail.Message) (string, []string, error) {
+	from := m.GetHeader("Sender")
+	if len(from) == 0 {
+		from = m.GetHeader("From")
+	}
+	if len(from) == 0 {
+		return "", nil, fmt.Errorf("message has no From header")
+	}
+
+	sender, err := mail.ParseAddress(from[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid From address %q: %w", from[0], err)
+	}
+
+	var recipients []string
+	for _, field := range []string{"To", "Cc", "Bcc"} {
+		for _, value := range m.GetHeader(field) {
+			addr, err := mail.ParseAddress(value)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid %s address %q: %w", field, value, err)
+			}
+			recipients = append(recipients, addr.Address)
+		}
+	}
+	if len(recipients) == 0 {
+		return "", nil, fmt.Errorf("message has no recipients")
+	}
+
+	return sender.Address, recipients, nil
+}
+
+// loginAuth implements the LOGIN authentication mechanism used by Office 365 and similar relays
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
+
+// xoauth2Auth implements the XOAUTH2 mechanism used by Microsoft 365 and Gmail
+type xoauth2Auth struct {
+	username string
+	token    string
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, fmt.Errorf("refusing to send an OAuth2 token over an unencrypted connection")
+	}
+	return "XOAUTH2", []byte("user=" + a.username + "\x01auth=Bearer " + a.token + "\x01\x01"), nil
+}
+
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	// The server sends its error details as a challenge; an empty reply gets the final error
+	return []byte{}, nil
+}
@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/cloudauth"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// maxSNSMessage bounds the message of a publish, leaving room in the 256KiB SNS
+// limit for its attributes
+const maxSNSMessage = 250 << 10
+
+// SNSNotifier publishes every event to an Amazon SNS topic, for Lambda functions,
+// SQS queues and other subscribers to react to
+type SNSNotifier struct {
+	config      *config.Config
+	client      *http.Client
+	credentials *cloudauth.AWSCredentialSource
+}
+
+// NewSNSNotifier creates an SNS notifier; credentials are resolved on first use
+func NewSNSNotifier(cfg *config.Config) *SNSNotifier {
+	client := &http.Client{Timeout: cfg.SNS.GetTimeout()}
+	return &SNSNotifier{
+		config:      cfg,
+		client:      client,
+		credentials: cloudauth.NewAWSCredentialSource(cfg.SNS.GetRegion(), client),
+	}
+}
+
+// SendNotification publishes the event with its identifying fields as message attributes.
+// FIFO topics get the object as message group, so the changes of one object stay in order.
+func (n *SNSNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	snsConfig := n.config.SNS
+	event = fitMessage(n.config, event, maxSNSMessage, func(e NotificationEvent) int {
+		return jsonSize(eventMessage(n.config, e, snsConfig.GetFormat()))
+	})
+	message, err := json.Marshal(eventMessage(n.config, event, snsConfig.GetFormat()))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {"2010-03-31"},
+		"TopicArn": {snsConfig.TopicARN},
+		"Message":  {string(message)},
+	}
+	attributes := messageAttributes(n.config, event)
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		prefix := "MessageAttributes.entry." + strconv.Itoa(i+1)
+		form.Set(prefix+".Name", name)
+		form.Set(prefix+".Value.DataType", "String")
+		form.Set(prefix+".Value.StringValue", attributes[name])
+	}
+	if strings.HasSuffix(snsConfig.TopicARN, ".fifo") {
+		id := event.ID
+		if id == "" {
+			id = newID()
+		}
+		group := clusterName(n.config, event) + "/" + event.ResourceKind + "/" + event.Ref()
+		if len(group) > 128 {
+			group = cloudauth.SHA256Hex([]byte(group)) // Group IDs are limited to 128 characters
+		}
+		form.Set("MessageGroupId", group)
+		form.Set("MessageDeduplicationId", id)
+	}
+
+	if err := n.post(ctx, []byte(form.Encode())); err != nil {
+		return err
+	}
+	eventLogger(event).Info("Published event to SNS", "topic", snsConfig.TopicARN)
+	return nil
+}
+
+// post signs and sends a Query API request, retrying transient failures
+func (n *SNSNotifier) post(ctx context.Context, body []byte) error {
+	const contentType = "application/x-www-form-urlencoded; charset=utf-8"
+	credentials, err := n.credentials.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := n.config.SNS.GetEndpoint() + "/"
+	headers, err := cloudauth.SignAWS(credentials, "sns", n.config.SNS.GetRegion(), http.MethodPost, endpoint, body, contentType)
+	if err != nil {
+		return err
+	}
+	return postWithRetries(ctx, n.client, endpoint, body, headers)
+}
+
+// TestConnection resolves the credentials, which assumes the IRSA role, and connects to the endpoint
+func (n *SNSNotifier) TestConnection(ctx context.Context) error {
+	if _, err := n.credentials.Credentials(ctx); err != nil {
+		return err
+	}
+	return dialURL(ctx, n.config.SNS.GetEndpoint())
+}
@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// SplunkOnCallNotifier opens a Splunk On-Call (VictorOps) incident per object, of a
+// message type from the event's severity, and recovers it when a resolving event arrives
+type SplunkOnCallNotifier struct {
+	config *config.Config
+	client *http.Client
+}
+
+// splunkOnCallAlert is a REST endpoint integration alert
+type splunkOnCallAlert struct {
+	MessageType       string `json:"message_type"`
+	EntityID          string `json:"entity_id"` // Incidents of the same entity are updated or recovered together
+	EntityDisplayName string `json:"entity_display_name"`
+	StateMessage      string `json:"state_message,omitempty"`
+	MonitoringTool    string `json:"monitoring_tool"`
+	Cluster           string `json:"cluster"`
+	Kind              string `json:"kind"`
+	Namespace         string `json:"namespace,omitempty"`
+	Name              string `json:"name"`
+	EventType         string `json:"event_type"`
+	EventID           string `json:"event_id,omitempty"`
+}
+
+// NewSplunkOnCallNotifier creates a new Splunk On-Call notifier
+func NewSplunkOnCallNotifier(cfg *config.Config) *SplunkOnCallNotifier {
+	return &SplunkOnCallNotifier{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.SplunkOnCall.GetTimeout()},
+	}
+}
+
+// SendNotification posts the event as an alert, or as a recovery for resolving events
+func (n *SplunkOnCallNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	splunk := n.config.SplunkOnCall
+	alert := splunkOnCallAlert{
+		MessageType:       splunkOnCallMessageType(event),
+		EntityID:          alertKey(n.config, event),
+		EntityDisplayName: alertTitle(n.config, event),
+		StateMessage:      alertDescription(n.config, event),
+		MonitoringTool:    alertSource,
+		Cluster:           clusterName(n.config, event),
+		Kind:              event.ResourceKind,
+		Namespace:         event.Namespace,
+		Name:              event.ResourceName,
+		EventType:         string(event.EventType),
+		EventID:           event.ID,
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/%s", splunk.GetEndpoint(), url.PathEscape(splunk.GetAPIKey()), url.PathEscape(splunk.RoutingKey))
+	if err := postJSON(ctx, n.client, endpoint, alert, nil); err != nil {
+		return fmt.Errorf("splunk on-call: %w", err)
+	}
+	eventLogger(event).Info("Sent Splunk On-Call alert", "entityID", alert.EntityID, "messageType", alert.MessageType)
+	return nil
+}
+
+// splunkOnCallMessageType maps a resolving event to a recovery and others to their severity
+func splunkOnCallMessageType(event NotificationEvent) string {
+	if resolvesAlert(event) {
+		return "RECOVERY"
+	}
+	switch event.Severity {
+	case config.SeverityCritical:
+		return "CRITICAL"
+	case config.SeverityWarning:
+		return "WARNING"
+	default:
+		return "INFO"
+	}
+}
+
+// TestConnection checks that the Splunk On-Call REST endpoint is reachable
+func (n *SplunkOnCallNotifier) TestConnection(ctx context.Context) error {
+	return dialURL(ctx, n.config.SplunkOnCall.GetEndpoint())
+}
@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+)
+
+// streamBuffer is how many events a subscriber may fall behind before it misses some
+const streamBuffer = 256
+
+// StreamEvent is an event as sent to stream subscribers
+type StreamEvent struct {
+	ID            string            `json:"id,omitempty"`
+	Cluster       string            `json:"cluster,omitempty"`
+	EventType     EventType         `json:"eventType"`
+	Severity      string            `json:"severity,omitempty"`
+	Kind          string            `json:"kind"`
+	Name          string            `json:"name"`
+	Namespace     string            `json:"namespace,omitempty"`
+	Time          time.Time         `json:"time"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+	ChangedFields []string          `json:"changedFields,omitempty"`
+	ChangedBy     string            `json:"changedBy,omitempty"`
+	Origin        string            `json:"origin,omitempty"`
+	Diff          []string          `json:"diff,omitempty"`
+	ImageChanges  []ImageChange     `json:"imageChanges,omitempty"`
+	Warnings      []string          `json:"warnings,omitempty"`
+	Summary       []string          `json:"summary,omitempty"`
+	Error         string            `json:"error,omitempty"` // Why notifying failed
+}
+
+// EventStream copies every event published to it to its subscribers, such as
+// clients of the event stream endpoint. Subscribers that fall behind miss events
+// instead of delaying the others.
+type EventStream struct {
+	mu          sync.Mutex
+	subscribers map[chan StreamEvent]struct{}
+	closed      bool
+}
+
+// NewEventStream creates a stream without subscribers
+func NewEventStream() *EventStream {
+	return &EventStream{subscribers: make(map[chan StreamEvent]struct{})}
+}
+
+// Publish sends a notified event and the error notifying returned, if any, to the subscribers
+func (s *EventStream) Publish(event NotificationEvent, err error) {
+	published := StreamEvent{
+		ID:            event.ID,
+		Cluster:       event.Cluster,
+		EventType:     event.EventType,
+		Severity:      event.Severity,
+		Kind:          event.ResourceKind,
+		Name:          event.ResourceName,
+		Namespace:     event.Namespace,
+		Time:          time.Now().UTC(),
+		Labels:        event.Labels,
+		Annotations:   event.Annotations,
+		ChangedFields: event.ChangedFields,
+		ChangedBy:     event.ChangedBy,
+		Origin:        event.Origin,
+		Diff:          event.Diff,
+		ImageChanges:  event.ImageChanges,
+		Warnings:      event.Warnings,
+		Summary:       event.Summary,
+	}
+	if err != nil {
+		published.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- published:
+		default:
+		}
+	}
+	s.mu.Unlock()
+}
+
+// Subscribe returns a channel receiving the events sent from now on and a function
+// ending the subscription. The channel is closed when either is done or the stream closes.
+func (s *EventStream) Subscribe() (<-chan StreamEvent, func()) {
+	subscriber := make(chan StreamEvent, streamBuffer)
+	s.mu.Lock()
+	if s.closed {
+		close(subscriber)
+	} else {
+		s.subscribers[subscriber] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	return subscriber, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[subscriber]; ok {
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Close ends every subscription, e.g. so open streams do not hold up server shutdown
+func (s *EventStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for subscriber := range s.subscribers {
+		delete(s.subscribers, subscriber)
+		close(subscriber)
+	}
+}
@@ -0,0 +1,264 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+)
+
+// TeamsMetrics tracks metrics for Teams notifications
+type TeamsMetrics struct {
+	MessagesSent   int64
+	MessagesFailed int64
+}
+
+// TeamsNotifier posts Adaptive Card messages to Microsoft Teams incoming webhooks
+type TeamsNotifier struct {
+	config  *config.Config
+	client  *http.Client
+	metrics *TeamsMetrics
+	limiter *sendLimiter
+	mu      sync.RWMutex
+}
+
+// NewTeamsNotifier creates a new Teams notifier
+func NewTeamsNotifier(cfg *config.Config) *TeamsNotifier {
+	return &TeamsNotifier{
+		config:  cfg,
+		client:  &http.Client{Timeout: cfg.Teams.GetTimeout()},
+		metrics: &TeamsMetrics{},
+		limiter: newSendLimiter(cfg.Teams.Concurrency),
+	}
+}
+
+// SendNotification posts the event to every webhook routed to the event's namespace
+func (n *TeamsNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	if !event.EventType.Valid() {
+		return nil
+	}
+
+	card := n.fitCard(event)
+	summary := event.EventType == EventBurstSummary || event.EventType == EventBaseline || event.EventType == EventReport
+
+	// Webhooks are posted to in parallel, within the concurrency limits
+	var wg sync.WaitGroup
+	errs := make([]error, len(n.config.Teams.Webhooks))
+	for i, webhook := range n.config.Teams.Webhooks {
+		// Summaries, baselines and reports span namespaces, so every webhook receives them
+		if !summary && len(webhook.Namespaces) > 0 && !config.MatchAny(webhook.Namespaces, event.Namespace) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, webhook config.TeamsWebhookConfig) {
+			defer exitcode.Recover()
+			defer wg.Done()
+			err := n.limiter.do(ctx, webhook.Name, func() error {
+				return postJSON(ctx, n.client, webhook.GetURL(), card, nil)
+			})
+			if err != nil {
+				n.mu.Lock()
+				n.metrics.MessagesFailed++
+				n.mu.Unlock()
+				errs[i] = fmt.Errorf("teams webhook %s: %w", webhook.Name, err)
+				return
+			}
+
+			n.mu.Lock()
+			n.metrics.MessagesSent++
+			n.mu.Unlock()
+			eventLogger(event).Info("Sent Teams notification", "webhook", webhook.Name)
+		}(i, webhook)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// fitCard builds the card, dropping diff sections to fit teams.maxMessageSize
+func (n *TeamsNotifier) fitCard(event NotificationEvent) map[string]interface{} {
+	return n.buildCard(fitMessage(n.config, event, n.config.Teams.GetMaxMessageSize(), func(e NotificationEvent) int {
+		return jsonSize(n.buildCard(e))
+	}))
+}
+
+// buildCard renders the event as a Teams message carrying an Adaptive Card
+func (n *TeamsNotifier) buildCard(event NotificationEvent) map[string]interface{} {
+	if event.EventType == EventBurstSummary {
+		return adaptiveCardMessage([]interface{}{
+			n.titleBlock("Attention", fmt.Sprintf("[%s] %d notifications suppressed by burst protection", clusterName(n.config, event), event.SuppressedEvents)),
+			map[string]interface{}{
+				"type": "TextBlock",
+				"wrap": true,
+				"text": strings.Join(event.Summary, "\n\n"),
+			},
+		})
+	}
+
+	facts := []map[string]string{
+		{"title": "Cluster", "value": clusterName(n.config, event)},
+		{"title": "Resource", "value": event.ResourceKind},
+		{"title": "Name", "value": event.ResourceName},
+		{"title": "Namespace", "value": displayNamespace(event)},
+		{"title": "Event", "value": string(event.EventType)},
+		{"title": "Time", "value": time.Now().Format(time.RFC3339)},
+	}
+	if event.Severity != "" {
+		facts = append(facts, map[string]string{"title": "Severity", "value": event.Severity})
+	}
+	if event.ID != "" {
+		facts = append(facts, map[string]string{"title": "Event ID", "value": event.ID})
+	}
+	if changedBy := describeChangedBy(event); changedBy != "" {
+		facts = append(facts, map[string]string{"title": "Changed by", "value": changedBy})
+	}
+	if len(event.ChangedFields) > 0 {
+		facts = append(facts, map[string]string{"title": "Changed fields", "value": strings.Join(event.ChangedFields, ", ")})
+	}
+	for _, change := range event.ImageChanges {
+		facts = append(facts, map[string]string{"title": "Image (" + change.Container + ")", "value": change.Old + " → " + change.New})
+	}
+
+	body := []interface{}{
+		n.titleBlock(teamsColor(event), fmt.Sprintf("[%s] %s %s was %s%s",
+			clusterName(n.config, event), event.ResourceKind, event.Ref(), event.EventType, imageHeadline(event))),
+		map[string]interface{}{
+			"type":  "FactSet",
+			"facts": facts,
+		},
+	}
+
+	if len(event.Summary) > 0 {
+		body = append(body, map[string]interface{}{
+			"type": "TextBlock",
+			"wrap": true,
+			"text": strings.Join(event.Summary, "\n\n"),
+		})
+	}
+
+	if len(event.Diff) > 0 {
+		body = append(body, map[string]interface{}{
+			"type":     "TextBlock",
+			"wrap":     true,
+			"fontType": "Monospace",
+			"text":     strings.Join(event.Diff, "\n\n"),
+		})
+	}
+
+	if len(event.Warnings) > 0 {
+		body = append(body, map[string]interface{}{
+			"type":  "TextBlock",
+			"wrap":  true,
+			"color": "Warning",
+			"text":  "⚠ " + strings.Join(event.Warnings, "\n\n⚠ "),
+		})
+	}
+
+	if event.SuppressedEvents > 0 {
+		body = append(body, map[string]interface{}{
+			"type":     "TextBlock",
+			"wrap":     true,
+			"isSubtle": true,
+			"text":     fmt.Sprintf("%d further events for this resource were suppressed by rate limiting since the last notification.", event.SuppressedEvents),
+		})
+	}
+
+	return adaptiveCardMessage(body)
+}
+
+// titleBlock renders the card title decorated with the cluster theme, on a
+// banner in the theme color when one is configured
+func (n *TeamsNotifier) titleBlock(color, text string) map[string]interface{} {
+	title := map[string]interface{}{
+		"type":   "TextBlock",
+		"size":   "Medium",
+		"weight": "Bolder",
+		"wrap":   true,
+		"color":  color,
+		"text":   n.config.Theme.Title(text),
+	}
+	if n.config.Theme.Color == "" {
+		return title
+	}
+	return map[string]interface{}{
+		"type":  "Container",
+		"style": n.config.Theme.Color,
+		"bleed": true,
+		"items": []interface{}{title},
+	}
+}
+
+// adaptiveCardMessage wraps Adaptive Card body elements in a Teams message
+func adaptiveCardMessage(body []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
+
+// teamsColor maps critical events and otherwise event types to Adaptive Card text colors
+func teamsColor(event NotificationEvent) string {
+	if event.Severity == config.SeverityCritical {
+		return "Attention"
+	}
+	switch event.EventType {
+	case EventDeleted, EventNamespaceDeleted, EventSelfAlert, EventAnomaly,
+		EventPodOOMKilled, EventPodCrashLoop, EventPodImagePullBackOff, EventHelmFailed, EventCertificateFailed:
+		return "Attention"
+	case EventModified, EventChangedWhileDisconnected, EventKubeEvent, EventHelmRolledBack,
+		EventCertificateExpiring:
+		return "Warning"
+	case EventAdded, EventHelmInstalled, EventHelmUpgraded, EventCertificateRenewed:
+		return "Good"
+	default:
+		return "Default"
+	}
+}
+
+// Preview returns the card that would be posted for the event and the webhooks it would go to
+func (n *TeamsNotifier) Preview(event NotificationEvent) interface{} {
+	webhooks := []string{}
+	for _, webhook := range n.config.Teams.Webhooks {
+		if event.EventType == EventBurstSummary || event.EventType == EventBaseline || event.EventType == EventReport || len(webhook.Namespaces) == 0 || config.MatchAny(webhook.Namespaces, event.Namespace) {
+			webhooks = append(webhooks, webhook.Name)
+		}
+	}
+	return map[string]interface{}{
+		"webhooks": webhooks,
+		"card":     n.fitCard(event),
+	}
+}
+
+// TestConnection checks that every webhook's host accepts connections
+func (n *TeamsNotifier) TestConnection(ctx context.Context) error {
+	for _, webhook := range n.config.Teams.Webhooks {
+		if err := dialURL(ctx, webhook.GetURL()); err != nil {
+			return fmt.Errorf("teams webhook %s: %w", webhook.Name, err)
+		}
+	}
+	return nil
+}
+
+// GetMetrics returns a copy of the current metrics
+func (n *TeamsNotifier) GetMetrics() TeamsMetrics {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return *n.metrics
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/templates"
+)
+
+// TemplateData is the set of variables available to notification templates, e.g.
+//
+//	[{{ .Cluster }}] {{ .Kind }} {{ .Namespace }}/{{ .Name }} owned by {{ index .Annotations "team.example.com/owner" | default "nobody" }}
+type TemplateData struct {
+	ID               string // Correlation ID shared with the watcher's log records
+	Cluster          string
+	Kind             string
+	Name             string
+	Namespace        string
+	EventType        string
+	Severity         string
+	Time             string
+	ChangedFields    []string
+	ChangedBy        string
+	Origin           string
+	Diff             []string
+	ImageChanges     []ImageChange
+	Summary          []string
+	Warnings         []string
+	SuppressedEvents int
+	Labels           map[string]string
+	Annotations      map[string]string
+}
+
+// newTemplateData builds template variables for an event
+func newTemplateData(clusterName string, event NotificationEvent) TemplateData {
+	labels := event.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := event.Annotations
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	return TemplateData{
+		ID:               event.ID,
+		Cluster:          clusterName,
+		Kind:             event.ResourceKind,
+		Name:             event.ResourceName,
+		Namespace:        event.Namespace,
+		EventType:        string(event.EventType),
+		Severity:         event.Severity,
+		Time:             time.Now().Format(time.RFC3339),
+		ChangedFields:    event.ChangedFields,
+		ChangedBy:        event.ChangedBy,
+		Origin:           event.Origin,
+		Diff:             event.Diff,
+		ImageChanges:     event.ImageChanges,
+		Summary:          event.Summary,
+		Warnings:         event.Warnings,
+		SuppressedEvents: event.SuppressedEvents,
+		Labels:           labels,
+		Annotations:      annotations,
+	}
+}
+
+// parseOptionalTemplate parses text, returning nil when no template is configured
+func parseOptionalTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return templates.ParseText(name, text)
+}
+
+// renderTemplate executes tmpl against data
+func renderTemplate(tmpl *template.Template, data TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// fitMessage returns the event reduced until size reports that its rendered message fits
+// in maxSize bytes: the largest diff sections go first, then the warnings. The summary is
+// kept, and a note says what was left out, linking to the full event when watcher.dashboardURL is set.
+func fitMessage(cfg *config.Config, event NotificationEvent, maxSize int, size func(NotificationEvent) int) NotificationEvent {
+	if size(event) <= maxSize {
+		return event
+	}
+
+	fitted := event
+	sections := diffSections(event.Diff)
+	for dropped := 1; len(sections) > 0 && size(fitted) > maxSize; dropped++ {
+		largest := 0
+		for i, section := range sections {
+			if sectionSize(section) > sectionSize(sections[largest]) {
+				largest = i
+			}
+		}
+		sections = append(sections[:largest:largest], sections[largest+1:]...)
+
+		var diff []string
+		for _, section := range sections {
+			diff = append(diff, section...)
+		}
+		fitted.Diff = append(diff, omittedNote(cfg, event, fmt.Sprintf("%d of %d changes", dropped, dropped+len(sections))))
+	}
+
+	if size(fitted) > maxSize && len(fitted.Warnings) > 0 {
+		fitted.Warnings = []string{omittedNote(cfg, event, fmt.Sprintf("%d warnings", len(event.Warnings)))}
+	}
+
+	if actual := size(fitted); actual > maxSize {
+		eventLogger(event).Warn("Notification is above the size limit with its diff left out", "size", actual, "maxSize", maxSize)
+	}
+	return fitted
+}
+
+// diffSections groups diff lines into changes: a top-level line and the indented lines below it
+func diffSections(diff []string) [][]string {
+	var sections [][]string
+	for _, line := range diff {
+		if len(sections) > 0 && strings.HasPrefix(line, " ") {
+			sections[len(sections)-1] = append(sections[len(sections)-1], line)
+			continue
+		}
+		sections = append(sections, []string{line})
+	}
+	return sections
+}
+
+func sectionSize(section []string) int {
+	size := 0
+	for _, line := range section {
+		size += len(line) + 1
+	}
+	return size
+}
+
+// omittedNote says what was left out of a message and where to find it
+func omittedNote(cfg *config.Config, event NotificationEvent, what string) string {
+	note := fmt.Sprintf("... %s omitted to fit the message size limit", what)
+	if link := eventLink(cfg, event); link != "" {
+		note += "; full event: " + link
+	}
+	return note
+}
+
+// eventLink returns the URL showing the full event: its record in the event history
+// when the store is enabled, else the dashboard. It is empty without watcher.dashboardURL.
+func eventLink(cfg *config.Config, event NotificationEvent) string {
+	base := strings.TrimSuffix(cfg.Watcher.DashboardURL, "/")
+	switch {
+	case base == "":
+		return ""
+	case cfg.Store.Enabled && event.ID != "":
+		return base + "/api/v1/events?id=" + url.QueryEscape(event.ID)
+	default:
+		return base + "/ui"
+	}
+}
+
+// jsonSize returns the encoded size of a payload, or 0 if it cannot be encoded
+func jsonSize(payload interface{}) int {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+func TestFitMessage(t *testing.T) {
+	diff := []string{
+		"~ spec.replicas: 3 -> 5",
+		"~ data.config: " + strings.Repeat("x", 200),
+		"  line 1",
+		"+ metadata.labels.team: payments",
+	}
+	event := NotificationEvent{
+		ID:       "abc",
+		Diff:     diff,
+		Warnings: []string{strings.Repeat("w", 100)},
+		Summary:  []string{"summary"},
+	}
+	size := func(e NotificationEvent) int {
+		return len(strings.Join(e.Diff, "\n")) + len(strings.Join(e.Warnings, "\n")) + len(strings.Join(e.Summary, "\n"))
+	}
+	linked := &config.Config{}
+	linked.Watcher.DashboardURL = "https://watcher.example.com/"
+	linked.Store.Enabled = true
+
+	tests := []struct {
+		name         string
+		cfg          *config.Config
+		maxSize      int
+		wantDiff     []string
+		wantWarnings []string
+	}{
+		{
+			name:         "fits",
+			cfg:          &config.Config{},
+			maxSize:      1000,
+			wantDiff:     diff,
+			wantWarnings: event.Warnings,
+		},
+		{
+			name:    "largest section dropped",
+			cfg:     &config.Config{},
+			maxSize: 250,
+			wantDiff: []string{
+				"~ spec.replicas: 3 -> 5",
+				"+ metadata.labels.team: payments",
+				"... 1 of 3 changes omitted to fit the message size limit",
+			},
+			wantWarnings: event.Warnings,
+		},
+		{
+			name:    "note links to the event history",
+			cfg:     linked,
+			maxSize: 320,
+			wantDiff: []string{
+				"~ spec.replicas: 3 -> 5",
+				"+ metadata.labels.team: payments",
+				"... 1 of 3 changes omitted to fit the message size limit; full event: https://watcher.example.com/api/v1/events?id=abc",
+			},
+			wantWarnings: event.Warnings,
+		},
+		{
+			name:         "warnings dropped after the diff",
+			cfg:          &config.Config{},
+			maxSize:      150,
+			wantDiff:     []string{"... 3 of 3 changes omitted to fit the message size limit"},
+			wantWarnings: []string{"... 1 warnings omitted to fit the message size limit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitMessage(tt.cfg, event, tt.maxSize, size)
+			if !reflect.DeepEqual(got.Diff, tt.wantDiff) {
+				t.Errorf("diff = %q, want %q", got.Diff, tt.wantDiff)
+			}
+			if !reflect.DeepEqual(got.Warnings, tt.wantWarnings) {
+				t.Errorf("warnings = %q, want %q", got.Warnings, tt.wantWarnings)
+			}
+			if !reflect.DeepEqual(got.Summary, event.Summary) {
+				t.Errorf("summary = %q, want it kept", got.Summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// WebhookMetrics tracks metrics for generic webhook notifications
+type WebhookMetrics struct {
+	RequestsSent   int64
+	RequestsFailed int64
+}
+
+// WebhookNotifier posts events as JSON to an HTTP endpoint, optionally
+// authenticating with OAuth2 client credentials
+type WebhookNotifier struct {
+	config  *config.Config
+	client  *http.Client
+	tokens  *clientCredentialsSource
+	metrics *WebhookMetrics
+	limiter *sendLimiter
+	mu      sync.RWMutex
+}
+
+// webhookPayload is the JSON document posted for each event
+type webhookPayload struct {
+	ID               string            `json:"id,omitempty"`
+	Cluster          string            `json:"cluster"`
+	EventType        EventType         `json:"eventType"`
+	Severity         string            `json:"severity,omitempty"`
+	Kind             string            `json:"kind"`
+	Name             string            `json:"name"`
+	Namespace        string            `json:"namespace,omitempty"`
+	Time             time.Time         `json:"time"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+	ChangedFields    []string          `json:"changedFields,omitempty"`
+	ChangedBy        string            `json:"changedBy,omitempty"`
+	Origin           string            `json:"origin,omitempty"`
+	Diff             []string          `json:"diff,omitempty"`
+	ImageChanges     []ImageChange     `json:"imageChanges,omitempty"`
+	Warnings         []string          `json:"warnings,omitempty"`
+	Summary          []string          `json:"summary,omitempty"`
+	SuppressedEvents int               `json:"suppressedEvents,omitempty"`
+	Markers          []Marker          `json:"markers,omitempty"`
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(cfg *config.Config) *WebhookNotifier {
+	client := &http.Client{Timeout: cfg.Webhook.GetTimeout()}
+	n := &WebhookNotifier{
+		config:  cfg,
+		client:  client,
+		metrics: &WebhookMetrics{},
+		limiter: newSendLimiter(cfg.Webhook.Concurrency),
+	}
+	if cfg.Webhook.OAuth2 != nil {
+		n.tokens = newClientCredentialsSource(cfg.Webhook.OAuth2, client)
+	}
+	return n
+}
+
+// SendNotification posts the event to the configured endpoint
+func (n *WebhookNotifier) SendNotification(ctx context.Context, event NotificationEvent) error {
+	headers := make(map[string]string, len(n.config.Webhook.Headers)+1)
+	for key, value := range n.config.Webhook.Headers {
+		headers[key] = value
+	}
+	if n.tokens != nil {
+		token, err := n.tokens.Token(ctx)
+		if err != nil {
+			n.recordFailure()
+			return fmt.Errorf("webhook: failed to obtain OAuth2 token: %w", err)
+		}
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	url := n.config.Webhook.GetURL()
+	err := n.limiter.do(ctx, url, func() error {
+		return postJSON(ctx, n.client, url, n.fitPayload(event), headers)
+	})
+	if err != nil {
+		n.recordFailure()
+		if n.tokens != nil {
+			// The token may have been revoked early; fetch a fresh one next time
+			n.tokens.Invalidate()
+		}
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	n.mu.Lock()
+	n.metrics.RequestsSent++
+	n.mu.Unlock()
+	eventLogger(event).Info("Sent webhook notification")
+	return nil
+}
+
+// fitPayload builds the payload, dropping diff sections to fit webhook.maxMessageSize
+func (n *WebhookNotifier) fitPayload(event NotificationEvent) webhookPayload {
+	return n.buildPayload(fitMessage(n.config, event, n.config.Webhook.GetMaxMessageSize(), func(e NotificationEvent) int {
+		return jsonSize(n.buildPayload(e))
+	}))
+}
+
+func (n *WebhookNotifier) buildPayload(event NotificationEvent) webhookPayload {
+	return newWebhookPayload(n.config, event)
+}
+
+// newWebhookPayload builds the JSON document describing an event, also published by the publisher
+func newWebhookPayload(cfg *config.Config, event NotificationEvent) webhookPayload {
+	return webhookPayload{
+		ID:               event.ID,
+		Cluster:          clusterName(cfg, event),
+		EventType:        event.EventType,
+		Severity:         event.Severity,
+		Kind:             event.ResourceKind,
+		Name:             event.ResourceName,
+		Namespace:        event.Namespace,
+		Time:             time.Now().UTC(),
+		Labels:           event.Labels,
+		Annotations:      event.Annotations,
+		ChangedFields:    event.ChangedFields,
+		ChangedBy:        event.ChangedBy,
+		Origin:           event.Origin,
+		Diff:             event.Diff,
+		ImageChanges:     event.ImageChanges,
+		Warnings:         event.Warnings,
+		Summary:          event.Summary,
+		SuppressedEvents: event.SuppressedEvents,
+		Markers:          event.Markers,
+	}
+}
+
+// Preview returns the payload that would be posted for the event
+func (n *WebhookNotifier) Preview(event NotificationEvent) interface{} {
+	return n.fitPayload(event)
+}
+
+// TestConnection fetches an OAuth2 token, when configured, and checks that the endpoint's host accepts connections
+func (n *WebhookNotifier) TestConnection(ctx context.Context) error {
+	if n.tokens != nil {
+		if _, err := n.tokens.Token(ctx); err != nil {
+			return fmt.Errorf("webhook OAuth2 token: %w", err)
+		}
+	}
+	if err := dialURL(ctx, n.config.Webhook.GetURL()); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) recordFailure() {
+	n.mu.Lock()
+	n.metrics.RequestsFailed++
+	n.mu.Unlock()
+}
@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"sort"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/celfilter"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// Event is the subset of a resource event that rules match against
+type Event struct {
+	Kind      string
+	Namespace string
+	Name      string
+	EventType string
+	Cluster   string
+
+	// Labels, Annotations and ChangedFields are only read by match expressions
+	Labels        map[string]string
+	Annotations   map[string]string
+	ChangedFields []string
+	ChangedBy     string
+	Origin        string
+}
+
+// Matches reports whether the event satisfies every non-empty criterion of m
+func Matches(m config.MatchConfig, event Event) bool {
+	if len(m.Kinds) > 0 && !contains(m.Kinds, event.Kind) {
+		return false
+	}
+	if len(m.EventTypes) > 0 && !contains(m.EventTypes, event.EventType) {
+		return false
+	}
+	if len(m.Namespaces) > 0 && !config.MatchAny(m.Namespaces, event.Namespace) {
+		return false
+	}
+	if len(m.Names) > 0 && !config.MatchAny(m.Names, event.Name) {
+		return false
+	}
+	if len(m.Clusters) > 0 && !config.MatchAny(m.Clusters, event.Cluster) {
+		return false
+	}
+	if len(m.ChangedBy) > 0 && (event.ChangedBy == "" || !config.MatchAny(m.ChangedBy, event.ChangedBy)) {
+		return false
+	}
+	if len(m.Origins) > 0 && !contains(m.Origins, event.Origin) {
+		return false
+	}
+	if m.Expression != "" && !matchesExpression(m.Expression, event) {
+		return false
+	}
+	return true
+}
+
+// matchesExpression evaluates a match expression; one that fails to evaluate does not match
+func matchesExpression(expression string, event Event) bool {
+	program, err := celfilter.Compile(expression)
+	if err != nil {
+		return false
+	}
+	matched, err := program.Match(celfilter.Input{
+		Event: celfilter.Event{
+			Type:          event.EventType,
+			Kind:          event.Kind,
+			Namespace:     event.Namespace,
+			Name:          event.Name,
+			Cluster:       event.Cluster,
+			ChangedFields: event.ChangedFields,
+			ChangedBy:     event.ChangedBy,
+			Origin:        event.Origin,
+		},
+		Object: celfilter.MetadataObject(event.Kind, event.Namespace, event.Name, event.Labels, event.Annotations),
+	})
+	return err == nil && matched
+}
+
+// Severity returns the severity of the first severity rule matching the event, or the default
+func Severity(cfg config.SeverityConfig, event Event) string {
+	for _, rule := range cfg.Rules {
+		if Matches(rule.Match, event) {
+			return rule.Severity
+		}
+	}
+	return cfg.GetDefault()
+}
+
+// RuleSet evaluates routing rules in a deterministic priority order
+type RuleSet struct {
+	Name             string
+	Mode             string
+	DefaultNotifiers []string
+	Rules            []config.RuleConfig
+}
+
+// Decision is the outcome of evaluating a ruleset against an event
+type Decision struct {
+	Notifiers    []string // Notifiers to deliver to, in rule order without duplicates
+	MatchedRules []string // Names of the rules that matched, in evaluation order
+	Dropped      bool     // A drop rule matched
+}
+
+// NewRuleSet sorts the configured rules by descending priority, keeping config order for ties
+func NewRuleSet(cfg config.RuleSetConfig) *RuleSet {
+	rules := append([]config.RuleConfig(nil), cfg.Rules...)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+
+	return &RuleSet{
+		Name:             cfg.Name,
+		Mode:             cfg.GetMode(),
+		DefaultNotifiers: cfg.DefaultNotifiers,
+		Rules:            rules,
+	}
+}
+
+// Evaluate applies the ruleset to an event.
+//
+// In first-match mode the highest-priority matching rule decides the outcome.
+// In all-match mode the notifiers of every matching rule are combined, unless
+// a matching drop rule is reached, which discards the event for this ruleset.
+// When nothing matches, the ruleset's default notifiers are used.
+func (r *RuleSet) Evaluate(event Event) Decision {
+	var decision Decision
+	seen := make(map[string]bool)
+
+	for _, rule := range r.Rules {
+		if !Matches(rule.Match, event) {
+			continue
+		}
+		decision.MatchedRules = append(decision.MatchedRules, rule.Name)
+
+		if rule.Action == config.RuleActionDrop {
+			decision.Notifiers = nil
+			decision.Dropped = true
+			return decision
+		}
+
+		for _, name := range rule.Notifiers {
+			if !seen[name] {
+				seen[name] = true
+				decision.Notifiers = append(decision.Notifiers, name)
+			}
+		}
+
+		if r.Mode == config.RoutingModeFirstMatch {
+			return decision
+		}
+	}
+
+	if len(decision.MatchedRules) == 0 {
+		decision.Notifiers = append(decision.Notifiers, r.DefaultNotifiers...)
+	}
+	return decision
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+func TestMatches(t *testing.T) {
+	event := Event{
+		Kind:      "Deployment",
+		Namespace: "payments-prod",
+		Name:      "api",
+		EventType: "MODIFIED",
+		Cluster:   "eu-1",
+		Labels:    map[string]string{"team": "payments"},
+		ChangedBy: "argocd-controller",
+		Origin:    config.ChangeOriginArgoCD,
+	}
+
+	tests := []struct {
+		name  string
+		match config.MatchConfig
+		want  bool
+	}{
+		{name: "empty matches everything", match: config.MatchConfig{}, want: true},
+		{name: "kind", match: config.MatchConfig{Kinds: []string{"ConfigMap", "Deployment"}}, want: true},
+		{name: "other kind", match: config.MatchConfig{Kinds: []string{"ConfigMap"}}, want: false},
+		{name: "event type", match: config.MatchConfig{EventTypes: []string{"DELETED"}}, want: false},
+		{name: "namespace pattern", match: config.MatchConfig{Namespaces: []string{"*-prod"}}, want: true},
+		{name: "namespace mismatch", match: config.MatchConfig{Namespaces: []string{"*-dev"}}, want: false},
+		{name: "name pattern", match: config.MatchConfig{Names: []string{"a*"}}, want: true},
+		{name: "cluster", match: config.MatchConfig{Clusters: []string{"us-*"}}, want: false},
+		{name: "changed by", match: config.MatchConfig{ChangedBy: []string{"argocd-*"}}, want: true},
+		{name: "origin", match: config.MatchConfig{Origins: []string{config.ChangeOriginFlux}}, want: false},
+		{name: "every criterion", match: config.MatchConfig{Kinds: []string{"Deployment"}, Namespaces: []string{"payments-*"}, EventTypes: []string{"MODIFIED"}}, want: true},
+		{name: "one criterion fails", match: config.MatchConfig{Kinds: []string{"Deployment"}, EventTypes: []string{"ADDED"}}, want: false},
+		{name: "expression", match: config.MatchConfig{Expression: `object.metadata.labels["team"] == "payments"`}, want: true},
+		{name: "false expression", match: config.MatchConfig{Expression: `event.type == "DELETED"`}, want: false},
+		{name: "invalid expression", match: config.MatchConfig{Expression: `event.type ==`}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.match, event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesUnknownOrigin(t *testing.T) {
+	deletion := Event{Kind: "Secret", EventType: "DELETED"}
+	if Matches(config.MatchConfig{ChangedBy: []string{"*"}}, deletion) {
+		t.Error("changedBy matched an event without a field manager")
+	}
+}
+
+func TestRuleSetEvaluate(t *testing.T) {
+	prodRule := config.RuleConfig{Name: "prod", Match: config.MatchConfig{Namespaces: []string{"prod"}}, Notifiers: []string{"teams"}}
+	secretRule := config.RuleConfig{Name: "secrets", Match: config.MatchConfig{Kinds: []string{"Secret"}}, Notifiers: []string{"email", "teams"}}
+	dropRule := config.RuleConfig{Name: "drop-sandbox", Match: config.MatchConfig{Namespaces: []string{"sandbox"}}, Action: config.RuleActionDrop}
+
+	tests := []struct {
+		name    string
+		ruleset config.RuleSetConfig
+		event   Event
+		want    Decision
+	}{
+		{
+			name:    "first match",
+			ruleset: config.RuleSetConfig{Rules: []config.RuleConfig{prodRule, secretRule}},
+			event:   Event{Kind: "Secret", Namespace: "prod"},
+			want:    Decision{Notifiers: []string{"teams"}, MatchedRules: []string{"prod"}},
+		},
+		{
+			name:    "priority before config order",
+			ruleset: config.RuleSetConfig{Rules: []config.RuleConfig{prodRule, withPriority(secretRule, 10)}},
+			event:   Event{Kind: "Secret", Namespace: "prod"},
+			want:    Decision{Notifiers: []string{"email", "teams"}, MatchedRules: []string{"secrets"}},
+		},
+		{
+			name:    "all match combines notifiers once",
+			ruleset: config.RuleSetConfig{Mode: config.RoutingModeAllMatch, Rules: []config.RuleConfig{prodRule, secretRule}},
+			event:   Event{Kind: "Secret", Namespace: "prod"},
+			want:    Decision{Notifiers: []string{"teams", "email"}, MatchedRules: []string{"prod", "secrets"}},
+		},
+		{
+			name:    "drop",
+			ruleset: config.RuleSetConfig{Mode: config.RoutingModeAllMatch, Rules: []config.RuleConfig{secretRule, dropRule}},
+			event:   Event{Kind: "Secret", Namespace: "sandbox"},
+			want:    Decision{MatchedRules: []string{"secrets", "drop-sandbox"}, Dropped: true},
+		},
+		{
+			name:    "defaults when nothing matches",
+			ruleset: config.RuleSetConfig{DefaultNotifiers: []string{"email"}, Rules: []config.RuleConfig{prodRule}},
+			event:   Event{Kind: "ConfigMap", Namespace: "dev"},
+			want:    Decision{Notifiers: []string{"email"}},
+		},
+		{
+			name:    "no defaults",
+			ruleset: config.RuleSetConfig{Rules: []config.RuleConfig{prodRule}},
+			event:   Event{Kind: "ConfigMap", Namespace: "dev"},
+			want:    Decision{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRuleSet(tt.ruleset).Evaluate(tt.event); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	cfg := config.SeverityConfig{
+		Default: config.SeverityInfo,
+		Rules: []config.SeverityRuleConfig{
+			{Name: "prod deletions", Match: config.MatchConfig{Namespaces: []string{"prod"}, EventTypes: []string{"DELETED"}}, Severity: config.SeverityCritical},
+			{Name: "prod", Match: config.MatchConfig{Namespaces: []string{"prod"}}, Severity: config.SeverityWarning},
+		},
+	}
+
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{event: Event{Namespace: "prod", EventType: "DELETED"}, want: config.SeverityCritical},
+		{event: Event{Namespace: "prod", EventType: "MODIFIED"}, want: config.SeverityWarning},
+		{event: Event{Namespace: "dev", EventType: "DELETED"}, want: config.SeverityInfo},
+	}
+
+	for _, tt := range tests {
+		if got := Severity(cfg, tt.event); got != tt.want {
+			t.Errorf("Severity(%+v) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
+
+func withPriority(rule config.RuleConfig, priority int) config.RuleConfig {
+	rule.Priority = priority
+	return rule
+}
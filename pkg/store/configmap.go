@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
+)
+
+// ConfigMapStorage keeps every document as a key of one ConfigMap, so state
+// survives restarts without a PersistentVolume. ConfigMaps hold at most 1 MiB.
+type ConfigMapStorage struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// OpenConfigMapStorage stores documents in the named ConfigMap of the cluster
+// the watcher runs in, which is created on the first write
+func OpenConfigMapStorage(namespace, name string, restConfig *rest.Config) (*ConfigMapStorage, error) {
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return NewConfigMapStorage(client, namespace, name), nil
+}
+
+// NewConfigMapStorage stores documents in the named ConfigMap using client
+func NewConfigMapStorage(client kubernetes.Interface, namespace, name string) *ConfigMapStorage {
+	return &ConfigMapStorage{client: client, namespace: namespace, name: name}
+}
+
+// Get reads the document stored under key
+func (s *ConfigMapStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	configMap, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	value, ok := configMap.Data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return []byte(value), nil
+}
+
+// Put replaces the document stored under key, creating the ConfigMap if needed
+func (s *ConfigMapStorage) Put(ctx context.Context, key string, value []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	return s.update(ctx, func(data map[string]string) { data[key] = string(value) })
+}
+
+// Delete removes the document stored under key, if any
+func (s *ConfigMapStorage) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	return s.update(ctx, func(data map[string]string) { delete(data, key) })
+}
+
+// update applies fn to the ConfigMap's data, retrying when another writer got there first
+func (s *ConfigMapStorage) update(ctx context.Context, fn func(data map[string]string)) error {
+	configMaps := s.client.CoreV1().ConfigMaps(s.namespace)
+	conflict := func(err error) bool { return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) }
+
+	err := retry.OnError(retry.DefaultRetry, conflict, func() error {
+		configMap, err := configMaps.Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.name,
+					Namespace: s.namespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "k8s-resource-watcher"},
+				},
+				Data: make(map[string]string),
+			}
+			fn(configMap.Data)
+			_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		fn(configMap.Data)
+		_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	return nil
+}
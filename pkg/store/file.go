@@ -0,0 +1,370 @@
+package store
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRecordSize bounds a single stored line; larger records are rejected on write
+const maxRecordSize = 1 << 20
+
+// FileRetention bounds the history a FileStore keeps; zero sizes and ages keep everything
+type FileRetention struct {
+	MaxSize  int64         // Bytes the file may reach before it is rotated
+	MaxAge   time.Duration // Age after which records are dropped
+	MaxFiles int           // Rotated files kept, at least one
+}
+
+// enabled reports whether the file is ever rotated
+func (r FileRetention) enabled() bool {
+	return r.MaxSize > 0 || r.MaxAge > 0
+}
+
+// FileStore appends records as JSON lines to a local file, e.g. on a PersistentVolume.
+// With retention, the file is rotated to <path>.1, <path>.2, ... with the newest first.
+type FileStore struct {
+	path      string
+	retention FileRetention
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64     // Bytes in the file
+	oldest time.Time // Time of the file's first record; zero while it is empty
+}
+
+// OpenFileStore opens the file at path for appending, creating it and its directory if needed
+func OpenFileStore(path string, retention FileRetention) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event store: %w", err)
+	}
+	if err := writeStateInfo(path); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if retention.MaxFiles < 1 {
+		retention.MaxFiles = 1
+	}
+	s := &FileStore{path: path, retention: retention, file: file}
+	if err := s.readState(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	s.prune()
+	return s, nil
+}
+
+// rotatedPath returns the path of the index-th newest rotated file
+func rotatedPath(path string, index int) string {
+	return path + "." + strconv.Itoa(index)
+}
+
+// readState sets the size of the open file and the time of its first record
+func (s *FileStore) readState() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read event store: %w", err)
+	}
+	s.size = info.Size()
+	if s.size == 0 {
+		return nil
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open event store: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	var record Record
+	if scanner.Scan() && json.Unmarshal(scanner.Bytes(), &record) == nil {
+		s.oldest = record.Time
+	} else {
+		// Age the file from now rather than never rotating it
+		s.oldest = time.Now()
+	}
+	return nil
+}
+
+// Append writes the record as one line, first rotating the file if retention says so
+func (s *FileStore) Append(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	if len(data) >= maxRecordSize {
+		return fmt.Errorf("record %s is too large to store (%d bytes)", record.ID, len(data))
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rotationDue(int64(len(data))) {
+		if err := s.rotate(); err != nil {
+			// Keep appending to the current file rather than lose the record
+			slog.Warn("Failed to rotate event store", "path", s.path, "error", err)
+		}
+	}
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	if s.oldest.IsZero() {
+		s.oldest = record.Time
+	}
+	return nil
+}
+
+// rotationDue reports whether the file is to be rotated before appending size bytes
+func (s *FileStore) rotationDue(size int64) bool {
+	if s.size == 0 {
+		return false
+	}
+	if s.retention.MaxSize > 0 && s.size+size > s.retention.MaxSize {
+		return true
+	}
+	return s.retention.MaxAge > 0 && time.Now().Sub(s.oldest) > s.retention.MaxAge
+}
+
+// rotate renames the file to <path>.1, shifting the rotated files up and deleting the
+// oldest past MaxFiles, and starts a new file
+func (s *FileStore) rotate() error {
+	os.Remove(rotatedPath(s.path, s.retention.MaxFiles))
+	for i := s.retention.MaxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(rotatedPath(s.path, i), rotatedPath(s.path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate event store: %w", err)
+		}
+	}
+	if err := os.Rename(s.path, rotatedPath(s.path, 1)); err != nil {
+		return fmt.Errorf("failed to rotate event store: %w", err)
+	}
+	// The records are in <path>.1 now, whether or not a new file can be opened
+	s.size, s.oldest = 0, time.Time{}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open event store: %w", err)
+	}
+	s.file.Close()
+	s.file = file
+	s.prune()
+	return nil
+}
+
+// prune deletes the rotated files past MaxFiles, e.g. left by a larger maxFiles before a
+// restart, and those whose last record is older than MaxAge
+func (s *FileStore) prune() {
+	if !s.retention.enabled() {
+		return
+	}
+	for i := s.retention.MaxFiles + 1; ; i++ {
+		if err := os.Remove(rotatedPath(s.path, i)); err != nil {
+			break
+		}
+	}
+	if s.retention.MaxAge <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-s.retention.MaxAge)
+	for i := 1; i <= s.retention.MaxFiles; i++ {
+		path := rotatedPath(s.path, i)
+		if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				slog.Warn("Failed to delete expired event store file", "path", path, "error", err)
+			}
+		}
+	}
+}
+
+// Query scans the file and its rotated predecessors and returns matching records, newest
+// first. Only the records up to the requested page are held while scanning.
+func (s *FileStore) Query(ctx context.Context, filter Filter) ([]Record, error) {
+	files, err := s.openFiles()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	if s.retention.MaxAge > 0 {
+		if cutoff := time.Now().Add(-s.retention.MaxAge); filter.Since.Before(cutoff) {
+			filter.Since = cutoff
+		}
+	}
+
+	page := &recordPage{size: filter.Offset + filter.Limit}
+	if filter.Limit == 0 {
+		page.size = 0
+	}
+	for index, file := range files {
+		// A file holds no record newer than its last write, and older files even less so.
+		// File times are coarse though, and may trail the time of the last record a little.
+		if info, err := file.Stat(); err == nil {
+			newest := info.ModTime().Add(time.Second)
+			if newest.Before(filter.Since) || page.full() && newest.Before(page.last().Time) {
+				break
+			}
+		}
+		if err := s.scan(ctx, file, index, filter, page); err != nil {
+			return nil, err
+		}
+	}
+	return page.records(filter.Offset), nil
+}
+
+// openFiles opens the file and the rotated ones, newest first. They are opened under the
+// lock, so a rotation cannot move records from one to the next during a query.
+func (s *FileStore) openFiles() ([]*os.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var files []*os.File
+	for i := 0; i <= s.retention.MaxFiles; i++ {
+		path := s.path
+		if i > 0 {
+			path = rotatedPath(s.path, i)
+		}
+		file, err := os.Open(path)
+		if i > 0 && errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			for _, file := range files {
+				file.Close()
+			}
+			return nil, fmt.Errorf("failed to open event store: %w", err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// scan adds the matching records of one file to the page
+func (s *FileStore) scan(ctx context.Context, file *os.File, index int, filter Filter, page *recordPage) error {
+	// Writes append whole lines under the lock, so reading without it only
+	// risks missing a record that is being written
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// A crash can leave a truncated last line; skip it rather than fail every query
+			slog.Warn("Skipping unreadable event store record", "path", file.Name(), "line", line, "error", err)
+			continue
+		}
+		if filter.Matches(record) {
+			page.add(pageEntry{record: record, file: index, line: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event store: %w", err)
+	}
+	return nil
+}
+
+// pageEntry is a matching record and where it was read
+type pageEntry struct {
+	record Record
+	file   int // 0 for the current file, higher for older rotated ones
+	line   int
+}
+
+// before reports whether e comes before other in query results: newer records first, and
+// records of the same time in the order they were written
+func (e pageEntry) before(other pageEntry) bool {
+	if !e.record.Time.Equal(other.record.Time) {
+		return e.record.Time.After(other.record.Time)
+	}
+	if e.file != other.file {
+		return e.file > other.file
+	}
+	return e.line < other.line
+}
+
+// recordPage collects the first size query results as records stream by, in a heap with
+// the last of them on top; a size of 0 keeps every record
+type recordPage struct {
+	size    int
+	entries []pageEntry
+}
+
+func (p *recordPage) Len() int           { return len(p.entries) }
+func (p *recordPage) Less(i, j int) bool { return p.entries[j].before(p.entries[i]) }
+func (p *recordPage) Swap(i, j int)      { p.entries[i], p.entries[j] = p.entries[j], p.entries[i] }
+func (p *recordPage) Push(x interface{}) { p.entries = append(p.entries, x.(pageEntry)) }
+
+func (p *recordPage) Pop() interface{} {
+	last := p.entries[len(p.entries)-1]
+	p.entries = p.entries[:len(p.entries)-1]
+	return last
+}
+
+// add keeps the entry if it is among the first size results so far
+func (p *recordPage) add(entry pageEntry) {
+	switch {
+	case p.size == 0:
+		p.entries = append(p.entries, entry)
+	case len(p.entries) < p.size:
+		heap.Push(p, entry)
+	case entry.before(p.entries[0]):
+		p.entries[0] = entry
+		heap.Fix(p, 0)
+	}
+}
+
+// full reports whether later records only make it into the page by being newer than the last
+func (p *recordPage) full() bool {
+	return p.size > 0 && len(p.entries) == p.size
+}
+
+// last returns the record that comes last in a full page
+func (p *recordPage) last() Record {
+	return p.entries[0].record
+}
+
+// records returns the collected records in result order, skipping the first offset
+func (p *recordPage) records(offset int) []Record {
+	sort.Slice(p.entries, func(i, j int) bool { return p.entries[i].before(p.entries[j]) })
+	if offset >= len(p.entries) {
+		return nil
+	}
+	records := make([]Record, 0, len(p.entries)-offset)
+	for _, entry := range p.entries[offset:] {
+		records = append(records, entry.record)
+	}
+	return records
+}
+
+// Close flushes and closes the file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileStoreQueryPages(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now().UTC().Add(-time.Hour)
+
+	// Small enough to rotate every few records, so the pages span files
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	s, err := OpenFileStore(path, FileRetention{MaxSize: 400, MaxFiles: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for i := 0; i < 20; i++ {
+		kind := "ConfigMap"
+		if i%2 == 1 {
+			kind = "Secret"
+		}
+		record := Record{ID: fmt.Sprint(i), Time: start.Add(time.Duration(i) * time.Minute), Kind: kind, Name: "app", Status: StatusSent}
+		if err := s.Append(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err != nil {
+		t.Fatalf("file was not rotated: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "first page", filter: Filter{Limit: 3}, want: []string{"19", "18", "17"}},
+		{name: "page in rotated files", filter: Filter{Offset: 12, Limit: 3}, want: []string{"7", "6", "5"}},
+		{name: "last page", filter: Filter{Offset: 18, Limit: 5}, want: []string{"1", "0"}},
+		{name: "past the end", filter: Filter{Offset: 20, Limit: 5}, want: nil},
+		{name: "filtered", filter: Filter{Kind: "Secret", Offset: 1, Limit: 2}, want: []string{"17", "15"}},
+		{name: "no limit", filter: Filter{Kind: "ConfigMap", Offset: 7}, want: []string{"4", "2", "0"}},
+		{name: "since", filter: Filter{Since: start.Add(17 * time.Minute)}, want: []string{"19", "18", "17"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := s.Query(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, record := range records {
+				ids = append(ids, record.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Query() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStoreRetention(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	written := time.Now().Add(-2 * time.Hour)
+
+	s, err := OpenFileStore(path, FileRetention{MaxSize: 300, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := s.Append(ctx, Record{ID: fmt.Sprint(i), Time: written.Add(time.Duration(i) * time.Millisecond), Kind: "ConfigMap", Name: "app"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("file past maxFiles kept: %v", err)
+	}
+	records, err := s.Query(ctx, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) == 0 || len(records) == 20 || records[0].ID != "19" {
+		t.Errorf("Query() returned %d records starting at %v, want the newest few", len(records), records)
+	}
+	s.Close()
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if err := os.Chtimes(name, written, written); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reopened with an age limit, the records written earlier have all expired
+	s, err = OpenFileStore(path, FileRetention{MaxAge: time.Hour, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := os.Stat(path + ".2"); !os.IsNotExist(err) {
+		t.Errorf("expired rotated file kept: %v", err)
+	}
+	if err := s.Append(ctx, Record{ID: "new", Time: time.Now(), Kind: "ConfigMap", Name: "app"}); err != nil {
+		t.Fatal(err)
+	}
+	records, err = s.Query(ctx, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ID != "new" {
+		t.Errorf("Query() = %v, want only the record written after the others expired", records)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("expired file kept after rotation: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/kubeconfig"
+)
+
+// ErrNotFound is returned by Storage.Get for keys that were never written or were deleted
+var ErrNotFound = errors.New("key not found")
+
+// Storage keeps small named documents, such as the active silences, across
+// restarts so each stateful feature does not need its own persistence.
+// Keys must be valid ConfigMap keys. Implementations must be safe for concurrent use.
+type Storage interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// OpenStorage creates the state storage selected by the configuration
+func OpenStorage(watcherCfg *config.Config) (Storage, error) {
+	cfg := watcherCfg.Storage
+	switch cfg.GetDriver() {
+	case config.StorageDriverMemory:
+		return NewMemoryStorage(), nil
+	case config.StorageDriverFile:
+		return OpenDirStorage(cfg.Path)
+	case config.StorageDriverConfigMap:
+		restConfig, err := kubeconfig.Local(watcherCfg.LocalKubeconfig, watcherCfg.LocalContext)
+		if err != nil {
+			return nil, err
+		}
+		restConfig.UserAgent = watcherCfg.Client.GetUserAgent()
+		return OpenConfigMapStorage(cfg.GetNamespace(), cfg.ConfigMap, restConfig)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+}
+
+// checkKey rejects keys that are not valid ConfigMap keys, which also keeps them safe as file names
+func checkKey(key string) error {
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		return fmt.Errorf("invalid storage key %q: %s", key, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// MemoryStorage keeps documents in memory only; they are lost on restart
+type MemoryStorage struct {
+	mu        sync.RWMutex
+	documents map[string][]byte
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{documents: make(map[string][]byte)}
+}
+
+// Get returns a copy of the document stored under key
+func (s *MemoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.documents[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Put stores a copy of value under key
+func (s *MemoryStorage) Put(ctx context.Context, key string, value []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete removes the document stored under key, if any
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.documents, key)
+	return nil
+}
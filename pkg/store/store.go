@@ -0,0 +1,92 @@
+// Package store persists processed resource events so the history survives restarts
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// Notification statuses recorded with each event
+const (
+	StatusSent     = "sent"
+	StatusFailed   = "failed"
+	StatusRecorded = "recorded" // Kept in the history only, without being notified
+)
+
+// Record is one processed resource event and the outcome of its notification
+type Record struct {
+	ID            string    `json:"id"`
+	Time          time.Time `json:"time"`
+	Cluster       string    `json:"cluster"`
+	EventType     string    `json:"eventType"`
+	Severity      string    `json:"severity,omitempty"`
+	Kind          string    `json:"kind"`
+	Namespace     string    `json:"namespace,omitempty"`
+	Name          string    `json:"name"`
+	ChangedFields []string  `json:"changedFields,omitempty"`
+	ChangedBy     string    `json:"changedBy,omitempty"`
+	Origin        string    `json:"origin,omitempty"`
+	Diff          []string  `json:"diff,omitempty"`
+	Warnings      []string  `json:"warnings,omitempty"`
+	Summary       []string  `json:"summary,omitempty"`
+	Status        string    `json:"status"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Filter selects records; zero fields match everything
+type Filter struct {
+	ID        string
+	Kind      string
+	Namespace string
+	Name      string
+	EventType string
+	Status    string
+	Since     time.Time
+	Until     time.Time
+	Offset    int // Matching records to skip, newest first
+	Limit     int // Maximum number of records returned; 0 means no limit
+}
+
+// Matches reports whether the record satisfies every set criterion of f
+func (f Filter) Matches(r Record) bool {
+	switch {
+	case f.ID != "" && r.ID != f.ID:
+		return false
+	case f.Kind != "" && r.Kind != f.Kind:
+		return false
+	case f.Namespace != "" && r.Namespace != f.Namespace:
+		return false
+	case f.Name != "" && r.Name != f.Name:
+		return false
+	case f.EventType != "" && r.EventType != f.EventType:
+		return false
+	case f.Status != "" && r.Status != f.Status:
+		return false
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Time.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// Store records events. Implementations must be safe for concurrent use.
+type Store interface {
+	Append(ctx context.Context, record Record) error
+	// Query returns matching records, newest first
+	Query(ctx context.Context, filter Filter) ([]Record, error)
+	Close() error
+}
+
+// Open creates the store selected by the configuration
+func Open(cfg config.StoreConfig) (Store, error) {
+	switch cfg.GetDriver() {
+	case config.StoreDriverFile:
+		return OpenFileStore(cfg.Path, FileRetention{MaxSize: int64(cfg.MaxSize), MaxAge: cfg.MaxAge, MaxFiles: cfg.GetMaxFiles()})
+	default:
+		return nil, fmt.Errorf("unsupported store driver %q", cfg.Driver)
+	}
+}
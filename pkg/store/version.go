@@ -0,0 +1,60 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/version"
+)
+
+// StateVersion is the persisted record format written by this binary. Bump it
+// when older binaries can no longer read what newer ones write.
+const StateVersion = 1
+
+// StateInfo describes who last wrote the persisted state
+type StateInfo struct {
+	StateVersion  int    `json:"stateVersion"`
+	BinaryVersion string `json:"binaryVersion,omitempty"`
+}
+
+// stateInfoPath is the sidecar file recording the state version of a file store
+func stateInfoPath(path string) string {
+	return path + ".version"
+}
+
+// ReadStateInfo returns the version of the persisted state. State written
+// before versioning, or not written yet, reports version 1.
+func ReadStateInfo(cfg config.StoreConfig) (StateInfo, error) {
+	if cfg.GetDriver() != config.StoreDriverFile {
+		return StateInfo{}, fmt.Errorf("unsupported store driver %q", cfg.Driver)
+	}
+
+	data, err := os.ReadFile(stateInfoPath(cfg.Path))
+	if errors.Is(err, os.ErrNotExist) {
+		return StateInfo{StateVersion: 1}, nil
+	}
+	if err != nil {
+		return StateInfo{}, fmt.Errorf("failed to read state version: %w", err)
+	}
+
+	var info StateInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return StateInfo{}, fmt.Errorf("failed to parse state version: %w", err)
+	}
+	return info, nil
+}
+
+// writeStateInfo records that this binary writes the state at path
+func writeStateInfo(path string) error {
+	data, err := json.Marshal(StateInfo{StateVersion: StateVersion, BinaryVersion: version.Version})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(stateInfoPath(path), data, 0o640); err != nil {
+		return fmt.Errorf("failed to write state version: %w", err)
+	}
+	return nil
+}
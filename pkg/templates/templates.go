@@ -0,0 +1,34 @@
+package templates
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+// Funcs returns the helper functions available to notification templates
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		// default returns def when value is empty: {{ index .Labels "env" | default "unknown" }}
+		"default": func(def, value string) string {
+			if value == "" {
+				return def
+			}
+			return value
+		},
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// ParseHTML parses an HTML template with the notification helper functions
+// registered; values are escaped for the context they appear in
+func ParseHTML(name, text string) (*htmltemplate.Template, error) {
+	return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(Funcs())).Option("missingkey=zero").Parse(text)
+}
+
+// ParseText parses a text template with the notification helper functions registered
+func ParseText(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs()).Option("missingkey=zero").Parse(text)
+}
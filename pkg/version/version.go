@@ -0,0 +1,6 @@
+// Package version identifies the running build
+package version
+
+// Version is the release the binary was built from, set at build time with
+// -ldflags "-X github.com/jimohabdol/k8s-resource-watcher/pkg/version.Version=v1.2.3"
+var Version = "dev"
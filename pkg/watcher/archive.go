@@ -0,0 +1,201 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/archive"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// archiveObserved is the event type of the version an object had before its first change
+// since the watcher started. It is skipped when it is the newest version in the archive.
+const archiveObserved notifier.EventType = "OBSERVED"
+
+// archiveHandler archives every version of the objects the resource entries of a shared
+// informer select. It sees the events before the entries' filter expressions, change
+// checks, deduplication, silences and routing, so versions that are not notified are kept too.
+type archiveHandler struct {
+	watcher *InformerWatcher
+	kind    string
+	entries []config.ResourceConfig // Only added before the informer runs
+
+	mu       sync.Mutex
+	observed map[string]bool // Objects whose version from before the watcher started was archived
+}
+
+// archivesEntry reports whether the objects of a resource entry are archived. Pods and
+// Events only raise alerts, and Helm release entries read Secrets of another entry's kind.
+func (w *InformerWatcher) archivesEntry(resourceConfig config.ResourceConfig) bool {
+	if w.archive == nil || !w.config.Archive.Archives(resourceConfig.Kind) {
+		return false
+	}
+	return resourceConfig.Kind != "Pod" && resourceConfig.Kind != "Event" && !resourceConfig.HelmReleases
+}
+
+// archiveEntry adds a resource entry to the archive handler of its shared informer,
+// registering the handler with the informer on first use
+func (w *InformerWatcher) archiveEntry(shared *sharedInformer, resourceConfig config.ResourceConfig) {
+	if shared.archiver == nil {
+		shared.archiver = &archiveHandler{watcher: w, kind: shared.kind, observed: make(map[string]bool)}
+		shared.handlers = append(shared.handlers, w.trackInFlight(shared.archiver))
+	}
+	shared.archiver.entries = append(shared.archiver.entries, resourceConfig)
+}
+
+func (h *archiveHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	if object, ok := h.selects(obj); ok && h.watcher.started() {
+		h.observe(object)
+		h.publish(notifier.EventAdded, object, time.Now())
+	}
+}
+
+// OnUpdate archives the new version of a changed object. On the first change since the
+// watcher started, the version before it is archived too, as that is the one to restore
+// when the change was a mistake.
+func (h *archiveHandler) OnUpdate(oldObj, newObj interface{}) {
+	oldObject, okOld := oldObj.(metav1.Object)
+	newObject, okNew := h.selects(newObj)
+	if !okOld || !okNew || !h.watcher.started() || oldObject.GetResourceVersion() == newObject.GetResourceVersion() {
+		return
+	}
+
+	now := time.Now()
+	if !h.observe(newObject) {
+		h.publish(archiveObserved, oldObject, versionTime(oldObject, now))
+	}
+	h.publish(notifier.EventModified, newObject, now)
+}
+
+func (h *archiveHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if object, ok := h.selects(obj); ok && h.watcher.started() {
+		h.mu.Lock()
+		delete(h.observed, objectKey(object))
+		h.mu.Unlock()
+		h.publish(notifier.EventDeleted, object, time.Now())
+	}
+}
+
+// observe records that an object's earlier versions need no archiving, reporting whether
+// that was known already
+func (h *archiveHandler) observe(obj metav1.Object) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := objectKey(obj)
+	if h.observed[key] {
+		return true
+	}
+	h.observed[key] = true
+	return false
+}
+
+// selects returns the object if any of the entries selects it by namespace, name and
+// ownership, whatever its filter expression says
+func (h *archiveHandler) selects(obj interface{}) (metav1.Object, bool) {
+	for _, entry := range h.entries {
+		switch typed := obj.(type) {
+		case *unstructured.Unstructured:
+			if h.watcher.shouldProcessResource(typed, entry) {
+				return typed, true
+			}
+		case *appsv1.Deployment:
+			if h.watcher.shouldProcessDeployment(typed, entry) {
+				return typed, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// publish queues an object version for the archive, which uploads on its own worker so a
+// slow bucket never delays notifications
+func (h *archiveHandler) publish(eventType notifier.EventType, obj metav1.Object, at time.Time) {
+	logger := h.watcher.logger.With("kind", h.kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+	content, err := filterContent(obj)
+	if err != nil || content == nil {
+		logger.Warn("Failed to convert object for the archive", "error", err)
+		return
+	}
+	// Typed objects leave out their kind and API version
+	if _, ok := content["kind"]; !ok {
+		if resource, ok := supportedKinds[h.kind]; ok {
+			content["apiVersion"] = resource.gvr.GroupVersion().String()
+			content["kind"] = h.kind
+		}
+	}
+	h.watcher.bus.publish(topicArchive, busMessage{
+		event: notifier.NotificationEvent{
+			EventType:    eventType,
+			ResourceKind: h.kind,
+			ResourceName: obj.GetName(),
+			Namespace:    obj.GetNamespace(),
+		},
+		object: content,
+		at:     at,
+	})
+}
+
+// versionTime estimates when an object got its current version: the time of its latest
+// managed fields entry, else its creation. It is kept before now, so the version sorts
+// before the change that replaced it.
+func versionTime(obj metav1.Object, now time.Time) time.Time {
+	at := obj.GetCreationTimestamp().Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(at) {
+			at = entry.Time.Time
+		}
+	}
+	if at.IsZero() || !at.Before(now) {
+		at = now.Add(-time.Millisecond)
+	}
+	return at
+}
+
+// archiveManifest uploads the manifest of a queued object version
+func (w *InformerWatcher) archiveManifest(message busMessage) {
+	event := message.event
+	logger := w.logger.With("kind", event.ResourceKind, "namespace", event.Namespace, "name", event.ResourceName)
+
+	// Stopping must not lose the last versions, so don't use the watcher context
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.Archive.GetTimeout())
+	defer cancel()
+
+	manifest, err := archive.Manifest(message.object, w.config.Archive.SecretValues)
+	if err == nil && event.EventType == archiveObserved && w.isLatestVersion(ctx, event, manifest) {
+		logger.Debug("Version before the change is archived already")
+		return
+	}
+	if err == nil {
+		key := archive.Key(w.config.ClusterName, event.Namespace, event.ResourceKind, event.ResourceName, message.at, string(event.EventType))
+		err = w.archive.Put(ctx, key, manifest)
+		if err == nil {
+			logger.Debug("Archived manifest", "location", w.archive.Location(), "key", key)
+		}
+	}
+	if err != nil {
+		logger.Warn("Failed to archive manifest", "location", w.archive.Location(), "error", err)
+	}
+	w.metrics.RecordManifestArchived(err)
+}
+
+// isLatestVersion reports whether the manifest is the newest archived version of the
+// event's object, e.g. archived before the watcher restarted
+func (w *InformerWatcher) isLatestVersion(ctx context.Context, event notifier.NotificationEvent, manifest []byte) bool {
+	versions, err := archive.Versions(ctx, w.archive, w.config.ClusterName, event.Namespace, event.ResourceKind, event.ResourceName)
+	if err != nil || len(versions) == 0 {
+		return false
+	}
+	latest, err := w.archive.Get(ctx, versions[0].Key)
+	return err == nil && bytes.Equal(latest, manifest)
+}
@@ -0,0 +1,240 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// autoscalerKinds are the autoscaler kinds whose spec edits are notified and whose status
+// updates, current replicas and recommendations, are not
+var autoscalerKinds = map[string]bool{"HorizontalPodAutoscaler": true, "VerticalPodAutoscaler": true}
+
+// handleAutoscalerUpdated notifies a MODIFIED HPA or VPA only when its spec changed; the
+// autoscaler controllers rewrite status on every sync
+func (w *InformerWatcher) handleAutoscalerUpdated(trace *EventTrace, resourceKind string, oldObj, newObj *unstructured.Unstructured) {
+	changedFields, notify := w.specChanges(trace, resourceKind, oldObj, newObj)
+	if !notify {
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED")
+	w.sendNotificationWithSummary(trace, resourceKind, notifier.EventModified, newObj, changedFields,
+		autoscalerDiff(resourceKind, oldObj, newObj), autoscalerSummary(resourceKind, newObj))
+}
+
+// autoscalerDiff renders the spec changes between two versions of an HPA or VPA, e.g.
+// "~ maxReplicas: 10 -> 20" or "+ metric: Resource cpu Utilization 70%". Either object may
+// be nil for ADDED and DELETED events.
+func autoscalerDiff(kind string, oldObj, newObj *unstructured.Unstructured) []string {
+	switch kind {
+	case "HorizontalPodAutoscaler":
+		var oldHPA, newHPA autoscalingv2.HorizontalPodAutoscaler
+		if !convertObject(oldObj, &oldHPA) || !convertObject(newObj, &newHPA) {
+			return nil
+		}
+		var before, after map[string]string
+		var beforeMetrics, afterMetrics, beforePolicies, afterPolicies []string
+		if oldObj != nil {
+			before, beforeMetrics, beforePolicies = describeHPA(&oldHPA)
+		}
+		if newObj != nil {
+			after, afterMetrics, afterPolicies = describeHPA(&newHPA)
+		}
+		lines := diffFields(before, after)
+		lines = append(lines, diffLines("metric", beforeMetrics, afterMetrics)...)
+		return append(lines, diffLines("behavior", beforePolicies, afterPolicies)...)
+	case "VerticalPodAutoscaler":
+		var before, after map[string]string
+		var beforePolicies, afterPolicies []string
+		if oldObj != nil {
+			before, beforePolicies = describeVPA(oldObj)
+		}
+		if newObj != nil {
+			after, afterPolicies = describeVPA(newObj)
+		}
+		return append(diffFields(before, after), diffLines("containerPolicy", beforePolicies, afterPolicies)...)
+	}
+	return nil
+}
+
+// autoscalerSummary gives an HPA's replica counts, so a changed range can be read against them
+func autoscalerSummary(kind string, obj *unstructured.Unstructured) []string {
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	if kind != "HorizontalPodAutoscaler" || !convertObject(obj, &hpa) || hpa.Status.DesiredReplicas == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("Replicas: %d current, %d desired", hpa.Status.CurrentReplicas, hpa.Status.DesiredReplicas)}
+}
+
+// describeHPA returns an HPA's scalar settings, its metrics and its scaling behavior policies
+func describeHPA(hpa *autoscalingv2.HorizontalPodAutoscaler) (map[string]string, []string, []string) {
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	fields := map[string]string{
+		"scaleTargetRef": hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name,
+		"minReplicas":    fmt.Sprint(minReplicas),
+		"maxReplicas":    fmt.Sprint(hpa.Spec.MaxReplicas),
+	}
+
+	metrics := make([]string, 0, len(hpa.Spec.Metrics))
+	for _, metric := range hpa.Spec.Metrics {
+		metrics = append(metrics, describeMetric(metric))
+	}
+
+	var policies []string
+	if behavior := hpa.Spec.Behavior; behavior != nil {
+		for direction, rules := range map[string]*autoscalingv2.HPAScalingRules{"scaleUp": behavior.ScaleUp, "scaleDown": behavior.ScaleDown} {
+			if rules == nil {
+				continue
+			}
+			if rules.StabilizationWindowSeconds != nil {
+				fields["behavior."+direction+".stabilizationWindowSeconds"] = fmt.Sprint(*rules.StabilizationWindowSeconds)
+			}
+			if rules.SelectPolicy != nil {
+				fields["behavior."+direction+".selectPolicy"] = string(*rules.SelectPolicy)
+			}
+			for _, policy := range rules.Policies {
+				policies = append(policies, fmt.Sprintf("%s %d %s per %ds", direction, policy.Value, policy.Type, policy.PeriodSeconds))
+			}
+		}
+	}
+	return fields, metrics, policies
+}
+
+// describeMetric renders a metric and its target, e.g. "Resource cpu Utilization 70%"
+func describeMetric(metric autoscalingv2.MetricSpec) string {
+	var subject string
+	var target autoscalingv2.MetricTarget
+	switch {
+	case metric.Resource != nil:
+		subject, target = string(metric.Resource.Name), metric.Resource.Target
+	case metric.ContainerResource != nil:
+		subject, target = metric.ContainerResource.Container+"/"+string(metric.ContainerResource.Name), metric.ContainerResource.Target
+	case metric.Pods != nil:
+		subject, target = metric.Pods.Metric.Name, metric.Pods.Target
+	case metric.Object != nil:
+		subject = metric.Object.DescribedObject.Kind + "/" + metric.Object.DescribedObject.Name + " " + metric.Object.Metric.Name
+		target = metric.Object.Target
+	case metric.External != nil:
+		subject, target = metric.External.Metric.Name, metric.External.Target
+	}
+
+	line := string(metric.Type) + " " + subject + " " + string(target.Type)
+	switch {
+	case target.AverageUtilization != nil:
+		line += fmt.Sprintf(" %d%%", *target.AverageUtilization)
+	case target.AverageValue != nil:
+		line += " " + target.AverageValue.String()
+	case target.Value != nil:
+		line += " " + target.Value.String()
+	}
+	return line
+}
+
+// describeVPA returns a VPA's scalar settings and a line per container policy. VPA is a CRD,
+// so it is read from the unstructured object.
+func describeVPA(obj *unstructured.Unstructured) (map[string]string, []string) {
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "targetRef", "name")
+	updateMode, _, _ := unstructured.NestedString(obj.Object, "spec", "updatePolicy", "updateMode")
+	if updateMode == "" {
+		updateMode = "Auto"
+	}
+	fields := map[string]string{
+		"targetRef":  kind + "/" + name,
+		"updateMode": updateMode,
+	}
+	if minReplicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "updatePolicy", "minReplicas"); found {
+		fields["minReplicas"] = fmt.Sprint(minReplicas)
+	}
+	if recommenders, found, _ := unstructured.NestedSlice(obj.Object, "spec", "recommenders"); found {
+		var names []string
+		for _, recommender := range recommenders {
+			if recommender, ok := recommender.(map[string]interface{}); ok {
+				names = append(names, fmt.Sprint(recommender["name"]))
+			}
+		}
+		fields["recommenders"] = strings.Join(names, ",")
+	}
+
+	containerPolicies, _, _ := unstructured.NestedSlice(obj.Object, "spec", "resourcePolicy", "containerPolicies")
+	policies := make([]string, 0, len(containerPolicies))
+	for _, policy := range containerPolicies {
+		policy, ok := policy.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parts := []string{fmt.Sprint(policy["containerName"])}
+		for _, key := range []string{"mode", "controlledValues"} {
+			if value, ok := policy[key].(string); ok {
+				parts = append(parts, key+"="+value)
+			}
+		}
+		for _, key := range []string{"minAllowed", "maxAllowed"} {
+			if resources, ok := policy[key].(map[string]interface{}); ok {
+				parts = append(parts, key+"="+describeResourceMap(resources))
+			}
+		}
+		if resources, ok := policy["controlledResources"].([]interface{}); ok {
+			names := make([]string, len(resources))
+			for i, resource := range resources {
+				names[i] = fmt.Sprint(resource)
+			}
+			parts = append(parts, "controlledResources=["+strings.Join(names, ",")+"]")
+		}
+		policies = append(policies, strings.Join(parts, " "))
+	}
+	return fields, policies
+}
+
+// describeResourceMap renders resource amounts as "cpu:100m,memory:128Mi"
+func describeResourceMap(resources map[string]interface{}) string {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s:%v", name, resources[name])
+	}
+	return strings.Join(parts, ",")
+}
+
+// diffFields reports settings only in before as removed, only in after as added and
+// differing ones as changed, sorted by name
+func diffFields(before, after map[string]string) []string {
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		oldValue, hadOld := before[name]
+		newValue, hasNew := after[name]
+		switch {
+		case !hadOld:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", name, newValue))
+		case !hasNew:
+			lines = append(lines, fmt.Sprintf("- %s: %s", name, oldValue))
+		case oldValue != newValue:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", name, oldValue, newValue))
+		}
+	}
+	return lines
+}
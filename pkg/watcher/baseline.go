@@ -0,0 +1,143 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/celfilter"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/store"
+)
+
+// sendBaseline sends or records the BASELINE report of what each resource entry matches in
+// the synced caches. Recorded-only reports go to the event store without being notified.
+func (w *InformerWatcher) sendBaseline() {
+	baseline := w.config.Watcher.Baseline
+	trace := w.traces.Start("baseline", "Watcher", notifier.EventBaseline, "", "baseline")
+	event := notifier.NotificationEvent{
+		ID:           trace.ID,
+		Cluster:      w.config.ClusterName,
+		EventType:    notifier.EventBaseline,
+		Severity:     config.SeverityInfo,
+		ResourceKind: "Watcher",
+		ResourceName: "baseline",
+		Summary:      w.baselineReport(baseline.GetMaxObjects()),
+	}
+
+	if baseline.Notify {
+		w.deliver(trace, event)
+		return
+	}
+	w.appendRecord(w.redactor.RedactAll(event), store.StatusRecorded, nil)
+	trace.Step(StageSent, "recorded in the event store")
+	w.traces.Finish(trace, "recorded")
+}
+
+// baselineReport lists the objects each resource entry matches, in config order, with at
+// most maxObjects names per entry
+func (w *InformerWatcher) baselineReport(maxObjects int) []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	summary := []string{fmt.Sprintf("Objects matched by the %d resource entries once the caches synced:", len(w.config.Resources))}
+	for i, resourceConfig := range w.config.Resources {
+		heading := fmt.Sprintf("resources[%d] (%s)", i, resourceConfig.Describe())
+		if resourceConfig.Kind == "Event" {
+			summary = append(summary, heading+": Events are not inventoried")
+			continue
+		}
+		objects, ok := w.matchedObjects(resourceConfig)
+		if !ok {
+			summary = append(summary, heading+": not cached, the kind is served by the raw watch engine")
+			continue
+		}
+
+		summary = append(summary, fmt.Sprintf("%s: %d matched", heading, len(objects)))
+		for j, object := range objects {
+			if j == maxObjects {
+				summary = append(summary, fmt.Sprintf("  ... and %d more", len(objects)-maxObjects))
+				break
+			}
+			summary = append(summary, "  - "+object)
+		}
+	}
+	return summary
+}
+
+// matchedObjects returns the sorted references of the cached objects a resource entry
+// matches, using the checks its notifications go through; false if the entry has no cache.
+// Callers hold w.mu.
+func (w *InformerWatcher) matchedObjects(resourceConfig config.ResourceConfig) ([]string, bool) {
+	informerStore, ok := w.informerStore(resourceConfig)
+	if !ok {
+		return nil, false
+	}
+
+	var objects []string
+	for _, obj := range informerStore.List() {
+		switch obj := obj.(type) {
+		case *appsv1.Deployment:
+			if w.shouldProcessDeployment(obj, resourceConfig) {
+				objects = append(objects, objectRef(obj.Namespace, obj.Name))
+			}
+		case *unstructured.Unstructured:
+			if resourceConfig.HelmReleases {
+				if release, ok := w.cachedRelease(obj, resourceConfig); ok {
+					objects = append(objects, release)
+				}
+			} else if w.shouldProcessResource(obj, resourceConfig) && w.filterMatches(obj, resourceConfig) {
+				objects = append(objects, objectRef(obj.GetNamespace(), obj.GetName()))
+			}
+		}
+	}
+	sort.Strings(objects)
+	return objects, true
+}
+
+// cachedRelease describes the deployed revision of a Helm release from its release Secret,
+// e.g. "production/checkout (revision 4)"
+func (w *InformerWatcher) cachedRelease(secret *unstructured.Unstructured, resourceConfig config.ResourceConfig) (string, bool) {
+	if secretType, _, _ := unstructured.NestedString(secret.Object, "type"); secretType != helmReleaseType || isIgnored(secret) {
+		return "", false
+	}
+	labels := secret.GetLabels()
+	if labels["status"] != "deployed" || !w.matchesResourceConfig(secret.GetNamespace(), labels["name"], resourceConfig) {
+		return "", false
+	}
+	return fmt.Sprintf("%s (revision %s)", objectRef(secret.GetNamespace(), labels["name"]), labels["version"]), true
+}
+
+// filterMatches evaluates the entry's filter expression as for an ADDED event of the object;
+// expressions that fail to evaluate do not match, as for notifications
+func (w *InformerWatcher) filterMatches(obj *unstructured.Unstructured, resourceConfig config.ResourceConfig) bool {
+	if resourceConfig.Filter == "" {
+		return true
+	}
+	program, err := celfilter.Compile(resourceConfig.Filter)
+	if err != nil {
+		return false
+	}
+	matched, err := program.Match(celfilter.Input{
+		Event: celfilter.Event{
+			Type:      string(notifier.EventAdded),
+			Kind:      resourceConfig.Kind,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Cluster:   w.config.ClusterName,
+		},
+		Object: obj.Object,
+	})
+	return err == nil && matched
+}
+
+// objectRef returns namespace/name, or just the name for cluster-scoped objects
+func objectRef(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
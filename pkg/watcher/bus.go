@@ -0,0 +1,251 @@
+package watcher
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// Event bus topics
+const (
+	topicNotify    = "notify"    // Events to hand to the notifiers
+	topicDelivered = "delivered" // Events the notifiers are done with, and the outcome
+	topicArchive   = "archive"   // Object versions whose manifests are archived
+)
+
+// busCloseTimeout bounds how long Stop waits for subscribers to work through their queues
+const busCloseTimeout = 10 * time.Second
+
+// busMessage is an event published on the bus
+type busMessage struct {
+	trace *EventTrace
+	event notifier.NotificationEvent
+	err   error // Why notifying failed; topicDelivered only
+
+	object map[string]interface{} // Content of the event's object; topicArchive only
+	at     time.Time              // When the object got this version; topicArchive only
+}
+
+// BusSubscriberStats describes a subscriber of the internal event bus
+type BusSubscriberStats struct {
+	Name     string `json:"name"`
+	Topic    string `json:"topic"`
+	Workers  int    `json:"workers"`
+	Queued   int    `json:"queued"`   // Events waiting to be handled
+	Capacity int    `json:"capacity"` // Events that may wait before publishing blocks or drops
+	Dropped  int64  `json:"dropped"`  // Events missed because the queue was full
+}
+
+// busSubscriber handles the events of one topic with its own queues and workers,
+// so a slow subscriber only ever delays itself
+type busSubscriber struct {
+	name   string
+	topic  string
+	block  bool // Make publishers wait for queue space instead of dropping
+	queues []chan busMessage
+	handle func(busMessage)
+
+	mu      sync.Mutex
+	dropped int64
+}
+
+// eventBus fans the watcher's events out to independent subscribers
+type eventBus struct {
+	mu          sync.RWMutex
+	subscribers []*busSubscriber
+	closed      bool
+	closing     chan struct{} // Closed first on close, releasing publishers waiting for queue space
+	workers     sync.WaitGroup
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{closing: make(chan struct{})}
+}
+
+// subscribe starts handling topic's events with workers goroutines, each queueing up to
+// buffer events. Events of one object always go to the same worker, so they stay in order.
+func (b *eventBus) subscribe(topic, name string, workers, buffer int, block bool, handle func(busMessage)) {
+	subscriber := &busSubscriber{
+		name:   name,
+		topic:  topic,
+		block:  block,
+		queues: make([]chan busMessage, workers),
+		handle: handle,
+	}
+	for i := range subscriber.queues {
+		subscriber.queues[i] = make(chan busMessage, buffer)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.subscribers = append(b.subscribers, subscriber)
+	for _, queue := range subscriber.queues {
+		b.workers.Add(1)
+		go func(queue chan busMessage) {
+			defer exitcode.Recover()
+			defer b.workers.Done()
+			for message := range queue {
+				subscriber.handle(message)
+			}
+		}(queue)
+	}
+}
+
+// publish queues message for every subscriber of topic. It returns false once the bus
+// is closing, when blocking subscribers may not have received the message.
+func (b *eventBus) publish(topic string, message busMessage) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return false
+	}
+	published := true
+	for _, subscriber := range b.subscribers {
+		if subscriber.topic == topic && !subscriber.enqueue(message, b.closing) {
+			published = false
+		}
+	}
+	return published
+}
+
+// enqueue hands message to the worker of its object, dropping it if that worker's queue is
+// full and the subscriber does not block, or returning false if closing is closed first
+func (s *busSubscriber) enqueue(message busMessage, closing <-chan struct{}) bool {
+	queue := s.queues[0]
+	if len(s.queues) > 1 {
+		hash := fnv.New32a()
+		hash.Write([]byte(message.event.ResourceKind + "/" + message.event.Ref()))
+		queue = s.queues[hash.Sum32()%uint32(len(s.queues))]
+	}
+	if s.block {
+		select {
+		case queue <- message:
+			return true
+		case <-closing:
+			return false
+		}
+	}
+
+	select {
+	case queue <- message:
+	default:
+		s.mu.Lock()
+		s.dropped++
+		dropped := s.dropped
+		s.mu.Unlock()
+		// Log the first drop and every hundredth after it rather than each one
+		if dropped%100 == 1 {
+			slog.Warn("Event bus subscriber is falling behind", "subscriber", s.name, "dropped", dropped)
+		}
+	}
+	return true
+}
+
+// close stops accepting events and waits until ctx is done for the subscribers
+// to handle the ones already queued, returning how many were left
+func (b *eventBus) close(ctx context.Context) int {
+	select {
+	case <-b.closing:
+	default:
+		close(b.closing)
+	}
+
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, subscriber := range b.subscribers {
+			for _, queue := range subscriber.queues {
+				close(queue)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+		remaining := 0
+		for _, stats := range b.stats() {
+			remaining += stats.Queued
+		}
+		return remaining
+	}
+}
+
+// stats returns the queue and drop counts of every subscriber, by topic and name
+func (b *eventBus) stats() []BusSubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stats := make([]BusSubscriberStats, 0, len(b.subscribers))
+	for _, subscriber := range b.subscribers {
+		entry := BusSubscriberStats{
+			Name:    subscriber.name,
+			Topic:   subscriber.topic,
+			Workers: len(subscriber.queues),
+		}
+		for _, queue := range subscriber.queues {
+			entry.Queued += len(queue)
+			entry.Capacity += cap(queue)
+		}
+		subscriber.mu.Lock()
+		entry.Dropped = subscriber.dropped
+		subscriber.mu.Unlock()
+		stats = append(stats, entry)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Topic > stats[j].Topic // notify before delivered
+	})
+	return stats
+}
+
+// Subscribe calls handle with every event the notifiers are done with and the error
+// notifying returned, if any. Each subscriber has its own queue of the configured
+// eventBus.queueSize; a subscriber falling further behind misses events instead of
+// delaying notifications or other subscribers.
+func (w *InformerWatcher) Subscribe(name string, handle func(event notifier.NotificationEvent, err error)) {
+	w.bus.subscribe(topicDelivered, name, 1, w.config.Watcher.EventBus.GetQueueSize(), false, func(message busMessage) {
+		handle(message.event, message.err)
+	})
+}
+
+// subscribeConsumers registers the watcher's own consumers of events: the notifiers,
+// and the metrics and history recorders of their outcome
+func (w *InformerWatcher) subscribeConsumers() {
+	busConfig := w.config.Watcher.EventBus
+	w.bus.subscribe(topicNotify, "notifiers", busConfig.GetNotifierWorkers(), busConfig.GetQueueSize(), true, w.notify)
+
+	w.Subscribe("metrics", func(event notifier.NotificationEvent, err error) {
+		if err != nil {
+			w.metrics.RecordNotificationFailed()
+		} else {
+			w.metrics.RecordNotificationSent()
+		}
+	})
+	if w.eventStore != nil {
+		// The history is meant to be complete, so it holds the notifiers up instead of missing events
+		w.bus.subscribe(topicDelivered, "history", 1, busConfig.GetQueueSize(), true, func(message busMessage) {
+			w.recordEvent(message.event, message.err)
+		})
+	}
+	if len(w.config.Watcher.Reports) > 0 {
+		w.Subscribe("reports", w.recordReportChange)
+	}
+	if w.archive != nil {
+		w.bus.subscribe(topicArchive, "archive", 1, busConfig.GetQueueSize(), false, w.archiveManifest)
+	}
+}
@@ -0,0 +1,354 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// certificateListTimeout bounds listing Certificates for expiry checks without an informer cache
+const certificateListTimeout = 30 * time.Second
+
+// certificateTracker remembers which Certificates were alerted on, so each expiry is
+// alerted once per certificate version and a renewal can resolve the alert
+type certificateTracker struct {
+	mu       sync.Mutex
+	expiring map[string]string // namespace/name -> notAfter alerted as expiring
+	failed   map[string]bool   // namespace/name -> issuance failed since the last renewal
+}
+
+func newCertificateTracker() *certificateTracker {
+	return &certificateTracker{expiring: make(map[string]string), failed: make(map[string]bool)}
+}
+
+// createCertificateEventHandler notifies cert-manager Certificates: failed issuances as
+// CERTIFICATE_FAILED, renewals after an alert as CERTIFICATE_RENEWED, and spec edits as
+// MODIFIED. The alerts are sent whatever the entry's eventTypes.
+func (w *InformerWatcher) createCertificateEventHandler(resourceConfig config.ResourceConfig) cache.ResourceEventHandlerFuncs {
+	handler := w.createResourceEventHandler(resourceConfig, "Certificate")
+	handler.UpdateFunc = func(oldObj, newObj interface{}) {
+		if !w.started() {
+			return
+		}
+		w.handleCertificateUpdated(oldObj, newObj, resourceConfig)
+	}
+	return handler
+}
+
+func (w *InformerWatcher) handleCertificateUpdated(oldObj, newObj interface{}, resourceConfig config.ResourceConfig) {
+	oldUnstructured, okOld := oldObj.(*unstructured.Unstructured)
+	newUnstructured, okNew := newObj.(*unstructured.Unstructured)
+	if !okOld || !okNew {
+		w.logger.Warn("Failed to convert object to unstructured", "kind", "Certificate")
+		return
+	}
+
+	if w.shouldProcessResource(newUnstructured, resourceConfig) {
+		key := objectKey(newUnstructured)
+		if changedFields, diff, failed := certificateFailure(oldUnstructured, newUnstructured); failed {
+			w.certificates.mu.Lock()
+			w.certificates.failed[key] = true
+			w.certificates.mu.Unlock()
+			w.sendCertificateAlert(resourceConfig, notifier.EventCertificateFailed, newUnstructured, changedFields, diff, certificateSummary(newUnstructured))
+		}
+
+		oldNotAfter, _, _ := unstructured.NestedString(oldUnstructured.Object, "status", "notAfter")
+		newNotAfter, _, _ := unstructured.NestedString(newUnstructured.Object, "status", "notAfter")
+		if oldNotAfter != "" && newNotAfter != "" && oldNotAfter != newNotAfter {
+			w.certificates.mu.Lock()
+			_, wasExpiring := w.certificates.expiring[key]
+			wasFailed := w.certificates.failed[key]
+			delete(w.certificates.expiring, key)
+			delete(w.certificates.failed, key)
+			w.certificates.mu.Unlock()
+			if wasExpiring || wasFailed {
+				w.sendCertificateAlert(resourceConfig, notifier.EventCertificateRenewed, newUnstructured, []string{"status.notAfter"},
+					[]string{fmt.Sprintf("~ notAfter: %s -> %s", oldNotAfter, newNotAfter)}, nil)
+			}
+		}
+	}
+
+	if resourceConfig.NotifiesEventType(string(notifier.EventModified)) {
+		w.handleResourceUpdated(oldObj, newObj, resourceConfig, "Certificate")
+	}
+}
+
+// handleCertificateSpecUpdated notifies a MODIFIED Certificate only when its spec changed;
+// cert-manager updates its status on every issuance
+func (w *InformerWatcher) handleCertificateSpecUpdated(trace *EventTrace, oldObj, newObj *unstructured.Unstructured) {
+	changedFields, notify := w.specChanges(trace, "Certificate", oldObj, newObj)
+	if !notify {
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED")
+	w.sendNotification(trace, "Certificate", notifier.EventModified, newObj, changedFields, objectDiff(withoutStatus(oldObj), withoutStatus(newObj)))
+}
+
+// sendCertificateAlert traces and sends an alert about a Certificate
+func (w *InformerWatcher) sendCertificateAlert(resourceConfig config.ResourceConfig, eventType notifier.EventType, obj *unstructured.Unstructured, changedFields, diff, summary []string) {
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), "Certificate", eventType, obj.GetNamespace(), obj.GetName())
+	trace.Step(StageFiltered, "matched "+resourceConfig.Describe())
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	trace.Logger().Info("Certificate alert", "diff", diff)
+	w.sendNotificationWithSummary(trace, "Certificate", eventType, obj, changedFields, diff, summary)
+}
+
+// certificateFailure reports a failed issuance, which cert-manager records by setting
+// status.lastFailureTime, with the Issuing condition's reason and the failed attempts
+func certificateFailure(oldObj, newObj *unstructured.Unstructured) (changedFields, diff []string, failed bool) {
+	oldFailure, _, _ := unstructured.NestedString(oldObj.Object, "status", "lastFailureTime")
+	newFailure, _, _ := unstructured.NestedString(newObj.Object, "status", "lastFailureTime")
+	if newFailure == "" || newFailure == oldFailure {
+		return nil, nil, false
+	}
+
+	changedFields = []string{"status.lastFailureTime"}
+	condition := certificateCondition(newObj, "Issuing")
+	if condition == nil {
+		condition = certificateCondition(newObj, "Ready")
+	}
+	if condition != nil {
+		diff = append(diff, "~ "+describeCertificateCondition(condition))
+	}
+	if attempts, found, _ := unstructured.NestedInt64(newObj.Object, "status", "failedIssuanceAttempts"); found {
+		changedFields = append(changedFields, "status.failedIssuanceAttempts")
+		diff = append(diff, fmt.Sprintf("~ failedIssuanceAttempts: %d", attempts))
+	}
+	diff = append(diff, "~ lastFailureTime: "+newFailure)
+	return changedFields, diff, true
+}
+
+// certificateSummary describes a Certificate's validity and renewal, e.g.
+// "Valid until 2026-11-01T00:00:00Z (in 17d 4h)" and "Renewal was due at ..."
+func certificateSummary(obj *unstructured.Unstructured) []string {
+	var summary []string
+	if notAfter, ok := certificateTime(obj, "notAfter"); ok {
+		if remaining := time.Until(notAfter); remaining > 0 {
+			summary = append(summary, fmt.Sprintf("Valid until %s (in %s)", notAfter.UTC().Format(time.RFC3339), describeRemaining(remaining)))
+		} else {
+			summary = append(summary, fmt.Sprintf("Expired at %s (%s ago)", notAfter.UTC().Format(time.RFC3339), describeRemaining(-remaining)))
+		}
+	}
+	if renewalTime, ok := certificateTime(obj, "renewalTime"); ok {
+		if time.Until(renewalTime) > 0 {
+			summary = append(summary, "Renewal scheduled for "+renewalTime.UTC().Format(time.RFC3339))
+		} else {
+			summary = append(summary, "Renewal was due at "+renewalTime.UTC().Format(time.RFC3339))
+		}
+	}
+	if condition := certificateCondition(obj, "Ready"); condition != nil {
+		summary = append(summary, describeCertificateCondition(condition))
+	}
+	return summary
+}
+
+// runCertificateExpiryChecks alerts on watched Certificates within the expiry threshold, at
+// start and then every check interval; nearing expiry changes nothing in the cluster to watch
+func (w *InformerWatcher) runCertificateExpiryChecks() {
+	defer exitcode.Recover()
+	alerts := w.config.Watcher.CertificateAlerts
+	ticker := time.NewTicker(alerts.GetCheckInterval())
+	defer ticker.Stop()
+	for {
+		w.checkCertificateExpiry(alerts.GetExpiryThreshold())
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkCertificateExpiry sends CERTIFICATE_EXPIRING for cached Certificates expiring within
+// threshold, once per notAfter; a Certificate matched by several entries is alerted once
+func (w *InformerWatcher) checkCertificateExpiry(threshold time.Duration) {
+	seen := make(map[string]bool)
+	complete := true
+	for _, resourceConfig := range w.config.Resources {
+		if resourceConfig.Kind != "Certificate" {
+			continue
+		}
+		items, err := w.listCertificates(resourceConfig)
+		if err != nil {
+			w.logger.Warn("Failed to list Certificates for expiry checks", "namespace", resourceConfig.Namespace, "error", err)
+			complete = false
+			continue
+		}
+
+		for _, item := range items {
+			obj, ok := item.(*unstructured.Unstructured)
+			if !ok || !w.shouldProcessResource(obj, resourceConfig) {
+				continue
+			}
+			key := objectKey(obj)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			notAfter, ok := certificateTime(obj, "notAfter")
+			if !ok || time.Until(notAfter) > threshold {
+				continue
+			}
+			notAfterValue, _, _ := unstructured.NestedString(obj.Object, "status", "notAfter")
+			w.certificates.mu.Lock()
+			alerted := w.certificates.expiring[key] == notAfterValue
+			w.certificates.expiring[key] = notAfterValue
+			w.certificates.mu.Unlock()
+			if alerted {
+				continue
+			}
+
+			line := "~ notAfter: " + notAfterValue
+			if remaining := time.Until(notAfter); remaining > 0 {
+				line += " (expires in " + describeRemaining(remaining) + ")"
+			} else {
+				line += " (expired " + describeRemaining(-remaining) + " ago)"
+			}
+			w.sendCertificateAlert(resourceConfig, notifier.EventCertificateExpiring, obj, []string{"status.notAfter"}, []string{line}, certificateSummary(obj))
+		}
+	}
+
+	// Forget deleted Certificates, unless some could not be listed
+	if !complete {
+		return
+	}
+	w.certificates.mu.Lock()
+	for key := range w.certificates.expiring {
+		if !seen[key] {
+			delete(w.certificates.expiring, key)
+		}
+	}
+	w.certificates.mu.Unlock()
+}
+
+// listCertificates returns the Certificates of an entry's scope from its informer cache or,
+// for kinds served by the raw watch engine, which keeps no cache, from the API server
+func (w *InformerWatcher) listCertificates(resourceConfig config.ResourceConfig) ([]interface{}, error) {
+	w.mu.RLock()
+	informerStore, ok := w.informerStore(resourceConfig)
+	w.mu.RUnlock()
+	if ok {
+		return informerStore.List(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, certificateListTimeout)
+	defer cancel()
+	scope := w.entryScope(resourceConfig)
+	// ResourceVersion 0 is served from the API server's cache
+	list, err := w.dynamicClient.Resource(scope.gvr).Namespace(scope.namespace).List(ctx, metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]interface{}, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, &list.Items[i])
+	}
+	return items, nil
+}
+
+// certificateCondition returns the status condition of the given type, or nil when absent
+func certificateCondition(obj *unstructured.Unstructured, conditionType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		if condition, ok := condition.(map[string]interface{}); ok && condition["type"] == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
+
+// describeCertificateCondition renders a condition as "Ready: False (Failed: message)"
+func describeCertificateCondition(condition map[string]interface{}) string {
+	line := fmt.Sprintf("%v: %v", condition["type"], condition["status"])
+	reason, _ := condition["reason"].(string)
+	message, _ := condition["message"].(string)
+	switch {
+	case reason != "" && message != "":
+		line += fmt.Sprintf(" (%s: %s)", reason, message)
+	case reason != "":
+		line += " (" + reason + ")"
+	}
+	return line
+}
+
+func certificateTime(obj *unstructured.Unstructured, field string) (time.Time, bool) {
+	value, _, _ := unstructured.NestedString(obj.Object, "status", field)
+	parsed, err := time.Parse(time.RFC3339, value)
+	return parsed, err == nil
+}
+
+// describeRemaining renders a duration in days and hours, or hours and minutes below a day
+func describeRemaining(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd %dh", int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour))
+	}
+	return fmt.Sprintf("%dh %dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// handleCSRUpdated notifies a CertificateSigningRequest being approved, denied or failing,
+// and its certificate being issued
+func (w *InformerWatcher) handleCSRUpdated(trace *EventTrace, oldObj, newObj *unstructured.Unstructured) {
+	var oldCSR, newCSR certificatesv1.CertificateSigningRequest
+	if !convertObject(oldObj, &oldCSR) || !convertObject(newObj, &newCSR) {
+		trace.Logger().Warn("Failed to convert to a typed CertificateSigningRequest")
+		w.traces.Finish(trace, "failed")
+		return
+	}
+
+	changedFields, diff := csrTransitions(&oldCSR, &newCSR)
+	if len(changedFields) == 0 {
+		trace.Step(StageDiffed, "no signing request transitions")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("Signing request transitions", "diff", diff)
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	summary := []string{fmt.Sprintf("Requested by %s for signer %s", newCSR.Spec.Username, newCSR.Spec.SignerName)}
+	w.sendNotificationWithSummary(trace, "CertificateSigningRequest", notifier.EventModified, newObj, changedFields, diff, summary)
+}
+
+// csrTransitions returns the changed fields and a line per transition: Approved, Denied
+// and Failed conditions appearing and the certificate being issued
+func csrTransitions(oldCSR, newCSR *certificatesv1.CertificateSigningRequest) ([]string, []string) {
+	var changedFields, diff []string
+
+	previous := make(map[certificatesv1.RequestConditionType]bool)
+	for _, condition := range oldCSR.Status.Conditions {
+		previous[condition.Type] = true
+	}
+	for _, condition := range newCSR.Status.Conditions {
+		if previous[condition.Type] {
+			continue
+		}
+		line := "+ condition: " + string(condition.Type)
+		switch {
+		case condition.Reason != "" && condition.Message != "":
+			line += fmt.Sprintf(" (%s: %s)", condition.Reason, condition.Message)
+		case condition.Reason != "":
+			line += " (" + condition.Reason + ")"
+		}
+		diff = append(diff, line)
+	}
+	if len(diff) > 0 {
+		changedFields = append(changedFields, "status.conditions")
+	}
+
+	if len(oldCSR.Status.Certificate) == 0 && len(newCSR.Status.Certificate) > 0 {
+		changedFields = append(changedFields, "status.certificate")
+		diff = append(diff, "+ certificate issued")
+	}
+	return changedFields, diff
+}
@@ -0,0 +1,42 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+func certificate(name string, notAfter time.Time) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "prod"},
+		"status":     map[string]interface{}{"notAfter": notAfter.UTC().Format(time.RFC3339)},
+	}}
+}
+
+// Without an informer, as under the raw watch engine, the Certificates are listed from the API server
+func TestCheckCertificateExpiryWithoutCache(t *testing.T) {
+	cfg := &config.Config{Resources: []config.ResourceConfig{{Kind: "Certificate", Namespace: "prod"}}}
+	w, recorder, _ := newTestWatcher(t, cfg,
+		certificate("expiring", time.Now().Add(24*time.Hour)),
+		certificate("valid", time.Now().Add(90*24*time.Hour)),
+	)
+
+	w.checkCertificateExpiry(14 * 24 * time.Hour)
+	events := recorder.waitForEvents(t, 1)
+	if events[0].EventType != notifier.EventCertificateExpiring || events[0].ResourceName != "expiring" {
+		t.Errorf("notified %s %s, want CERTIFICATE_EXPIRING expiring", events[0].EventType, events[0].ResourceName)
+	}
+
+	// The next check alerts nothing new
+	w.checkCertificateExpiry(14 * 24 * time.Hour)
+	time.Sleep(100 * time.Millisecond)
+	if events := recorder.sent(); len(events) != 1 {
+		t.Errorf("%d events notified after the second check, want 1", len(events))
+	}
+}
@@ -0,0 +1,139 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxLineDiffCells bounds the line diff of a multi-line value (old lines x new lines)
+const maxLineDiffCells = 250000
+
+// configMapDiff renders the key-level changes between two ConfigMap versions, cut off after
+// the configured size: "+"/"-"/"~" per data key, changed multi-line values followed by their
+// removed and added lines. binaryData keys are only named.
+func (w *InformerWatcher) configMapDiff(oldObj, newObj *unstructured.Unstructured) []string {
+	diffConfig := w.config.Watcher.ConfigMapDiff
+	oldData, _, _ := unstructured.NestedStringMap(oldObj.Object, "data")
+	newData, _, _ := unstructured.NestedStringMap(newObj.Object, "data")
+	oldBinary, _, _ := unstructured.NestedStringMap(oldObj.Object, "binaryData")
+	newBinary, _, _ := unstructured.NestedStringMap(newObj.Object, "binaryData")
+
+	lines := dataKeyDiff("data", oldData, newData, diffConfig.HideValues)
+	lines = append(lines, dataKeyDiff("binaryData", oldBinary, newBinary, true)...)
+	return truncateLines(lines, diffConfig.GetMaxSize())
+}
+
+// dataKeyDiff compares one data section key by key, in key order
+func dataKeyDiff(section string, before, after map[string]string, hideValues bool) []string {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, key := range sorted {
+		oldValue, inBefore := before[key]
+		newValue, inAfter := after[key]
+		field := section + "." + key
+		switch {
+		case !inBefore:
+			lines = append(lines, describeValue("+", field, newValue, hideValues)...)
+		case !inAfter:
+			lines = append(lines, describeValue("-", field, oldValue, hideValues)...)
+		case oldValue == newValue:
+		case hideValues:
+			lines = append(lines, "~ "+field)
+		case !strings.Contains(oldValue, "\n") && !strings.Contains(newValue, "\n"):
+			lines = append(lines, fmt.Sprintf("~ %s: %q -> %q", field, oldValue, newValue))
+		default:
+			lines = append(lines, "~ "+field+":")
+			lines = append(lines, valueLineDiff(oldValue, newValue)...)
+		}
+	}
+	return lines
+}
+
+// describeValue renders an added or removed key, with its value unless hidden
+func describeValue(sign, field, value string, hideValues bool) []string {
+	switch {
+	case hideValues:
+		return []string{sign + " " + field}
+	case !strings.Contains(value, "\n"):
+		return []string{fmt.Sprintf("%s %s: %q", sign, field, value)}
+	}
+	lines := []string{sign + " " + field + ":"}
+	for _, line := range splitValueLines(value) {
+		lines = append(lines, "    "+sign+" "+line)
+	}
+	return lines
+}
+
+// valueLineDiff lists the removed and added lines between two multi-line values, in order
+func valueLineDiff(oldValue, newValue string) []string {
+	before, after := splitValueLines(oldValue), splitValueLines(newValue)
+	if len(before)*len(after) > maxLineDiffCells {
+		return []string{fmt.Sprintf("    (%d -> %d lines, too large to compare line by line)", len(before), len(after))}
+	}
+
+	// Longest common subsequence; common[i][j] covers before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			switch {
+			case before[i] == after[j]:
+				common[i][j] = common[i+1][j+1] + 1
+			case common[i+1][j] >= common[i][j+1]:
+				common[i][j] = common[i+1][j]
+			default:
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			i++
+			j++
+		case i < len(before) && (j == len(after) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, "    - "+before[i])
+			i++
+		default:
+			lines = append(lines, "    + "+after[j])
+			j++
+		}
+	}
+	return lines
+}
+
+func splitValueLines(value string) []string {
+	return strings.Split(strings.TrimSuffix(value, "\n"), "\n")
+}
+
+// truncateLines keeps lines up to maxSize bytes, replacing the rest with a note
+func truncateLines(lines []string, maxSize int) []string {
+	size := 0
+	for i, line := range lines {
+		size += len(line) + 1
+		if size > maxSize {
+			return append(lines[:i:i], fmt.Sprintf("... diff truncated at %d bytes, %d more lines", maxSize, len(lines)-i))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,216 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// consumerIndex indexes workloads by the ConfigMaps and Secrets their pod template references
+const consumerIndex = "consumers"
+
+// maxConsumersListed caps the workload names listed per kind in a notification
+const maxConsumersListed = 10
+
+// consumerKinds are the workload kinds searched for ConfigMap and Secret consumers, in report order
+var consumerKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "CronJob"}
+
+// newConsumerInformers creates the workload caches used to report which
+// workloads a changed ConfigMap or Secret affects
+func (w *InformerWatcher) newConsumerInformers() map[string]cache.SharedIndexInformer {
+	factory := informers.NewSharedInformerFactory(w.k8sClient, 0)
+	consumers := map[string]cache.SharedIndexInformer{
+		"Deployment":  factory.Apps().V1().Deployments().Informer(),
+		"StatefulSet": factory.Apps().V1().StatefulSets().Informer(),
+		"DaemonSet":   factory.Apps().V1().DaemonSets().Informer(),
+		"CronJob":     factory.Batch().V1().CronJobs().Informer(),
+	}
+	for kind, informer := range consumers {
+		if err := informer.AddIndexers(cache.Indexers{consumerIndex: consumerIndexFunc}); err != nil {
+			w.logger.Warn("Failed to index ConfigMap and Secret consumers", "kind", kind, "error", err)
+		}
+	}
+	return consumers
+}
+
+// consumerIndexFunc returns namespace/Kind/name keys for every ConfigMap and Secret a workload references
+func consumerIndexFunc(obj interface{}) ([]string, error) {
+	namespace, spec := workloadPodSpec(obj)
+	if spec == nil {
+		return nil, nil
+	}
+
+	var keys []string
+	for _, ref := range podSpecReferences(spec) {
+		keys = append(keys, namespace+"/"+ref)
+	}
+	return keys, nil
+}
+
+// workloadPodSpec returns the namespace and pod template spec of a cached workload
+func workloadPodSpec(obj interface{}) (string, *corev1.PodSpec) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return workload.Namespace, &workload.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		return workload.Namespace, &workload.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		return workload.Namespace, &workload.Spec.Template.Spec
+	case *batchv1.CronJob:
+		return workload.Namespace, &workload.Spec.JobTemplate.Spec.Template.Spec
+	default:
+		return "", nil
+	}
+}
+
+// podSpecReferences returns the distinct Kind/name of every ConfigMap and Secret
+// a pod spec mounts, projects, loads into the environment or pulls images with
+func podSpecReferences(spec *corev1.PodSpec) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	add := func(kind, name string) {
+		if ref := kind + "/" + name; name != "" && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			add("ConfigMap", volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			add("Secret", volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					add("ConfigMap", source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					add("Secret", source.Secret.Name)
+				}
+			}
+		}
+	}
+
+	for _, container := range append(append([]corev1.Container(nil), spec.InitContainers...), spec.Containers...) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				add("ConfigMap", envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				add("Secret", envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				add("ConfigMap", env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				add("Secret", env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	for _, pullSecret := range spec.ImagePullSecrets {
+		add("Secret", pullSecret.Name)
+	}
+
+	return refs
+}
+
+// blastRadius describes the cached workloads referencing a ConfigMap or Secret,
+// one line per workload kind, e.g. "Referenced by 3 Deployments: api, cron, worker",
+// followed by whether their pods pick the change up or need a rollout
+func (w *InformerWatcher) blastRadius(kind, namespace, name string, eventType notifier.EventType) []string {
+	if w.consumerInformers == nil {
+		return nil
+	}
+
+	key := namespace + "/" + kind + "/" + name
+	var lines []string
+	var consumers []consumer
+	for _, consumerKind := range consumerKinds {
+		informer := w.consumerInformers[consumerKind]
+		if !informer.HasSynced() {
+			continue
+		}
+		workloads, err := informer.GetIndexer().ByIndex(consumerIndex, key)
+		if err != nil {
+			w.logger.Warn("Failed to look up consumers", "consumerKind", consumerKind, "kind", kind, "namespace", namespace, "name", name, "error", err)
+			continue
+		}
+		if len(workloads) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(workloads))
+		for _, workload := range workloads {
+			accessor, ok := workload.(metav1.Object)
+			if !ok {
+				continue
+			}
+			names = append(names, accessor.GetName())
+			if _, spec := workloadPodSpec(workload); spec != nil {
+				consumers = append(consumers, consumer{
+					Kind:  consumerKind,
+					Name:  accessor.GetName(),
+					Modes: consumptionModes(spec, kind, name),
+				})
+			}
+		}
+		sort.Strings(names)
+
+		noun := consumerKind
+		if len(names) != 1 {
+			noun += "s"
+		}
+		listed := names
+		if len(listed) > maxConsumersListed {
+			listed = listed[:maxConsumersListed]
+		}
+		line := fmt.Sprintf("Referenced by %d %s: %s", len(names), noun, strings.Join(listed, ", "))
+		if len(names) > len(listed) {
+			line += fmt.Sprintf(" and %d more", len(names)-len(listed))
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 && w.consumersSynced() {
+		lines = append(lines, "Not referenced by any Deployment, StatefulSet, DaemonSet or CronJob")
+	}
+	kindOrder := make(map[string]int, len(consumerKinds))
+	for i, consumerKind := range consumerKinds {
+		kindOrder[consumerKind] = i
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		if consumers[i].Kind != consumers[j].Kind {
+			return kindOrder[consumers[i].Kind] < kindOrder[consumers[j].Kind]
+		}
+		return consumers[i].Name < consumers[j].Name
+	})
+	return append(lines, restartGuidance(namespace, eventType, consumers)...)
+}
+
+// consumersSynced reports whether every workload cache used for consumer lookups is synced
+func (w *InformerWatcher) consumersSynced() bool {
+	for _, informer := range w.consumerInformers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
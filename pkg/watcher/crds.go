@@ -0,0 +1,189 @@
+package watcher
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// handleCRDUpdated notifies a MODIFIED CustomResourceDefinition only when its versions,
+// schemas, scope or conversion changed; the API server rewrites status on every change
+func (w *InformerWatcher) handleCRDUpdated(trace *EventTrace, oldObj, newObj *unstructured.Unstructured) {
+	changedFields, diff := crdChanges(oldObj, newObj)
+	if len(changedFields) == 0 {
+		trace.Step(StageDiffed, "no version or conversion changes")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("CustomResourceDefinition changed", "diff", diff)
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	w.sendNotificationWithSummary(trace, "CustomResourceDefinition", notifier.EventModified, newObj, changedFields, diff, crdSummary(newObj))
+}
+
+// crdChanges returns the changed CRD fields and a line per change, e.g. "+ version.v2: served"
+// or "~ conversion: None -> Webhook service crd-system/converter:443/convert". Either object
+// may be nil for ADDED and DELETED events.
+func crdChanges(oldObj, newObj *unstructured.Unstructured) ([]string, []string) {
+	var changedFields, diff []string
+	before, beforeSchemas := describeCRD(oldObj)
+	after, afterSchemas := describeCRD(newObj)
+
+	versionLines := diffFields(prefixedFields(before, "version."), prefixedFields(after, "version."))
+	names := make([]string, 0, len(afterSchemas))
+	for name := range afterSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if previous, ok := beforeSchemas[name]; ok && !reflect.DeepEqual(previous, afterSchemas[name]) {
+			versionLines = append(versionLines, "~ version."+name+".schema (changed)")
+		}
+	}
+	if len(versionLines) > 0 {
+		changedFields = append(changedFields, "spec.versions")
+		diff = append(diff, versionLines...)
+	}
+
+	if lines := diffFields(prefixedFields(before, "scope"), prefixedFields(after, "scope")); len(lines) > 0 {
+		changedFields = append(changedFields, "spec.scope")
+		diff = append(diff, lines...)
+	}
+
+	conversionLines := diffFields(prefixedFields(before, "conversion"), prefixedFields(after, "conversion"))
+	if oldCA, newCA := crdCABundle(oldObj), crdCABundle(newObj); oldCA != "" && newCA != "" && oldCA != newCA {
+		conversionLines = append(conversionLines, "~ conversion.caBundle (rotated)")
+	}
+	if len(conversionLines) > 0 {
+		changedFields = append(changedFields, "spec.conversion")
+		diff = append(diff, conversionLines...)
+	}
+
+	return changedFields, diff
+}
+
+// crdDiff renders a CRD's versions and conversion for ADDED and DELETED events
+func crdDiff(oldObj, newObj *unstructured.Unstructured) []string {
+	_, diff := crdChanges(oldObj, newObj)
+	return diff
+}
+
+// describeCRD returns a CRD's scope, the flags of each version as "version.<name>" and its
+// conversion strategy, plus each version's schema; both are empty for a nil object
+func describeCRD(obj *unstructured.Unstructured) (map[string]string, map[string]interface{}) {
+	fields := make(map[string]string)
+	schemas := make(map[string]interface{})
+	if obj == nil {
+		return fields, schemas
+	}
+
+	if scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope"); scope != "" {
+		fields["scope"] = scope
+	}
+
+	versions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+	for _, version := range versions {
+		version, ok := version.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := version["name"].(string)
+		var flags []string
+		if served, _ := version["served"].(bool); served {
+			flags = append(flags, "served")
+		} else {
+			flags = append(flags, "not served")
+		}
+		if storage, _ := version["storage"].(bool); storage {
+			flags = append(flags, "storage")
+		}
+		if deprecated, _ := version["deprecated"].(bool); deprecated {
+			flags = append(flags, "deprecated")
+		}
+		fields["version."+name] = strings.Join(flags, ", ")
+		schemas[name] = version["schema"]
+	}
+
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "conversion", "strategy")
+	if strategy == "" {
+		strategy = "None"
+	}
+	if strategy == "Webhook" {
+		strategy += " " + describeWebhookClient(obj)
+		if reviewVersions, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "conversion", "webhook", "conversionReviewVersions"); len(reviewVersions) > 0 {
+			fields["conversion.reviewVersions"] = strings.Join(reviewVersions, ",")
+		}
+	}
+	fields["conversion"] = strategy
+	return fields, schemas
+}
+
+// describeWebhookClient renders a conversion webhook's endpoint, e.g.
+// "service crd-system/converter:443/convert" or "url https://converter.example.com/convert"
+func describeWebhookClient(obj *unstructured.Unstructured) string {
+	if url, found, _ := unstructured.NestedString(obj.Object, "spec", "conversion", "webhook", "clientConfig", "url"); found {
+		return "url " + url
+	}
+	service, found, _ := unstructured.NestedMap(obj.Object, "spec", "conversion", "webhook", "clientConfig", "service")
+	if !found {
+		return "(no client config)"
+	}
+	port := int64(443)
+	if value, ok := service["port"].(int64); ok {
+		port = value
+	}
+	path, _ := service["path"].(string)
+	return fmt.Sprintf("service %v/%v:%d%s", service["namespace"], service["name"], port, path)
+}
+
+func crdCABundle(obj *unstructured.Unstructured) string {
+	if obj == nil {
+		return ""
+	}
+	caBundle, _, _ := unstructured.NestedString(obj.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	return caBundle
+}
+
+// prefixedFields returns the fields named prefix or starting with it
+func prefixedFields(fields map[string]string, prefix string) map[string]string {
+	selected := make(map[string]string)
+	for name, value := range fields {
+		if strings.HasPrefix(name, prefix) {
+			selected[name] = value
+		}
+	}
+	return selected
+}
+
+// crdSummary gives a CRD's kind and storage version, and warns about versions that were
+// removed from spec while objects may still be stored in them
+func crdSummary(obj *unstructured.Unstructured) []string {
+	group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+	summary := []string{"Defines " + kind + "." + group}
+
+	defined := make(map[string]bool)
+	versions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+	for _, version := range versions {
+		if version, ok := version.(map[string]interface{}); ok {
+			name, _ := version["name"].(string)
+			defined[name] = true
+			if storage, _ := version["storage"].(bool); storage {
+				summary = append(summary, "Storage version: "+name)
+			}
+		}
+	}
+
+	storedVersions, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "storedVersions")
+	for _, stored := range storedVersions {
+		if !defined[stored] {
+			summary = append(summary, fmt.Sprintf("Version %s is no longer defined but is still listed in status.storedVersions", stored))
+		}
+	}
+	return summary
+}
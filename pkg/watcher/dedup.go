@@ -0,0 +1,87 @@
+package watcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Deduplicator suppresses identical events seen within a sliding window.
+// Two events are identical when their kind, namespace, name, event type and
+// set of distinguishing values hash to the same value. Object changes are
+// distinguished by resource version, so only replays of one version are
+// suppressed, never two edits of the same fields.
+type Deduplicator struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewDeduplicator creates a deduplicator with the given window
+func NewDeduplicator(window time.Duration) *Deduplicator {
+	return &Deduplicator{
+		window:    window,
+		seen:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// IsDuplicate reports whether an identical event was accepted within the
+// window. Accepted events start a new window; duplicates do not extend it.
+func (d *Deduplicator) IsDuplicate(kind, namespace, name, eventType string, values []string) bool {
+	key := dedupKey(kind, namespace, name, eventType, values)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.sweep(now)
+
+	if last, ok := d.seen[key]; ok && now.Sub(last) < d.window {
+		return true
+	}
+
+	d.seen[key] = now
+	return false
+}
+
+// IsReplay is IsDuplicate for a change to an object: it reports whether the same version
+// of the object was accepted with the same changed fields within the window, as when a
+// relist delivers it again. Every new version is a new edit and is never a duplicate.
+func (d *Deduplicator) IsReplay(kind, namespace, name, eventType, resourceVersion string, changedFields []string) bool {
+	return d.IsDuplicate(kind, namespace, name, eventType, append([]string{"resourceVersion=" + resourceVersion}, changedFields...))
+}
+
+// sweep removes entries that have aged out of the window
+func (d *Deduplicator) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.window {
+		return
+	}
+	d.lastSweep = now
+
+	for key, last := range d.seen {
+		if now.Sub(last) >= d.window {
+			delete(d.seen, key)
+		}
+	}
+}
+
+// dedupKey builds the content hash for an event
+func dedupKey(kind, namespace, name, eventType string, values []string) string {
+	fields := append([]string(nil), values...)
+	sort.Strings(fields)
+
+	h := sha256.New()
+	for _, part := range []string{kind, namespace, name, eventType, strings.Join(fields, ",")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeduplicatorIsReplay(t *testing.T) {
+	type event struct {
+		after         time.Duration // Since the previous event
+		eventType     string
+		name          string
+		version       string
+		changedFields []string
+		duplicate     bool
+	}
+
+	tests := []struct {
+		name   string
+		events []event
+	}{
+		{
+			name: "replay of a version within the window",
+			events: []event{
+				{eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"spec"}},
+				{after: 10 * time.Second, eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"spec"}, duplicate: true},
+			},
+		},
+		{
+			name: "second edit of the same fields",
+			events: []event{
+				{eventType: "MODIFIED", name: "db-password", version: "10", changedFields: []string{"data"}},
+				{after: time.Second, eventType: "MODIFIED", name: "db-password", version: "11", changedFields: []string{"data"}},
+			},
+		},
+		{
+			name: "replay after the window",
+			events: []event{
+				{eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"spec"}},
+				{after: 30 * time.Second, eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"spec"}},
+			},
+		},
+		{
+			name: "changed field order does not matter",
+			events: []event{
+				{eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"spec", "metadata"}},
+				{after: time.Second, eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"metadata", "spec"}, duplicate: true},
+			},
+		},
+		{
+			name: "same version with other changed fields",
+			events: []event{
+				{eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"spec"}},
+				{after: time.Second, eventType: "MODIFIED", name: "web", version: "10", changedFields: []string{"metadata"}},
+			},
+		},
+		{
+			name: "different event type",
+			events: []event{
+				{eventType: "MODIFIED", name: "web", version: "10"},
+				{after: time.Second, eventType: "DELETED", name: "web", version: "10"},
+			},
+		},
+		{
+			name: "different object",
+			events: []event{
+				{eventType: "MODIFIED", name: "web", version: "10"},
+				{after: time.Second, eventType: "MODIFIED", name: "api", version: "10"},
+			},
+		},
+		{
+			name: "duplicates do not extend the window",
+			events: []event{
+				{eventType: "MODIFIED", name: "web", version: "10"},
+				{after: 20 * time.Second, eventType: "MODIFIED", name: "web", version: "10", duplicate: true},
+				{after: 15 * time.Second, eventType: "MODIFIED", name: "web", version: "10"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+			d := NewDeduplicator(30 * time.Second)
+			d.now = func() time.Time { return now }
+
+			for i, e := range tt.events {
+				now = now.Add(e.after)
+				if got := d.IsReplay("Deployment", "prod", e.name, e.eventType, e.version, e.changedFields); got != e.duplicate {
+					t.Errorf("event %d: IsReplay() = %v, want %v", i, got, e.duplicate)
+				}
+			}
+		})
+	}
+}
+
+func TestDeduplicatorIsDuplicate(t *testing.T) {
+	now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+	d := NewDeduplicator(30 * time.Second)
+	d.now = func() time.Time { return now }
+
+	// Kubernetes Events are keyed by reason, so repeats of one problem collapse
+	if d.IsDuplicate("Pod", "prod", "web-0", "KUBE_EVENT", []string{"BackOff"}) {
+		t.Error("first BackOff event is a duplicate")
+	}
+	now = now.Add(time.Second)
+	if !d.IsDuplicate("Pod", "prod", "web-0", "KUBE_EVENT", []string{"BackOff"}) {
+		t.Error("repeated BackOff event is not a duplicate")
+	}
+	if d.IsDuplicate("Pod", "prod", "web-0", "KUBE_EVENT", []string{"FailedMount"}) {
+		t.Error("FailedMount event after BackOff is a duplicate")
+	}
+}
+
+func TestDeduplicatorSweep(t *testing.T) {
+	now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+	d := NewDeduplicator(time.Minute)
+	d.now = func() time.Time { return now }
+	d.lastSweep = now
+
+	d.IsDuplicate("ConfigMap", "prod", "a", "MODIFIED", nil)
+	d.IsDuplicate("ConfigMap", "prod", "b", "MODIFIED", nil)
+	now = now.Add(2 * time.Minute)
+	d.IsDuplicate("ConfigMap", "prod", "c", "MODIFIED", nil)
+
+	if len(d.seen) != 1 {
+		t.Errorf("%d entries kept after the window passed, want 1", len(d.seen))
+	}
+}
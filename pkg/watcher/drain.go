@@ -0,0 +1,121 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+)
+
+// inFlightTracker counts events being processed so Stop can let their
+// notifications finish instead of cutting retries off mid-flight
+type inFlightTracker struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	idle     chan struct{} // Closed once draining with no event in progress
+}
+
+func newInFlightTracker() *inFlightTracker {
+	return &inFlightTracker{idle: make(chan struct{})}
+}
+
+// begin registers an event, returning false once the watcher is draining
+func (t *inFlightTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.active++
+	return true
+}
+
+// hold registers an event even while draining, for work an event already
+// being processed hands off, such as its queued notification
+func (t *inFlightTracker) hold() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active++
+}
+
+// end marks an event registered with begin or hold as done
+func (t *inFlightTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	if t.draining && t.active == 0 {
+		t.closeIdle()
+	}
+}
+
+// closeIdle closes idle unless an earlier drain or end already did
+func (t *inFlightTracker) closeIdle() {
+	select {
+	case <-t.idle:
+	default:
+		close(t.idle)
+	}
+}
+
+// drain rejects new events and waits for the ones in progress until ctx is
+// done, returning how many were still in progress
+func (t *inFlightTracker) drain(ctx context.Context) int {
+	t.mu.Lock()
+	if !t.draining {
+		t.draining = true
+		if t.active == 0 {
+			t.closeIdle()
+		}
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.idle:
+		return 0
+	case <-ctx.Done():
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.active
+	}
+}
+
+// trackedHandler drops events once the watcher drains and tracks the ones in progress
+type trackedHandler struct {
+	tracker *inFlightTracker
+	next    cache.ResourceEventHandler
+}
+
+// trackInFlight wraps a handler so Stop waits for the events it is processing
+func (w *InformerWatcher) trackInFlight(handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	return trackedHandler{tracker: w.inFlight, next: handler}
+}
+
+func (h trackedHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	defer exitcode.Recover()
+	if !h.tracker.begin() {
+		return
+	}
+	defer h.tracker.end()
+	h.next.OnAdd(obj, isInInitialList)
+}
+
+func (h trackedHandler) OnUpdate(oldObj, newObj interface{}) {
+	defer exitcode.Recover()
+	if !h.tracker.begin() {
+		return
+	}
+	defer h.tracker.end()
+	h.next.OnUpdate(oldObj, newObj)
+}
+
+func (h trackedHandler) OnDelete(obj interface{}) {
+	defer exitcode.Recover()
+	if !h.tracker.begin() {
+		return
+	}
+	defer h.tracker.end()
+	h.next.OnDelete(obj)
+}
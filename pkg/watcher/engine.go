@@ -0,0 +1,32 @@
+package watcher
+
+import "time"
+
+// Watch engines that can serve a kind
+const (
+	EngineInformer = "informer"
+	EngineRawWatch = "raw-watch"
+)
+
+// EngineStatus describes the watch engine serving a kind
+type EngineStatus struct {
+	Kind        string    `json:"kind"`
+	Engine      string    `json:"engine"`
+	Degraded    bool      `json:"degraded"`
+	CacheSynced bool      `json:"cacheSynced"` // Informer cache is synced; always false for raw watches
+	Reason      string    `json:"reason,omitempty"`
+	Since       time.Time `json:"since"`
+	WatchErrors int64     `json:"watchErrors"`
+	LastError   string    `json:"lastError,omitempty"`
+
+	// LastEventTime is when the kind last delivered an event; ConsecutiveFailures
+	// counts watch errors since then
+	LastEventTime       time.Time `json:"lastEventTime"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+
+	// failingSince is when the current run of consecutive failures started
+	failingSince time.Time
+
+	// lastErr is the error behind LastError, for callers matching API errors
+	lastErr error
+}
@@ -0,0 +1,132 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// createKubeEventHandler notifies new Events and repeats of existing ones
+// (the API server bumps count/series instead of creating a new Event).
+// Deletions are just garbage collection and are not notified.
+func (w *InformerWatcher) createKubeEventHandler(resourceConfig config.ResourceConfig) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if !w.started() {
+				return
+			}
+			w.handleKubeEvent(nil, obj, resourceConfig)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !w.started() {
+				return
+			}
+			w.handleKubeEvent(oldObj, newObj, resourceConfig)
+		},
+	}
+}
+
+func (w *InformerWatcher) handleKubeEvent(oldObj, newObj interface{}, resourceConfig config.ResourceConfig) {
+	newUnstructured, ok := newObj.(*unstructured.Unstructured)
+	if !ok || isIgnored(newUnstructured) {
+		return
+	}
+
+	var event corev1.Event
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(newUnstructured.Object, &event); err != nil {
+		w.logger.Warn("Failed to convert to a typed event", "kind", "Event", "namespace", newUnstructured.GetNamespace(), "name", newUnstructured.GetName(), "error", err)
+		return
+	}
+	if oldUnstructured, ok := oldObj.(*unstructured.Unstructured); ok {
+		var oldEvent corev1.Event
+		if runtime.DefaultUnstructuredConverter.FromUnstructured(oldUnstructured.Object, &oldEvent) == nil &&
+			eventOccurrences(&oldEvent) >= eventOccurrences(&event) {
+			return
+		}
+	}
+
+	involved := event.InvolvedObject
+	if !w.matchesResourceConfig(event.Namespace, involved.Name, resourceConfig) || !matchesEventFilter(resourceConfig.EventFilter, &event) {
+		return
+	}
+
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), involved.Kind, notifier.EventKubeEvent, event.Namespace, involved.Name)
+	trace.Step(StageFiltered, fmt.Sprintf("%s %s matched %s", event.Type, event.Reason, resourceConfig.Describe()))
+	w.metrics.RecordEventProcessed()
+
+	// Keyed by reason, so different problems with the same object are not collapsed
+	if w.deduplicator.IsDuplicate(involved.Kind, event.Namespace, involved.Name, string(notifier.EventKubeEvent), []string{event.Reason}) {
+		w.metrics.RecordEventDeduplicated()
+		trace.Step(StageDeduplicated, "duplicate within "+w.config.Watcher.GetEventDeduplicationWindow().String())
+		w.traces.Finish(trace, "duplicate")
+		return
+	}
+	trace.Step(StageDeduplicated, "not a duplicate")
+
+	summary := []string{
+		fmt.Sprintf("%s event %s: %s", event.Type, event.Reason, strings.TrimSpace(event.Message)),
+	}
+	if count := eventOccurrences(&event); count > 1 {
+		summary = append(summary, fmt.Sprintf("Seen %d times", count))
+	}
+	if source := eventSource(&event); source != "" {
+		summary = append(summary, "Reported by "+source)
+	}
+
+	trace.Logger().Info("Kubernetes event", "type", event.Type, "reason", event.Reason)
+	w.deliver(trace, notifier.NotificationEvent{
+		ID:           trace.ID,
+		EventType:    notifier.EventKubeEvent,
+		ResourceKind: involved.Kind,
+		ResourceName: involved.Name,
+		Namespace:    event.Namespace,
+		Summary:      summary,
+	})
+}
+
+// matchesEventFilter reports whether the Event passes the filter; a nil filter matches everything
+func matchesEventFilter(filter *config.EventFilterConfig, event *corev1.Event) bool {
+	if filter == nil {
+		return true
+	}
+	if len(filter.Reasons) > 0 && !config.MatchAny(filter.Reasons, event.Reason) {
+		return false
+	}
+	if len(filter.Types) > 0 && !config.MatchAny(filter.Types, event.Type) {
+		return false
+	}
+	if len(filter.InvolvedKinds) > 0 && !config.MatchAny(filter.InvolvedKinds, event.InvolvedObject.Kind) {
+		return false
+	}
+	return true
+}
+
+// eventOccurrences returns how often the Event has been observed, covering both the
+// legacy count field and event series
+func eventOccurrences(event *corev1.Event) int32 {
+	if event.Series != nil && event.Series.Count > event.Count {
+		return event.Series.Count
+	}
+	if event.Count > 0 {
+		return event.Count
+	}
+	return 1
+}
+
+func eventSource(event *corev1.Event) string {
+	switch {
+	case event.ReportingController != "":
+		return event.ReportingController
+	case event.Source.Host != "":
+		return event.Source.Component + " on " + event.Source.Host
+	default:
+		return event.Source.Component
+	}
+}
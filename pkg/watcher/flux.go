@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// fluxKinds are the Flux reconciler kinds whose status is translated into notifications
+var fluxKinds = map[string]bool{
+	"HelmRelease":   true,
+	"Kustomization": true,
+}
+
+// fluxConditionTypes are the Flux conditions whose transitions are notified. Reconciling
+// flips on every reconciliation, so it only adds context to failure messages.
+var fluxConditionTypes = []string{"Ready", "Stalled"}
+
+// handleFluxUpdated notifies readiness changes and applied revisions of Flux
+// objects instead of every status update the controllers write
+func (w *InformerWatcher) handleFluxUpdated(trace *EventTrace, kind string, oldObj, newObj *unstructured.Unstructured) {
+	changedFields, diff := fluxTransitions(kind, oldObj, newObj)
+	if len(changedFields) == 0 {
+		trace.Step(StageDiffed, "no reconciliation state transitions")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("Reconciliation transitions", "diff", diff)
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	w.sendNotification(trace, kind, notifier.EventModified, newObj, changedFields, diff)
+}
+
+// fluxTransitions returns the changed reconciliation fields and a readable line per transition,
+// e.g. "~ upgraded to chart 1.9.3 (from 1.9.2)" or "~ Ready: True -> False: install retries exhausted"
+func fluxTransitions(kind string, oldObj, newObj *unstructured.Unstructured) ([]string, []string) {
+	var changedFields, diff []string
+
+	revisionLabel := "revision"
+	if kind == "HelmRelease" {
+		revisionLabel = "chart"
+	}
+
+	oldApplied, newApplied := fluxAppliedRevision(kind, oldObj), fluxAppliedRevision(kind, newObj)
+	if newApplied != "" && oldApplied != newApplied {
+		changedFields = append(changedFields, "status.lastAppliedRevision")
+		if oldApplied == "" {
+			diff = append(diff, fmt.Sprintf("~ applied %s %s", revisionLabel, newApplied))
+		} else if kind == "HelmRelease" {
+			diff = append(diff, fmt.Sprintf("~ upgraded to chart %s (from %s)", newApplied, oldApplied))
+		} else {
+			diff = append(diff, fmt.Sprintf("~ applied revision %s (from %s)", newApplied, oldApplied))
+		}
+	}
+
+	for _, conditionType := range fluxConditionTypes {
+		oldCondition, newCondition := fluxCondition(oldObj, conditionType), fluxCondition(newObj, conditionType)
+		if oldCondition.status == newCondition.status {
+			continue
+		}
+		changedFields = append(changedFields, "conditions."+conditionType)
+
+		line := fmt.Sprintf("~ %s: %s -> %s", conditionType, oldCondition.describeStatus(), newCondition.describeStatus())
+		if newCondition.status == "False" && conditionType == "Ready" {
+			// Name the revision that failed when it is not the one already running
+			attempted, _, _ := unstructured.NestedString(newObj.Object, "status", "lastAttemptedRevision")
+			if attempted != "" && attempted != newApplied {
+				line += fmt.Sprintf(", %s %s failed", revisionLabel, attempted)
+			}
+		}
+		if message := newCondition.describe(); message != "" {
+			line += ": " + message
+		}
+		diff = append(diff, line)
+	}
+
+	return changedFields, diff
+}
+
+// fluxAppliedRevision returns the revision the controller last applied successfully.
+// Newer HelmRelease versions record it only in status.history.
+func fluxAppliedRevision(kind string, obj *unstructured.Unstructured) string {
+	if revision, _, _ := unstructured.NestedString(obj.Object, "status", "lastAppliedRevision"); revision != "" {
+		return revision
+	}
+	if kind != "HelmRelease" {
+		return ""
+	}
+	history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history")
+	if len(history) == 0 {
+		return ""
+	}
+	if latest, ok := history[0].(map[string]interface{}); ok {
+		version, _, _ := unstructured.NestedString(latest, "chartVersion")
+		return version
+	}
+	return ""
+}
+
+// fluxConditionState is the part of a status condition used for notifications
+type fluxConditionState struct {
+	status  string
+	reason  string
+	message string
+}
+
+func (c fluxConditionState) describeStatus() string {
+	if c.status == "" {
+		return "Unknown"
+	}
+	return c.status
+}
+
+// describe returns the condition message, falling back to its reason
+func (c fluxConditionState) describe() string {
+	if message := strings.TrimSpace(c.message); message != "" {
+		return message
+	}
+	return c.reason
+}
+
+// fluxCondition returns the condition of the given type; absent conditions have an empty status
+func fluxCondition(obj *unstructured.Unstructured, conditionType string) fluxConditionState {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		state := fluxConditionState{}
+		state.status, _, _ = unstructured.NestedString(condition, "status")
+		state.reason, _, _ = unstructured.NestedString(condition, "reason")
+		state.message, _, _ = unstructured.NestedString(condition, "message")
+		return state
+	}
+	return fluxConditionState{}
+}
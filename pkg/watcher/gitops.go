@@ -0,0 +1,62 @@
+package watcher
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// maxDriftLines caps the drifted fields listed in one notification
+const maxDriftLines = 20
+
+// changeEventTypes are the events of changes to the object itself, as opposed to alerts
+// about it; their objects are compared with Git
+var changeEventTypes = map[notifier.EventType]bool{
+	notifier.EventAdded:                    true,
+	notifier.EventModified:                 true,
+	notifier.EventDeleted:                  true,
+	notifier.EventChangedWhileDisconnected: true,
+}
+
+// gitDrift tells whether a notified object matches its manifest in the GitOps repository,
+// e.g. "Differs from Git (apps/checkout.yaml at 3f2c1ab): 1 field" and the drifted fields.
+// Nothing is reported until the repository has been pulled once.
+func (w *InformerWatcher) gitDrift(kind string, eventType notifier.EventType, obj metav1.Object) []string {
+	commit := w.gitops.Commit()
+	if !changeEventTypes[eventType] || commit == "" {
+		return nil
+	}
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+
+	manifest, ok := w.gitops.Lookup(kind, obj.GetNamespace(), obj.GetName())
+	if !ok {
+		if w.config.Watcher.GitOps.ReportUntracked && eventType != notifier.EventDeleted {
+			return []string{fmt.Sprintf("Not declared in Git (at %s)", commit)}
+		}
+		return nil
+	}
+	source := fmt.Sprintf("%s at %s", manifest.File, commit)
+	if eventType == notifier.EventDeleted {
+		return []string{fmt.Sprintf("Still declared in Git (%s), so GitOps may recreate it", source)}
+	}
+
+	live, err := filterContent(obj)
+	if err != nil {
+		w.logger.Debug("Failed to compare object with Git", "kind", kind, "name", obj.GetName(), "error", err)
+		return nil
+	}
+	drift := w.gitops.Diff(manifest, live)
+	if len(drift) == 0 {
+		return []string{fmt.Sprintf("Matches Git (%s)", source)}
+	}
+	fields := "fields"
+	if len(drift) == 1 {
+		fields = "field"
+	}
+	summary := []string{fmt.Sprintf("Differs from Git (%s): %d %s", source, len(drift), fields)}
+	return append(summary, limitLines(prefixLines("  ", drift), maxDriftLines)...)
+}
@@ -0,0 +1,295 @@
+package watcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// helmReleaseType is the type of the Secrets Helm 3 stores each release revision in
+const helmReleaseType = "helm.sh/release.v1"
+
+// maxHelmReleaseSize bounds a decompressed release payload
+const maxHelmReleaseSize = 32 << 20
+
+// helmLookupTimeout bounds fetching the previous revision of a release
+const helmLookupTimeout = 10 * time.Second
+
+// helmRelease is the part of a stored Helm release that notifications use
+type helmRelease struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"` // Revision
+	Info    struct {
+		Status      string `json:"status"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+	Config map[string]interface{} `json:"config"` // Values supplied on install or upgrade
+}
+
+// createHelmReleaseEventHandler notifies release revisions once Helm marks them deployed
+// or failed. Deletions are uninstalls or pruned history and are not notified.
+func (w *InformerWatcher) createHelmReleaseEventHandler(resourceConfig config.ResourceConfig) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if !w.started() {
+				return
+			}
+			w.handleHelmRelease(nil, obj, resourceConfig)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !w.started() {
+				return
+			}
+			w.handleHelmRelease(oldObj, newObj, resourceConfig)
+		},
+	}
+}
+
+func (w *InformerWatcher) handleHelmRelease(oldObj, newObj interface{}, resourceConfig config.ResourceConfig) {
+	secret, ok := newObj.(*unstructured.Unstructured)
+	if !ok || isIgnored(secret) {
+		return
+	}
+	if secretType, _, _ := unstructured.NestedString(secret.Object, "type"); secretType != helmReleaseType {
+		return
+	}
+
+	// Helm creates a revision as pending-install, pending-upgrade or pending-rollback and
+	// mirrors its status into the labels, so only the final transition is decoded
+	status := secret.GetLabels()["status"]
+	previousStatus := ""
+	if old, ok := oldObj.(*unstructured.Unstructured); ok {
+		previousStatus = old.GetLabels()["status"]
+	}
+	if (status != "deployed" && status != "failed") || status == previousStatus {
+		return
+	}
+	name, namespace := secret.GetLabels()["name"], secret.GetNamespace()
+	if !w.matchesResourceConfig(namespace, name, resourceConfig) {
+		return
+	}
+
+	release, err := decodeHelmRelease(secret)
+	if err != nil {
+		w.logger.Warn("Failed to decode Helm release Secret", "kind", "HelmRelease", "namespace", namespace, "name", secret.GetName(), "error", err)
+		return
+	}
+	action, eventType := helmAction(release, previousStatus)
+	if release.Info.Status == "failed" {
+		eventType = notifier.EventHelmFailed
+	}
+
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), "HelmRelease", eventType, namespace, name)
+	trace.Step(StageFiltered, fmt.Sprintf("revision %d %s matched %s", release.Version, release.Info.Status, resourceConfig.Describe()))
+	w.metrics.RecordEventProcessed()
+
+	if w.deduplicator.IsDuplicate("HelmRelease", namespace, name, string(eventType), []string{strconv.Itoa(release.Version)}) {
+		w.metrics.RecordEventDeduplicated()
+		trace.Step(StageDeduplicated, "duplicate within "+w.config.Watcher.GetEventDeduplicationWindow().String())
+		w.traces.Finish(trace, "duplicate")
+		return
+	}
+	trace.Step(StageDeduplicated, "not a duplicate")
+
+	// Compare with the revision this one replaced; a first install is compared with nothing
+	var previous *helmRelease
+	var changedFields, diff []string
+	if release.Version > 1 {
+		previous, err = w.previousHelmRelease(namespace, release)
+	}
+	if err != nil {
+		trace.Logger().Warn("No values diff", "revision", release.Version, "error", err)
+		trace.Step(StageDiffed, "previous revision unavailable")
+	} else {
+		changedFields, diff = w.helmReleaseChanges(previous, release)
+		trace.Step(StageDiffed, describeChangedFields(changedFields))
+	}
+
+	summary := []string{
+		helmChartLine(previous, release),
+		fmt.Sprintf("Revision %d %s: %s", release.Version, release.Info.Status, release.Info.Description),
+	}
+
+	trace.Logger().Info("Helm release "+strings.ToLower(action), "revision", release.Version, "status", release.Info.Status)
+	w.deliver(trace, notifier.NotificationEvent{
+		ID:            trace.ID,
+		EventType:     eventType,
+		ResourceKind:  "HelmRelease",
+		ResourceName:  name,
+		Namespace:     namespace,
+		ChangedFields: changedFields,
+		Diff:          diff,
+		Summary:       summary,
+	})
+}
+
+// decodeHelmRelease decodes the release of a Helm release Secret: the "release" key holds
+// the release JSON, usually gzipped, base64-encoded once by Helm and once more as Secret data
+func decodeHelmRelease(secret *unstructured.Unstructured) (*helmRelease, error) {
+	encoded, ok := secretData(secret)["release"]
+	if !ok {
+		return nil, fmt.Errorf("no release key")
+	}
+	helmEncoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Secret data: %w", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(string(helmEncoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release encoding: %w", err)
+	}
+
+	if bytes.HasPrefix(payload, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+		defer reader.Close()
+		if payload, err = io.ReadAll(io.LimitReader(reader, maxHelmReleaseSize)); err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+	}
+
+	var release helmRelease
+	if err := json.Unmarshal(payload, &release); err != nil {
+		return nil, fmt.Errorf("invalid release: %w", err)
+	}
+	return &release, nil
+}
+
+// previousHelmRelease fetches the revision before release from its Secret
+func (w *InformerWatcher) previousHelmRelease(namespace string, release *helmRelease) (*helmRelease, error) {
+	ctx, cancel := context.WithTimeout(w.ctx, helmLookupTimeout)
+	defer cancel()
+
+	name := fmt.Sprintf("sh.helm.release.v1.%s.v%d", release.Name, release.Version-1)
+	secret, err := w.dynamicClient.Resource(supportedKinds["Secret"].gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return decodeHelmRelease(secret)
+}
+
+// helmAction names what Helm did to reach a revision, from the pending status it left
+// or, when that was not seen, from the revision and Helm's description of it
+func helmAction(release *helmRelease, previousStatus string) (string, notifier.EventType) {
+	switch {
+	case previousStatus == "pending-rollback" || (previousStatus == "" && strings.HasPrefix(release.Info.Description, "Rollback")):
+		return "Rolled back", notifier.EventHelmRolledBack
+	case previousStatus == "pending-install" || (previousStatus == "" && release.Version == 1):
+		return "Installed", notifier.EventHelmInstalled
+	default:
+		return "Upgraded", notifier.EventHelmUpgraded
+	}
+}
+
+// helmChartLine describes the chart of a revision and, when it changed, the previous one,
+// e.g. "Chart nginx 15.1.0 (from 15.0.2), app version 1.25.3 (from 1.25.1)"
+func helmChartLine(previous, release *helmRelease) string {
+	chart := release.Chart.Metadata
+	line := fmt.Sprintf("Chart %s %s", chart.Name, chart.Version)
+	if previous != nil {
+		if old := previous.Chart.Metadata; old.Name != chart.Name {
+			line += fmt.Sprintf(" (from %s %s)", old.Name, old.Version)
+		} else if old.Version != chart.Version {
+			line += fmt.Sprintf(" (from %s)", old.Version)
+		}
+	}
+	if chart.AppVersion != "" {
+		line += ", app version " + chart.AppVersion
+		if previous != nil && previous.Chart.Metadata.AppVersion != "" && previous.Chart.Metadata.AppVersion != chart.AppVersion {
+			line += fmt.Sprintf(" (from %s)", previous.Chart.Metadata.AppVersion)
+		}
+	}
+	return line
+}
+
+// helmReleaseChanges returns the changed chart version and values paths as fields, and
+// "+"/"-"/"~" lines per changed value, cut off after the configured size
+func (w *InformerWatcher) helmReleaseChanges(previous, release *helmRelease) ([]string, []string) {
+	diffConfig := w.config.Watcher.HelmValuesDiff
+	before := make(map[string]string)
+	var changedFields []string
+	if previous != nil {
+		flattenValues("values", previous.Config, before)
+		if previous.Chart.Metadata.Version != release.Chart.Metadata.Version {
+			changedFields = append(changedFields, "chart.version")
+		}
+	}
+	after := make(map[string]string)
+	flattenValues("values", release.Config, after)
+
+	paths := make([]string, 0, len(before)+len(after))
+	for path := range after {
+		paths = append(paths, path)
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var lines []string
+	for _, path := range paths {
+		oldValue, inBefore := before[path]
+		newValue, inAfter := after[path]
+		switch {
+		case oldValue == newValue && inBefore == inAfter:
+			continue
+		case diffConfig.HideValues && !inBefore:
+			lines = append(lines, "+ "+path)
+		case diffConfig.HideValues && !inAfter:
+			lines = append(lines, "- "+path)
+		case diffConfig.HideValues:
+			lines = append(lines, "~ "+path)
+		case !inBefore:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", path, newValue))
+		case !inAfter:
+			lines = append(lines, fmt.Sprintf("- %s: %s", path, oldValue))
+		default:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", path, oldValue, newValue))
+		}
+		changedFields = append(changedFields, path)
+	}
+	return changedFields, truncateLines(lines, diffConfig.GetMaxSize())
+}
+
+// flattenValues maps every leaf of a values tree to its dotted path and JSON value.
+// Lists are leaves, so a changed list is shown whole.
+func flattenValues(prefix string, values map[string]interface{}, flat map[string]string) {
+	for key, value := range values {
+		path := prefix + "." + key
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenValues(path, nested, flat)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		flat[path] = string(encoded)
+	}
+}
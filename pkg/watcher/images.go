@@ -0,0 +1,44 @@
+package watcher
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// imageChanges returns the init and regular containers whose image differs between two
+// pod templates, in template order. Added and removed containers are not image changes.
+func imageChanges(oldSpec, newSpec *corev1.PodSpec) []notifier.ImageChange {
+	previous := make(map[string]string, len(oldSpec.InitContainers)+len(oldSpec.Containers))
+	for _, containers := range [][]corev1.Container{oldSpec.InitContainers, oldSpec.Containers} {
+		for _, container := range containers {
+			previous[container.Name] = container.Image
+		}
+	}
+
+	var changes []notifier.ImageChange
+	for _, containers := range [][]corev1.Container{newSpec.InitContainers, newSpec.Containers} {
+		for _, container := range containers {
+			if image, ok := previous[container.Name]; ok && image != container.Image {
+				changes = append(changes, notifier.ImageChange{Container: container.Name, Old: image, New: container.Image})
+			}
+		}
+	}
+	return changes
+}
+
+// workloadImageChanges returns the image changes of a workload kind's pod template,
+// or nil for other kinds and templates that cannot be read
+func workloadImageChanges(kind string, oldObj, newObj *unstructured.Unstructured) []notifier.ImageChange {
+	resource := supportedKinds[kind]
+	if resource.podSpecPath == nil {
+		return nil
+	}
+	oldSpec, errOld := resource.podSpec(oldObj)
+	newSpec, errNew := resource.podSpec(newObj)
+	if errOld != nil || errNew != nil {
+		return nil
+	}
+	return imageChanges(oldSpec, newSpec)
+}
@@ -2,482 +2,1421 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"reflect"
-
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
-	"k8s.io/client-go/informers"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/archive"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/celfilter"
 	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/fieldpath"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/gitops"
 	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/rules"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/store"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // InformerWatcher represents a Kubernetes resource watcher using Informers
 type InformerWatcher struct {
-	config             *config.Config
-	notifier           notifier.Notifier
-	dynamicClient      dynamic.Interface
-	k8sClient          *kubernetes.Clientset
-	informerFactory    dynamicinformer.DynamicSharedInformerFactory
-	k8sInformerFactory informers.SharedInformerFactory
-
-	informers map[string]cache.SharedIndexInformer
+	config        *config.Config
+	notifier      notifier.Notifier
+	dynamicClient dynamic.Interface
+	k8sClient     kubernetes.Interface
+
+	// One informer per API resource and namespace scope, shared by every resource
+	// entry in that scope. Each informer has its own stop function so a kind can be
+	// moved to the raw watch engine without affecting the others.
+	informers map[informerScope]*sharedInformer
+	engines   map[string]*EngineStatus
+
+	// Namespace lifecycle tracking, so deleted namespaces produce one summary
+	namespaceInformer cache.SharedIndexInformer
+	namespaces        *namespaceTracker
+
+	certificates *certificateTracker // Certificate expiry and failure alerts, resolved on renewal
+	reports      *reportTracker      // What each scheduled report covers since it was last sent
+
+	// Pod metadata cache for object validation; nil unless validateObjects is enabled
+	metadataClient metadata.Interface
+	podInformer    cache.SharedIndexInformer
+	nodeInformer   cache.SharedIndexInformer // Node metadata for topology enrichment; nil unless enabled
+
+	// Workload caches for ConfigMap and Secret blast radius; nil unless blastRadius is enabled
+	consumerInformers map[string]cache.SharedIndexInformer
+
+	gitops  *gitops.Repository // Manifests notified objects are compared with; nil unless enabled
+	archive archive.Archive    // Where the manifests of watched objects are copied; nil unless enabled
+
+	ignoreFields      map[string][]fieldpath.Path
+	significantFields map[string][]fieldpath.Path
+	deduplicator      *Deduplicator
+	logger            *slog.Logger // Adds the cluster name to each record
+	metrics           *WatcherMetrics
+	traces            *TraceRecorder
+	lifecycle         *lifecycle
+	inFlight          *inFlightTracker
+	bus               *eventBus          // Hands events to the notifiers, metrics, history and other subscribers
+	redactor          *notifier.Redactor // Scrubs events handed to the history and other subscribers; nil without rules
+
+	// Persistent event history; nil unless the store is enabled. Closed on
+	// Stop only by the watcher that opened it.
+	eventStore store.Store
+	ownsStore  bool
 
 	mu        sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
 	isStarted bool
+	stopOnce  sync.Once
 }
 
 func NewInformerWatcher(cfg *config.Config, notifier notifier.Notifier) (*InformerWatcher, error) {
+	clients, err := NewClients(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewInformerWatcherWithClients(cfg, notifier, clients)
+}
+
+// NewInformerWatcherWithClients creates a watcher talking to the cluster
+// through the given clients, e.g. the fake cluster of demo mode
+func NewInformerWatcherWithClients(cfg *config.Config, notifier notifier.Notifier, clients Clients) (*InformerWatcher, error) {
+	var eventStore store.Store
+	if cfg.Store.Enabled {
+		var err error
+		if eventStore, err = store.Open(cfg.Store); err != nil {
+			return nil, fmt.Errorf("failed to open event store: %w", err)
+		}
+	}
+
+	watcher := newInformerWatcher(cfg, notifier, eventStore, clients)
+	watcher.ownsStore = true
+	return watcher, nil
+}
+
+// NewClusterWatcher creates a watcher for one of several clusters watched by
+// this instance. It records into eventStore, which may be nil, but leaves
+// closing it to the caller.
+func NewClusterWatcher(cfg *config.Config, notifier notifier.Notifier, eventStore store.Store) (*InformerWatcher, error) {
+	clients, err := NewClients(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newInformerWatcher(cfg, notifier, eventStore, clients), nil
+}
+
+// Clients are the API clients a watcher uses
+type Clients struct {
+	Dynamic    dynamic.Interface
+	Kubernetes kubernetes.Interface
+	Metadata   metadata.Interface // Only needed for validateObjects and topology
+}
+
+// NewClients connects to the cluster the configuration selects
+func NewClients(cfg *config.Config) (Clients, error) {
 	// Load kubeconfig
-	kubeconfig, err := clientcmd.BuildConfigFromFlags("", "")
+	kubeconfig, err := loadRESTConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
+		return Clients{}, err
 	}
 
 	// Create dynamic client
 	dynamicClient, err := dynamic.NewForConfig(kubeconfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+		return Clients{}, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
 	// Create Kubernetes client
 	k8sClient, err := kubernetes.NewForConfig(kubeconfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+		return Clients{}, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	// Create shared informer factories
-	informerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
-	k8sInformerFactory := informers.NewSharedInformerFactory(k8sClient, 0)
+	var metadataClient metadata.Interface
+	if cfg.Watcher.ValidateObjects || cfg.Watcher.Topology.Enabled {
+		if metadataClient, err = metadata.NewForConfig(kubeconfig); err != nil {
+			return Clients{}, fmt.Errorf("failed to create metadata client: %w", err)
+		}
+	}
+	return Clients{Dynamic: dynamicClient, Kubernetes: k8sClient, Metadata: metadataClient}, nil
+}
 
+func newInformerWatcher(cfg *config.Config, notifier notifier.Notifier, eventStore store.Store, clients Clients) *InformerWatcher {
 	ctx, cancel := context.WithCancel(context.Background())
+	logger := slog.Default().With("cluster", cfg.ClusterName)
 
 	watcher := &InformerWatcher{
-		config:             cfg,
-		notifier:           notifier,
-		dynamicClient:      dynamicClient,
-		k8sClient:          k8sClient,
-		informerFactory:    informerFactory,
-		k8sInformerFactory: k8sInformerFactory,
-		informers:          make(map[string]cache.SharedIndexInformer),
-		ctx:                ctx,
-		cancel:             cancel,
-		isStarted:          false,
+		config:            cfg,
+		notifier:          notifier,
+		dynamicClient:     clients.Dynamic,
+		k8sClient:         clients.Kubernetes,
+		metadataClient:    clients.Metadata,
+		eventStore:        eventStore,
+		informers:         make(map[informerScope]*sharedInformer),
+		engines:           make(map[string]*EngineStatus),
+		deduplicator:      NewDeduplicator(cfg.Watcher.GetEventDeduplicationWindow()),
+		metrics:           NewWatcherMetrics(),
+		logger:            logger,
+		traces:            NewTraceRecorder(cfg.Watcher.GetTraceBufferSize(), logger),
+		lifecycle:         newLifecycle(logger),
+		inFlight:          newInFlightTracker(),
+		bus:               newEventBus(),
+		namespaces:        newNamespaceTracker(),
+		certificates:      newCertificateTracker(),
+		reports:           newReportTracker(),
+		ignoreFields:      make(map[string][]fieldpath.Path),
+		significantFields: make(map[string][]fieldpath.Path),
+		ctx:               ctx,
+		cancel:            cancel,
+		isStarted:         false,
 	}
 
-	return watcher, nil
+	// Compile the field paths once per kind
+	for _, resourceConfig := range cfg.Resources {
+		kind := resourceConfig.Kind
+		watcher.ignoreFields[kind] = compileFieldPaths(cfg.Watcher.GetIgnoreFields(kind))
+		watcher.significantFields[kind] = compileFieldPaths(cfg.Watcher.SignificantFields[kind])
+	}
+
+	if cfg.Watcher.GitOps.Enabled {
+		watcher.gitops = gitops.NewRepository(cfg.Watcher.GitOps, cfg.ClusterName, logger)
+	}
+	if cfg.Archive.Enabled {
+		watcher.archive = archive.New(cfg.Archive)
+	}
+
+	watcher.subscribeConsumers()
+	return watcher
 }
 
 // Start begins watching all configured resources
 func (w *InformerWatcher) Start() error {
-	log.Printf("Starting Informer-based resource watcher...")
+	w.logger.Info("Starting Informer-based resource watcher")
 
 	// Create and start informers for each resource type
 	for _, resourceConfig := range w.config.Resources {
 		if err := w.createInformer(resourceConfig); err != nil {
-			log.Printf("Failed to create informer for %s: %v", resourceConfig.Kind, err)
+			w.logger.Error("Failed to create informer", "kind", resourceConfig.Kind, "error", err)
 			continue
 		}
 	}
 
+	// Find missing RBAC permissions before the informers retry denied requests forever
+	if err := w.checkPermissions(); err != nil {
+		return err
+	}
+
+	// With watcher.engine: raw-watch, the same handlers are served without informer caches
+	if w.config.Watcher.GetEngine() == config.WatchEngineRawWatch {
+		for _, status := range w.GetEngineStatus() {
+			w.switchToRawWatch(status.Kind, false, "selected by watcher.engine")
+		}
+		w.logger.Info("Serving every kind from raw watch engines")
+	}
+
 	// Start all informers
-	w.informerFactory.Start(w.ctx.Done())
-	w.k8sInformerFactory.Start(w.ctx.Done())
+	w.mu.Lock()
+	for _, shared := range w.informers {
+		informerCtx, stop := context.WithCancel(w.ctx)
+		shared.stop = stop
+		go shared.informer.Run(informerCtx.Done())
+	}
+	w.mu.Unlock()
+
+	// Namespaces are tracked for pruning only and are not part of the cache sync
+	if w.tracksNamespaces() {
+		w.namespaceInformer = w.newNamespaceInformer()
+		go w.namespaceInformer.Run(w.ctx.Done())
+	} else {
+		w.logger.Info("Not tracking namespaces: every kind is watched in named namespaces (set watcher.namespaceTracking to enable)")
+	}
 
-	// Wait for caches to sync
-	log.Printf("Waiting for informer caches to sync...")
-	if !cache.WaitForCacheSync(w.ctx.Done(), w.getCacheSyncFuncs()...) {
-		return fmt.Errorf("failed to sync informer caches")
+	// Pods are only cached for validation; a slow sync just delays the Service check
+	if w.config.Watcher.ValidateObjects {
+		w.podInformer = newPodMetadataInformer(w.metadataClient)
+		go w.podInformer.Run(w.ctx.Done())
+	}
+
+	// Nodes are only cached for topology enrichment, which is skipped until they sync
+	if w.config.Watcher.Topology.Enabled {
+		w.nodeInformer = newNodeMetadataInformer(w.metadataClient)
+		go w.nodeInformer.Run(w.ctx.Done())
+	}
+
+	// Workloads are only cached for blast radius lookups, which are skipped until they sync
+	if w.config.Watcher.BlastRadius {
+		w.consumerInformers = w.newConsumerInformers()
+		for _, informer := range w.consumerInformers {
+			go informer.Run(w.ctx.Done())
+		}
 	}
 
+	// The GitOps repository is pulled alongside; drift is not reported until the first pull
+	if w.gitops != nil {
+		go w.gitops.Run(w.ctx)
+	}
+
+	// Wait for caches to sync, falling back to raw watches for kinds that cannot
+	syncStart := time.Now()
+	if err := w.waitForCacheSync(); err != nil {
+		return err
+	}
+	w.metrics.RecordStartupSync(time.Since(syncStart))
+
+	w.logger.Info("All informer caches synced successfully")
+	w.lifecycle.run(w.ctx, PhaseCacheSynced)
+
 	// Set the startup flag AFTER caches are synced
 	w.mu.Lock()
 	w.isStarted = true
 	w.mu.Unlock()
 
-	log.Printf("All informer caches synced successfully")
+	for _, warning := range w.LintLiveSelectors() {
+		w.logger.Warn("Config warning: " + warning)
+	}
+	if baseline := w.config.Watcher.Baseline; baseline.Notify || baseline.Store {
+		w.sendBaseline()
+	}
+	if len(w.config.Watcher.Reports) > 0 {
+		w.startReports()
+	}
+
+	for _, resourceConfig := range w.config.Resources {
+		if resourceConfig.Kind == "Certificate" {
+			go w.runCertificateExpiryChecks()
+			break
+		}
+	}
+
+	w.lifecycle.run(w.ctx, PhaseStarted)
+	return nil
+}
+
+// GetMetrics returns a snapshot of the watcher metrics with process and cache stats
+func (w *InformerWatcher) GetMetrics() MetricsSnapshot {
+	snapshot := w.metrics.Snapshot()
+	snapshot.Goroutines = runtime.NumGoroutine()
+	snapshot.EventBus = w.bus.stats()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	snapshot.CacheObjects = make(map[string]int, len(w.informers))
+	for _, shared := range w.informers {
+		snapshot.CacheObjects[shared.kind] += len(shared.informer.GetStore().ListKeys())
+	}
+	return snapshot
+}
+
+// waitForCacheSync waits up to the cache sync timeout for every informer.
+// Kinds that fail to sync are switched to the raw watch engine unless the
+// fallback is disabled, in which case startup fails.
+func (w *InformerWatcher) waitForCacheSync() error {
+	timeout := w.config.Watcher.GetCacheSyncTimeout()
+	w.logger.Info("Waiting for informer caches to sync", "timeout", timeout.String())
+
+	syncCtx, cancel := context.WithTimeout(w.ctx, timeout)
+	defer cancel()
+
+	w.mu.RLock()
+	informers := make([]*sharedInformer, 0, len(w.informers))
+	for _, shared := range w.informers {
+		informers = append(informers, shared)
+	}
+	w.mu.RUnlock()
+	sort.Slice(informers, func(i, j int) bool { return informers[i].key < informers[j].key })
+
+	var failed []string
+	for _, shared := range informers {
+		if !cache.WaitForCacheSync(syncCtx.Done(), shared.informer.HasSynced) {
+			if w.ctx.Err() != nil {
+				return fmt.Errorf("watcher stopped before informer caches synced")
+			}
+			// A kind falls back as a whole, even if only one of its namespaces failed
+			if len(failed) == 0 || failed[len(failed)-1] != shared.kind {
+				failed = append(failed, shared.kind)
+			}
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	if w.config.Watcher.DisableWatchFallback {
+		// Keep the API error, e.g. forbidden, for the caller to tell why
+		if lastErr := w.engineStatus(failed[0]).lastErr; lastErr != nil {
+			return fmt.Errorf("failed to sync informer caches for %s within %s: %w", strings.Join(failed, ", "), timeout, lastErr)
+		}
+		return fmt.Errorf("failed to sync informer caches for %s within %s", strings.Join(failed, ", "), timeout)
+	}
+
+	for _, kind := range failed {
+		w.fallBackToRawWatch(kind, fmt.Sprintf("informer cache did not sync within %s (%d list/watch errors)",
+			timeout, w.engineStatus(kind).WatchErrors))
+	}
 	return nil
 }
 
+// fallBackToRawWatch serves kind from raw watch engines after its informers failed, marking it degraded
+func (w *InformerWatcher) fallBackToRawWatch(kind, reason string) {
+	if w.switchToRawWatch(kind, true, reason) {
+		w.logger.Warn("Falling back to raw watch engine", "kind", kind, "reason", reason)
+		w.metrics.RecordEngineFallback()
+	}
+}
+
+// switchToRawWatch stops the informers for kind and serves their handlers from raw watch
+// engines instead, reporting false if raw watches already serve the kind
+func (w *InformerWatcher) switchToRawWatch(kind string, degraded bool, reason string) bool {
+	w.mu.Lock()
+	status := w.engines[kind]
+	if status.Engine == EngineRawWatch {
+		w.mu.Unlock()
+		return false
+	}
+	informers := w.kindInformers(kind)
+	for _, shared := range informers {
+		if shared.stop != nil {
+			shared.stop()
+		}
+		delete(w.informers, informerScope{gvr: supportedKinds[kind].gvr, namespace: shared.namespace})
+	}
+
+	status.Engine = EngineRawWatch
+	status.Degraded = degraded
+	status.Reason = reason
+	status.Since = time.Now()
+	w.mu.Unlock()
+
+	for _, shared := range informers {
+		w.startRawWatch(shared)
+	}
+	return true
+}
+
+// startRawWatch serves the handlers of a shared informer from a raw watch engine
+func (w *InformerWatcher) startRawWatch(shared *sharedInformer) {
+	engine := newRawWatchEngine(w.dynamicClient, shared.kind, shared.namespace, supportedKinds[shared.kind], shared.handlers, func(err error) {
+		w.recordWatchError(shared.kind, err)
+	}, w.logger.With("watcher", shared.key, "kind", shared.kind))
+	go engine.Run(w.ctx)
+}
+
+// recordWatchError counts a list/watch failure against a kind
+func (w *InformerWatcher) recordWatchError(kind string, err error) {
+	w.mu.Lock()
+	if status, ok := w.engines[kind]; ok {
+		if status.ConsecutiveFailures == 0 {
+			status.failingSince = time.Now()
+		}
+		status.WatchErrors++
+		status.ConsecutiveFailures++
+		status.LastError = err.Error()
+		status.lastErr = err
+	}
+	w.mu.Unlock()
+
+	w.metrics.RecordWatchError()
+	w.logger.Warn("Watch error", "kind", kind, "error", err)
+}
+
+// engineStatus returns a copy of the engine status for kind
+func (w *InformerWatcher) engineStatus(kind string) EngineStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if status, ok := w.engines[kind]; ok {
+		return *status
+	}
+	return EngineStatus{Kind: kind}
+}
+
+// GetEngineStatus reports which engine serves each kind and whether it is degraded
+func (w *InformerWatcher) GetEngineStatus() []EngineStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	statuses := make([]EngineStatus, 0, len(w.engines))
+	for kind, status := range w.engines {
+		copied := *status
+		if found, synced := w.kindSynced(kind); found && status.Engine == EngineInformer {
+			copied.CacheSynced = synced
+		}
+		statuses = append(statuses, copied)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Kind < statuses[j].Kind })
+	return statuses
+}
+
 // Stop gracefully shuts down the watcher
 func (w *InformerWatcher) Stop() {
-	log.Printf("Stopping Informer-based resource watcher...")
-	w.cancel()
-	log.Printf("Informer-based resource watcher stopped")
+	w.stopOnce.Do(func() {
+		w.logger.Info("Stopping Informer-based resource watcher")
+
+		// Stop accepting events and let notifications already being sent finish
+		drainTimeout := w.config.Watcher.GetDrainTimeout()
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+		if remaining := w.inFlight.drain(drainCtx); remaining > 0 {
+			w.logger.Warn("Drain timeout reached; cancelling the events still being processed", "timeout", drainTimeout.String(), "remaining", remaining)
+		}
+		cancelDrain()
+
+		// Stopping hooks get their own deadline since the watcher context is still live
+		ctx, cancel := context.WithTimeout(context.Background(), stoppingHookTimeout)
+		w.lifecycle.run(ctx, PhaseStopping)
+		cancel()
+
+		w.cancel()
+
+		// Let the subscribers record the outcome of the last notifications
+		busCtx, cancelBus := context.WithTimeout(context.Background(), busCloseTimeout)
+		if remaining := w.bus.close(busCtx); remaining > 0 {
+			w.logger.Warn("Event bus close timeout reached with events still queued", "timeout", busCloseTimeout.String(), "remaining", remaining)
+		}
+		cancelBus()
+
+		if w.eventStore != nil && w.ownsStore {
+			if err := w.eventStore.Close(); err != nil {
+				w.logger.Error("Failed to close event store", "error", err)
+			}
+		}
+		w.logger.Info("Informer-based resource watcher stopped")
+	})
 }
 
-// createInformer creates an informer for a specific resource type
+// createInformer layers the event handler of a resource entry on the shared informer
+// of its scope, creating the informer on first use
 func (w *InformerWatcher) createInformer(resourceConfig config.ResourceConfig) error {
-	var informer cache.SharedIndexInformer
-
-	switch resourceConfig.Kind {
-	case "Deployment":
-		// Use Kubernetes client informer for Deployments (better type safety)
-		deploymentInformer := w.k8sInformerFactory.Apps().V1().Deployments().Informer()
-		deploymentInformer.AddEventHandler(w.createDeploymentEventHandler(resourceConfig))
-		informer = deploymentInformer
-
-	case "ConfigMap":
-		configMapInformer := w.informerFactory.ForResource(schema.GroupVersionResource{
-			Group:    "",
-			Version:  "v1",
-			Resource: "configmaps",
-		}).Informer()
-		configMapInformer.AddEventHandler(w.createResourceEventHandler(resourceConfig, "ConfigMap"))
-		informer = configMapInformer
-
-	case "Secret":
-		secretInformer := w.informerFactory.ForResource(schema.GroupVersionResource{
-			Group:    "",
-			Version:  "v1",
-			Resource: "secrets",
-		}).Informer()
-		secretInformer.AddEventHandler(w.createResourceEventHandler(resourceConfig, "Secret"))
-		informer = secretInformer
-
-	case "Service":
-		serviceInformer := w.informerFactory.ForResource(schema.GroupVersionResource{
-			Group:    "",
-			Version:  "v1",
-			Resource: "services",
-		}).Informer()
-		serviceInformer.AddEventHandler(w.createResourceEventHandler(resourceConfig, "Service"))
-		informer = serviceInformer
-
-	case "Ingress":
-		ingressInformer := w.informerFactory.ForResource(schema.GroupVersionResource{
-			Group:    "networking.k8s.io",
-			Version:  "v1",
-			Resource: "ingresses",
-		}).Informer()
-		ingressInformer.AddEventHandler(w.createResourceEventHandler(resourceConfig, "Ingress"))
-		informer = ingressInformer
+	resource, ok := supportedKinds[resourceConfig.Kind]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedKind, resourceConfig.Kind)
+	}
 
+	var handler cache.ResourceEventHandler
+	switch {
+	case resourceConfig.HelmReleases:
+		handler = w.createHelmReleaseEventHandler(resourceConfig)
+	case resourceConfig.Kind == "Deployment":
+		handler = w.createDeploymentEventHandler(resourceConfig)
+	case resourceConfig.Kind == "Pod":
+		handler = w.createPodEventHandler(resourceConfig)
+	case resourceConfig.Kind == "Event":
+		handler = w.createKubeEventHandler(resourceConfig)
+	case resourceConfig.Kind == "Certificate":
+		handler = w.createCertificateEventHandler(resourceConfig)
 	default:
-		return fmt.Errorf("unsupported resource kind: %s", resourceConfig.Kind)
+		handler = w.createResourceEventHandler(resourceConfig, resourceConfig.Kind)
 	}
 
-	// Store informer reference
 	w.mu.Lock()
-	w.informers[resourceConfig.Kind] = informer
-	w.mu.Unlock()
+	defer w.mu.Unlock()
 
-	// Log the monitoring configuration
-	if resourceConfig.ResourceName != "" {
-		if resourceConfig.Namespace != "" {
-			log.Printf("Created informer for %s '%s' in namespace '%s'",
-				resourceConfig.Kind, resourceConfig.ResourceName, resourceConfig.Namespace)
-		} else {
-			log.Printf("Created informer for %s '%s' across all namespaces",
-				resourceConfig.Kind, resourceConfig.ResourceName)
+	scope := w.entryScope(resourceConfig)
+	shared, exists := w.informers[scope]
+	if !exists {
+		shared = w.newSharedInformer(resourceConfig.Kind, scope, resource)
+		w.informers[scope] = shared
+	}
+	if _, ok := w.engines[resourceConfig.Kind]; !ok {
+		w.engines[resourceConfig.Kind] = &EngineStatus{
+			Kind:   resourceConfig.Kind,
+			Engine: EngineInformer,
+			Since:  time.Now(),
 		}
-	} else if resourceConfig.Namespace != "" {
-		log.Printf("Created informer for all %s resources in namespace '%s'",
-			resourceConfig.Kind, resourceConfig.Namespace)
-	} else {
-		log.Printf("Created informer for all %s resources across all namespaces",
-			resourceConfig.Kind)
 	}
+
+	handler = w.trackInFlight(w.observeEvents(resourceConfig.Kind, w.detectMissedChanges(shared, resourceConfig, handler)))
+	shared.handlers = append(shared.handlers, handler)
+	if w.archivesEntry(resourceConfig) {
+		w.archiveEntry(shared, resourceConfig)
+	}
+	w.resyncEntry(shared, resourceConfig)
+
+	// Log the monitoring configuration
+	w.logger.Info("Created informer for "+resourceConfig.Describe(), "watcher", shared.key)
 	return nil
 }
 
+// newSharedInformer builds the informer of a scope, counting its list/watch errors and
+// resyncing at the shortest period of its entries, with the shared informer as its handler
+func (w *InformerWatcher) newSharedInformer(kind string, scope informerScope, resource resourceKind) *sharedInformer {
+	shared := &sharedInformer{
+		key:       informerKey(kind, scope.namespace),
+		kind:      kind,
+		namespace: scope.namespace,
+		resyncs:   make(map[time.Duration]*resyncHandler),
+	}
+	w.buildInformer(shared, scope, resource)
+	return shared
+}
+
+// buildInformer gives a shared informer a new informer with an empty cache, registering the
+// shared informer and its resync handlers on it
+func (w *InformerWatcher) buildInformer(shared *sharedInformer, scope informerScope, resource resourceKind) {
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	resync := w.scopeResyncPeriod(scope)
+	if shared.kind == "Deployment" {
+		// Use Kubernetes client informer for Deployments (better type safety)
+		shared.informer = appsinformers.NewDeploymentInformer(w.k8sClient, scope.namespace, resync, indexers)
+	} else {
+		shared.informer = dynamicinformer.NewFilteredDynamicInformer(w.dynamicClient, resource.gvr, scope.namespace, resync, indexers, nil).Informer()
+	}
+
+	shared.informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		w.recordWatchError(shared.kind, err)
+		w.rememberCachedVersions(shared)
+	})
+	// Resyncs are received by the resync handlers only, so they never reach the entries
+	shared.informer.AddEventHandlerWithResyncPeriod(shared, 0)
+	for period, handler := range shared.resyncs {
+		if _, err := shared.informer.AddEventHandlerWithResyncPeriod(handler, period); err != nil {
+			w.logger.Warn("Failed to register resync", "watcher", shared.key, "resyncPeriod", period, "error", err)
+		}
+	}
+}
+
 // createResourceEventHandler creates event handlers for infrastructure resources
 func (w *InformerWatcher) createResourceEventHandler(resourceConfig config.ResourceConfig, resourceKind string) cache.ResourceEventHandlerFuncs {
-	return cache.ResourceEventHandlerFuncs{
+	return w.withEventTypes(resourceConfig, cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			if !w.isStarted {
-				log.Printf("[%s] Resource discovered during startup sync - skipping notification", resourceKind)
+			if !w.started() {
+				w.logger.Debug("Resource discovered during startup sync - skipping notification", "kind", resourceKind)
 				return
 			}
 			w.handleResourceAdded(obj, resourceConfig, resourceKind)
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
-			if !w.isStarted {
+			if !w.started() {
 				return
 			}
 			w.handleResourceUpdated(oldObj, newObj, resourceConfig, resourceKind)
 		},
 		DeleteFunc: func(obj interface{}) {
 			// Skip notifications during startup sync
-			if !w.isStarted {
+			if !w.started() {
 				return
 			}
 			w.handleResourceDeleted(obj, resourceConfig, resourceKind)
 		},
+	})
+}
+
+// started reports whether the initial cache sync has finished. Handlers check it under
+// the lock because Start sets it while the informers are already delivering events.
+func (w *InformerWatcher) started() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.isStarted
+}
+
+// withEventTypes drops the handlers of event types the resource entry does not notify,
+// so unwanted events are never processed or traced
+func (w *InformerWatcher) withEventTypes(resourceConfig config.ResourceConfig, handler cache.ResourceEventHandlerFuncs) cache.ResourceEventHandlerFuncs {
+	if !resourceConfig.NotifiesEventType(string(notifier.EventAdded)) {
+		handler.AddFunc = nil
 	}
+	if !resourceConfig.NotifiesEventType(string(notifier.EventModified)) {
+		handler.UpdateFunc = nil
+	}
+	if !resourceConfig.NotifiesEventType(string(notifier.EventDeleted)) {
+		handler.DeleteFunc = nil
+	}
+	return handler
 }
 
 // createDeploymentEventHandler creates event handlers specifically for Deployments
 func (w *InformerWatcher) createDeploymentEventHandler(resourceConfig config.ResourceConfig) cache.ResourceEventHandlerFuncs {
-	return cache.ResourceEventHandlerFuncs{
+	return w.withEventTypes(resourceConfig, cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			// Skip notifications during startup sync
-			w.mu.RLock()
-			started := w.isStarted
-			w.mu.RUnlock()
-
-			if !started {
-				log.Printf("[Deployment] Resource discovered during startup sync - will track for important field changes")
+			if !w.started() {
+				w.logger.Debug("Resource discovered during startup sync - will track for important field changes", "kind", "Deployment")
 				return
 			}
 			w.handleDeploymentAdded(obj, resourceConfig)
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			// Skip notifications during startup sync
-			w.mu.RLock()
-			started := w.isStarted
-			w.mu.RUnlock()
-
-			if !started {
+			if !w.started() {
 				return
 			}
 			w.handleDeploymentUpdated(oldObj, newObj, resourceConfig)
 		},
 		DeleteFunc: func(obj interface{}) {
 			// Skip notifications during startup sync
-			w.mu.RLock()
-			started := w.isStarted
-			w.mu.RUnlock()
-
-			if !started {
+			if !w.started() {
 				return
 			}
 			w.handleDeploymentDeleted(obj, resourceConfig)
 		},
-	}
+	})
 }
 
 // handleResourceAdded handles ADDED events for infrastructure resources
 func (w *InformerWatcher) handleResourceAdded(obj interface{}, resourceConfig config.ResourceConfig, resourceKind string) {
+	const eventType = notifier.EventAdded
 	unstructuredObj, ok := obj.(*unstructured.Unstructured)
 	if !ok {
-		log.Printf("Failed to convert %s to unstructured object", resourceKind)
+		w.logger.Warn("Failed to convert object to unstructured", "kind", resourceKind)
 		return
 	}
 
-	if !w.shouldProcessResource(unstructuredObj, resourceConfig) {
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), resourceKind, eventType, unstructuredObj.GetNamespace(), unstructuredObj.GetName())
+	if !w.filterEvent(trace, w.shouldProcessResource(unstructuredObj, resourceConfig), resourceConfig) ||
+		!w.matchesFilter(trace, resourceConfig, nil, unstructuredObj) {
 		return
 	}
 
-	log.Printf("[%s] Resource %s/%s was ADDED", resourceKind, unstructuredObj.GetNamespace(), unstructuredObj.GetName())
+	trace.Logger().Info("Resource was ADDED")
 
 	// Send immediate notification for infrastructure resources
-	w.sendNotification(resourceKind, "ADDED", unstructuredObj.GetName(), unstructuredObj.GetNamespace())
+	w.sendNotification(trace, resourceKind, eventType, unstructuredObj, nil, kindDiff(resourceKind, nil, unstructuredObj))
 }
 
 // handleResourceUpdated handles MODIFIED events for infrastructure resources
 func (w *InformerWatcher) handleResourceUpdated(oldObj, newObj interface{}, resourceConfig config.ResourceConfig, resourceKind string) {
-	_, ok := oldObj.(*unstructured.Unstructured)
+	oldUnstructured, ok := oldObj.(*unstructured.Unstructured)
 	if !ok {
-		log.Printf("Failed to convert old %s to unstructured object", resourceKind)
+		w.logger.Warn("Failed to convert old object to unstructured", "kind", resourceKind)
 		return
 	}
 
 	newUnstructured, ok := newObj.(*unstructured.Unstructured)
 	if !ok {
-		log.Printf("Failed to convert new %s to unstructured object", resourceKind)
+		w.logger.Warn("Failed to convert new object to unstructured", "kind", resourceKind)
 		return
 	}
 
-	if !w.shouldProcessResource(newUnstructured, resourceConfig) {
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), resourceKind, notifier.EventModified, newUnstructured.GetNamespace(), newUnstructured.GetName())
+	if !w.filterEvent(trace, w.shouldProcessResource(newUnstructured, resourceConfig), resourceConfig) ||
+		!w.matchesFilter(trace, resourceConfig, oldUnstructured, newUnstructured) {
 		return
 	}
 
-	log.Printf("[%s] Resource %s/%s was MODIFIED", resourceKind, newUnstructured.GetNamespace(), newUnstructured.GetName())
+	if len(resourceConfig.WatchExpressions) > 0 {
+		w.handleWatchExpressionsUpdated(trace, resourceConfig, resourceKind, oldUnstructured, newUnstructured)
+		return
+	}
+	if resourceKind == "Node" {
+		w.handleNodeUpdated(trace, oldUnstructured, newUnstructured)
+		return
+	}
+	if fluxKinds[resourceKind] {
+		w.handleFluxUpdated(trace, resourceKind, oldUnstructured, newUnstructured)
+		return
+	}
+	if resourceKind == "Secret" {
+		w.handleSecretUpdated(trace, oldUnstructured, newUnstructured)
+		return
+	}
+	if quotaKinds[resourceKind] {
+		w.handleQuotaUpdated(trace, resourceKind, oldUnstructured, newUnstructured)
+		return
+	}
+	if volumeKinds[resourceKind] {
+		w.handleVolumeUpdated(trace, resourceKind, oldUnstructured, newUnstructured)
+		return
+	}
+	if autoscalerKinds[resourceKind] {
+		w.handleAutoscalerUpdated(trace, resourceKind, oldUnstructured, newUnstructured)
+		return
+	}
+	if resourceKind == "Certificate" {
+		w.handleCertificateSpecUpdated(trace, oldUnstructured, newUnstructured)
+		return
+	}
+	if resourceKind == "CertificateSigningRequest" {
+		w.handleCSRUpdated(trace, oldUnstructured, newUnstructured)
+		return
+	}
+	if resourceKind == "ServiceAccount" {
+		w.handleServiceAccountUpdated(trace, oldUnstructured, newUnstructured)
+		return
+	}
+	if resourceKind == "CustomResourceDefinition" {
+		w.handleCRDUpdated(trace, oldUnstructured, newUnstructured)
+		return
+	}
+	if routingKinds[resourceKind] {
+		w.handleRoutingUpdated(trace, resourceKind, oldUnstructured, newUnstructured)
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED")
+
+	changedFields, notify := w.resourceChanges(trace, resourceKind, oldUnstructured, newUnstructured)
+	if !notify {
+		return
+	}
+
+	diff := rbacDiff(resourceKind, oldUnstructured, newUnstructured)
+	if resourceKind == "ConfigMap" {
+		diff = w.configMapDiff(oldUnstructured, newUnstructured)
+	}
 
 	// Send immediate notification for infrastructure resources
-	w.sendNotification(resourceKind, "MODIFIED", newUnstructured.GetName(), newUnstructured.GetNamespace())
+	w.sendEvent(trace, newUnstructured, notifier.NotificationEvent{
+		EventType:     notifier.EventModified,
+		ResourceKind:  resourceKind,
+		ChangedFields: changedFields,
+		Diff:          diff,
+		ImageChanges:  workloadImageChanges(resourceKind, oldUnstructured, newUnstructured),
+	})
+}
+
+// resourceChanges returns the fields whose change makes an update worth notifying,
+// or false after finishing the trace when only ignored or unimportant fields changed
+func (w *InformerWatcher) resourceChanges(trace *EventTrace, resourceKind string, oldUnstructured, newUnstructured *unstructured.Unstructured) ([]string, bool) {
+	changedFields := changedObjectFields(oldUnstructured, newUnstructured)
+	compareOld, compareNew := oldUnstructured, newUnstructured
+	if paths := w.ignoreFields[resourceKind]; len(paths) > 0 && len(changedFields) > 0 {
+		compareOld, compareNew = stripFields(oldUnstructured, paths), stripFields(newUnstructured, paths)
+		changedFields = changedObjectFields(compareOld, compareNew)
+		if len(changedFields) == 0 {
+			trace.Logger().Debug("Only ignored fields changed (skipping notification)")
+			trace.Step(StageDiffed, "only ignored fields changed")
+			w.traces.Finish(trace, "ignored")
+			return nil, false
+		}
+	}
+
+	// With significant fields configured, only changes to those paths are notified;
+	// workload kinds otherwise use the important pod template fields like Deployments
+	if paths := w.significantFields[resourceKind]; len(paths) > 0 {
+		changedFields = changedSignificantFields(paths, compareOld.Object, compareNew.Object)
+		if len(changedFields) == 0 {
+			trace.Logger().Debug("Non-significant changes detected (skipping notification)")
+			trace.Step(StageDiffed, "no significant fields changed")
+			w.traces.Finish(trace, "ignored")
+			return nil, false
+		}
+	} else if resource := supportedKinds[resourceKind]; resource.podSpecPath != nil {
+		oldSpec, errOld := resource.podSpec(compareOld)
+		newSpec, errNew := resource.podSpec(compareNew)
+		if errOld != nil || errNew != nil {
+			trace.Logger().Warn("Failed to read pod template, notifying all changes")
+		} else if changedFields = w.changedPodSpecFields(oldSpec, newSpec); len(changedFields) == 0 {
+			trace.Logger().Debug("Non-important changes detected (skipping notification)")
+			trace.Step(StageDiffed, "no important fields changed")
+			w.traces.Finish(trace, "ignored")
+			return nil, false
+		}
+	}
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	return changedFields, true
+}
+
+// specChanges is resourceChanges leaving out status, for kinds whose controllers keep
+// updating it; it returns false after finishing the trace when only status changed
+func (w *InformerWatcher) specChanges(trace *EventTrace, resourceKind string, oldUnstructured, newUnstructured *unstructured.Unstructured) ([]string, bool) {
+	compareOld, compareNew := withoutStatus(oldUnstructured), withoutStatus(newUnstructured)
+	if len(changedObjectFields(compareOld, compareNew)) == 0 {
+		trace.Logger().Debug("Only status changed (skipping notification)")
+		trace.Step(StageDiffed, "only status changed")
+		w.traces.Finish(trace, "ignored")
+		return nil, false
+	}
+	return w.resourceChanges(trace, resourceKind, compareOld, compareNew)
+}
+
+// withoutStatus returns a copy of the object with its status removed
+func withoutStatus(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	trimmed := obj.DeepCopy()
+	unstructured.RemoveNestedField(trimmed.Object, "status")
+	return trimmed
 }
 
 // handleResourceDeleted handles DELETED events for infrastructure resources
 func (w *InformerWatcher) handleResourceDeleted(obj interface{}, resourceConfig config.ResourceConfig, resourceKind string) {
+	const eventType = notifier.EventDeleted
 	unstructuredObj, ok := obj.(*unstructured.Unstructured)
 	if !ok {
-		log.Printf("Failed to convert %s to unstructured object", resourceKind)
+		w.logger.Warn("Failed to convert object to unstructured", "kind", resourceKind)
 		return
 	}
 
-	if !w.shouldProcessResource(unstructuredObj, resourceConfig) {
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), resourceKind, eventType, unstructuredObj.GetNamespace(), unstructuredObj.GetName())
+	if !w.filterEvent(trace, w.shouldProcessResource(unstructuredObj, resourceConfig), resourceConfig) ||
+		!w.matchesFilter(trace, resourceConfig, nil, unstructuredObj) {
 		return
 	}
 
-	log.Printf("[%s] Resource %s/%s was DELETED", resourceKind, unstructuredObj.GetNamespace(), unstructuredObj.GetName())
+	if w.pruneNamespaceDeletion(trace, resourceKind, unstructuredObj.GetNamespace()) {
+		return
+	}
+
+	trace.Logger().Info("Resource was DELETED")
+
+	if routingKinds[resourceKind] {
+		w.sendRoutingEvent(trace, resourceKind, eventType, unstructuredObj, nil, nil)
+		return
+	}
 
 	// Send immediate notification for infrastructure resources
-	w.sendNotification(resourceKind, "DELETED", unstructuredObj.GetName(), unstructuredObj.GetNamespace())
+	w.sendNotification(trace, resourceKind, eventType, unstructuredObj, nil, kindDiff(resourceKind, unstructuredObj, nil))
 }
 
 // handleDeploymentAdded handles ADDED events for Deployments
 func (w *InformerWatcher) handleDeploymentAdded(obj interface{}, resourceConfig config.ResourceConfig) {
 	deployment, ok := obj.(*appsv1.Deployment)
 	if !ok {
-		log.Printf("[Deployment] Failed to convert to deployment object")
+		w.logger.Warn("Failed to convert object to a typed deployment", "kind", "Deployment")
 		return
 	}
 
-	if !w.shouldProcessDeployment(deployment, resourceConfig) {
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), "Deployment", notifier.EventAdded, deployment.Namespace, deployment.Name)
+	if !w.filterEvent(trace, w.shouldProcessDeployment(deployment, resourceConfig), resourceConfig) ||
+		!w.matchesFilter(trace, resourceConfig, nil, deployment) {
 		return
 	}
 
-	log.Printf("[Deployment] Resource %s/%s was ADDED", deployment.Namespace, deployment.Name)
+	trace.Logger().Info("Resource was ADDED")
 
-	w.sendNotification("Deployment", "ADDED", deployment.Name, deployment.Namespace)
+	w.sendNotification(trace, "Deployment", notifier.EventAdded, deployment, nil, nil)
 }
 
 // handleDeploymentUpdated handles MODIFIED events for Deployments
 func (w *InformerWatcher) handleDeploymentUpdated(oldObj, newObj interface{}, resourceConfig config.ResourceConfig) {
 	oldDeployment, ok := oldObj.(*appsv1.Deployment)
 	if !ok {
-		log.Printf("Failed to convert old deployment to typed object")
+		w.logger.Warn("Failed to convert old object to a typed deployment", "kind", "Deployment")
 		return
 	}
 
 	newDeployment, ok := newObj.(*appsv1.Deployment)
 	if !ok {
-		log.Printf("Failed to convert new deployment to typed object")
+		w.logger.Warn("Failed to convert new object to a typed deployment", "kind", "Deployment")
 		return
 	}
 
-	if !w.shouldProcessDeployment(newDeployment, resourceConfig) {
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), "Deployment", notifier.EventModified, newDeployment.Namespace, newDeployment.Name)
+	if !w.filterEvent(trace, w.shouldProcessDeployment(newDeployment, resourceConfig), resourceConfig) ||
+		!w.matchesFilter(trace, resourceConfig, oldDeployment, newDeployment) {
 		return
 	}
 
+	if len(resourceConfig.WatchExpressions) > 0 {
+		oldUnstructured, errOld := toUnstructured(oldDeployment)
+		newUnstructured, errNew := toUnstructured(newDeployment)
+		if errOld == nil && errNew == nil {
+			w.handleWatchExpressionsUpdated(trace, resourceConfig, "Deployment", oldUnstructured, newUnstructured)
+			return
+		}
+		w.logger.Warn("Failed to convert deployment for watch expressions, using important fields", "kind", "Deployment", "namespace", newDeployment.Namespace, "name", newDeployment.Name)
+	}
+
+	// Ignored fields are stripped before comparing so they cannot count as important changes
+	compareOld, compareNew := oldDeployment, newDeployment
+	if paths := w.ignoreFields["Deployment"]; len(paths) > 0 {
+		compareOld, compareNew = stripDeploymentFields(oldDeployment, paths), stripDeploymentFields(newDeployment, paths)
+	}
+
 	// Only notify if important fields have changed
-	if w.hasImportantDeploymentChanges(oldDeployment, newDeployment) {
-		log.Printf("[Deployment] Important fields changed for %s/%s", newDeployment.Namespace, newDeployment.Name)
-		w.sendNotification("Deployment", "MODIFIED", newDeployment.Name, newDeployment.Namespace)
+	if changedFields := w.changedDeploymentFields(compareOld, compareNew); len(changedFields) > 0 {
+		trace.Logger().Info("Important fields changed", "changedFields", changedFields)
+		for _, field := range changedFields {
+			w.metrics.RecordDeploymentChange(field)
+		}
+		trace.Step(StageDiffed, describeChangedFields(changedFields))
+		w.sendEvent(trace, newDeployment, notifier.NotificationEvent{
+			EventType:     notifier.EventModified,
+			ResourceKind:  "Deployment",
+			ChangedFields: changedFields,
+			ImageChanges:  imageChanges(&oldDeployment.Spec.Template.Spec, &newDeployment.Spec.Template.Spec),
+		})
 	} else {
-		log.Printf("[Deployment] Non-important changes detected for %s/%s (skipping notification)", newDeployment.Namespace, newDeployment.Name)
+		trace.Logger().Debug("Non-important changes detected (skipping notification)")
+		w.metrics.RecordDeploymentChangeIgnored()
+		trace.Step(StageDiffed, "no important fields changed")
+		w.traces.Finish(trace, "ignored")
 	}
 }
 
-// hasImportantDeploymentChanges checks if any important fields have changed
-func (w *InformerWatcher) hasImportantDeploymentChanges(oldDeployment, newDeployment *appsv1.Deployment) bool {
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.Containers, newDeployment.Spec.Template.Spec.Containers) {
-		return true
-	}
+// deploymentFieldComparators reports whether a named pod template field differs between two specs
+var deploymentFieldComparators = map[string]func(oldSpec, newSpec *corev1.PodSpec) bool{
+	"containers": func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.Containers, n.Containers) },
+	"volumes":    func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.Volumes, n.Volumes) },
+	"serviceAccountName": func(o, n *corev1.PodSpec) bool {
+		return o.ServiceAccountName != n.ServiceAccountName
+	},
+	"nodeSelector":     func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.NodeSelector, n.NodeSelector) },
+	"affinity":         func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.Affinity, n.Affinity) },
+	"tolerations":      func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.Tolerations, n.Tolerations) },
+	"securityContext":  func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.SecurityContext, n.SecurityContext) },
+	"imagePullSecrets": func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.ImagePullSecrets, n.ImagePullSecrets) },
+	"hostAliases":      func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.HostAliases, n.HostAliases) },
+	"initContainers":   func(o, n *corev1.PodSpec) bool { return !reflect.DeepEqual(o.InitContainers, n.InitContainers) },
+}
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.Volumes, newDeployment.Spec.Template.Spec.Volumes) {
-		return true
+// changedDeploymentFields returns the configured important fields that differ between the two deployments.
+// Significant field paths, when configured for Deployments, take precedence over the named pod template fields.
+func (w *InformerWatcher) changedDeploymentFields(oldDeployment, newDeployment *appsv1.Deployment) []string {
+	if paths := w.significantFields["Deployment"]; len(paths) > 0 {
+		oldContent, errOld := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(oldDeployment)
+		newContent, errNew := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(newDeployment)
+		if errOld == nil && errNew == nil {
+			return changedSignificantFields(paths, oldContent, newContent)
+		}
+		w.logger.Warn("Failed to convert deployment for field comparison, using important fields", "kind", "Deployment", "namespace", newDeployment.Namespace, "name", newDeployment.Name)
 	}
 
-	if oldDeployment.Spec.Template.Spec.ServiceAccountName != newDeployment.Spec.Template.Spec.ServiceAccountName {
-		return true
-	}
+	return w.changedPodSpecFields(&oldDeployment.Spec.Template.Spec, &newDeployment.Spec.Template.Spec)
+}
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.NodeSelector, newDeployment.Spec.Template.Spec.NodeSelector) {
-		return true
+// changedPodSpecFields returns the configured important pod template fields that differ between two specs
+func (w *InformerWatcher) changedPodSpecFields(oldSpec, newSpec *corev1.PodSpec) []string {
+	var changed []string
+	for _, field := range w.config.Watcher.GetDeploymentImportantFields() {
+		compare, ok := deploymentFieldComparators[field]
+		if !ok {
+			continue
+		}
+		if compare(oldSpec, newSpec) {
+			changed = append(changed, field)
+		}
 	}
+	return changed
+}
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.Affinity, newDeployment.Spec.Template.Spec.Affinity) {
-		return true
-	}
+// changedObjectFields returns the top-level sections (plus labels and annotations) that differ between two objects
+func changedObjectFields(oldObj, newObj *unstructured.Unstructured) []string {
+	var changed []string
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.Tolerations, newDeployment.Spec.Template.Spec.Tolerations) {
-		return true
+	if !reflect.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) {
+		changed = append(changed, "metadata.labels")
+	}
+	if !reflect.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) {
+		changed = append(changed, "metadata.annotations")
 	}
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.SecurityContext, newDeployment.Spec.Template.Spec.SecurityContext) {
-		return true
+	keys := make(map[string]struct{})
+	for key := range oldObj.Object {
+		keys[key] = struct{}{}
+	}
+	for key := range newObj.Object {
+		keys[key] = struct{}{}
 	}
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.ImagePullSecrets, newDeployment.Spec.Template.Spec.ImagePullSecrets) {
-		return true
+	for key := range keys {
+		if key == "metadata" {
+			continue
+		}
+		if !reflect.DeepEqual(oldObj.Object[key], newObj.Object[key]) {
+			changed = append(changed, key)
+		}
 	}
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.HostAliases, newDeployment.Spec.Template.Spec.HostAliases) {
-		return true
+	sort.Strings(changed)
+	return changed
+}
+
+// stripFields returns a copy of obj with the given paths removed
+func stripFields(obj *unstructured.Unstructured, paths []fieldpath.Path) *unstructured.Unstructured {
+	stripped := obj.DeepCopy()
+	for _, path := range paths {
+		path.Remove(stripped.Object)
 	}
+	return stripped
+}
 
-	if !reflect.DeepEqual(oldDeployment.Spec.Template.Spec.InitContainers, newDeployment.Spec.Template.Spec.InitContainers) {
-		return true
+// stripDeploymentFields removes the given paths from a copy of the deployment,
+// returning the original if it cannot be round-tripped through unstructured
+func stripDeploymentFields(deployment *appsv1.Deployment, paths []fieldpath.Path) *appsv1.Deployment {
+	content, err := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+	if err != nil {
+		return deployment
+	}
+	for _, path := range paths {
+		path.Remove(content)
 	}
 
-	return false
+	stripped := &appsv1.Deployment{}
+	if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(content, stripped); err != nil {
+		return deployment
+	}
+	return stripped
 }
 
 func (w *InformerWatcher) handleDeploymentDeleted(obj interface{}, resourceConfig config.ResourceConfig) {
 	deployment, ok := obj.(*appsv1.Deployment)
 	if !ok {
-		log.Printf("[Deployment] Failed to convert to deployment object")
+		w.logger.Warn("Failed to convert object to a typed deployment", "kind", "Deployment")
 		return
 	}
 
 	// Check if this deployment matches our filter criteria
-	if !w.shouldProcessDeployment(deployment, resourceConfig) {
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), "Deployment", notifier.EventDeleted, deployment.Namespace, deployment.Name)
+	if !w.filterEvent(trace, w.shouldProcessDeployment(deployment, resourceConfig), resourceConfig) ||
+		!w.matchesFilter(trace, resourceConfig, nil, deployment) {
 		return
 	}
 
-	log.Printf("[Deployment] Resource %s/%s was DELETED", deployment.Namespace, deployment.Name)
-	w.sendNotification("Deployment", "DELETED", deployment.Name, deployment.Namespace)
+	if w.pruneNamespaceDeletion(trace, "Deployment", deployment.Namespace) {
+		return
+	}
+
+	trace.Logger().Info("Resource was DELETED")
+	w.sendNotification(trace, "Deployment", notifier.EventDeleted, deployment, nil, nil)
 }
 
-func (w *InformerWatcher) sendNotification(resourceKind, eventType, resourceName, namespace string) {
-	notificationEvent := notifier.NotificationEvent{
-		EventType:    eventType,
-		ResourceKind: resourceKind,
-		ResourceName: resourceName,
-		Namespace:    namespace,
+// filterEvent records the filter decision on the trace, finishing it when the event is filtered out
+func (w *InformerWatcher) filterEvent(trace *EventTrace, matched bool, resourceConfig config.ResourceConfig) bool {
+	if !matched {
+		trace.Step(StageFiltered, "did not match "+resourceConfig.Describe())
+		w.traces.Finish(trace, "filtered")
+		w.metrics.RecordEventFiltered()
+		return false
 	}
+	trace.Step(StageFiltered, "matched "+resourceConfig.Describe())
+	return true
+}
 
-	if err := w.notifier.SendNotification(notificationEvent); err != nil {
-		log.Printf("Failed to send notification for %s %s/%s: %v", resourceKind, namespace, resourceName, err)
+// matchesFilter evaluates the entry's filter expression against the event's objects, either of
+// which may be nil. Events it is false for, or fails to evaluate for, are filtered out.
+func (w *InformerWatcher) matchesFilter(trace *EventTrace, resourceConfig config.ResourceConfig, oldObj, newObj interface{}) bool {
+	if resourceConfig.Filter == "" {
+		return true
+	}
+	program, err := celfilter.Compile(resourceConfig.Filter)
+	if err != nil {
+		trace.Logger().Warn("Invalid filter expression", "error", err)
+		w.traces.Finish(trace, "failed")
+		return false
+	}
+
+	oldContent, errOld := filterContent(oldObj)
+	newContent, errNew := filterContent(newObj)
+	if errOld != nil || errNew != nil {
+		trace.Logger().Warn("Failed to convert object for the filter expression", "error", errors.Join(errOld, errNew))
+		w.traces.Finish(trace, "failed")
+		return false
+	}
+
+	event := celfilter.Event{
+		Type:      string(trace.EventType),
+		Kind:      trace.Kind,
+		Namespace: trace.Namespace,
+		Name:      trace.Name,
+		Cluster:   w.config.ClusterName,
+	}
+	if obj, ok := newObj.(metav1.Object); ok {
+		event.ChangedBy, event.Origin = changeOrigin(trace.EventType, obj)
+	}
+	matched, err := program.Match(celfilter.Input{
+		Event:     event,
+		Object:    newContent,
+		OldObject: oldContent,
+	})
+	switch {
+	case err != nil:
+		trace.Logger().Debug("Filter expression failed (skipping notification)", "error", err)
+		trace.Step(StageFiltered, "filter failed: "+err.Error())
+	case !matched:
+		trace.Step(StageFiltered, "filter is false")
+	default:
+		trace.Step(StageFiltered, "filter is true")
+		return true
+	}
+	w.traces.Finish(trace, "filtered")
+	w.metrics.RecordEventFiltered()
+	return false
+}
+
+// filterContent returns an object's content for filter expressions; nil for a nil object
+func filterContent(obj interface{}) (map[string]interface{}, error) {
+	switch obj := obj.(type) {
+	case nil:
+		return nil, nil
+	case *unstructured.Unstructured:
+		return obj.Object, nil
+	}
+	return k8sruntime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// describeChangedFields summarizes a diff result for traces
+func describeChangedFields(changedFields []string) string {
+	if len(changedFields) == 0 {
+		return "no field-level changes detected"
+	}
+	return "changed: " + strings.Join(changedFields, ", ")
+}
+
+// sendNotification deduplicates and delivers an event; diff holds optional
+// human-readable change lines for the notification body
+func (w *InformerWatcher) sendNotification(trace *EventTrace, resourceKind string, eventType notifier.EventType, obj metav1.Object, changedFields, diff []string) {
+	w.sendNotificationWithSummary(trace, resourceKind, eventType, obj, changedFields, diff, nil)
+}
+
+// sendNotificationWithSummary is sendNotification with leading summary lines
+func (w *InformerWatcher) sendNotificationWithSummary(trace *EventTrace, resourceKind string, eventType notifier.EventType, obj metav1.Object, changedFields, diff, summary []string) {
+	w.sendEvent(trace, obj, notifier.NotificationEvent{
+		EventType:     eventType,
+		ResourceKind:  resourceKind,
+		ChangedFields: changedFields,
+		Diff:          diff,
+		Summary:       summary,
+	})
+}
+
+// sendEvent completes an event with the object's identity, validation warnings and
+// impact lines, then delivers it unless it replays an object version delivered recently
+func (w *InformerWatcher) sendEvent(trace *EventTrace, obj metav1.Object, notificationEvent notifier.NotificationEvent) {
+	resourceKind, eventType, changedFields := notificationEvent.ResourceKind, notificationEvent.EventType, notificationEvent.ChangedFields
+	resourceName, namespace := obj.GetName(), obj.GetNamespace()
+	w.metrics.RecordEventProcessed()
+
+	if w.deduplicator.IsReplay(resourceKind, namespace, resourceName, string(eventType), obj.GetResourceVersion(), changedFields) {
+		trace.Logger().Debug("Duplicate event (skipping notification)", "window", w.config.Watcher.GetEventDeduplicationWindow().String())
+		w.metrics.RecordEventDeduplicated()
+		trace.Step(StageDeduplicated, "duplicate within "+w.config.Watcher.GetEventDeduplicationWindow().String())
+		w.traces.Finish(trace, "duplicate")
+		return
+	}
+	trace.Step(StageDeduplicated, "not a duplicate")
+
+	notificationEvent.ID = trace.ID
+	notificationEvent.ResourceName = resourceName
+	notificationEvent.Namespace = namespace
+	notificationEvent.Labels = obj.GetLabels()
+	notificationEvent.Annotations = notificationAnnotations(resourceKind, obj)
+	notificationEvent.ChangedBy, notificationEvent.Origin = changeOrigin(eventType, obj)
+	if w.config.Watcher.ValidateObjects && eventType != notifier.EventDeleted {
+		notificationEvent.Warnings = w.validateObject(resourceKind, obj)
+	}
+	if (resourceKind == "ConfigMap" || resourceKind == "Secret") && eventType != notifier.EventAdded {
+		notificationEvent.Summary = append(notificationEvent.Summary, w.blastRadius(resourceKind, namespace, resourceName, eventType)...)
+	}
+	if w.config.Watcher.Topology.Enabled {
+		notificationEvent.Summary = append(notificationEvent.Summary, w.topology(resourceKind, obj, eventType)...)
+	}
+	if w.gitops != nil {
+		notificationEvent.Summary = append(notificationEvent.Summary, w.gitDrift(resourceKind, eventType, obj)...)
+	}
+	w.deliver(trace, notificationEvent)
+}
+
+// deliver hands an event to the notification pipeline and records the outcome
+func (w *InformerWatcher) deliver(trace *EventTrace, notificationEvent notifier.NotificationEvent) {
+	if notificationEvent.Cluster == "" {
+		notificationEvent.Cluster = w.config.ClusterName
+	}
+	if notificationEvent.Severity == "" {
+		notificationEvent.Severity = w.severity(notificationEvent)
+	}
+
+	namespace := notificationEvent.Namespace
+	if notificationEvent.ResourceKind == "Namespace" {
+		namespace = notificationEvent.ResourceName
+	}
+	if until, silenced := w.namespaceSilencedUntil(namespace); silenced {
+		trace.Logger().Debug("Namespace is silenced (skipping notification)", "until", until.Format(time.RFC3339))
+		trace.Step(StageFiltered, "namespace silenced until "+until.Format(time.RFC3339)+" by "+SilenceUntilAnnotation)
+		w.traces.Finish(trace, "silenced")
+		w.metrics.RecordEventFiltered()
+		return
+	}
+
+	// Stop waits for queued events as for those being handled
+	w.inFlight.hold()
+	trace.Step(StageQueued, "handed to notification pipeline")
+	if !w.bus.publish(topicNotify, busMessage{trace: trace, event: notificationEvent}) {
+		w.inFlight.end()
+		trace.Logger().Warn("Dropping notification: watcher is stopping")
+		w.traces.Finish(trace, "dropped")
+	}
+}
+
+// severity returns the severity the configured rules assign to an event
+func (w *InformerWatcher) severity(event notifier.NotificationEvent) string {
+	return rules.Severity(w.config.Severity, rules.Event{
+		Kind:          event.ResourceKind,
+		Namespace:     event.Namespace,
+		Name:          event.ResourceName,
+		EventType:     string(event.EventType),
+		Cluster:       event.Cluster,
+		Labels:        event.Labels,
+		Annotations:   event.Annotations,
+		ChangedFields: event.ChangedFields,
+		ChangedBy:     event.ChangedBy,
+		Origin:        event.Origin,
+	})
+}
+
+// notify sends a queued event through the notifier, then publishes the outcome
+// to the subscribers of delivered events
+func (w *InformerWatcher) notify(message busMessage) {
+	defer w.inFlight.end()
+
+	trace, notificationEvent := message.trace, message.event
+	err := w.notifier.SendNotification(w.ctx, notificationEvent)
+	if err != nil {
+		trace.Logger().Error("Failed to send notification", "error", err)
+		trace.Step(StageSent, "failed: "+err.Error())
+		w.traces.Finish(trace, "failed")
 	} else {
-		log.Printf("Successfully sent notification for %s %s/%s", resourceKind, namespace, resourceName)
+		trace.Logger().Info("Successfully sent notification")
+		trace.Step(StageSent, "delivered")
+		w.traces.Finish(trace, "sent")
 	}
+	// The notifiers redacted their own copies; everyone else gets every rule applied
+	w.bus.publish(topicDelivered, busMessage{event: w.redactor.RedactAll(notificationEvent), err: err})
 }
 
-func (w *InformerWatcher) getCacheSyncFuncs() []cache.InformerSynced {
-	var syncFuncs []cache.InformerSynced
+// recordEvent appends the delivered event and its notification status to the event store
+func (w *InformerWatcher) recordEvent(event notifier.NotificationEvent, sendErr error) {
+	if w.eventStore == nil {
+		return
+	}
 
-	w.mu.RLock()
-	defer w.mu.RUnlock()
+	status := store.StatusSent
+	if sendErr != nil {
+		status = store.StatusFailed
+	}
+	w.appendRecord(event, status, sendErr)
+}
 
-	for _, informer := range w.informers {
-		syncFuncs = append(syncFuncs, informer.HasSynced)
+// appendRecord appends an event with a notification status, and the send error if any, to the event store
+func (w *InformerWatcher) appendRecord(event notifier.NotificationEvent, status string, sendErr error) {
+	record := store.Record{
+		ID:            event.ID,
+		Time:          time.Now().UTC(),
+		Cluster:       w.config.ClusterName,
+		EventType:     string(event.EventType),
+		Severity:      event.Severity,
+		Kind:          event.ResourceKind,
+		Namespace:     event.Namespace,
+		Name:          event.ResourceName,
+		ChangedFields: event.ChangedFields,
+		ChangedBy:     event.ChangedBy,
+		Origin:        event.Origin,
+		Diff:          event.Diff,
+		Warnings:      event.Warnings,
+		Summary:       event.Summary,
+		Status:        status,
 	}
+	if sendErr != nil {
+		record.Error = sendErr.Error()
+	}
+
+	// Stopping must not lose the last events, so don't use the watcher context
+	if err := w.eventStore.Append(context.Background(), record); err != nil {
+		w.logger.Error("Failed to record event in the event store", "eventID", event.ID, "error", err)
+	}
+}
+
+// SetRedactor makes the watcher redact events with every rule before they are recorded
+// in the event history or handed to subscribers. Only the manifest archive keeps the
+// original content.
+func (w *InformerWatcher) SetRedactor(redactor *notifier.Redactor) {
+	w.redactor = redactor
+}
+
+// ClusterName returns the name of the cluster this watcher watches
+func (w *InformerWatcher) ClusterName() string {
+	return w.config.ClusterName
+}
+
+// GetEventStore returns the event history store, or nil when it is disabled
+func (w *InformerWatcher) GetEventStore() store.Store {
+	return w.eventStore
+}
+
+// GetArchive returns the manifest archive, or nil when it is disabled
+func (w *InformerWatcher) GetArchive() archive.Archive {
+	return w.archive
+}
 
-	return syncFuncs
+// GetRecentEvents returns the traces of up to limit recent events, newest first
+func (w *InformerWatcher) GetRecentEvents(limit int) []EventTrace {
+	return w.traces.Recent(limit)
+}
+
+// GetEventTrace returns the processing trace of a recent event by ID
+func (w *InformerWatcher) GetEventTrace(id string) (EventTrace, bool) {
+	return w.traces.Get(id)
 }
 
 // shouldProcessResource checks if a resource should be processed based on configuration
 func (w *InformerWatcher) shouldProcessResource(obj *unstructured.Unstructured, resourceConfig config.ResourceConfig) bool {
-	objNamespace := obj.GetNamespace()
-	objName := obj.GetName()
-
-	if resourceConfig.Namespace != "" && objNamespace != resourceConfig.Namespace {
+	if isIgnored(obj) {
 		return false
 	}
-
-	if resourceConfig.ResourceName != "" && objName != resourceConfig.ResourceName {
+	// Token entries select Secrets by type, whatever ownership.skipSecretTypes skips
+	if resourceConfig.ServiceAccountTokens {
+		if !isServiceAccountToken(obj) {
+			return false
+		}
+	} else if w.isGenerated(resourceConfig.Kind, obj) {
 		return false
 	}
-
-	return true
+	return w.matchesResourceConfig(obj.GetNamespace(), obj.GetName(), resourceConfig)
 }
 
 // shouldProcessDeployment checks if a deployment should be processed based on configuration
 func (w *InformerWatcher) shouldProcessDeployment(deployment *appsv1.Deployment, resourceConfig config.ResourceConfig) bool {
-	if resourceConfig.Namespace != "" && deployment.Namespace != resourceConfig.Namespace {
+	if isIgnored(deployment) || w.hasSkippedOwner("Deployment", deployment) {
 		return false
 	}
+	return w.matchesResourceConfig(deployment.Namespace, deployment.Name, resourceConfig)
+}
 
-	if resourceConfig.ResourceName != "" && deployment.Name != resourceConfig.ResourceName {
-		return false
+// IgnoreAnnotation opts an individual object out of notifications when set to "true"
+const IgnoreAnnotation = "resource-watcher.io/ignore"
+
+// isIgnored reports whether the object carries the opt-out annotation
+func isIgnored(obj metav1.Object) bool {
+	value, ok := obj.GetAnnotations()[IgnoreAnnotation]
+	return ok && strings.EqualFold(strings.TrimSpace(value), "true")
+}
+
+// PreviewEvent fetches a live object and builds the MODIFIED event a change to it would produce
+func (w *InformerWatcher) PreviewEvent(ctx context.Context, kind, namespace, name string) (notifier.NotificationEvent, error) {
+	resource, ok := supportedKinds[kind]
+	if !ok {
+		return notifier.NotificationEvent{}, fmt.Errorf("%w: %s", ErrUnsupportedKind, kind)
 	}
 
-	return true
+	obj, err := w.dynamicClient.Resource(resource.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return notifier.NotificationEvent{}, err
+	}
+
+	event := notifier.NotificationEvent{
+		ID:           "preview",
+		Cluster:      w.config.ClusterName,
+		EventType:    notifier.EventModified,
+		ResourceKind: kind,
+		ResourceName: obj.GetName(),
+		Namespace:    obj.GetNamespace(),
+		Labels:       obj.GetLabels(),
+		Annotations:  notificationAnnotations(kind, obj),
+	}
+	event.Severity = w.severity(event)
+	if w.config.Watcher.ValidateObjects {
+		handlerObj, err := resource.toHandlerObject(obj)
+		if err != nil {
+			return notifier.NotificationEvent{}, err
+		}
+		if metaObj, ok := handlerObj.(metav1.Object); ok {
+			event.Warnings = w.validateObject(kind, metaObj)
+		}
+	}
+	return event, nil
 }
@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// recordingNotifier keeps the events the watcher notifies
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []notifier.NotificationEvent
+}
+
+func (n *recordingNotifier) SendNotification(ctx context.Context, event notifier.NotificationEvent) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *recordingNotifier) sent() []notifier.NotificationEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notifier.NotificationEvent(nil), n.events...)
+}
+
+// waitForEvents returns the notified events once there are count of them, failing the
+// test if that takes too long. Notifying is asynchronous, so expecting no event waits briefly.
+func (n *recordingNotifier) waitForEvents(t *testing.T, count int) []notifier.NotificationEvent {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	if count == 0 {
+		deadline = time.Now().Add(100 * time.Millisecond)
+	}
+	for time.Now().Before(deadline) {
+		if events := n.sent(); len(events) > count || count > 0 && len(events) == count {
+			return events
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	events := n.sent()
+	if len(events) != count {
+		t.Fatalf("%d events notified, want %d: %+v", len(events), count, events)
+	}
+	return events
+}
+
+// newTestWatcher creates a watcher on fake clients holding objects, marked as started so
+// handlers notify; Stop is left to the test's cleanup
+func newTestWatcher(t *testing.T, cfg *config.Config, objects ...runtime.Object) (*InformerWatcher, *recordingNotifier, *kubernetesfake.Clientset) {
+	t.Helper()
+	if cfg.ClusterName == "" {
+		cfg.ClusterName = "test"
+	}
+	kubernetes := kubernetesfake.NewSimpleClientset()
+	recorder := &recordingNotifier{}
+	w := newInformerWatcher(cfg, recorder, nil, Clients{
+		Dynamic:    dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...),
+		Kubernetes: kubernetes,
+	})
+	w.isStarted = true
+	t.Cleanup(w.Stop)
+	return w, recorder, kubernetes
+}
@@ -0,0 +1,285 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// routingKinds are the kinds whose hosts, routes and TLS Secrets are rendered old -> new
+var routingKinds = map[string]bool{"Ingress": true, "HTTPRoute": true, "Gateway": true}
+
+// routingLists are the list labels of a routing object, in the order they are diffed
+var routingLists = []string{"host", "rule", "tls", "listener", "parentRef"}
+
+// routing is what an Ingress, HTTPRoute or Gateway serves: scalar settings and lists of
+// hosts, rules, TLS Secrets, listeners and parents keyed by label
+type routing struct {
+	fields map[string]string
+	lists  map[string][]string
+}
+
+// handleRoutingUpdated notifies a MODIFIED Ingress, HTTPRoute or Gateway only when what it
+// routes changed; controllers update status with addresses and conditions
+func (w *InformerWatcher) handleRoutingUpdated(trace *EventTrace, resourceKind string, oldObj, newObj *unstructured.Unstructured) {
+	changedFields, notify := w.specChanges(trace, resourceKind, oldObj, newObj)
+	if !notify {
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED")
+	w.sendRoutingEvent(trace, resourceKind, notifier.EventModified, oldObj, newObj, changedFields)
+}
+
+// sendRoutingEvent sends a MODIFIED or DELETED routing event; with
+// watcher.ingressAlerts.hostRemovedSeverity set, events removing a host get that severity
+func (w *InformerWatcher) sendRoutingEvent(trace *EventTrace, resourceKind string, eventType notifier.EventType, oldObj, newObj *unstructured.Unstructured, changedFields []string) {
+	obj := newObj
+	if obj == nil {
+		obj = oldObj
+	}
+	event := notifier.NotificationEvent{
+		EventType:     eventType,
+		ResourceKind:  resourceKind,
+		ChangedFields: changedFields,
+		Diff:          routingDiff(resourceKind, oldObj, newObj),
+	}
+	if removed := removedHosts(describeRouting(resourceKind, oldObj), describeRouting(resourceKind, newObj)); len(removed) > 0 {
+		event.Summary = []string{fmt.Sprintf("No longer routes %s; requests for it may fail", strings.Join(removed, ", "))}
+		event.Severity = w.config.Watcher.IngressAlerts.HostRemovedSeverity
+	}
+	w.sendEvent(trace, obj, event)
+}
+
+// routingDiff renders the routing changes between two versions of an Ingress, HTTPRoute or
+// Gateway, e.g. "- host: shop.example.com" or "+ rule: shop.example.com/api (Prefix) -> api:8080".
+// Either object may be nil for ADDED and DELETED events.
+func routingDiff(kind string, oldObj, newObj *unstructured.Unstructured) []string {
+	before, after := describeRouting(kind, oldObj), describeRouting(kind, newObj)
+	lines := diffFields(before.fields, after.fields)
+	for _, label := range routingLists {
+		lines = append(lines, diffLines(label, before.lists[label], after.lists[label])...)
+	}
+	return lines
+}
+
+// removedHosts returns the hosts before serves that after does not. Nothing is removed when
+// after matches every host, e.g. an Ingress rule without a host or an HTTPRoute without hostnames.
+func removedHosts(before, after routing) []string {
+	remaining := make(map[string]bool)
+	for _, host := range after.lists["host"] {
+		if host == "*" {
+			return nil
+		}
+		remaining[host] = true
+	}
+	var removed []string
+	for _, host := range before.lists["host"] {
+		switch {
+		case remaining[host]:
+		case host == "*":
+			removed = append(removed, "any host")
+		default:
+			removed = append(removed, host)
+		}
+	}
+	return removed
+}
+
+// describeRouting describes a routing object; an empty routing for nil
+func describeRouting(kind string, obj *unstructured.Unstructured) routing {
+	r := routing{fields: make(map[string]string), lists: make(map[string][]string)}
+	if obj == nil {
+		return r
+	}
+	switch kind {
+	case "Ingress":
+		var ingress networkingv1.Ingress
+		if convertObject(obj, &ingress) {
+			describeIngress(&ingress, &r)
+		}
+	case "HTTPRoute":
+		describeHTTPRoute(obj, &r)
+	case "Gateway":
+		describeGateway(obj, &r)
+	}
+	for label, values := range r.lists {
+		r.lists[label] = uniqueSorted(values)
+	}
+	return r
+}
+
+// describeIngress lists an Ingress's hosts ("*" for rules without one), a rule per path
+// as "host/path (pathType) -> service:port", and its TLS Secrets with their hosts
+func describeIngress(ingress *networkingv1.Ingress, r *routing) {
+	if ingress.Spec.IngressClassName != nil {
+		r.fields["ingressClassName"] = *ingress.Spec.IngressClassName
+	}
+	if ingress.Spec.DefaultBackend != nil {
+		r.fields["defaultBackend"] = describeIngressBackend(*ingress.Spec.DefaultBackend)
+	}
+	for _, rule := range ingress.Spec.Rules {
+		host := rule.Host
+		if host == "" {
+			host = "*"
+		}
+		r.lists["host"] = append(r.lists["host"], host)
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			pathType := "ImplementationSpecific"
+			if path.PathType != nil {
+				pathType = string(*path.PathType)
+			}
+			r.lists["rule"] = append(r.lists["rule"], fmt.Sprintf("%s%s (%s) -> %s", host, path.Path, pathType, describeIngressBackend(path.Backend)))
+		}
+	}
+	for _, tls := range ingress.Spec.TLS {
+		r.lists["tls"] = append(r.lists["tls"], fmt.Sprintf("%s [%s]", tls.SecretName, strings.Join(tls.Hosts, ",")))
+	}
+}
+
+func describeIngressBackend(backend networkingv1.IngressBackend) string {
+	switch {
+	case backend.Service != nil && backend.Service.Port.Name != "":
+		return backend.Service.Name + ":" + backend.Service.Port.Name
+	case backend.Service != nil:
+		return fmt.Sprintf("%s:%d", backend.Service.Name, backend.Service.Port.Number)
+	case backend.Resource != nil:
+		return backend.Resource.Kind + "/" + backend.Resource.Name
+	}
+	return "(none)"
+}
+
+// describeHTTPRoute lists an HTTPRoute's hostnames ("*" without any), its parent Gateways,
+// a rule per match as "PathPrefix /api -> api:8080" and each backend with its weight.
+// Gateway API kinds are CRDs, so they are read from the unstructured object.
+func describeHTTPRoute(obj *unstructured.Unstructured, r *routing) {
+	hostnames, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "hostnames")
+	if len(hostnames) == 0 {
+		hostnames = []string{"*"}
+	}
+	r.lists["host"] = hostnames
+
+	parentRefs, _, _ := unstructured.NestedSlice(obj.Object, "spec", "parentRefs")
+	for _, parent := range parentRefs {
+		if parent, ok := parent.(map[string]interface{}); ok {
+			r.lists["parentRef"] = append(r.lists["parentRef"], describeParentRef(parent, obj.GetNamespace()))
+		}
+	}
+
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+	for _, rule := range rules {
+		rule, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var backends []string
+		backendRefs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		for _, backend := range backendRefs {
+			if backend, ok := backend.(map[string]interface{}); ok {
+				backends = append(backends, describeBackendRef(backend))
+			}
+		}
+		target := strings.Join(backends, ", ")
+		if target == "" {
+			target = "(no backends)"
+		}
+
+		matches, _, _ := unstructured.NestedSlice(rule, "matches")
+		if len(matches) == 0 {
+			matches = []interface{}{map[string]interface{}{}}
+		}
+		for _, match := range matches {
+			match, _ := match.(map[string]interface{})
+			pathType, _, _ := unstructured.NestedString(match, "path", "type")
+			value, _, _ := unstructured.NestedString(match, "path", "value")
+			if pathType == "" {
+				pathType, value = "PathPrefix", "/"
+			}
+			line := pathType + " " + value
+			if method, ok := match["method"].(string); ok {
+				line = method + " " + line
+			}
+			r.lists["rule"] = append(r.lists["rule"], line+" -> "+target)
+		}
+	}
+}
+
+// describeGateway lists a Gateway's listener hostnames ("*" for listeners without one) and
+// a line per listener with its protocol, port, hostname and TLS certificate Secrets
+func describeGateway(obj *unstructured.Unstructured, r *routing) {
+	if class, _, _ := unstructured.NestedString(obj.Object, "spec", "gatewayClassName"); class != "" {
+		r.fields["gatewayClassName"] = class
+	}
+	listeners, _, _ := unstructured.NestedSlice(obj.Object, "spec", "listeners")
+	for _, listener := range listeners {
+		listener, ok := listener.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		hostname, _ := listener["hostname"].(string)
+		if hostname == "" {
+			hostname = "*"
+		}
+		r.lists["host"] = append(r.lists["host"], hostname)
+
+		port, _, _ := unstructured.NestedInt64(listener, "port")
+		line := fmt.Sprintf("%v %v :%d %s", listener["name"], listener["protocol"], port, hostname)
+		certificateRefs, _, _ := unstructured.NestedSlice(listener, "tls", "certificateRefs")
+		var secrets []string
+		for _, ref := range certificateRefs {
+			if ref, ok := ref.(map[string]interface{}); ok {
+				secrets = append(secrets, fmt.Sprint(ref["name"]))
+			}
+		}
+		if len(secrets) > 0 {
+			line += " tls=[" + strings.Join(secrets, ",") + "]"
+		}
+		r.lists["listener"] = append(r.lists["listener"], line)
+	}
+}
+
+// describeParentRef renders a parent reference as "namespace/name", with its section if set
+func describeParentRef(parent map[string]interface{}, routeNamespace string) string {
+	namespace, _ := parent["namespace"].(string)
+	if namespace == "" {
+		namespace = routeNamespace
+	}
+	line := fmt.Sprintf("%s/%v", namespace, parent["name"])
+	if section, ok := parent["sectionName"].(string); ok {
+		line += " (" + section + ")"
+	}
+	return line
+}
+
+// describeBackendRef renders a backend reference as "name:port", with its weight if set
+func describeBackendRef(backend map[string]interface{}) string {
+	line := fmt.Sprint(backend["name"])
+	if port, ok := backend["port"].(int64); ok {
+		line += fmt.Sprintf(":%d", port)
+	}
+	if weight, ok := backend["weight"].(int64); ok {
+		line += fmt.Sprintf(" (weight %d)", weight)
+	}
+	return line
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,139 @@
+package watcher
+
+import (
+	"errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// resourceKind describes how a supported kind is listed and watched
+type resourceKind struct {
+	gvr schema.GroupVersionResource
+
+	// newTyped returns an empty typed object for kinds whose handlers expect
+	// typed objects rather than unstructured ones; nil for unstructured kinds
+	newTyped func() runtime.Object
+
+	// podSpecPath locates the pod template spec of workload kinds, whose
+	// MODIFIED events are filtered on the important pod template fields
+	podSpecPath []string
+}
+
+// supportedKinds maps configured kinds to their API resources
+var supportedKinds = map[string]resourceKind{
+	"Deployment": {
+		gvr:      schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		newTyped: func() runtime.Object { return &appsv1.Deployment{} },
+	},
+	"ConfigMap": {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}},
+	"Secret":    {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}},
+	"Service":   {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}},
+	"Ingress":   {gvr: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}},
+	"StatefulSet": {
+		gvr:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		podSpecPath: []string{"spec", "template", "spec"},
+	},
+	"DaemonSet": {
+		gvr:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		podSpecPath: []string{"spec", "template", "spec"},
+	},
+	"Job": {
+		gvr:         schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"},
+		podSpecPath: []string{"spec", "template", "spec"},
+	},
+	"CronJob": {
+		gvr:         schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"},
+		podSpecPath: []string{"spec", "jobTemplate", "spec", "template", "spec"},
+	},
+	"HelmRelease":        {gvr: schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2beta1", Resource: "helmreleases"}},
+	"Kustomization":      {gvr: schema.GroupVersionResource{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"}},
+	"Pod":                {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}},
+	"Event":              {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "events"}},
+	"Node":               {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}},
+	"Role":               {gvr: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"}},
+	"RoleBinding":        {gvr: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}},
+	"ClusterRole":        {gvr: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}},
+	"ClusterRoleBinding": {gvr: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}},
+	"ResourceQuota":      {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "resourcequotas"}},
+	"LimitRange":         {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "limitranges"}},
+
+	"PersistentVolumeClaim": {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumeclaims"}},
+	"PersistentVolume":      {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumes"}},
+
+	"HorizontalPodAutoscaler": {gvr: schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}},
+	"VerticalPodAutoscaler":   {gvr: schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}},
+
+	"HTTPRoute": {gvr: schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}},
+	"Gateway":   {gvr: schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}},
+
+	"CustomResourceDefinition": {gvr: schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}},
+
+	"ServiceAccount": {gvr: schema.GroupVersionResource{Group: "", Version: "v1", Resource: "serviceaccounts"}},
+
+	"Certificate":               {gvr: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}},
+	"CertificateSigningRequest": {gvr: schema.GroupVersionResource{Group: "certificates.k8s.io", Version: "v1", Resource: "certificatesigningrequests"}},
+}
+
+// kindDiff renders the changes of kinds with a readable diff of their own, RBAC rules,
+// quota limits, autoscaler settings, routes, CRD versions, ServiceAccount credentials and
+// the ServiceAccount of token Secrets; nil for other kinds
+func kindDiff(kind string, oldObj, newObj *unstructured.Unstructured) []string {
+	switch {
+	case quotaKinds[kind]:
+		return quotaDiff(kind, oldObj, newObj)
+	case autoscalerKinds[kind]:
+		return autoscalerDiff(kind, oldObj, newObj)
+	case routingKinds[kind]:
+		return routingDiff(kind, oldObj, newObj)
+	case kind == "CustomResourceDefinition":
+		return crdDiff(oldObj, newObj)
+	case kind == "ServiceAccount":
+		return serviceAccountDiff(oldObj, newObj)
+	case kind == "Secret":
+		return diffFields(tokenOwner(oldObj), tokenOwner(newObj))
+	}
+	return rbacDiff(kind, oldObj, newObj)
+}
+
+// podSpec extracts the pod template spec of a workload object
+func (k resourceKind) podSpec(obj *unstructured.Unstructured) (*corev1.PodSpec, error) {
+	content, found, err := unstructured.NestedMap(obj.Object, k.podSpecPath...)
+	if err != nil || !found {
+		return &corev1.PodSpec{}, err
+	}
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// ErrUnsupportedKind is returned for kinds missing from supportedKinds
+var ErrUnsupportedKind = errors.New("unsupported resource kind")
+
+// toHandlerObject converts an unstructured object into the representation the
+// kind's event handlers expect
+func (k resourceKind) toHandlerObject(obj *unstructured.Unstructured) (interface{}, error) {
+	if k.newTyped == nil {
+		return obj, nil
+	}
+	typed := k.newTyped()
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return nil, err
+	}
+	return typed, nil
+}
+
+// KindResources returns the API resource of every supported kind
+func KindResources() map[string]schema.GroupVersionResource {
+	resources := make(map[string]schema.GroupVersionResource, len(supportedKinds))
+	for kind, resource := range supportedKinds {
+		resources[kind] = resource.gvr
+	}
+	return resources
+}
@@ -0,0 +1,91 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/kubeconfig"
+)
+
+// secretLookupTimeout bounds the request that fetches a kubeconfig Secret
+const secretLookupTimeout = 30 * time.Second
+
+// loadRESTConfig returns the client configuration for the watched cluster
+// with the configured client identity applied
+func loadRESTConfig(cfg *config.Config) (*rest.Config, error) {
+	var restConfig *rest.Config
+	var err error
+	if cfg.Kubeconfig != "" || cfg.KubeconfigContext != "" {
+		path := cfg.Kubeconfig
+		if path == "" {
+			path = cfg.LocalKubeconfig
+		}
+		restConfig, err = kubeconfig.File(path, cfg.KubeconfigContext)
+	} else {
+		restConfig, err = clusterRESTConfig(cfg, cfg.KubeconfigSecret)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig.UserAgent = cfg.Client.GetUserAgent()
+	if imp := cfg.Client.Impersonate; imp.User != "" {
+		restConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: imp.User,
+			Groups:   imp.Groups,
+			Extra:    imp.Extra,
+		}
+		slog.Info("Impersonating user", "user", imp.User, "groups", imp.Groups)
+	}
+	return restConfig, nil
+}
+
+// clusterRESTConfig returns the base client configuration. Without a secret
+// reference that is the local cluster; otherwise the kubeconfig is read from
+// the referenced Secret through the local cluster's API, so remote
+// credentials stay in memory and never touch disk or env. Impersonation is
+// not applied to the Secret lookup, which uses the watcher's own identity.
+func clusterRESTConfig(cfg *config.Config, ref *config.KubeconfigSecretRef) (*rest.Config, error) {
+	local, err := kubeconfig.Local(cfg.LocalKubeconfig, cfg.LocalContext)
+	if err != nil {
+		return nil, err
+	}
+	if ref == nil {
+		return local, nil
+	}
+	local.UserAgent = cfg.Client.GetUserAgent()
+
+	client, err := kubernetes.NewForConfig(local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretLookupTimeout)
+	defer cancel()
+
+	namespace, key := ref.GetNamespace(), ref.GetKey()
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	data, ok := secret.Data[key]
+	if !ok || len(data) == 0 {
+		return nil, fmt.Errorf("kubeconfig secret %s/%s has no %q key", namespace, ref.Name, key)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig in secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	slog.Info("Using kubeconfig from secret", "namespace", namespace, "secret", ref.Name, "apiServer", restConfig.Host)
+	return restConfig, nil
+}
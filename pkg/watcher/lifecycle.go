@@ -0,0 +1,93 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Lifecycle phases hooks can be registered for
+const (
+	PhaseCacheSynced = "cache-synced"
+	PhaseStarted     = "started"
+	PhaseStopping    = "stopping"
+)
+
+// stoppingHookTimeout bounds the total time OnStopping hooks may take
+const stoppingHookTimeout = 30 * time.Second
+
+// LifecycleHook is work run at a lifecycle phase. The context is cancelled
+// when the phase's time budget runs out.
+type LifecycleHook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	fn   LifecycleHook
+}
+
+// lifecycle keeps the hooks registered for each phase
+type lifecycle struct {
+	mu     sync.Mutex
+	hooks  map[string][]namedHook
+	logger *slog.Logger
+}
+
+func newLifecycle(logger *slog.Logger) *lifecycle {
+	return &lifecycle{hooks: make(map[string][]namedHook), logger: logger}
+}
+
+func (l *lifecycle) register(phase, name string, fn LifecycleHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks[phase] = append(l.hooks[phase], namedHook{name: name, fn: fn})
+}
+
+// run executes the hooks of a phase one at a time. Stopping hooks run in
+// reverse registration order, like defers, so later subsystems clean up
+// before the ones they depend on. Hook failures are logged and do not stop
+// the remaining hooks.
+func (l *lifecycle) run(ctx context.Context, phase string) {
+	l.mu.Lock()
+	hooks := append([]namedHook(nil), l.hooks[phase]...)
+	l.mu.Unlock()
+
+	if phase == PhaseStopping {
+		for i, j := 0, len(hooks)-1; i < j; i, j = i+1, j-1 {
+			hooks[i], hooks[j] = hooks[j], hooks[i]
+		}
+	}
+
+	for _, hook := range hooks {
+		if err := runHook(ctx, hook); err != nil {
+			l.logger.Warn("Lifecycle hook failed", "hook", hook.name, "phase", phase, "error", err)
+		}
+	}
+}
+
+func runHook(ctx context.Context, hook namedHook) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.fn(ctx)
+}
+
+// OnCacheSynced registers a hook run once every watched kind is being served,
+// before the watcher reports itself started
+func (w *InformerWatcher) OnCacheSynced(name string, fn LifecycleHook) {
+	w.lifecycle.register(PhaseCacheSynced, name, fn)
+}
+
+// OnStarted registers a hook run at the end of a successful Start
+func (w *InformerWatcher) OnStarted(name string, fn LifecycleHook) {
+	w.lifecycle.register(PhaseStarted, name, fn)
+}
+
+// OnStopping registers a hook run by Stop before informers shut down, so
+// final work (queue flush, checkpoint save, digest send) still sees a live watcher
+func (w *InformerWatcher) OnStopping(name string, fn LifecycleHook) {
+	w.lifecycle.register(PhaseStopping, name, fn)
+}
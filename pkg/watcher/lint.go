@@ -0,0 +1,61 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// LintLiveSelectors reports resource entries that match no object in the
+// synced informer caches, which usually means a typo in a namespace or name
+func (w *InformerWatcher) LintLiveSelectors() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var warnings []string
+	for i, resourceConfig := range w.config.Resources {
+		informerStore, ok := w.informerStore(resourceConfig)
+		if !ok {
+			continue
+		}
+
+		matched := 0
+		found := make(map[string]bool)
+		for _, obj := range informerStore.List() {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				continue
+			}
+			if w.matchesResourceConfig(accessor.GetNamespace(), accessor.GetName(), resourceConfig) {
+				matched++
+				found[accessor.GetName()] = true
+			}
+		}
+
+		if matched == 0 {
+			warnings = append(warnings, fmt.Sprintf("resources[%d] (%s) currently matches no objects in the cluster", i, resourceConfig.Describe()))
+			continue
+		}
+		var missing []string
+		for _, name := range resourceConfig.ResourceNames {
+			if !found[name] {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			warnings = append(warnings, fmt.Sprintf("resources[%d] (%s) currently matches no object named %s in the cluster", i, resourceConfig.Describe(), strings.Join(missing, ", ")))
+		}
+	}
+	return warnings
+}
+
+// matchesResourceConfig applies the namespace/name filters of a resource entry
+func (w *InformerWatcher) matchesResourceConfig(namespace, name string, resourceConfig config.ResourceConfig) bool {
+	if !resourceConfig.MatchesNamespace(namespace) {
+		return false
+	}
+	return resourceConfig.MatchesName(name)
+}
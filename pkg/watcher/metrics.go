@@ -12,9 +12,19 @@ type WatcherMetrics struct {
 	// Event counts
 	EventsProcessed     int64
 	EventsFiltered      int64
+	EventsDeduplicated  int64
 	NotificationsSent   int64
 	NotificationsFailed int64
 
+	// Watch engine metrics
+	WatchErrors     int64
+	EngineFallbacks int64
+	ResyncReplays   int64
+
+	// Manifest archive metrics
+	ManifestsArchived int64
+	ArchiveFailures   int64
+
 	// Deployment-specific metrics
 	DeploymentChangesDetected int64
 	DeploymentChangesIgnored  int64
@@ -25,12 +35,15 @@ type WatcherMetrics struct {
 
 	// Field change metrics
 	FieldChanges map[string]int64
+
+	startedAt time.Time
 }
 
 // NewWatcherMetrics creates a new metrics instance
 func NewWatcherMetrics() *WatcherMetrics {
 	return &WatcherMetrics{
 		FieldChanges: make(map[string]int64),
+		startedAt:    time.Now(),
 	}
 }
 
@@ -49,6 +62,13 @@ func (m *WatcherMetrics) RecordEventFiltered() {
 	m.EventsFiltered++
 }
 
+// RecordEventDeduplicated records an event suppressed by the deduplicator
+func (m *WatcherMetrics) RecordEventDeduplicated() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.EventsDeduplicated++
+}
+
 // RecordNotificationSent records a successful notification
 func (m *WatcherMetrics) RecordNotificationSent() {
 	m.mu.Lock()
@@ -63,6 +83,38 @@ func (m *WatcherMetrics) RecordNotificationFailed() {
 	m.NotificationsFailed++
 }
 
+// RecordWatchError records a failed list or watch call
+func (m *WatcherMetrics) RecordWatchError() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.WatchErrors++
+}
+
+// RecordEngineFallback records a kind switching from its informer to the raw watch engine
+func (m *WatcherMetrics) RecordEngineFallback() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.EngineFallbacks++
+}
+
+// RecordResyncReplay records a cached object replayed by an informer resync
+func (m *WatcherMetrics) RecordResyncReplay() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ResyncReplays++
+}
+
+// RecordManifestArchived records an archived manifest, or a failure to archive one
+func (m *WatcherMetrics) RecordManifestArchived(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.ArchiveFailures++
+	} else {
+		m.ManifestsArchived++
+	}
+}
+
 // RecordDeploymentChange records a deployment field change
 func (m *WatcherMetrics) RecordDeploymentChange(fieldName string) {
 	m.mu.Lock()
@@ -78,27 +130,71 @@ func (m *WatcherMetrics) RecordDeploymentChangeIgnored() {
 	m.DeploymentChangesIgnored++
 }
 
-// GetMetrics returns a copy of the current metrics
-func (m *WatcherMetrics) GetMetrics() *WatcherMetrics {
+// RecordStartupSync records how long the initial cache sync took
+func (m *WatcherMetrics) RecordStartupSync(duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.StartupSyncTime = duration
+}
+
+// MetricsSnapshot is a point-in-time copy of the watcher metrics plus process stats
+type MetricsSnapshot struct {
+	EventsProcessed     int64 `json:"eventsProcessed"`
+	EventsFiltered      int64 `json:"eventsFiltered"`
+	EventsDeduplicated  int64 `json:"eventsDeduplicated"`
+	NotificationsSent   int64 `json:"notificationsSent"`
+	NotificationsFailed int64 `json:"notificationsFailed"`
+
+	WatchErrors     int64 `json:"watchErrors"`
+	EngineFallbacks int64 `json:"engineFallbacks"`
+	ResyncReplays   int64 `json:"resyncReplays"` // Cached objects replayed by resyncs, never notified
+
+	ManifestsArchived int64 `json:"manifestsArchived"`
+	ArchiveFailures   int64 `json:"archiveFailures"`
+
+	DeploymentChangesDetected int64            `json:"deploymentChangesDetected"`
+	DeploymentChangesIgnored  int64            `json:"deploymentChangesIgnored"`
+	FieldChanges              map[string]int64 `json:"fieldChanges"`
+
+	StartupSyncTime time.Duration `json:"startupSyncTimeNs"`
+	LastEventTime   time.Time     `json:"lastEventTime"`
+
+	// Process stats
+	Uptime       time.Duration        `json:"uptimeNs"`
+	Goroutines   int                  `json:"goroutines"`
+	CacheObjects map[string]int       `json:"cacheObjects"` // Objects held in each kind's informer cache
+	EventBus     []BusSubscriberStats `json:"eventBus"`     // Queues of the event bus subscribers
+}
+
+// Snapshot returns a consistent copy of the current metrics. Process stats
+// other than uptime are filled in by the watcher.
+func (m *WatcherMetrics) Snapshot() MetricsSnapshot {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	metrics := &WatcherMetrics{
+	snapshot := MetricsSnapshot{
 		EventsProcessed:           m.EventsProcessed,
 		EventsFiltered:            m.EventsFiltered,
+		EventsDeduplicated:        m.EventsDeduplicated,
 		NotificationsSent:         m.NotificationsSent,
 		NotificationsFailed:       m.NotificationsFailed,
+		WatchErrors:               m.WatchErrors,
+		EngineFallbacks:           m.EngineFallbacks,
+		ResyncReplays:             m.ResyncReplays,
+		ManifestsArchived:         m.ManifestsArchived,
+		ArchiveFailures:           m.ArchiveFailures,
 		DeploymentChangesDetected: m.DeploymentChangesDetected,
 		DeploymentChangesIgnored:  m.DeploymentChangesIgnored,
+		FieldChanges:              make(map[string]int64, len(m.FieldChanges)),
 		StartupSyncTime:           m.StartupSyncTime,
 		LastEventTime:             m.LastEventTime,
-		FieldChanges:              make(map[string]int64),
+		Uptime:                    time.Since(m.startedAt),
 	}
 
 	// Copy the field changes map
 	for k, v := range m.FieldChanges {
-		metrics.FieldChanges[k] = v
+		snapshot.FieldChanges[k] = v
 	}
 
-	return metrics
+	return snapshot
 }
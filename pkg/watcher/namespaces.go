@@ -0,0 +1,309 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// SilenceUntilAnnotation on a Namespace mutes every notification from it until the RFC3339 time it holds
+const SilenceUntilAnnotation = "resource-watcher.io/silence-until"
+
+// namespaceTracker follows the lifecycle of watched namespaces. While a
+// namespace terminates, deletions of the objects inside it are counted
+// instead of notified one by one; once it is gone a single summary is sent
+// and the informers scoped to it are stopped until it is recreated.
+type namespaceTracker struct {
+	// terminating maps namespace -> kind -> objects deleted with it
+	terminating map[string]map[string]int
+	// deleted records namespaces removed while watched, so a recreation can be reported
+	deleted map[string]time.Time
+	// stopped holds the informers scoped to deleted namespaces, restarted on recreation
+	stopped map[string][]*sharedInformer
+}
+
+func newNamespaceTracker() *namespaceTracker {
+	return &namespaceTracker{
+		terminating: make(map[string]map[string]int),
+		deleted:     make(map[string]time.Time),
+		stopped:     make(map[string][]*sharedInformer),
+	}
+}
+
+// tracksNamespaces reports whether the cluster-wide Namespace informer runs: as configured, or
+// by default when a kind or feature already requires cluster-wide permissions
+func (w *InformerWatcher) tracksNamespaces() bool {
+	if tracking := w.config.Watcher.NamespaceTracking; tracking != nil {
+		return *tracking
+	}
+	if w.config.Watcher.ValidateObjects || w.config.Watcher.BlastRadius || w.config.Watcher.Topology.Enabled {
+		return true
+	}
+	for _, resourceConfig := range w.config.Resources {
+		if len(w.scopedNamespaces(resourceConfig.Kind)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// newNamespaceInformer creates the informer that drives namespace pruning and silence-until annotations
+func (w *InformerWatcher) newNamespaceInformer() cache.SharedIndexInformer {
+	informer := coreinformers.NewNamespaceInformer(w.k8sClient, 0, cache.Indexers{})
+	informer.AddEventHandler(w.trackInFlight(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			namespace, ok := obj.(*corev1.Namespace)
+			if !ok {
+				return
+			}
+			w.logNamespaceSilence(nil, namespace)
+			if w.started() {
+				w.handleNamespaceAdded(namespace)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			namespace, ok := newObj.(*corev1.Namespace)
+			if !ok {
+				return
+			}
+			if old, ok := oldObj.(*corev1.Namespace); ok {
+				w.logNamespaceSilence(old, namespace)
+			}
+			if w.started() && namespace.DeletionTimestamp != nil {
+				w.markNamespaceTerminating(namespace.Name)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if namespace, ok := obj.(*corev1.Namespace); ok && w.started() {
+				w.handleNamespaceDeleted(namespace)
+			}
+		},
+	}))
+	informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		w.recordWatchError("Namespace", err)
+	})
+	return informer
+}
+
+// namespaceSilencedUntil returns the time a namespace's silence-until annotation mutes it until,
+// and whether that is still ahead. Annotations are read from the namespace informer's cache,
+// so adding, changing or removing one takes effect without a restart.
+func (w *InformerWatcher) namespaceSilencedUntil(name string) (time.Time, bool) {
+	if w.namespaceInformer == nil || name == "" {
+		return time.Time{}, false
+	}
+	obj, exists, err := w.namespaceInformer.GetStore().GetByKey(name)
+	if err != nil || !exists {
+		return time.Time{}, false
+	}
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return time.Time{}, false
+	}
+	until, ok, err := silenceUntil(namespace)
+	if err != nil || !ok {
+		return time.Time{}, false
+	}
+	return until, time.Now().Before(until)
+}
+
+// silenceUntil parses a namespace's silence-until annotation, reporting false when it has none
+func silenceUntil(namespace *corev1.Namespace) (time.Time, bool, error) {
+	value, ok := namespace.GetAnnotations()[SilenceUntilAnnotation]
+	if !ok || strings.TrimSpace(value) == "" {
+		return time.Time{}, false, nil
+	}
+	until, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%s must be an RFC3339 time: %w", SilenceUntilAnnotation, err)
+	}
+	return until, true, nil
+}
+
+// logNamespaceSilence logs a namespace's silence-until annotation when it is set, changed or removed
+func (w *InformerWatcher) logNamespaceSilence(old, namespace *corev1.Namespace) {
+	value := namespace.GetAnnotations()[SilenceUntilAnnotation]
+	if old != nil && old.GetAnnotations()[SilenceUntilAnnotation] == value {
+		return
+	}
+
+	until, ok, err := silenceUntil(namespace)
+	switch {
+	case err != nil:
+		w.logger.Warn("Ignoring invalid namespace silence annotation", "namespace", namespace.Name, "error", err)
+	case !ok && old != nil:
+		w.logger.Info("Namespace silence annotation removed; resuming notifications", "namespace", namespace.Name)
+	case ok && time.Now().Before(until):
+		w.logger.Info("Namespace silenced by annotation", "namespace", namespace.Name, "until", until.Format(time.RFC3339))
+	}
+}
+
+// isWatchedNamespace reports whether any resource entry covers the namespace
+func (w *InformerWatcher) isWatchedNamespace(namespace string) bool {
+	for i := range w.config.Resources {
+		if w.config.Resources[i].MatchesNamespace(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *InformerWatcher) markNamespaceTerminating(namespace string) {
+	if !w.isWatchedNamespace(namespace) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.namespaces.terminating[namespace]; !ok {
+		w.logger.Info("Namespace is terminating; summarizing deletions of its resources", "namespace", namespace)
+		w.namespaces.terminating[namespace] = make(map[string]int)
+	}
+}
+
+// pruneDeletion counts an object deletion caused by its namespace terminating.
+// It returns false when the namespace is not terminating and the deletion should be notified.
+func (w *InformerWatcher) pruneDeletion(kind, namespace string) bool {
+	if namespace == "" {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	counts, ok := w.namespaces.terminating[namespace]
+	if !ok {
+		return false
+	}
+	counts[kind]++
+	return true
+}
+
+// pruneNamespaceDeletion finishes the trace of a deletion that will be reported in its namespace's summary
+func (w *InformerWatcher) pruneNamespaceDeletion(trace *EventTrace, kind, namespace string) bool {
+	if !w.pruneDeletion(kind, namespace) {
+		return false
+	}
+	trace.Step(StageFiltered, "namespace terminating; counted in the namespace summary")
+	w.traces.Finish(trace, "pruned")
+	return true
+}
+
+func (w *InformerWatcher) handleNamespaceDeleted(namespace *corev1.Namespace) {
+	if !w.isWatchedNamespace(namespace.Name) {
+		return
+	}
+
+	w.mu.Lock()
+	counts := w.namespaces.terminating[namespace.Name]
+	delete(w.namespaces.terminating, namespace.Name)
+	w.namespaces.deleted[namespace.Name] = time.Now()
+	w.mu.Unlock()
+
+	w.logger.Info("Namespace was deleted", "namespace", namespace.Name)
+	w.sendNamespaceDeleted(namespace, counts)
+	w.stopNamespaceInformers(namespace.Name)
+}
+
+// stopNamespaceInformers stops the informers scoped to a deleted namespace, dropping their
+// caches, so nothing keeps watching it or holds its objects. Raw watch engines cache nothing
+// and keep watching the namespace by name.
+func (w *InformerWatcher) stopNamespaceInformers(namespace string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for scope, shared := range w.informers {
+		if scope.namespace != namespace || shared.stop == nil {
+			continue
+		}
+		shared.stop()
+		shared.stop = nil
+		delete(w.informers, scope)
+		for _, handler := range shared.reconnects {
+			handler.forget(namespace)
+		}
+		w.namespaces.stopped[namespace] = append(w.namespaces.stopped[namespace], shared)
+		w.logger.Info("Stopped the informer of a deleted namespace", "watcher", shared.key)
+	}
+}
+
+func (w *InformerWatcher) handleNamespaceAdded(namespace *corev1.Namespace) {
+	w.mu.Lock()
+	deletedAt, recreated := w.namespaces.deleted[namespace.Name]
+	delete(w.namespaces.deleted, namespace.Name)
+	w.mu.Unlock()
+
+	if recreated {
+		// Cluster-wide informers pick up objects in the new namespace without re-subscribing
+		w.logger.Info("Namespace was recreated after deletion; resuming notifications",
+			"namespace", namespace.Name, "deletedFor", time.Since(deletedAt).Round(time.Second).String())
+	}
+	w.resumeNamespaceInformers(namespace.Name)
+}
+
+// resumeNamespaceInformers restarts the informers stopped with a namespace once it is
+// recreated, with empty caches, so the objects of the new namespace are notified as added.
+// Kinds that fell back to raw watches meanwhile are served by raw watch engines instead.
+func (w *InformerWatcher) resumeNamespaceInformers(namespace string) {
+	w.mu.Lock()
+	stopped := w.namespaces.stopped[namespace]
+	delete(w.namespaces.stopped, namespace)
+	var rawWatches []*sharedInformer
+	for _, shared := range stopped {
+		if status, ok := w.engines[shared.kind]; ok && status.Engine == EngineRawWatch {
+			rawWatches = append(rawWatches, shared)
+			continue
+		}
+		resource := supportedKinds[shared.kind]
+		scope := informerScope{gvr: resource.gvr, namespace: namespace}
+		w.buildInformer(shared, scope, resource)
+		w.informers[scope] = shared
+		informerCtx, stop := context.WithCancel(w.ctx)
+		shared.stop = stop
+		go shared.informer.Run(informerCtx.Done())
+		w.logger.Info("Restarted the informer of a recreated namespace", "watcher", shared.key)
+	}
+	w.mu.Unlock()
+
+	for _, shared := range rawWatches {
+		w.startRawWatch(shared)
+	}
+}
+
+// sendNamespaceDeleted sends one notification summarizing the objects removed with a namespace
+func (w *InformerWatcher) sendNamespaceDeleted(namespace *corev1.Namespace, counts map[string]int) {
+	trace := w.traces.Start("Namespace", "Namespace", notifier.EventNamespaceDeleted, namespace.Name, namespace.Name)
+
+	kinds := make([]string, 0, len(counts))
+	total := 0
+	for kind, count := range counts {
+		kinds = append(kinds, kind)
+		total += count
+	}
+	sort.Strings(kinds)
+
+	summary := []string{fmt.Sprintf("Namespace %s was deleted together with %d watched resources.", namespace.Name, total)}
+	for _, kind := range kinds {
+		summary = append(summary, fmt.Sprintf("%s: %d deleted", kind, counts[kind]))
+	}
+
+	w.deliver(trace, notifier.NotificationEvent{
+		ID:           trace.ID,
+		EventType:    notifier.EventNamespaceDeleted,
+		ResourceKind: "Namespace",
+		ResourceName: namespace.Name,
+		Namespace:    namespace.Name, // Lets namespace-based routing reach the namespace's owners
+		Labels:       namespace.GetLabels(),
+		Annotations:  namespace.GetAnnotations(),
+		Summary:      summary,
+	})
+}
@@ -0,0 +1,62 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+func TestNamespaceDeletionStopsInformers(t *testing.T) {
+	cfg := &config.Config{Resources: []config.ResourceConfig{{Kind: "ConfigMap", Namespace: "prod"}}}
+	w, recorder, _ := newTestWatcher(t, cfg, configMap("1", nil))
+	if err := w.createInformer(cfg.Resources[0]); err != nil {
+		t.Fatal(err)
+	}
+	// Objects in the initial list are existing state, as during Start
+	w.isStarted = false
+	scope := w.entryScope(cfg.Resources[0])
+	shared := w.informers[scope]
+	informerCtx, stop := context.WithCancel(w.ctx)
+	shared.stop = stop
+	go shared.informer.Run(informerCtx.Done())
+	if !cache.WaitForCacheSync(w.ctx.Done(), shared.informer.HasSynced) {
+		t.Fatal("informer did not sync")
+	}
+	w.mu.Lock()
+	w.isStarted = true
+	w.mu.Unlock()
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}}
+	w.handleNamespaceDeleted(namespace)
+	if events := recorder.waitForEvents(t, 1); events[0].EventType != notifier.EventNamespaceDeleted {
+		t.Errorf("notified %s, want NAMESPACE_DELETED", events[0].EventType)
+	}
+	if _, ok := w.informers[scope]; ok {
+		t.Fatal("informer of the deleted namespace still runs")
+	}
+
+	// Recreated, the namespace is watched again from an empty cache, so its objects are new
+	w.handleNamespaceAdded(namespace)
+	restarted, ok := w.informers[scope]
+	if !ok {
+		t.Fatal("informer of the recreated namespace was not restarted")
+	}
+	if !cache.WaitForCacheSync(w.ctx.Done(), restarted.informer.HasSynced) {
+		t.Fatal("restarted informer did not sync")
+	}
+	events := recorder.waitForEvents(t, 2)
+	if events[1].EventType != notifier.EventAdded || events[1].ResourceName != "app" {
+		t.Errorf("notified %s %s after the recreation, want ADDED app", events[1].EventType, events[1].ResourceName)
+	}
+
+	w.handleNamespaceDeleted(namespace)
+	if _, ok := w.informers[scope]; ok {
+		t.Error("restarted informer was not stopped by the second deletion")
+	}
+}
@@ -0,0 +1,113 @@
+package watcher
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// nodeConditionTypes are the node conditions whose transitions are notified
+var nodeConditionTypes = []corev1.NodeConditionType{
+	corev1.NodeReady,
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+	corev1.NodeNetworkUnavailable,
+}
+
+// handleNodeUpdated notifies only meaningful node state transitions; nodes
+// update their status every few seconds, so raw MODIFIED events are noise
+func (w *InformerWatcher) handleNodeUpdated(trace *EventTrace, oldObj, newObj *unstructured.Unstructured) {
+	var oldNode, newNode corev1.Node
+	if runtime.DefaultUnstructuredConverter.FromUnstructured(oldObj.Object, &oldNode) != nil ||
+		runtime.DefaultUnstructuredConverter.FromUnstructured(newObj.Object, &newNode) != nil {
+		trace.Logger().Warn("Failed to convert to a typed node")
+		w.traces.Finish(trace, "failed")
+		return
+	}
+
+	changedFields, diff := nodeTransitions(&oldNode, &newNode)
+	if len(changedFields) == 0 {
+		trace.Step(StageDiffed, "no node state transitions")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("Node state transitions", "diff", diff)
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	w.sendNotification(trace, "Node", notifier.EventModified, newObj, changedFields, diff)
+}
+
+// nodeTransitions returns the changed node state fields and a readable line per transition:
+// condition status changes, cordon/uncordon, taint changes and kubelet upgrades
+func nodeTransitions(oldNode, newNode *corev1.Node) ([]string, []string) {
+	var changedFields, diff []string
+
+	for _, conditionType := range nodeConditionTypes {
+		oldCondition, newCondition := nodeCondition(oldNode, conditionType), nodeCondition(newNode, conditionType)
+		if oldCondition.Status == newCondition.Status {
+			continue
+		}
+		changedFields = append(changedFields, "conditions."+string(conditionType))
+		line := fmt.Sprintf("~ %s: %s -> %s", conditionType, conditionStatus(oldCondition), conditionStatus(newCondition))
+		if newCondition.Reason != "" {
+			line += " (" + newCondition.Reason + ")"
+		}
+		diff = append(diff, line)
+	}
+
+	if oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable {
+		changedFields = append(changedFields, "spec.unschedulable")
+		if newNode.Spec.Unschedulable {
+			diff = append(diff, "~ cordoned")
+		} else {
+			diff = append(diff, "~ uncordoned")
+		}
+	}
+
+	if taintDiff := diffLines("taint", describeTaints(oldNode.Spec.Taints), describeTaints(newNode.Spec.Taints)); len(taintDiff) > 0 {
+		changedFields = append(changedFields, "spec.taints")
+		diff = append(diff, taintDiff...)
+	}
+
+	if oldVersion, newVersion := oldNode.Status.NodeInfo.KubeletVersion, newNode.Status.NodeInfo.KubeletVersion; oldVersion != newVersion {
+		changedFields = append(changedFields, "status.nodeInfo.kubeletVersion")
+		diff = append(diff, fmt.Sprintf("~ kubeletVersion: %s -> %s", oldVersion, newVersion))
+	}
+
+	return changedFields, diff
+}
+
+// nodeCondition returns the condition of the given type, or an empty condition when absent
+func nodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType) corev1.NodeCondition {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition
+		}
+	}
+	return corev1.NodeCondition{}
+}
+
+func conditionStatus(condition corev1.NodeCondition) string {
+	if condition.Status == "" {
+		return "absent"
+	}
+	return string(condition.Status)
+}
+
+// describeTaints renders taints as key=value:Effect, ignoring when they were added
+func describeTaints(taints []corev1.Taint) []string {
+	lines := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		if taint.Value != "" {
+			lines = append(lines, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s:%s", taint.Key, taint.Effect))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,76 @@
+package watcher
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// gitOpsManagers are the field managers of the Argo CD and Flux controllers
+var gitOpsManagers = map[string]string{
+	"argocd-application-controller": config.ChangeOriginArgoCD, // Client-side apply
+	"argocd-controller":             config.ChangeOriginArgoCD, // Server-side apply
+	"kustomize-controller":          config.ChangeOriginFlux,
+	"helm-controller":               config.ChangeOriginFlux,
+}
+
+// gitOpsMarkers are the labels and annotations Argo CD and Flux put on the objects they
+// apply, for objects whose managed fields were not kept
+var gitOpsMarkers = []struct {
+	key    string
+	origin string
+}{
+	{"argocd.argoproj.io/tracking-id", config.ChangeOriginArgoCD},
+	{"argocd.argoproj.io/instance", config.ChangeOriginArgoCD},
+	{"kustomize.toolkit.fluxcd.io/name", config.ChangeOriginFlux},
+	{"helm.toolkit.fluxcd.io/name", config.ChangeOriginFlux},
+}
+
+// changeOrigin returns the field manager of the object's latest write and whether it was
+// Argo CD, Flux or anyone else. Deletions are not recorded in the object, so their origin
+// is unknown; without managed fields only the GitOps markers tell the origin.
+func changeOrigin(eventType notifier.EventType, obj metav1.Object) (string, string) {
+	if obj == nil || eventType == notifier.EventDeleted {
+		return "", ""
+	}
+
+	if manager := latestManager(obj.GetManagedFields()); manager != "" {
+		if origin, ok := gitOpsManagers[manager]; ok {
+			return manager, origin
+		}
+		return manager, config.ChangeOriginOther
+	}
+
+	for _, marker := range gitOpsMarkers {
+		if _, ok := obj.GetAnnotations()[marker.key]; ok {
+			return "", marker.origin
+		}
+		if _, ok := obj.GetLabels()[marker.key]; ok {
+			return "", marker.origin
+		}
+	}
+	return "", ""
+}
+
+// latestManager returns the manager of the most recent managed fields entry. Writes to the
+// object itself are preferred over status writes, which controllers make after most changes.
+func latestManager(entries []metav1.ManagedFieldsEntry) string {
+	var latest *metav1.ManagedFieldsEntry
+	for i := range entries {
+		entry := &entries[i]
+		switch {
+		case latest == nil:
+		case entry.Subresource != "" && latest.Subresource == "":
+			continue
+		case entry.Subresource == "" && latest.Subresource != "":
+		case entry.Time == nil || (latest.Time != nil && entry.Time.Before(latest.Time)):
+			continue
+		}
+		latest = entry
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Manager
+}
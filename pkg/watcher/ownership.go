@@ -0,0 +1,40 @@
+package watcher
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// isGenerated reports whether watcher.ownership skips the object as generated by a
+// controller: it has an owner of a skipped kind, or it is a Secret of a skipped type
+func (w *InformerWatcher) isGenerated(kind string, obj *unstructured.Unstructured) bool {
+	if w.hasSkippedOwner(kind, obj) {
+		return true
+	}
+	if kind != "Secret" {
+		return false
+	}
+	secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+	for _, skipped := range w.config.Watcher.Ownership.SkipSecretTypes {
+		if secretType == skipped {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSkippedOwner reports whether one of the object's owner references is of a kind skipped for kind
+func (w *InformerWatcher) hasSkippedOwner(kind string, obj metav1.Object) bool {
+	skipped := w.config.Watcher.Ownership.GetSkipOwnerKinds(kind)
+	if len(skipped) == 0 {
+		return false
+	}
+	for _, owner := range obj.GetOwnerReferences() {
+		for _, ownerKind := range skipped {
+			if ownerKind == "*" || ownerKind == owner.Kind {
+				return true
+			}
+		}
+	}
+	return false
+}
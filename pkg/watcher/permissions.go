@@ -0,0 +1,170 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// permissionCheckTimeout bounds the access reviews run at startup
+const permissionCheckTimeout = 30 * time.Second
+
+// ErrMissingPermissions is returned by Start when RBAC denies watching a configured resource
+var ErrMissingPermissions = errors.New("missing RBAC permissions")
+
+var namespacesResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+
+// permissionTarget is a resource an informer lists and watches
+type permissionTarget struct {
+	kind      string
+	resource  schema.GroupVersionResource
+	namespace string // "" for all namespaces
+	purpose   string // Why an auxiliary informer is needed; empty for configured resources
+}
+
+func (t permissionTarget) String() string {
+	scope := "all namespaces"
+	if t.namespace != "" {
+		scope = "namespace " + t.namespace
+	}
+	if t.purpose != "" {
+		return fmt.Sprintf("%s in %s (%s)", t.resource.Resource, scope, t.purpose)
+	}
+	return fmt.Sprintf("%s in %s", t.resource.Resource, scope)
+}
+
+// permissionTargets returns every resource the watcher's informers will list and watch:
+// one per informer key of the configured entries, then the auxiliary informers
+func (w *InformerWatcher) permissionTargets() []permissionTarget {
+	seen := make(map[string]bool)
+	var targets []permissionTarget
+	add := func(target permissionTarget) {
+		key := target.resource.String() + "@" + target.namespace
+		if !seen[key] {
+			seen[key] = true
+			targets = append(targets, target)
+		}
+	}
+
+	for _, resourceConfig := range w.config.Resources {
+		resource, ok := supportedKinds[resourceConfig.Kind]
+		if !ok {
+			continue
+		}
+		add(permissionTarget{kind: resourceConfig.Kind, resource: resource.gvr, namespace: w.entryScope(resourceConfig).namespace})
+	}
+
+	add(permissionTarget{kind: "Namespace", resource: namespacesResource, purpose: "namespace deletion summaries and silence annotations"})
+	if w.config.Watcher.ValidateObjects {
+		add(permissionTarget{kind: "Pod", resource: podsResource, purpose: "watcher.validateObjects"})
+	}
+	if w.config.Watcher.Topology.Enabled {
+		add(permissionTarget{kind: "Node", resource: nodesResource, purpose: "watcher.topology"})
+	}
+	if w.config.Watcher.BlastRadius {
+		for _, kind := range consumerKinds {
+			add(permissionTarget{kind: kind, resource: supportedKinds[kind].gvr, purpose: "watcher.blastRadius"})
+		}
+	}
+	return targets
+}
+
+// checkPermissions asks the API server whether the watcher may list and watch what it is
+// configured to, before the informers start retrying denied requests forever. A kind that
+// may be watched but not listed is served by raw watches right away. Other denials fail
+// startup with ErrMissingPermissions, or are only logged with watcher.permissionCheck: warn;
+// the auxiliary informers of optional features are always only logged.
+func (w *InformerWatcher) checkPermissions() error {
+	mode := w.config.Watcher.GetPermissionCheck()
+	if mode == config.PermissionCheckOff {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, permissionCheckTimeout)
+	defer cancel()
+
+	var problems []string
+	listDenied := make(map[string]string) // kind -> reason
+	for _, target := range w.permissionTargets() {
+		// Raw watch engines never list
+		verbs := []string{"list", "watch"}
+		if target.purpose == "" && w.config.Watcher.GetEngine() == config.WatchEngineRawWatch {
+			verbs = []string{"watch"}
+		}
+
+		var denied []string
+		var reason string
+		for _, verb := range verbs {
+			allowed, why, err := w.reviewAccess(ctx, verb, target)
+			if err != nil {
+				// Some API servers or proxies do not serve the authorization API; the informers report real failures
+				w.logger.Warn("Skipping the RBAC permission check: access review failed", "error", err)
+				return nil
+			}
+			if !allowed {
+				denied = append(denied, verb)
+				reason = why
+			}
+		}
+		if len(denied) == 0 {
+			continue
+		}
+
+		problem := fmt.Sprintf("cannot %s %s", strings.Join(denied, " or "), target)
+		if reason != "" {
+			problem += ": " + reason
+		}
+		switch {
+		case target.purpose != "":
+			w.logger.Warn("RBAC permission check: "+problem, "kind", target.kind)
+		case len(denied) == 1 && denied[0] == "list" && !w.config.Watcher.DisableWatchFallback:
+			w.logger.Warn("RBAC permission check: "+problem+"; serving the kind from raw watches", "kind", target.kind)
+			listDenied[target.kind] = "RBAC denies list on " + target.String()
+		default:
+			w.logger.Error("RBAC permission check: "+problem, "kind", target.kind)
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) > 0 && mode == config.PermissionCheckFail {
+		return fmt.Errorf("%w: %s (grant them or set watcher.permissionCheck to warn)", ErrMissingPermissions, strings.Join(problems, "; "))
+	}
+	for kind, reason := range listDenied {
+		w.fallBackToRawWatch(kind, reason)
+	}
+	if len(problems) == 0 && len(listDenied) == 0 {
+		w.logger.Info("RBAC permission check passed")
+	}
+	return nil
+}
+
+// reviewAccess reports whether the API server allows verb on target for the watcher's identity
+func (w *InformerWatcher) reviewAccess(ctx context.Context, verb string, target permissionTarget) (bool, string, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: target.namespace,
+				Verb:      verb,
+				Group:     target.resource.Group,
+				Version:   target.resource.Version,
+				Resource:  target.resource.Resource,
+			},
+		},
+	}
+	result, err := w.k8sClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	if result.Status.EvaluationError != "" && !result.Status.Allowed {
+		return false, result.Status.EvaluationError, nil
+	}
+	return result.Status.Allowed, result.Status.Reason, nil
+}
@@ -0,0 +1,119 @@
+package watcher
+
+import (
+	"errors"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	tests := []struct {
+		name          string
+		watcher       config.WatcherConfig
+		denied        map[string]bool // "verb resource"
+		reviewFails   bool
+		wantErr       bool
+		wantRawWatch  bool
+		wantReviewed  []string
+		wantNotReview []string
+	}{
+		{
+			name:         "allowed",
+			wantReviewed: []string{"list configmaps", "watch configmaps", "list namespaces"},
+		},
+		{
+			name:    "watch denied",
+			denied:  map[string]bool{"watch configmaps": true},
+			wantErr: true,
+		},
+		{
+			name:    "watch denied in warn mode",
+			watcher: config.WatcherConfig{PermissionCheck: config.PermissionCheckWarn},
+			denied:  map[string]bool{"watch configmaps": true},
+		},
+		{
+			name:         "list denied falls back to raw watches",
+			denied:       map[string]bool{"list configmaps": true},
+			wantRawWatch: true,
+		},
+		{
+			name:    "list denied without the fallback",
+			watcher: config.WatcherConfig{DisableWatchFallback: true},
+			denied:  map[string]bool{"list configmaps": true},
+			wantErr: true,
+		},
+		{
+			name:          "raw watch engine only watches",
+			watcher:       config.WatcherConfig{Engine: config.WatchEngineRawWatch},
+			denied:        map[string]bool{"list configmaps": true},
+			wantReviewed:  []string{"watch configmaps"},
+			wantNotReview: []string{"list configmaps"},
+		},
+		{
+			name:    "optional feature denied",
+			watcher: config.WatcherConfig{ValidateObjects: true},
+			denied:  map[string]bool{"list pods": true, "watch pods": true},
+		},
+		{
+			name:        "access reviews unavailable",
+			denied:      map[string]bool{"watch configmaps": true},
+			reviewFails: true,
+		},
+		{
+			name:          "check off",
+			watcher:       config.WatcherConfig{PermissionCheck: config.PermissionCheckOff},
+			denied:        map[string]bool{"watch configmaps": true},
+			wantNotReview: []string{"watch configmaps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Resources: []config.ResourceConfig{{Kind: "ConfigMap", Namespace: "prod"}},
+				Watcher:   tt.watcher,
+			}
+			w, _, kubernetes := newTestWatcher(t, cfg)
+			w.engines["ConfigMap"] = &EngineStatus{Kind: "ConfigMap", Engine: EngineInformer}
+
+			reviewed := make(map[string]bool)
+			kubernetes.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if tt.reviewFails {
+					return true, nil, errors.New("the server could not find the requested resource")
+				}
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				key := attributes.Verb + " " + attributes.Resource
+				reviewed[key] = true
+				review.Status.Allowed = !tt.denied[key]
+				return true, review, nil
+			})
+
+			err := w.checkPermissions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkPermissions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrMissingPermissions) {
+				t.Errorf("checkPermissions() error = %v, want ErrMissingPermissions", err)
+			}
+			if got := w.engineStatus("ConfigMap").Engine == EngineRawWatch; got != tt.wantRawWatch {
+				t.Errorf("served by raw watches = %v, want %v", got, tt.wantRawWatch)
+			}
+			for _, key := range tt.wantReviewed {
+				if !reviewed[key] {
+					t.Errorf("%q was not reviewed", key)
+				}
+			}
+			for _, key := range tt.wantNotReview {
+				if reviewed[key] {
+					t.Errorf("%q was reviewed", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package watcher
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// createPodEventHandler only reacts to container status transitions. Pods are
+// created, updated and deleted constantly, so their ADDED/DELETED events and
+// ordinary updates are not notified and do not produce traces.
+func (w *InformerWatcher) createPodEventHandler(resourceConfig config.ResourceConfig) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !w.started() {
+				return
+			}
+			w.handlePodUpdated(oldObj, newObj, resourceConfig)
+		},
+	}
+}
+
+func (w *InformerWatcher) handlePodUpdated(oldObj, newObj interface{}, resourceConfig config.ResourceConfig) {
+	oldUnstructured, okOld := oldObj.(*unstructured.Unstructured)
+	newUnstructured, okNew := newObj.(*unstructured.Unstructured)
+	if !okOld || !okNew || !w.shouldProcessResource(newUnstructured, resourceConfig) {
+		return
+	}
+
+	var oldPod, newPod corev1.Pod
+	if runtime.DefaultUnstructuredConverter.FromUnstructured(oldUnstructured.Object, &oldPod) != nil ||
+		runtime.DefaultUnstructuredConverter.FromUnstructured(newUnstructured.Object, &newPod) != nil {
+		w.logger.Warn("Failed to convert to a typed pod", "kind", "Pod", "namespace", newUnstructured.GetNamespace(), "name", newUnstructured.GetName())
+		return
+	}
+
+	eventType, changedFields, diff := podAlerts(&oldPod, &newPod, w.config.Watcher.PodAlerts.GetMinRestarts())
+	if eventType == "" {
+		return
+	}
+
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), "Pod", eventType, newPod.Namespace, newPod.Name)
+	trace.Step(StageFiltered, "matched "+resourceConfig.Describe())
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+
+	trace.Logger().Info("Pod alert", "diff", diff)
+	w.sendNotification(trace, "Pod", eventType, newUnstructured, changedFields, diff)
+}
+
+// podAlerts compares container statuses and returns the most severe alert
+// type with the affected containers and a line per transition; eventType is
+// empty when nothing alert-worthy happened
+func podAlerts(oldPod, newPod *corev1.Pod, minRestarts int) (eventType notifier.EventType, changedFields, diff []string) {
+	previous := make(map[string]corev1.ContainerStatus)
+	for _, status := range append(append([]corev1.ContainerStatus(nil), oldPod.Status.InitContainerStatuses...), oldPod.Status.ContainerStatuses...) {
+		previous[status.Name] = status
+	}
+
+	// Alert types in the order they take precedence when several happen at once
+	found := make(map[notifier.EventType]bool)
+	for _, status := range append(append([]corev1.ContainerStatus(nil), newPod.Status.InitContainerStatuses...), newPod.Status.ContainerStatuses...) {
+		old := previous[status.Name]
+
+		switch {
+		// Containers that are not restarted, e.g. of Jobs, only show the kill in their state;
+		// restarted ones may show it there before the restart, which is then not alerted again
+		case terminatedReason(status.State) == "OOMKilled" && terminatedReason(old.State) != "OOMKilled":
+			found[notifier.EventPodOOMKilled] = true
+			diff = append(diff, oomKilledLine(newPod, status.Name, fmt.Sprintf("exit code: %d", status.State.Terminated.ExitCode)))
+		case status.RestartCount > old.RestartCount && terminatedReason(status.LastTerminationState) == "OOMKilled" && terminatedReason(old.State) != "OOMKilled":
+			found[notifier.EventPodOOMKilled] = true
+			diff = append(diff, oomKilledLine(newPod, status.Name, fmt.Sprintf("restarts: %d", status.RestartCount)))
+		case waitingReason(status) == "CrashLoopBackOff" && waitingReason(old) != "CrashLoopBackOff" && int(status.RestartCount) >= minRestarts:
+			found[notifier.EventPodCrashLoop] = true
+			line := fmt.Sprintf("~ container %s: CrashLoopBackOff (restarts: %d", status.Name, status.RestartCount)
+			if reason := terminatedReason(status.LastTerminationState); reason != "" {
+				line += ", last exit: " + reason
+			}
+			diff = append(diff, line+")")
+		case isImagePullFailure(waitingReason(status)) && !isImagePullFailure(waitingReason(old)):
+			found[notifier.EventPodImagePullBackOff] = true
+			diff = append(diff, fmt.Sprintf("~ container %s: %s (image: %s)", status.Name, waitingReason(status), status.Image))
+		default:
+			continue
+		}
+		changedFields = append(changedFields, "containers."+status.Name)
+	}
+
+	for _, candidate := range []notifier.EventType{notifier.EventPodOOMKilled, notifier.EventPodCrashLoop, notifier.EventPodImagePullBackOff} {
+		if found[candidate] {
+			return candidate, changedFields, diff
+		}
+	}
+	return "", nil, nil
+}
+
+// oomKilledLine describes an OOMKilled container, with its memory limit when set
+func oomKilledLine(pod *corev1.Pod, containerName, detail string) string {
+	line := fmt.Sprintf("~ container %s: OOMKilled (%s", containerName, detail)
+	if limit, ok := memoryLimit(pod, containerName); ok {
+		line += ", memory limit: " + limit
+	}
+	return line + ")"
+}
+
+func waitingReason(status corev1.ContainerStatus) string {
+	if status.State.Waiting == nil {
+		return ""
+	}
+	return status.State.Waiting.Reason
+}
+
+func terminatedReason(state corev1.ContainerState) string {
+	if state.Terminated == nil {
+		return ""
+	}
+	return state.Terminated.Reason
+}
+
+func isImagePullFailure(reason string) bool {
+	return reason == "ImagePullBackOff" || reason == "ErrImagePull"
+}
+
+// memoryLimit returns the memory limit of the named container, if set
+func memoryLimit(pod *corev1.Pod, containerName string) (string, bool) {
+	for _, container := range append(append([]corev1.Container(nil), pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if container.Name != containerName {
+			continue
+		}
+		if limit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			return limit.String(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+func podWithStatus(status corev1.ContainerStatus) *corev1.Pod {
+	status.Name = "app"
+	return &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}}}
+}
+
+func TestPodAlerts(t *testing.T) {
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	oomKilled := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}}
+	crashLoop := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+
+	tests := []struct {
+		name      string
+		old, new  corev1.ContainerStatus
+		wantEvent notifier.EventType
+		wantDiff  []string
+	}{
+		{
+			name:      "restarted after OOMKilled",
+			old:       corev1.ContainerStatus{State: running, RestartCount: 1},
+			new:       corev1.ContainerStatus{State: running, RestartCount: 2, LastTerminationState: oomKilled},
+			wantEvent: notifier.EventPodOOMKilled,
+			wantDiff:  []string{"~ container app: OOMKilled (restarts: 2)"},
+		},
+		{
+			name:      "OOMKilled without a restart",
+			old:       corev1.ContainerStatus{State: running},
+			new:       corev1.ContainerStatus{State: oomKilled},
+			wantEvent: notifier.EventPodOOMKilled,
+			wantDiff:  []string{"~ container app: OOMKilled (exit code: 137)"},
+		},
+		{
+			name: "restart after an alerted kill",
+			old:  corev1.ContainerStatus{State: oomKilled},
+			new:  corev1.ContainerStatus{State: running, RestartCount: 1, LastTerminationState: oomKilled},
+		},
+		{
+			name: "still terminated",
+			old:  corev1.ContainerStatus{State: oomKilled},
+			new:  corev1.ContainerStatus{State: oomKilled},
+		},
+		{
+			name:      "crash loop",
+			old:       corev1.ContainerStatus{State: running, RestartCount: 2},
+			new:       corev1.ContainerStatus{State: crashLoop, RestartCount: 3, LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error"}}},
+			wantEvent: notifier.EventPodCrashLoop,
+			wantDiff:  []string{"~ container app: CrashLoopBackOff (restarts: 3, last exit: Error)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventType, _, diff := podAlerts(podWithStatus(tt.old), podWithStatus(tt.new), 3)
+			if eventType != tt.wantEvent {
+				t.Errorf("podAlerts() = %q, want %q", eventType, tt.wantEvent)
+			}
+			if !reflect.DeepEqual(diff, tt.wantDiff) {
+				t.Errorf("diff = %v, want %v", diff, tt.wantDiff)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// quotaKinds are the namespace capacity kinds whose limits are rendered old -> new
+var quotaKinds = map[string]bool{"ResourceQuota": true, "LimitRange": true}
+
+// handleQuotaUpdated notifies a MODIFIED ResourceQuota or LimitRange with its changed limits.
+// The quota controller updates status.used whenever pods come and go, so status is left out.
+func (w *InformerWatcher) handleQuotaUpdated(trace *EventTrace, resourceKind string, oldObj, newObj *unstructured.Unstructured) {
+	changedFields, notify := w.specChanges(trace, resourceKind, oldObj, newObj)
+	if !notify {
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED")
+	w.sendNotificationWithSummary(trace, resourceKind, notifier.EventModified, newObj, changedFields,
+		quotaDiff(resourceKind, oldObj, newObj), quotaSummary(resourceKind, newObj))
+}
+
+// quotaDiff renders the limit changes between two versions of a ResourceQuota or LimitRange
+// as "+"/"-"/"~" lines, e.g. "~ hard.requests.cpu: 10 -> 20 (used 8)". Either object may be
+// nil for ADDED and DELETED events.
+func quotaDiff(kind string, oldObj, newObj *unstructured.Unstructured) []string {
+	switch kind {
+	case "ResourceQuota":
+		var oldQuota, newQuota corev1.ResourceQuota
+		if !convertObject(oldObj, &oldQuota) || !convertObject(newObj, &newQuota) {
+			return nil
+		}
+		lines := diffQuantities("hard", oldQuota.Spec.Hard, newQuota.Spec.Hard, newQuota.Status.Used)
+		lines = append(lines, diffLines("scope", quotaScopes(oldQuota.Spec.Scopes), quotaScopes(newQuota.Spec.Scopes))...)
+		return append(lines, diffLines("scopeSelector", describeScopeSelector(oldQuota.Spec.ScopeSelector),
+			describeScopeSelector(newQuota.Spec.ScopeSelector))...)
+	case "LimitRange":
+		var oldRange, newRange corev1.LimitRange
+		if !convertObject(oldObj, &oldRange) || !convertObject(newObj, &newRange) {
+			return nil
+		}
+		before, after := limitsByType(oldRange.Spec.Limits), limitsByType(newRange.Spec.Limits)
+		types := make([]string, 0, len(before)+len(after))
+		for limitType := range after {
+			types = append(types, limitType)
+		}
+		for limitType := range before {
+			if _, ok := after[limitType]; !ok {
+				types = append(types, limitType)
+			}
+		}
+		sort.Strings(types)
+
+		var lines []string
+		for _, limitType := range types {
+			oldItem, newItem := before[limitType], after[limitType]
+			lines = append(lines, diffQuantities(limitType+".max", oldItem.Max, newItem.Max, nil)...)
+			lines = append(lines, diffQuantities(limitType+".min", oldItem.Min, newItem.Min, nil)...)
+			lines = append(lines, diffQuantities(limitType+".default", oldItem.Default, newItem.Default, nil)...)
+			lines = append(lines, diffQuantities(limitType+".defaultRequest", oldItem.DefaultRequest, newItem.DefaultRequest, nil)...)
+			lines = append(lines, diffQuantities(limitType+".maxLimitRequestRatio", oldItem.MaxLimitRequestRatio, newItem.MaxLimitRequestRatio, nil)...)
+		}
+		return lines
+	}
+	return nil
+}
+
+// quotaSummary warns about quota limits now below the namespace's usage, which keep
+// new pods and objects from being created until usage drops
+func quotaSummary(kind string, obj *unstructured.Unstructured) []string {
+	var quota corev1.ResourceQuota
+	if kind != "ResourceQuota" || !convertObject(obj, &quota) {
+		return nil
+	}
+	var summary []string
+	for _, name := range sortedResourceNames(quota.Spec.Hard) {
+		hard := quota.Spec.Hard[name]
+		if used, ok := quota.Status.Used[name]; ok && used.Cmp(hard) > 0 {
+			summary = append(summary, fmt.Sprintf("%s: used %s exceeds the limit of %s", name, used.String(), hard.String()))
+		}
+	}
+	return summary
+}
+
+// diffQuantities compares two resource lists; lines for resources in used show the current usage
+func diffQuantities(label string, before, after, used corev1.ResourceList) []string {
+	names := sortedResourceNames(before)
+	for _, name := range sortedResourceNames(after) {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	var lines []string
+	for _, name := range names {
+		oldValue, hadOld := before[name]
+		newValue, hasNew := after[name]
+		var line string
+		switch {
+		case !hadOld:
+			line = fmt.Sprintf("+ %s.%s: %s", label, name, newValue.String())
+		case !hasNew:
+			lines = append(lines, fmt.Sprintf("- %s.%s: %s", label, name, oldValue.String()))
+			continue
+		case oldValue.Cmp(newValue) != 0:
+			line = fmt.Sprintf("~ %s.%s: %s -> %s", label, name, oldValue.String(), newValue.String())
+		default:
+			continue
+		}
+		if usage, ok := used[name]; ok {
+			line += " (used " + usage.String() + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func sortedResourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// limitsByType indexes LimitRange items by their type (Container, Pod, PersistentVolumeClaim)
+func limitsByType(items []corev1.LimitRangeItem) map[string]corev1.LimitRangeItem {
+	byType := make(map[string]corev1.LimitRangeItem, len(items))
+	for _, item := range items {
+		byType[string(item.Type)] = item
+	}
+	return byType
+}
+
+func quotaScopes(scopes []corev1.ResourceQuotaScope) []string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return names
+}
+
+func describeScopeSelector(selector *corev1.ScopeSelector) []string {
+	if selector == nil {
+		return nil
+	}
+	lines := make([]string, 0, len(selector.MatchExpressions))
+	for _, expression := range selector.MatchExpressions {
+		line := fmt.Sprintf("%s %s", expression.ScopeName, expression.Operator)
+		if len(expression.Values) > 0 {
+			line += " [" + strings.Join(expression.Values, ",") + "]"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
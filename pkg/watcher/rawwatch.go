@@ -0,0 +1,192 @@
+package watcher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+)
+
+// rawWatchEngine watches a resource with plain Watch calls and dispatches
+// events to informer-style handlers. It is the fallback for kinds whose
+// informer cannot sync: it only lists to catch up after its watch expired,
+// so it keeps working when lists are too large or RBAC only grants watch.
+type rawWatchEngine struct {
+	client    dynamic.Interface
+	kind      string
+	namespace string // All namespaces for ""
+	resource  resourceKind
+	handlers  []cache.ResourceEventHandler
+	onError   func(error)
+	logger    *slog.Logger
+
+	// known holds the last seen version of each object, keyed by namespace/name
+	known     map[string]*unstructured.Unstructured
+	startedAt time.Time
+}
+
+func newRawWatchEngine(client dynamic.Interface, kind, namespace string, resource resourceKind, handlers []cache.ResourceEventHandler, onError func(error), logger *slog.Logger) *rawWatchEngine {
+	return &rawWatchEngine{
+		client:    client,
+		kind:      kind,
+		namespace: namespace,
+		resource:  resource,
+		handlers:  handlers,
+		onError:   onError,
+		logger:    logger,
+		known:     make(map[string]*unstructured.Unstructured),
+	}
+}
+
+// Run watches until ctx is cancelled, re-establishing the watch with backoff
+func (e *rawWatchEngine) Run(ctx context.Context) {
+	defer exitcode.Recover()
+	e.startedAt = time.Now()
+	e.logger.Info("Raw watch engine started")
+
+	resourceVersion := ""
+	backoff := time.Second
+
+	for ctx.Err() == nil {
+		watcher, err := e.client.Resource(e.resource.gvr).Namespace(e.namespace).Watch(ctx, metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			e.onError(err)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+			}
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+			continue
+		}
+
+		backoff = time.Second
+		resourceVersion = e.consume(ctx, watcher, resourceVersion)
+		watcher.Stop()
+	}
+
+	e.logger.Info("Raw watch engine stopped")
+}
+
+// consume dispatches events from one watch and returns the resource version to resume from
+func (e *rawWatchEngine) consume(ctx context.Context, watcher watch.Interface, resourceVersion string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return resourceVersion
+			}
+
+			if event.Type == watch.Error {
+				err := apierrors.FromObject(event.Object)
+				if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					// The events since resourceVersion are gone; relist to find what changed meanwhile
+					return e.relist(ctx)
+				}
+				e.onError(err)
+				return resourceVersion
+			}
+
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			resourceVersion = obj.GetResourceVersion()
+
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				e.handleUpsert(obj, event.Type == watch.Added)
+			case watch.Deleted:
+				e.handleDelete(obj)
+			}
+		}
+	}
+}
+
+// relist lists the current objects once a watch expired and diffs them with the known
+// versions: changed objects are dispatched as updates and objects that are gone as deletes.
+// It returns the list's resource version to watch from. Without list permission it returns
+// "" to start over from the current state, missing the deletions made meanwhile.
+func (e *rawWatchEngine) relist(ctx context.Context) string {
+	list, err := e.client.Resource(e.resource.gvr).Namespace(e.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		e.logger.Warn("Failed to relist after the watch expired, deletions made meanwhile are missed", "error", err)
+		return ""
+	}
+
+	listed := make(map[string]bool, len(list.Items))
+	for i := range list.Items {
+		obj := &list.Items[i]
+		listed[objectKey(obj)] = true
+		e.handleUpsert(obj, true)
+	}
+	for key, obj := range e.known {
+		if !listed[key] {
+			e.handleDelete(obj)
+		}
+	}
+	return list.GetResourceVersion()
+}
+
+// handleUpsert dispatches an add or update depending on whether the object is already known.
+// Objects created before the engine started are existing state and only recorded.
+func (e *rawWatchEngine) handleUpsert(obj *unstructured.Unstructured, added bool) {
+	key := obj.GetNamespace() + "/" + obj.GetName()
+	old, known := e.known[key]
+	e.known[key] = obj
+
+	switch {
+	case !known && added && obj.GetCreationTimestamp().Time.Before(e.startedAt):
+		return
+	case !known:
+		if converted, ok := e.convert(obj); ok {
+			for _, handler := range e.handlers {
+				handler.OnAdd(converted, false)
+			}
+		}
+	case old.GetResourceVersion() != obj.GetResourceVersion():
+		oldConverted, okOld := e.convert(old)
+		newConverted, okNew := e.convert(obj)
+		if okOld && okNew {
+			for _, handler := range e.handlers {
+				handler.OnUpdate(oldConverted, newConverted)
+			}
+		}
+	}
+}
+
+// handleDelete dispatches a delete and forgets the object
+func (e *rawWatchEngine) handleDelete(obj *unstructured.Unstructured) {
+	delete(e.known, obj.GetNamespace()+"/"+obj.GetName())
+	if converted, ok := e.convert(obj); ok {
+		for _, handler := range e.handlers {
+			handler.OnDelete(converted)
+		}
+	}
+}
+
+func (e *rawWatchEngine) convert(obj *unstructured.Unstructured) (interface{}, bool) {
+	converted, err := e.resource.toHandlerObject(obj)
+	if err != nil {
+		e.logger.Warn("Failed to convert object from raw watch", "namespace", obj.GetNamespace(), "name", obj.GetName(), "error", err)
+		return nil, false
+	}
+	return converted, true
+}
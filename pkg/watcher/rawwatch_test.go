@@ -0,0 +1,147 @@
+package watcher
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func rawObject(name, version string, created time.Time) *unstructured.Unstructured {
+	obj := configMap(version, nil)
+	obj.SetName(name)
+	obj.SetCreationTimestamp(metav1.NewTime(created))
+	return obj
+}
+
+func TestRawWatchEngineConsume(t *testing.T) {
+	started := time.Now()
+	before, after := started.Add(-time.Hour), started.Add(time.Minute)
+	expired := &apierrors.NewResourceExpired("too old resource version").ErrStatus
+
+	tests := []struct {
+		name          string
+		known         []*unstructured.Unstructured
+		events        []watch.Event
+		listed        []*unstructured.Unstructured // Returned by a relist, at resource version 20
+		listForbidden bool
+		wantCalls     []string
+		wantRV        string
+		wantErr       bool // Reported to onError
+	}{
+		{
+			name: "existing objects are only recorded",
+			events: []watch.Event{
+				{Type: watch.Added, Object: rawObject("a", "5", before)},
+				{Type: watch.Modified, Object: rawObject("a", "6", before)},
+			},
+			wantCalls: []string{"update prod/a"},
+			wantRV:    "6",
+		},
+		{
+			name: "created, changed and deleted",
+			events: []watch.Event{
+				{Type: watch.Added, Object: rawObject("b", "7", after)},
+				{Type: watch.Modified, Object: rawObject("b", "8", after)},
+				{Type: watch.Deleted, Object: rawObject("b", "9", after)},
+			},
+			wantCalls: []string{"add prod/b", "update prod/b", "delete prod/b"},
+			wantRV:    "9",
+		},
+		{
+			name:  "relisted objects are diffed with the known versions",
+			known: []*unstructured.Unstructured{rawObject("a", "5", before), rawObject("b", "5", before)},
+			events: []watch.Event{
+				{Type: watch.Added, Object: rawObject("a", "5", before)},
+				{Type: watch.Added, Object: rawObject("b", "8", before)},
+			},
+			wantCalls: []string{"update prod/b"},
+			wantRV:    "8",
+		},
+		{
+			name:  "expired resource version relists",
+			known: []*unstructured.Unstructured{rawObject("a", "5", before), rawObject("c", "5", before)},
+			events: []watch.Event{
+				{Type: watch.Added, Object: rawObject("b", "7", after)},
+				{Type: watch.Error, Object: expired},
+			},
+			listed:    []*unstructured.Unstructured{rawObject("a", "5", before), rawObject("b", "9", after), rawObject("d", "10", after)},
+			wantCalls: []string{"add prod/b", "update prod/b", "add prod/d", "delete prod/c"},
+			wantRV:    "20",
+		},
+		{
+			name: "expired resource version starts over without list permission",
+			events: []watch.Event{
+				{Type: watch.Added, Object: rawObject("b", "7", after)},
+				{Type: watch.Error, Object: expired},
+			},
+			listForbidden: true,
+			wantCalls:     []string{"add prod/b"},
+			wantRV:        "",
+		},
+		{
+			name: "other errors resume from the last version",
+			events: []watch.Event{
+				{Type: watch.Added, Object: rawObject("b", "7", after)},
+				{Type: watch.Error, Object: &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusInternalServerError, Reason: metav1.StatusReasonInternalError}},
+			},
+			wantCalls: []string{"add prod/b"},
+			wantRV:    "7",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := &handlerCalls{}
+			var errs []error
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{supportedKinds["ConfigMap"].gvr: "ConfigMapList"})
+			client.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if tt.listForbidden {
+					return true, nil, apierrors.NewForbidden(supportedKinds["ConfigMap"].gvr.GroupResource(), "", nil)
+				}
+				list := &unstructured.UnstructuredList{}
+				list.SetResourceVersion("20")
+				for _, obj := range tt.listed {
+					list.Items = append(list.Items, *obj)
+				}
+				return true, list, nil
+			})
+			engine := newRawWatchEngine(client, "ConfigMap", "prod", supportedKinds["ConfigMap"], []cache.ResourceEventHandler{calls},
+				func(err error) { errs = append(errs, err) }, slog.Default())
+			engine.startedAt = started
+			for _, obj := range tt.known {
+				engine.known[objectKey(obj)] = obj
+			}
+
+			watcher := watch.NewFakeWithChanSize(len(tt.events), false)
+			for _, event := range tt.events {
+				watcher.Action(event.Type, event.Object)
+			}
+			watcher.Stop()
+
+			if rv := engine.consume(context.Background(), watcher, "1"); rv != tt.wantRV {
+				t.Errorf("consume() = %q, want %q", rv, tt.wantRV)
+			}
+			if !reflect.DeepEqual(calls.calls, tt.wantCalls) {
+				t.Errorf("handlers got %v, want %v", calls.calls, tt.wantCalls)
+			}
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("errors reported: %v, want some: %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
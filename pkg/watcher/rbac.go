@@ -0,0 +1,123 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// rbacDiff renders the permission changes between two versions of an RBAC
+// object as "+"/"-" lines. Either object may be nil for ADDED and DELETED events.
+func rbacDiff(kind string, oldObj, newObj *unstructured.Unstructured) []string {
+	switch kind {
+	case "Role", "ClusterRole":
+		var oldRole, newRole rbacv1.ClusterRole
+		if !convertObject(oldObj, &oldRole) || !convertObject(newObj, &newRole) {
+			return nil
+		}
+		return diffLines("rule", describeRules(oldRole.Rules), describeRules(newRole.Rules))
+	case "RoleBinding", "ClusterRoleBinding":
+		var oldBinding, newBinding rbacv1.ClusterRoleBinding
+		if !convertObject(oldObj, &oldBinding) || !convertObject(newObj, &newBinding) {
+			return nil
+		}
+		var lines []string
+		switch {
+		case oldObj == nil:
+			lines = append(lines, "+ roleRef: "+describeRoleRef(newBinding.RoleRef))
+		case newObj == nil:
+			lines = append(lines, "- roleRef: "+describeRoleRef(oldBinding.RoleRef))
+		case oldBinding.RoleRef != newBinding.RoleRef:
+			lines = append(lines, fmt.Sprintf("~ roleRef: %s -> %s", describeRoleRef(oldBinding.RoleRef), describeRoleRef(newBinding.RoleRef)))
+		}
+		return append(lines, diffLines("subject", describeSubjects(oldBinding.Subjects), describeSubjects(newBinding.Subjects))...)
+	}
+	return nil
+}
+
+// convertObject decodes obj into out; a nil obj leaves out empty.
+// Roles and ClusterRoles (and their bindings) share a schema, so the cluster types decode both.
+func convertObject(obj *unstructured.Unstructured, out interface{}) bool {
+	if obj == nil {
+		return true
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, out) == nil
+}
+
+func describeRules(rules []rbacv1.PolicyRule) []string {
+	lines := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		var parts []string
+		add := func(name string, values []string) {
+			if len(values) > 0 {
+				parts = append(parts, fmt.Sprintf("%s=[%s]", name, strings.Join(values, ",")))
+			}
+		}
+		add("verbs", rule.Verbs)
+		add("apiGroups", quoteCoreGroup(rule.APIGroups))
+		add("resources", rule.Resources)
+		add("resourceNames", rule.ResourceNames)
+		add("nonResourceURLs", rule.NonResourceURLs)
+		lines = append(lines, strings.Join(parts, " "))
+	}
+	return lines
+}
+
+// quoteCoreGroup shows the core API group as "" rather than an empty string
+func quoteCoreGroup(groups []string) []string {
+	quoted := make([]string, len(groups))
+	for i, group := range groups {
+		if group == "" {
+			group = `""`
+		}
+		quoted[i] = group
+	}
+	return quoted
+}
+
+func describeSubjects(subjects []rbacv1.Subject) []string {
+	lines := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.Namespace != "" {
+			lines = append(lines, fmt.Sprintf("%s %s/%s", subject.Kind, subject.Namespace, subject.Name))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s %s", subject.Kind, subject.Name))
+		}
+	}
+	return lines
+}
+
+func describeRoleRef(ref rbacv1.RoleRef) string {
+	return ref.Kind + "/" + ref.Name
+}
+
+// diffLines reports entries only in before as removed and only in after as added
+func diffLines(label string, before, after []string) []string {
+	inBefore := make(map[string]bool, len(before))
+	for _, line := range before {
+		inBefore[line] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, line := range after {
+		inAfter[line] = true
+	}
+
+	var removed, added []string
+	for _, line := range before {
+		if !inAfter[line] {
+			removed = append(removed, fmt.Sprintf("- %s: %s", label, line))
+		}
+	}
+	for _, line := range after {
+		if !inBefore[line] {
+			added = append(added, fmt.Sprintf("+ %s: %s", label, line))
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return append(removed, added...)
+}
@@ -0,0 +1,107 @@
+package watcher
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func role(rules ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "Role",
+		"metadata":   map[string]interface{}{"name": "reader", "namespace": "prod"},
+		"rules":      rules,
+	}}
+}
+
+func binding(roleName string, subjects ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "RoleBinding",
+		"metadata":   map[string]interface{}{"name": "readers", "namespace": "prod"},
+		"roleRef":    map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "Role", "name": roleName},
+		"subjects":   subjects,
+	}}
+}
+
+func TestRBACDiff(t *testing.T) {
+	readPods := map[string]interface{}{"apiGroups": []interface{}{""}, "resources": []interface{}{"pods"}, "verbs": []interface{}{"get", "list"}}
+	readSecrets := map[string]interface{}{"apiGroups": []interface{}{""}, "resources": []interface{}{"secrets"}, "resourceNames": []interface{}{"db"}, "verbs": []interface{}{"get"}}
+	alice := map[string]interface{}{"kind": "User", "name": "alice"}
+	ci := map[string]interface{}{"kind": "ServiceAccount", "name": "ci", "namespace": "build"}
+
+	tests := []struct {
+		name     string
+		kind     string
+		old, new *unstructured.Unstructured
+		want     []string
+	}{
+		{
+			name: "rule added",
+			kind: "Role",
+			old:  role(readPods),
+			new:  role(readPods, readSecrets),
+			want: []string{`+ rule: verbs=[get] apiGroups=[""] resources=[secrets] resourceNames=[db]`},
+		},
+		{
+			name: "rule changed",
+			kind: "ClusterRole",
+			old:  role(readPods),
+			new:  role(map[string]interface{}{"apiGroups": []interface{}{""}, "resources": []interface{}{"pods"}, "verbs": []interface{}{"*"}}),
+			want: []string{
+				`- rule: verbs=[get,list] apiGroups=[""] resources=[pods]`,
+				`+ rule: verbs=[*] apiGroups=[""] resources=[pods]`,
+			},
+		},
+		{
+			name: "role deleted",
+			kind: "Role",
+			old:  role(readPods),
+			want: []string{`- rule: verbs=[get,list] apiGroups=[""] resources=[pods]`},
+		},
+		{
+			name: "unchanged",
+			kind: "Role",
+			old:  role(readPods),
+			new:  role(readPods),
+			want: nil,
+		},
+		{
+			name: "subject added",
+			kind: "RoleBinding",
+			old:  binding("reader", alice),
+			new:  binding("reader", alice, ci),
+			want: []string{"+ subject: ServiceAccount build/ci"},
+		},
+		{
+			name: "role reference changed",
+			kind: "ClusterRoleBinding",
+			old:  binding("reader", alice),
+			new:  binding("admin", alice),
+			want: []string{"~ roleRef: Role/reader -> Role/admin"},
+		},
+		{
+			name: "binding added",
+			kind: "RoleBinding",
+			new:  binding("reader", alice),
+			want: []string{"+ roleRef: Role/reader", "+ subject: User alice"},
+		},
+		{
+			name: "other kind",
+			kind: "ConfigMap",
+			old:  role(readPods),
+			new:  role(),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rbacDiff(tt.kind, tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rbacDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
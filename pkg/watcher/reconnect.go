@@ -0,0 +1,361 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// maxDisconnectedDiffLines caps the field changes listed for an object that changed while disconnected
+const maxDisconnectedDiffLines = 20
+
+// maxDiffValueLength caps a rendered field value in diff lines
+const maxDiffValueLength = 80
+
+// reconnectHandler tells the relist that follows a failed watch apart from live
+// changes. When the informer's list/watch fails, it remembers the resource
+// version of every cached object; the relist then delivers each of them as an
+// update from that cached version, and those that differ are notified as
+// CHANGED_WHILE_DISCONNECTED with a field diff instead of as MODIFIED.
+type reconnectHandler struct {
+	watcher        *InformerWatcher
+	resourceConfig config.ResourceConfig
+	next           cache.ResourceEventHandler
+
+	mu      sync.Mutex
+	pending map[string]string // object key -> resource version cached before the disconnect
+	since   time.Time
+}
+
+// detectMissedChanges wraps the handler of a resource entry to report changes the
+// watch missed. Pods, Events and Helm releases have their own alert types and are not wrapped.
+func (w *InformerWatcher) detectMissedChanges(shared *sharedInformer, resourceConfig config.ResourceConfig, handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	if resourceConfig.Kind == "Pod" || resourceConfig.Kind == "Event" || resourceConfig.HelmReleases {
+		return handler
+	}
+	reconnect := &reconnectHandler{watcher: w, resourceConfig: resourceConfig, next: handler}
+	shared.reconnects = append(shared.reconnects, reconnect)
+	return reconnect
+}
+
+// rememberCachedVersions snapshots the informer's cache for its handlers after a
+// list/watch failure. A snapshot still waiting for its relist is kept, so repeated
+// failures during one outage compare against the state from before the first.
+func (w *InformerWatcher) rememberCachedVersions(shared *sharedInformer) {
+	w.mu.RLock()
+	started := w.isStarted
+	handlers := shared.reconnects
+	w.mu.RUnlock()
+	if !started || len(handlers) == 0 {
+		return
+	}
+
+	versions := make(map[string]string)
+	for _, obj := range shared.informer.GetStore().List() {
+		accessor, ok := obj.(metav1.Object)
+		if !ok {
+			continue
+		}
+		versions[objectKey(accessor)] = accessor.GetResourceVersion()
+	}
+	if len(versions) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, handler := range handlers {
+		handler.remember(versions, now)
+	}
+}
+
+func (h *reconnectHandler) remember(versions map[string]string, since time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pending != nil {
+		return
+	}
+	// Each handler consumes its own copy as the relist replays the objects
+	h.pending = make(map[string]string, len(versions))
+	for key, version := range versions {
+		h.pending[key] = version
+	}
+	h.since = since
+	h.watcher.logger.Warn("Watch failed; comparing the cached objects with the next relist", "kind", h.resourceConfig.Kind, "cached", len(versions))
+}
+
+// forget drops the pre-disconnect versions of the objects in a namespace
+func (h *reconnectHandler) forget(namespace string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key := range h.pending {
+		if strings.HasPrefix(key, namespace+"/") {
+			delete(h.pending, key)
+		}
+	}
+	if len(h.pending) == 0 {
+		h.pending = nil
+	}
+}
+
+// take removes the object's pre-disconnect version, reporting whether there was one
+func (h *reconnectHandler) take(key string) (string, time.Time, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	version, ok := h.pending[key]
+	if !ok {
+		return "", time.Time{}, false
+	}
+	delete(h.pending, key)
+	since := h.since
+	if len(h.pending) == 0 {
+		h.pending = nil
+	}
+	return version, since, true
+}
+
+func (h *reconnectHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	h.next.OnAdd(obj, isInInitialList)
+}
+
+func (h *reconnectHandler) OnUpdate(oldObj, newObj interface{}) {
+	oldAccessor, okOld := oldObj.(metav1.Object)
+	newAccessor, okNew := newObj.(metav1.Object)
+	if !okOld || !okNew {
+		h.next.OnUpdate(oldObj, newObj)
+		return
+	}
+
+	// A relist replays every object; unchanged ones come back with the cached
+	// version and there is nothing to notify
+	version, since, disconnected := h.take(objectKey(newAccessor))
+	if oldAccessor.GetResourceVersion() == newAccessor.GetResourceVersion() {
+		return
+	}
+	if disconnected && version == oldAccessor.GetResourceVersion() &&
+		h.watcher.handleChangedWhileDisconnected(oldObj, newObj, h.resourceConfig, since) {
+		return
+	}
+	h.next.OnUpdate(oldObj, newObj)
+}
+
+func (h *reconnectHandler) OnDelete(obj interface{}) {
+	// Objects deleted while disconnected arrive as tombstones holding the cached version
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if accessor, ok := obj.(metav1.Object); ok {
+		h.take(objectKey(accessor))
+	}
+	h.next.OnDelete(obj)
+}
+
+func objectKey(obj metav1.Object) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// handleChangedWhileDisconnected notifies an object whose relisted version differs from
+// the one cached before the watch failed. Intermediate versions were never seen, so the
+// notification carries the net field changes. Status is left out since it nearly always
+// moves during an outage. It returns false if the objects cannot be compared, leaving
+// the update to the regular handler.
+func (w *InformerWatcher) handleChangedWhileDisconnected(oldObj, newObj interface{}, resourceConfig config.ResourceConfig, since time.Time) bool {
+	const eventType = notifier.EventChangedWhileDisconnected
+	kind := resourceConfig.Kind
+	if !resourceConfig.NotifiesEventType(string(notifier.EventModified)) {
+		return true
+	}
+
+	oldUnstructured, errOld := toUnstructured(oldObj)
+	newUnstructured, errNew := toUnstructured(newObj)
+	if errOld != nil || errNew != nil {
+		w.logger.Warn("Failed to convert relisted object for comparison, notifying as MODIFIED", "kind", kind)
+		return false
+	}
+	unstructured.RemoveNestedField(oldUnstructured.Object, "status")
+	unstructured.RemoveNestedField(newUnstructured.Object, "status")
+
+	trace := w.traces.Start(w.entryInformerKey(resourceConfig), kind, eventType, newUnstructured.GetNamespace(), newUnstructured.GetName())
+	if !w.filterEvent(trace, w.shouldProcessResource(newUnstructured, resourceConfig), resourceConfig) ||
+		!w.matchesFilter(trace, resourceConfig, oldObj, newObj) {
+		return true
+	}
+
+	if paths := compileFieldPaths(resourceConfig.WatchExpressions); len(paths) > 0 &&
+		len(changedSignificantFields(paths, oldUnstructured.Object, newUnstructured.Object)) == 0 {
+		trace.Logger().Debug("No watch expression changed while disconnected (skipping notification)")
+		trace.Step(StageDiffed, "no watch expression changed")
+		w.traces.Finish(trace, "ignored")
+		return true
+	}
+
+	var changedFields, diff []string
+	var images []notifier.ImageChange
+	summary := []string{fmt.Sprintf("Changed while the watch was disconnected from %s until %s; intermediate versions were not observed",
+		since.UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339))}
+	if kind == "Secret" {
+		var changed bool
+		if changedFields, diff, changed = secretChanges(oldUnstructured, newUnstructured); !changed {
+			trace.Logger().Debug("Data unchanged while disconnected (skipping notification)")
+			trace.Step(StageDiffed, "data hash unchanged")
+			w.traces.Finish(trace, "ignored")
+			return true
+		}
+		trace.Step(StageDiffed, describeChangedFields(changedFields))
+		summary = append(summary, secretHashSummary(oldUnstructured, newUnstructured))
+	} else if oldDeployment, ok := oldObj.(*appsv1.Deployment); ok {
+		compareOld, compareNew := oldDeployment, newObj.(*appsv1.Deployment)
+		if paths := w.ignoreFields[kind]; len(paths) > 0 {
+			compareOld, compareNew = stripDeploymentFields(compareOld, paths), stripDeploymentFields(compareNew, paths)
+		}
+		if changedFields = w.changedDeploymentFields(compareOld, compareNew); len(changedFields) == 0 {
+			trace.Logger().Debug("Non-important changes while disconnected (skipping notification)")
+			trace.Step(StageDiffed, "no important fields changed")
+			w.traces.Finish(trace, "ignored")
+			return true
+		}
+		trace.Step(StageDiffed, describeChangedFields(changedFields))
+		images = imageChanges(&compareOld.Spec.Template.Spec, &compareNew.Spec.Template.Spec)
+	} else {
+		var notify bool
+		if changedFields, notify = w.resourceChanges(trace, kind, oldUnstructured, newUnstructured); !notify {
+			return true
+		}
+		if len(changedFields) == 0 {
+			trace.Logger().Debug("Only status changed while disconnected (skipping notification)")
+			trace.Step(StageDiffed, "only status changed")
+			w.traces.Finish(trace, "ignored")
+			return true
+		}
+		images = workloadImageChanges(kind, oldUnstructured, newUnstructured)
+	}
+
+	trace.Logger().Info("Resource changed while the watch was disconnected", "since", since.Format(time.RFC3339))
+
+	if diff == nil && kind == "ConfigMap" {
+		diff = w.configMapDiff(oldUnstructured, newUnstructured)
+	}
+	if diff == nil {
+		diff = kindDiff(kind, oldUnstructured, newUnstructured)
+	}
+	if diff == nil {
+		diff = objectDiff(oldUnstructured, newUnstructured)
+	}
+	w.sendEvent(trace, newUnstructured, notifier.NotificationEvent{
+		EventType:     eventType,
+		ResourceKind:  kind,
+		ChangedFields: changedFields,
+		Diff:          diff,
+		Summary:       summary,
+		ImageChanges:  images,
+	})
+	return true
+}
+
+// toUnstructured returns a copy of a typed or unstructured handler object
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.DeepCopy(), nil
+	}
+	content, err := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// objectDiff renders the changed leaf fields of two objects as "+", "-" and "~" lines,
+// covering labels, annotations and every top-level section except metadata
+func objectDiff(oldObj, newObj *unstructured.Unstructured) []string {
+	before, after := diffContent(oldObj), diffContent(newObj)
+
+	var lines []string
+	collectDiff("", before, after, &lines)
+	sort.Strings(lines)
+	if len(lines) > maxDisconnectedDiffLines {
+		more := len(lines) - maxDisconnectedDiffLines
+		lines = append(lines[:maxDisconnectedDiffLines], fmt.Sprintf("... and %d more changed fields", more))
+	}
+	return lines
+}
+
+// diffContent is the part of an object compared by objectDiff
+func diffContent(obj *unstructured.Unstructured) map[string]interface{} {
+	content := make(map[string]interface{}, len(obj.Object))
+	for key, value := range obj.Object {
+		if key != "metadata" && key != "apiVersion" && key != "kind" {
+			content[key] = value
+		}
+	}
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		content["metadata.labels"] = toInterfaceMap(labels)
+	}
+	if annotations := obj.GetAnnotations(); len(annotations) > 0 {
+		content["metadata.annotations"] = toInterfaceMap(annotations)
+	}
+	return content
+}
+
+func toInterfaceMap(values map[string]string) map[string]interface{} {
+	converted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		converted[key] = value
+	}
+	return converted
+}
+
+// collectDiff walks nested maps, treating anything else (including lists) as a leaf
+func collectDiff(path string, before, after map[string]interface{}, lines *[]string) {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+
+	for key := range keys {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		oldValue, inBefore := before[key]
+		newValue, inAfter := after[key]
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+
+		switch {
+		case oldIsMap && newIsMap:
+			collectDiff(fieldPath, oldMap, newMap, lines)
+		case !inBefore:
+			*lines = append(*lines, fmt.Sprintf("+ %s: %s", fieldPath, renderDiffValue(newValue)))
+		case !inAfter:
+			*lines = append(*lines, fmt.Sprintf("- %s: %s", fieldPath, renderDiffValue(oldValue)))
+		case !reflect.DeepEqual(oldValue, newValue):
+			*lines = append(*lines, fmt.Sprintf("~ %s: %s -> %s", fieldPath, renderDiffValue(oldValue), renderDiffValue(newValue)))
+		}
+	}
+}
+
+func renderDiffValue(value interface{}) string {
+	rendered, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if len(rendered) > maxDiffValueLength {
+		return string(rendered[:maxDiffValueLength-3]) + "..."
+	}
+	return string(rendered)
+}
@@ -0,0 +1,138 @@
+package watcher
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+func configMap(version string, data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "app", "namespace": "prod", "resourceVersion": version},
+		"data":       data,
+	}}
+}
+
+// handlerCalls records the events a handler is given, e.g. "update prod/app"
+type handlerCalls struct {
+	calls []string
+}
+
+func (h *handlerCalls) record(call string, obj interface{}) {
+	if accessor, ok := obj.(metav1.Object); ok {
+		call += " " + objectKey(accessor)
+	}
+	h.calls = append(h.calls, call)
+}
+
+func (h *handlerCalls) OnAdd(obj interface{}, isInInitialList bool) { h.record("add", obj) }
+func (h *handlerCalls) OnUpdate(oldObj, newObj interface{})         { h.record("update", newObj) }
+func (h *handlerCalls) OnDelete(obj interface{})                    { h.record("delete", obj) }
+
+func TestReconnectHandler(t *testing.T) {
+	before := configMap("10", map[string]interface{}{"mode": "a"})
+	changed := configMap("12", map[string]interface{}{"mode": "b"})
+
+	tests := []struct {
+		name       string
+		remember   bool
+		old, new   *unstructured.Unstructured
+		wantCalls  []string
+		wantNotify notifier.EventType
+	}{
+		{
+			name:      "live change",
+			old:       before,
+			new:       changed,
+			wantCalls: []string{"update prod/app"},
+		},
+		{
+			name:     "relisted unchanged",
+			remember: true,
+			old:      before,
+			new:      before,
+		},
+		{
+			name:       "changed while disconnected",
+			remember:   true,
+			old:        before,
+			new:        changed,
+			wantNotify: notifier.EventChangedWhileDisconnected,
+		},
+		{
+			name:      "changed again after the relist",
+			remember:  true,
+			old:       configMap("11", map[string]interface{}{"mode": "a"}),
+			new:       changed,
+			wantCalls: []string{"update prod/app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resourceConfig := config.ResourceConfig{Kind: "ConfigMap", Namespace: "prod"}
+			w, recorder, _ := newTestWatcher(t, &config.Config{Resources: []config.ResourceConfig{resourceConfig}})
+			next := &handlerCalls{}
+			handler := &reconnectHandler{watcher: w, resourceConfig: resourceConfig, next: next}
+			if tt.remember {
+				handler.remember(map[string]string{"prod/app": "10"}, time.Now().Add(-time.Minute))
+			}
+
+			handler.OnUpdate(tt.old, tt.new)
+			if !reflect.DeepEqual(next.calls, tt.wantCalls) {
+				t.Errorf("passed on %v, want %v", next.calls, tt.wantCalls)
+			}
+			if tt.wantNotify == "" {
+				recorder.waitForEvents(t, 0)
+				return
+			}
+			events := recorder.waitForEvents(t, 1)
+			if events[0].EventType != tt.wantNotify || !reflect.DeepEqual(events[0].Diff, []string{`~ data.mode: "a" -> "b"`}) {
+				t.Errorf("notified %s with diff %q", events[0].EventType, events[0].Diff)
+			}
+			if handler.pending != nil {
+				t.Errorf("%d versions still pending after the relist", len(handler.pending))
+			}
+		})
+	}
+}
+
+func TestObjectDiff(t *testing.T) {
+	old := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "labels": map[string]interface{}{"tier": "web"}, "resourceVersion": "1"},
+		"spec":     map[string]interface{}{"replicas": int64(3), "paused": true, "ports": []interface{}{int64(80)}},
+	}}
+	new := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "labels": map[string]interface{}{"tier": "frontend"}, "resourceVersion": "2"},
+		"spec":     map[string]interface{}{"replicas": int64(5), "ports": []interface{}{int64(80), int64(443)}, "note": strings.Repeat("x", 100)},
+	}}
+
+	want := []string{
+		`+ spec.note: "` + strings.Repeat("x", maxDiffValueLength-4) + `...`,
+		`- spec.paused: true`,
+		`~ metadata.labels.tier: "web" -> "frontend"`,
+		`~ spec.ports: [80] -> [80,443]`,
+		`~ spec.replicas: 3 -> 5`,
+	}
+	if got := objectDiff(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("objectDiff() = %q, want %q", got, want)
+	}
+
+	many := make(map[string]interface{})
+	for i := 0; i < maxDisconnectedDiffLines+3; i++ {
+		many[strings.Repeat("k", i+1)] = int64(i)
+	}
+	got := objectDiff(&unstructured.Unstructured{Object: map[string]interface{}{"data": map[string]interface{}{}}}, &unstructured.Unstructured{Object: map[string]interface{}{"data": many}})
+	if len(got) != maxDisconnectedDiffLines+1 || got[len(got)-1] != "... and 3 more changed fields" {
+		t.Errorf("objectDiff() of many fields ends with %q after %d lines", got[len(got)-1], len(got))
+	}
+}
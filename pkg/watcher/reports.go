@@ -0,0 +1,224 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/exitcode"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// reportTracker collects, per scheduled report, what happened since the report was last sent
+type reportTracker struct {
+	mu      sync.Mutex
+	reports map[string]*reportPeriod
+}
+
+// reportPeriod is what one report covers: the notifications sent since it was last sent,
+// and the objects each resource entry matched at that time, by entry index
+type reportPeriod struct {
+	since     time.Time
+	counts    map[notifier.EventType]int
+	changes   []string
+	dropped   int
+	inventory map[int][]string
+}
+
+func newReportTracker() *reportTracker {
+	return &reportTracker{reports: make(map[string]*reportPeriod)}
+}
+
+// unreportedEventTypes are left out of reports: they are about the watcher, not the cluster
+var unreportedEventTypes = map[notifier.EventType]bool{
+	notifier.EventBurstSummary:    true,
+	notifier.EventSelfAlert:       true,
+	notifier.EventSilenceExpiring: true,
+	notifier.EventSilenceExpired:  true,
+	notifier.EventBaseline:        true,
+	notifier.EventReport:          true,
+}
+
+// startReports takes the inventory the first reports compare against and schedules each report
+func (w *InformerWatcher) startReports() {
+	inventory := w.reportInventory()
+	now := time.Now()
+	w.reports.mu.Lock()
+	for _, report := range w.config.Watcher.Reports {
+		w.reports.reports[report.Name] = &reportPeriod{since: now, counts: make(map[notifier.EventType]int), inventory: inventory}
+	}
+	w.reports.mu.Unlock()
+
+	for _, report := range w.config.Watcher.Reports {
+		go w.runReport(report)
+	}
+}
+
+// runReport sends a report at every time its schedule fires until the watcher stops
+func (w *InformerWatcher) runReport(report config.ReportConfig) {
+	defer exitcode.Recover()
+	schedule, err := cron.ParseStandard(report.Schedule)
+	if err != nil {
+		w.logger.Error("Invalid report schedule", "report", report.Name, "schedule", report.Schedule, "error", err)
+		return
+	}
+	for {
+		timer := time.NewTimer(time.Until(schedule.Next(time.Now())))
+		select {
+		case <-w.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		w.sendReport(report)
+	}
+}
+
+// recordReportChange adds a delivered notification to the period of every report
+func (w *InformerWatcher) recordReportChange(event notifier.NotificationEvent, err error) {
+	if unreportedEventTypes[event.EventType] {
+		return
+	}
+	line := fmt.Sprintf("%s %s %s %s", time.Now().UTC().Format(time.RFC3339), event.EventType, event.ResourceKind, event.Ref())
+
+	w.reports.mu.Lock()
+	defer w.reports.mu.Unlock()
+	for _, report := range w.config.Watcher.Reports {
+		period, ok := w.reports.reports[report.Name]
+		if !ok {
+			continue
+		}
+		period.counts[event.EventType]++
+		if len(period.changes) < report.GetMaxChanges() {
+			period.changes = append(period.changes, line)
+		} else {
+			period.dropped++
+		}
+	}
+}
+
+// sendReport sends a report of its period and starts the next period
+func (w *InformerWatcher) sendReport(report config.ReportConfig) {
+	inventory := w.reportInventory()
+	now := time.Now()
+
+	w.reports.mu.Lock()
+	period, ok := w.reports.reports[report.Name]
+	w.reports.reports[report.Name] = &reportPeriod{since: now, counts: make(map[notifier.EventType]int), inventory: inventory}
+	w.reports.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	trace := w.traces.Start("report", "Watcher", notifier.EventReport, "", report.Name)
+	w.deliver(trace, notifier.NotificationEvent{
+		ID:           trace.ID,
+		EventType:    notifier.EventReport,
+		Severity:     config.SeverityInfo,
+		ResourceKind: "Watcher",
+		ResourceName: report.Name,
+		Recipients:   report.ToEmails,
+		Summary:      w.reportSummary(report, period, inventory, now),
+	})
+}
+
+// reportSummary renders a report: each entry's matched objects with those that appeared or
+// disappeared during the period, then the notifications sent during it
+func (w *InformerWatcher) reportSummary(report config.ReportConfig, period *reportPeriod, inventory map[int][]string, now time.Time) []string {
+	maxChanges := report.GetMaxChanges()
+	summary := []string{fmt.Sprintf("Report %s from %s to %s", report.Name, period.since.UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339)), "Watched objects:"}
+	for i, resourceConfig := range w.config.Resources {
+		heading := fmt.Sprintf("resources[%d] (%s)", i, resourceConfig.Describe())
+		objects, ok := inventory[i]
+		if !ok {
+			summary = append(summary, heading+": not inventoried")
+			continue
+		}
+
+		added, removed := diffSets(period.inventory[i], objects)
+		line := fmt.Sprintf("%s: %d matched", heading, len(objects))
+		if len(added) > 0 || len(removed) > 0 {
+			line += fmt.Sprintf(" (%d new, %d gone)", len(added), len(removed))
+		}
+		summary = append(summary, line)
+		summary = append(summary, limitLines(append(prefixLines("  + ", added), prefixLines("  - ", removed)...), maxChanges)...)
+	}
+
+	total := 0
+	types := make([]string, 0, len(period.counts))
+	for eventType, count := range period.counts {
+		total += count
+		types = append(types, fmt.Sprintf("%s %d", eventType, count))
+	}
+	if total == 0 {
+		return append(summary, "No notifications were sent during the period")
+	}
+	sort.Strings(types)
+	summary = append(summary, fmt.Sprintf("Notifications: %d (%s)", total, strings.Join(types, ", ")))
+	summary = append(summary, prefixLines("  ", period.changes)...)
+	if period.dropped > 0 {
+		summary = append(summary, fmt.Sprintf("  ... and %d more", period.dropped))
+	}
+	return summary
+}
+
+// reportInventory returns the objects each resource entry matches in the caches, by entry
+// index; entries for Events and for kinds without a cache are left out
+func (w *InformerWatcher) reportInventory() map[int][]string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	inventory := make(map[int][]string, len(w.config.Resources))
+	for i, resourceConfig := range w.config.Resources {
+		if resourceConfig.Kind == "Event" {
+			continue
+		}
+		if objects, ok := w.matchedObjects(resourceConfig); ok {
+			inventory[i] = objects
+		}
+	}
+	return inventory
+}
+
+// diffSets returns the sorted values of after missing from before, and those of before
+// missing from after
+func diffSets(before, after []string) (added, removed []string) {
+	previous := make(map[string]bool, len(before))
+	for _, value := range before {
+		previous[value] = true
+	}
+	current := make(map[string]bool, len(after))
+	for _, value := range after {
+		current[value] = true
+		if !previous[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range before {
+		if !current[value] {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
+
+func prefixLines(prefix string, values []string) []string {
+	lines := make([]string, len(values))
+	for i, value := range values {
+		lines[i] = prefix + value
+	}
+	return lines
+}
+
+// limitLines keeps the first max lines and counts the rest
+func limitLines(lines []string, max int) []string {
+	if len(lines) <= max {
+		return lines
+	}
+	return append(lines[:max:max], fmt.Sprintf("  ... and %d more", len(lines)-max))
+}
@@ -0,0 +1,175 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// How a workload consumes a ConfigMap or Secret, which decides whether its running pods see a change
+const (
+	consumedByEnv        = "env"               // Read once at container start
+	consumedBySubPath    = "subPath mount"     // Never refreshed by the kubelet
+	consumedByVolume     = "volume mount"      // Refreshed by the kubelet in running pods
+	consumedByPullSecret = "image pull secret" // Only used when pulling images for new pods
+)
+
+// consumer is a workload referencing the changed ConfigMap or Secret
+type consumer struct {
+	Kind  string
+	Name  string
+	Modes []string
+}
+
+func (c consumer) String() string {
+	return c.Kind + "/" + c.Name
+}
+
+// needsRestart reports whether running pods keep the old values until restarted
+func (c consumer) needsRestart() bool {
+	for _, mode := range c.Modes {
+		if mode == consumedByEnv || mode == consumedBySubPath {
+			return true
+		}
+	}
+	return false
+}
+
+// consumptionModes returns how a pod spec consumes the ConfigMap or Secret kind/name
+func consumptionModes(spec *corev1.PodSpec, kind, name string) []string {
+	modes := make(map[string]bool)
+
+	volumes := make(map[string]bool)
+	for _, volume := range spec.Volumes {
+		if volumeReferences(volume, kind, name) {
+			volumes[volume.Name] = true
+		}
+	}
+
+	for _, container := range append(append([]corev1.Container(nil), spec.InitContainers...), spec.Containers...) {
+		for _, mount := range container.VolumeMounts {
+			if !volumes[mount.Name] {
+				continue
+			}
+			if mount.SubPath != "" || mount.SubPathExpr != "" {
+				modes[consumedBySubPath] = true
+			} else {
+				modes[consumedByVolume] = true
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if (kind == "ConfigMap" && envFrom.ConfigMapRef != nil && envFrom.ConfigMapRef.Name == name) ||
+				(kind == "Secret" && envFrom.SecretRef != nil && envFrom.SecretRef.Name == name) {
+				modes[consumedByEnv] = true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if (kind == "ConfigMap" && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name == name) ||
+				(kind == "Secret" && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == name) {
+				modes[consumedByEnv] = true
+			}
+		}
+	}
+
+	if kind == "Secret" {
+		for _, pullSecret := range spec.ImagePullSecrets {
+			if pullSecret.Name == name {
+				modes[consumedByPullSecret] = true
+			}
+		}
+	}
+
+	var ordered []string
+	for _, mode := range []string{consumedByEnv, consumedBySubPath, consumedByVolume, consumedByPullSecret} {
+		if modes[mode] {
+			ordered = append(ordered, mode)
+		}
+	}
+	return ordered
+}
+
+// volumeReferences reports whether a volume, directly or through a projection, holds the ConfigMap or Secret
+func volumeReferences(volume corev1.Volume, kind, name string) bool {
+	switch {
+	case kind == "ConfigMap" && volume.ConfigMap != nil:
+		return volume.ConfigMap.Name == name
+	case kind == "Secret" && volume.Secret != nil:
+		return volume.Secret.SecretName == name
+	case volume.Projected != nil:
+		for _, source := range volume.Projected.Sources {
+			if (kind == "ConfigMap" && source.ConfigMap != nil && source.ConfigMap.Name == name) ||
+				(kind == "Secret" && source.Secret != nil && source.Secret.Name == name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// restartGuidance tells recipients which consumers pick the change up on their
+// own and which need a rollout, with the command to run
+func restartGuidance(namespace string, eventType notifier.EventType, consumers []consumer) []string {
+	if len(consumers) == 0 {
+		return nil
+	}
+
+	if eventType == notifier.EventDeleted {
+		return []string{fmt.Sprintf("New pods of %s will fail to start until it is recreated; running pods are not affected",
+			describeConsumers(consumers, false))}
+	}
+
+	var restart, automatic, nextRun, pullOnly []consumer
+	for _, c := range consumers {
+		switch {
+		case c.Kind == "CronJob":
+			nextRun = append(nextRun, c)
+		case c.needsRestart():
+			restart = append(restart, c)
+		case len(c.Modes) == 1 && c.Modes[0] == consumedByPullSecret:
+			pullOnly = append(pullOnly, c)
+		case len(c.Modes) > 0:
+			automatic = append(automatic, c)
+		}
+	}
+
+	var lines []string
+	if len(restart) > 0 {
+		example := restart[0]
+		lines = append(lines, fmt.Sprintf("Rollout required for %s: running pods keep the old values until restarted, e.g. kubectl -n %s rollout restart %s/%s",
+			describeConsumers(restart, true), namespace, strings.ToLower(example.Kind), example.Name))
+	}
+	if len(automatic) > 0 {
+		lines = append(lines, fmt.Sprintf("Picked up without a restart by %s: the kubelet refreshes mounted files within about a minute, but the application must reload them",
+			describeConsumers(automatic, true)))
+	}
+	if len(nextRun) > 0 {
+		lines = append(lines, fmt.Sprintf("Used from the next scheduled run by %s", describeConsumers(nextRun, false)))
+	}
+	if len(pullOnly) > 0 {
+		lines = append(lines, fmt.Sprintf("Used for image pulls of new pods by %s", describeConsumers(pullOnly, false)))
+	}
+	return lines
+}
+
+// describeConsumers lists consumers, capped at maxConsumersListed, optionally with how each consumes the object
+func describeConsumers(consumers []consumer, withModes bool) string {
+	parts := make([]string, 0, len(consumers))
+	for i, c := range consumers {
+		if i == maxConsumersListed {
+			parts = append(parts, fmt.Sprintf("and %d more", len(consumers)-i))
+			break
+		}
+		part := c.String()
+		if withModes && len(c.Modes) > 0 {
+			part += " (" + strings.Join(c.Modes, ", ") + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// resyncHandler receives the periodic replays of an informer's cache for the entries with
+// one resync period. Replays carry no change, so they are only counted: they are not run
+// through the entries' change checks, and notifications that failed are not sent again
+// (the event history replay does that). The shared informer's own handler, which feeds the
+// entries, is registered without resync.
+type resyncHandler struct {
+	watcher *InformerWatcher
+	period  time.Duration
+
+	mu         sync.Mutex
+	lastResync time.Time
+}
+
+// scopeResyncPeriod returns the shortest resync period of the entries served by the
+// informer of scope, which the informer checks for due resyncs at; 0 when none resyncs
+func (w *InformerWatcher) scopeResyncPeriod(scope informerScope) time.Duration {
+	var shortest time.Duration
+	for _, resourceConfig := range w.config.Resources {
+		if _, ok := supportedKinds[resourceConfig.Kind]; !ok || w.entryScope(resourceConfig) != scope {
+			continue
+		}
+		if period := w.config.Watcher.GetResyncPeriod(resourceConfig); period > 0 && (shortest == 0 || period < shortest) {
+			shortest = period
+		}
+	}
+	return shortest
+}
+
+// resyncEntry registers the resync period of a resource entry on its shared informer;
+// entries with the same period share a resync handler. Callers hold w.mu.
+func (w *InformerWatcher) resyncEntry(shared *sharedInformer, resourceConfig config.ResourceConfig) {
+	period := w.config.Watcher.GetResyncPeriod(resourceConfig)
+	if period <= 0 {
+		return
+	}
+	if _, ok := shared.resyncs[period]; ok {
+		return
+	}
+	handler := &resyncHandler{watcher: w, period: period}
+	if _, err := shared.informer.AddEventHandlerWithResyncPeriod(handler, period); err != nil {
+		w.logger.Warn("Failed to register resync", "watcher", shared.key, "resyncPeriod", period, "error", err)
+		return
+	}
+	shared.resyncs[period] = handler
+}
+
+// entryLastResync returns when the informer of a resource entry last replayed its cache
+// for the entry; callers hold w.mu
+func (w *InformerWatcher) entryLastResync(resourceConfig config.ResourceConfig) *time.Time {
+	shared, ok := w.informers[w.entryScope(resourceConfig)]
+	if !ok {
+		return nil
+	}
+	handler, ok := shared.resyncs[w.config.Watcher.GetResyncPeriod(resourceConfig)]
+	if !ok {
+		return nil
+	}
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if handler.lastResync.IsZero() {
+		return nil
+	}
+	lastResync := handler.lastResync
+	return &lastResync
+}
+
+func (h *resyncHandler) OnAdd(obj interface{}, isInInitialList bool) {}
+
+// OnUpdate counts replays: updates whose object version did not change. A re-list after
+// a watch error replays unchanged objects the same way.
+func (h *resyncHandler) OnUpdate(oldObj, newObj interface{}) {
+	oldAccessor, okOld := oldObj.(metav1.Object)
+	newAccessor, okNew := newObj.(metav1.Object)
+	if !okOld || !okNew || oldAccessor.GetResourceVersion() != newAccessor.GetResourceVersion() {
+		return
+	}
+	h.mu.Lock()
+	h.lastResync = time.Now()
+	h.mu.Unlock()
+	h.watcher.metrics.RecordResyncReplay()
+}
+
+func (h *resyncHandler) OnDelete(obj interface{}) {}
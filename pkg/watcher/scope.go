@@ -0,0 +1,133 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// informerKey identifies an informer: the kind for a cluster-wide informer,
+// kind/namespace for one scoped to a namespace
+func informerKey(kind, namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return kind
+	}
+	return kind + "/" + namespace
+}
+
+// informerScope is what a shared informer lists and watches: an API resource,
+// in one namespace or in all of them ("")
+type informerScope struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// sharedInformer is the informer of one scope and the handlers of the resource entries it
+// serves. The informer has a single handler of its own, which passes every event to the
+// entry handlers in config order; each entry handler applies that entry's filters.
+type sharedInformer struct {
+	key        string // informerKey, as shown in traces, metrics and status
+	kind       string
+	namespace  string
+	informer   cache.SharedIndexInformer
+	stop       context.CancelFunc           // Set once the informer runs
+	handlers   []cache.ResourceEventHandler // One per resource entry, and the archiver; only added before the informer runs
+	archiver   *archiveHandler              // Archives the versions of the objects of its entries; nil unless archived
+	reconnects []*reconnectHandler          // Entry handlers reporting changes missed while disconnected
+	resyncs    map[time.Duration]*resyncHandler
+}
+
+func (s *sharedInformer) OnAdd(obj interface{}, isInInitialList bool) {
+	for _, handler := range s.handlers {
+		handler.OnAdd(obj, isInInitialList)
+	}
+}
+
+func (s *sharedInformer) OnUpdate(oldObj, newObj interface{}) {
+	for _, handler := range s.handlers {
+		handler.OnUpdate(oldObj, newObj)
+	}
+}
+
+func (s *sharedInformer) OnDelete(obj interface{}) {
+	for _, handler := range s.handlers {
+		handler.OnDelete(obj)
+	}
+}
+
+// scopedNamespaces returns the namespaces the informers of kind are scoped to.
+// A kind is only scoped when every entry for it names a single namespace, so
+// the watcher can run with namespaced Roles; otherwise it returns nil and the
+// kind has one cluster-wide informer.
+func (w *InformerWatcher) scopedNamespaces(kind string) []string {
+	if config.ClusterScopedKinds[kind] {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, resourceConfig := range w.config.Resources {
+		if resourceConfig.Kind != kind {
+			continue
+		}
+		if resourceConfig.Namespace == "" {
+			return nil
+		}
+		if !seen[resourceConfig.Namespace] {
+			seen[resourceConfig.Namespace] = true
+			namespaces = append(namespaces, resourceConfig.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// entryScope returns the scope of the informer serving a resource entry
+func (w *InformerWatcher) entryScope(resourceConfig config.ResourceConfig) informerScope {
+	scope := informerScope{gvr: supportedKinds[resourceConfig.Kind].gvr, namespace: metav1.NamespaceAll}
+	if len(w.scopedNamespaces(resourceConfig.Kind)) > 0 {
+		scope.namespace = resourceConfig.Namespace
+	}
+	return scope
+}
+
+// entryInformerKey returns the key of the informer serving a resource entry
+func (w *InformerWatcher) entryInformerKey(resourceConfig config.ResourceConfig) string {
+	return informerKey(resourceConfig.Kind, w.entryScope(resourceConfig).namespace)
+}
+
+// kindSynced reports whether kind has informers and all of them are synced; callers hold w.mu
+func (w *InformerWatcher) kindSynced(kind string) (bool, bool) {
+	found, synced := false, true
+	for _, shared := range w.kindInformers(kind) {
+		found = true
+		synced = synced && shared.informer.HasSynced()
+	}
+	return found, found && synced
+}
+
+// kindInformers returns the informers of kind, sorted by key; callers hold w.mu
+func (w *InformerWatcher) kindInformers(kind string) []*sharedInformer {
+	var informers []*sharedInformer
+	for _, shared := range w.informers {
+		if shared.kind == kind {
+			informers = append(informers, shared)
+		}
+	}
+	sort.Slice(informers, func(i, j int) bool { return informers[i].key < informers[j].key })
+	return informers
+}
+
+// informerStore returns the cache of the informer serving a resource entry, if it still runs; callers hold w.mu
+func (w *InformerWatcher) informerStore(resourceConfig config.ResourceConfig) (cache.Store, bool) {
+	shared, ok := w.informers[w.entryScope(resourceConfig)]
+	if !ok {
+		return nil, false
+	}
+	return shared.informer.GetStore(), true
+}
@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// secretHashPrefix is how many hex digits of a data hash notifications show
+const secretHashPrefix = 12
+
+// secretData returns a Secret's base64-encoded .data values; they are never decoded
+func secretData(obj *unstructured.Unstructured) map[string]string {
+	data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+	return data
+}
+
+// secretDataHash returns the SHA-256 of a Secret's .data over the keys in order and their encoded values
+func secretDataHash(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write([]byte(data[key]))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// secretChanges compares the data of two Secret versions by hash. It returns false when the
+// data is unchanged, e.g. for label updates by controllers; otherwise the changed data keys
+// as fields and "+"/"-"/"~" lines naming them, never their values.
+func secretChanges(oldObj, newObj *unstructured.Unstructured) (changedFields, diff []string, changed bool) {
+	before, after := secretData(oldObj), secretData(newObj)
+	if secretDataHash(before) == secretDataHash(after) {
+		return nil, nil, false
+	}
+
+	for key, value := range after {
+		previous, existed := before[key]
+		switch {
+		case !existed:
+			diff = append(diff, "+ data."+key)
+		case previous != value:
+			diff = append(diff, "~ data."+key+" (value changed)")
+		default:
+			continue
+		}
+		changedFields = append(changedFields, "data."+key)
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			diff = append(diff, "- data."+key)
+			changedFields = append(changedFields, "data."+key)
+		}
+	}
+	sort.Strings(changedFields)
+	sort.Strings(diff)
+	return changedFields, diff, true
+}
+
+// secretHashSummary describes a data change by the old and new hash prefixes
+func secretHashSummary(oldObj, newObj *unstructured.Unstructured) string {
+	return "Data hash sha256:" + secretDataHash(secretData(oldObj))[:secretHashPrefix] +
+		" -> sha256:" + secretDataHash(secretData(newObj))[:secretHashPrefix]
+}
+
+// handleSecretUpdated notifies a MODIFIED Secret only when its data hash changed, or for a
+// ServiceAccount token Secret, when it was moved to another ServiceAccount
+func (w *InformerWatcher) handleSecretUpdated(trace *EventTrace, oldSecret, newSecret *unstructured.Unstructured) {
+	if isServiceAccountToken(newSecret) {
+		w.handleTokenSecretUpdated(trace, oldSecret, newSecret)
+		return
+	}
+
+	changedFields, diff, changed := secretChanges(oldSecret, newSecret)
+	if !changed {
+		trace.Logger().Debug("Data unchanged, only metadata was updated (skipping notification)")
+		trace.Step(StageDiffed, "data hash unchanged")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED")
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	w.sendNotificationWithSummary(trace, "Secret", notifier.EventModified, newSecret, changedFields, diff,
+		[]string{secretHashSummary(oldSecret, newSecret)})
+}
+
+// GetSecretData returns the data of a Secret, for redacting its values from notifications
+func (w *InformerWatcher) GetSecretData(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	secret, err := w.k8sClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// notificationAnnotations returns the object's annotations for notifications. A Secret's
+// last-applied-configuration annotation holds its data, so it is left out.
+func notificationAnnotations(kind string, obj metav1.Object) map[string]string {
+	annotations := obj.GetAnnotations()
+	if kind != "Secret" {
+		return annotations
+	}
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; !ok {
+		return annotations
+	}
+	filtered := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if key != corev1.LastAppliedConfigAnnotation {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
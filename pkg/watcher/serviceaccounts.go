@@ -0,0 +1,181 @@
+package watcher
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// identityAnnotations bind a ServiceAccount to a cloud identity, so changing them changes
+// what its pods can access
+var identityAnnotations = []string{
+	"eks.amazonaws.com/role-arn",
+	"iam.gke.io/gcp-service-account",
+	"azure.workload.identity/client-id",
+}
+
+// handleServiceAccountUpdated notifies a MODIFIED ServiceAccount only when its credentials
+// changed: token and image pull Secrets, token automounting and cloud identity annotations
+func (w *InformerWatcher) handleServiceAccountUpdated(trace *EventTrace, oldObj, newObj *unstructured.Unstructured) {
+	var oldAccount, newAccount corev1.ServiceAccount
+	if !convertObject(oldObj, &oldAccount) || !convertObject(newObj, &newAccount) {
+		trace.Logger().Warn("Failed to convert to a typed ServiceAccount")
+		w.traces.Finish(trace, "failed")
+		return
+	}
+
+	changedFields, diff := serviceAccountChanges(&oldAccount, &newAccount)
+	if len(changedFields) == 0 {
+		trace.Step(StageDiffed, "no credential changes")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("ServiceAccount credentials changed", "diff", diff)
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	w.sendNotification(trace, "ServiceAccount", notifier.EventModified, newObj, changedFields, diff)
+}
+
+// serviceAccountChanges returns the changed credential fields of a ServiceAccount and a
+// line per change, e.g. "+ imagePullSecret: registry-creds"
+func serviceAccountChanges(oldAccount, newAccount *corev1.ServiceAccount) ([]string, []string) {
+	var changedFields, diff []string
+
+	if lines := diffLines("secret", secretNames(oldAccount.Secrets), secretNames(newAccount.Secrets)); len(lines) > 0 {
+		changedFields = append(changedFields, "secrets")
+		diff = append(diff, lines...)
+	}
+
+	if lines := diffLines("imagePullSecret", localNames(oldAccount.ImagePullSecrets), localNames(newAccount.ImagePullSecrets)); len(lines) > 0 {
+		changedFields = append(changedFields, "imagePullSecrets")
+		diff = append(diff, lines...)
+	}
+
+	if lines := diffFields(automountField(oldAccount), automountField(newAccount)); len(lines) > 0 {
+		changedFields = append(changedFields, "automountServiceAccountToken")
+		diff = append(diff, lines...)
+	}
+
+	if lines := diffFields(identityFields(oldAccount.Annotations), identityFields(newAccount.Annotations)); len(lines) > 0 {
+		changedFields = append(changedFields, "metadata.annotations")
+		diff = append(diff, lines...)
+	}
+
+	return changedFields, diff
+}
+
+// serviceAccountDiff renders a ServiceAccount's credentials for ADDED and DELETED events;
+// either object may be nil
+func serviceAccountDiff(oldObj, newObj *unstructured.Unstructured) []string {
+	var oldAccount, newAccount corev1.ServiceAccount
+	if !convertObject(oldObj, &oldAccount) || !convertObject(newObj, &newAccount) {
+		return nil
+	}
+	_, diff := serviceAccountChanges(&oldAccount, &newAccount)
+	return diff
+}
+
+func secretNames(refs []corev1.ObjectReference) []string {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref.Name
+	}
+	return names
+}
+
+func localNames(refs []corev1.LocalObjectReference) []string {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref.Name
+	}
+	return names
+}
+
+func automountField(account *corev1.ServiceAccount) map[string]string {
+	if account.AutomountServiceAccountToken == nil {
+		return nil
+	}
+	value := "false"
+	if *account.AutomountServiceAccountToken {
+		value = "true"
+	}
+	return map[string]string{"automountServiceAccountToken": value}
+}
+
+func identityFields(annotations map[string]string) map[string]string {
+	fields := make(map[string]string)
+	for _, key := range identityAnnotations {
+		if value, ok := annotations[key]; ok {
+			fields["annotations."+key] = value
+		}
+	}
+	return fields
+}
+
+// isServiceAccountToken reports whether the Secret holds a legacy ServiceAccount token
+func isServiceAccountToken(obj *unstructured.Unstructured) bool {
+	secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+	return secretType == string(corev1.SecretTypeServiceAccountToken)
+}
+
+// tokenOwner returns the ServiceAccount a token Secret authenticates as, by name and UID
+func tokenOwner(obj *unstructured.Unstructured) map[string]string {
+	if obj == nil || !isServiceAccountToken(obj) {
+		return nil
+	}
+	annotations := obj.GetAnnotations()
+	return map[string]string{
+		"serviceAccount":     annotations[corev1.ServiceAccountNameKey],
+		"serviceAccount.uid": annotations[corev1.ServiceAccountUIDKey],
+	}
+}
+
+// tokenSecretChanges compares two versions of a token Secret: the ServiceAccount it
+// belongs to, and its data by hash with the token reported as issued or regenerated.
+// It returns false when neither changed.
+func tokenSecretChanges(oldObj, newObj *unstructured.Unstructured) (changedFields, diff []string, changed bool) {
+	if ownerDiff := diffFields(tokenOwner(oldObj), tokenOwner(newObj)); len(ownerDiff) > 0 {
+		changedFields = append(changedFields, "metadata.annotations")
+		diff = append(diff, ownerDiff...)
+	}
+
+	dataFields, dataDiff, dataChanged := secretChanges(oldObj, newObj)
+	if dataChanged {
+		changedFields = append(changedFields, dataFields...)
+		for _, line := range dataDiff {
+			switch line {
+			case "+ data.token":
+				line += " (issued)"
+			case "~ data.token (value changed)":
+				line = "~ data.token (regenerated)"
+			}
+			diff = append(diff, line)
+		}
+	}
+	return changedFields, diff, len(changedFields) > 0
+}
+
+// tokenSummary names the ServiceAccount a token Secret belongs to
+func tokenSummary(obj *unstructured.Unstructured) string {
+	return "Token of ServiceAccount " + obj.GetNamespace() + "/" + tokenOwner(obj)["serviceAccount"]
+}
+
+// handleTokenSecretUpdated notifies a MODIFIED token Secret with its token and ServiceAccount changes
+func (w *InformerWatcher) handleTokenSecretUpdated(trace *EventTrace, oldSecret, newSecret *unstructured.Unstructured) {
+	changedFields, diff, changed := tokenSecretChanges(oldSecret, newSecret)
+	if !changed {
+		trace.Logger().Debug("Token and ServiceAccount unchanged, only metadata was updated (skipping notification)")
+		trace.Step(StageDiffed, "token unchanged")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED")
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	summary := []string{tokenSummary(newSecret)}
+	if _, _, dataChanged := secretChanges(oldSecret, newSecret); dataChanged {
+		summary = append(summary, secretHashSummary(oldSecret, newSecret))
+	}
+	w.sendNotificationWithSummary(trace, "Secret", notifier.EventModified, newSecret, changedFields, diff, summary)
+}
@@ -0,0 +1,120 @@
+package watcher
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/fieldpath"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// compileFieldPaths parses field paths that were already validated with the config
+func compileFieldPaths(texts []string) []fieldpath.Path {
+	var paths []fieldpath.Path
+	for _, text := range texts {
+		if path, err := fieldpath.Parse(text); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// changedSignificantFields returns the significant paths whose values differ
+// between two versions of an object; an empty result means the change is not
+// worth a notification
+func changedSignificantFields(paths []fieldpath.Path, oldObj, newObj map[string]interface{}) []string {
+	var changed []string
+	for _, path := range paths {
+		if !reflect.DeepEqual(path.Values(oldObj), path.Values(newObj)) {
+			changed = append(changed, path.String())
+		}
+	}
+	return changed
+}
+
+// handleWatchExpressionsUpdated notifies a MODIFIED object of an entry with watchExpressions
+// only when one of their values changed, with a line per changed expression
+func (w *InformerWatcher) handleWatchExpressionsUpdated(trace *EventTrace, resourceConfig config.ResourceConfig, resourceKind string, oldObj, newObj *unstructured.Unstructured) {
+	paths := compileFieldPaths(resourceConfig.WatchExpressions)
+	changedFields := changedSignificantFields(paths, oldObj.Object, newObj.Object)
+	if len(changedFields) == 0 {
+		trace.Logger().Debug("No watch expression changed (skipping notification)")
+		trace.Step(StageDiffed, "no watch expression changed")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("Resource was MODIFIED", "changedFields", changedFields)
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	// Secret values are never shown; ConfigMaps follow watcher.configMapDiff
+	hideValues := resourceKind == "Secret" || (resourceKind == "ConfigMap" && w.config.Watcher.ConfigMapDiff.HideValues)
+	diff := watchExpressionDiff(paths, oldObj.Object, newObj.Object, hideValues)
+	if resourceKind == "ConfigMap" {
+		diff = truncateLines(diff, w.config.Watcher.ConfigMapDiff.GetMaxSize())
+	}
+	w.sendEvent(trace, newObj, notifier.NotificationEvent{
+		EventType:     notifier.EventModified,
+		ResourceKind:  resourceKind,
+		ChangedFields: changedFields,
+		Diff:          diff,
+		ImageChanges:  workloadImageChanges(resourceKind, oldObj, newObj),
+	})
+}
+
+// watchExpressionDiff renders each changed path as "+"/"-"/"~" lines, e.g.
+// "~ spec.replicas: 3 -> 5"; changed multi-line strings are followed by their removed and
+// added lines, and hidden values are only named
+func watchExpressionDiff(paths []fieldpath.Path, oldObj, newObj map[string]interface{}, hideValues bool) []string {
+	var lines []string
+	for _, path := range paths {
+		before, after := path.Values(oldObj), path.Values(newObj)
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		field := path.String()
+		switch {
+		case hideValues && len(before) == 0:
+			lines = append(lines, "+ "+field)
+		case hideValues && len(after) == 0:
+			lines = append(lines, "- "+field)
+		case hideValues:
+			lines = append(lines, "~ "+field+" (value changed)")
+		case len(before) == 0:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", field, renderMatches(path, after)))
+		case len(after) == 0:
+			lines = append(lines, fmt.Sprintf("- %s: %s", field, renderMatches(path, before)))
+		default:
+			oldText, oldIsText := singleString(before)
+			newText, newIsText := singleString(after)
+			if oldIsText && newIsText && (strings.Contains(oldText, "\n") || strings.Contains(newText, "\n")) {
+				lines = append(lines, "~ "+field+":")
+				lines = append(lines, valueLineDiff(oldText, newText)...)
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", field, renderMatches(path, before), renderMatches(path, after)))
+		}
+	}
+	return lines
+}
+
+// renderMatches renders the value a path matched, or the list of them for wildcard paths
+func renderMatches(path fieldpath.Path, values []interface{}) string {
+	for _, segment := range path {
+		if segment.Wildcard {
+			return renderDiffValue(values)
+		}
+	}
+	return renderDiffValue(values[0])
+}
+
+func singleString(values []interface{}) (string, bool) {
+	if len(values) != 1 {
+		return "", false
+	}
+	text, ok := values[0].(string)
+	return text, ok
+}
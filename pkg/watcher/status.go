@@ -0,0 +1,137 @@
+package watcher
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// readinessFailureThreshold is the number of consecutive watch errors after which a kind makes the watcher unready
+const readinessFailureThreshold = 5
+
+// WatcherState describes one configured resource entry and the health of the watch serving it
+type WatcherState struct {
+	Cluster             string     `json:"cluster"`
+	Resource            string     `json:"resource"` // Human-readable description of the entry
+	Kind                string     `json:"kind"`
+	Namespace           string     `json:"namespace,omitempty"`
+	Namespaces          []string   `json:"namespaces,omitempty"`
+	Engine              string     `json:"engine"`
+	CacheSynced         bool       `json:"cacheSynced"`
+	LastEventTime       *time.Time `json:"lastEventTime,omitempty"`
+	Reconnects          int64      `json:"reconnects"` // List/watch errors, each followed by a re-list or re-watch
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	ResyncPeriod        string     `json:"resyncPeriod,omitempty"`
+	LastResyncTime      *time.Time `json:"lastResyncTime,omitempty"` // When the cache was last replayed for the entry
+}
+
+// observedHandler records when a kind last delivered an event before passing it on
+type observedHandler struct {
+	watcher *InformerWatcher
+	kind    string
+	next    cache.ResourceEventHandler
+}
+
+// observeEvents wraps a kind's handler to record when the kind last delivered an event
+func (w *InformerWatcher) observeEvents(kind string, handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	return observedHandler{watcher: w, kind: kind, next: handler}
+}
+
+func (h observedHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	h.watcher.recordEventReceived(h.kind)
+	h.next.OnAdd(obj, isInInitialList)
+}
+
+func (h observedHandler) OnUpdate(oldObj, newObj interface{}) {
+	h.watcher.recordEventReceived(h.kind)
+	h.next.OnUpdate(oldObj, newObj)
+}
+
+func (h observedHandler) OnDelete(obj interface{}) {
+	h.watcher.recordEventReceived(h.kind)
+	h.next.OnDelete(obj)
+}
+
+// recordEventReceived notes a delivered event, which proves the kind's watch works again
+func (w *InformerWatcher) recordEventReceived(kind string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if status, ok := w.engines[kind]; ok {
+		status.LastEventTime = time.Now()
+		status.ConsecutiveFailures = 0
+		status.failingSince = time.Time{}
+	}
+}
+
+// GetWatcherState reports the state of each configured resource entry, in config order
+func (w *InformerWatcher) GetWatcherState() []WatcherState {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	states := make([]WatcherState, 0, len(w.config.Resources))
+	for _, resourceConfig := range w.config.Resources {
+		state := WatcherState{
+			Cluster:    w.config.ClusterName,
+			Resource:   resourceConfig.Describe(),
+			Kind:       resourceConfig.Kind,
+			Namespace:  resourceConfig.Namespace,
+			Namespaces: resourceConfig.Namespaces,
+		}
+		if period := w.config.Watcher.GetResyncPeriod(resourceConfig); period > 0 {
+			state.ResyncPeriod = period.String()
+		}
+		if status, ok := w.engines[resourceConfig.Kind]; ok {
+			state.Engine = status.Engine
+			state.Reconnects = status.WatchErrors
+			state.ConsecutiveFailures = status.ConsecutiveFailures
+			state.LastError = status.LastError
+			if !status.LastEventTime.IsZero() {
+				lastEvent := status.LastEventTime
+				state.LastEventTime = &lastEvent
+			}
+			if shared, ok := w.informers[w.entryScope(resourceConfig)]; ok && status.Engine == EngineInformer {
+				state.CacheSynced = shared.informer.HasSynced()
+				state.LastResyncTime = w.entryLastResync(resourceConfig)
+			}
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// Ready reports whether the watcher is started, every informer cache is synced, no
+// kind keeps failing to watch and the watches have not all been failing for longer
+// than the disconnect timeout; the reason explains an unready result
+func (w *InformerWatcher) Ready() (bool, string) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if !w.isStarted {
+		return false, "watcher is not started"
+	}
+	for kind, status := range w.engines {
+		if found, synced := w.kindSynced(kind); found && status.Engine == EngineInformer && !synced {
+			return false, fmt.Sprintf("%s cache is not synced", kind)
+		}
+		if status.ConsecutiveFailures >= readinessFailureThreshold {
+			return false, fmt.Sprintf("%s watch failed %d times in a row: %s", kind, status.ConsecutiveFailures, status.LastError)
+		}
+	}
+
+	// Every watch failing means the API server is unreachable, not that one kind is broken
+	var allFailingSince time.Time
+	for _, status := range w.engines {
+		if status.ConsecutiveFailures == 0 {
+			return true, ""
+		}
+		if status.failingSince.After(allFailingSince) {
+			allFailingSince = status.failingSince
+		}
+	}
+	if timeout := w.config.Watcher.Readiness.GetDisconnectTimeout(); !allFailingSince.IsZero() && time.Since(allFailingSince) >= timeout {
+		return false, fmt.Sprintf("all watches have been failing for %s", time.Since(allFailingSince).Round(time.Second))
+	}
+	return true, ""
+}
@@ -0,0 +1,202 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+var nodesResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}
+
+// topologyLookupTimeout bounds the pod lookup for a workload's placement
+const topologyLookupTimeout = 5 * time.Second
+
+// maxNodesListed caps the node names listed for a workload in a notification
+const maxNodesListed = 5
+
+// placementKinds are the workload kinds whose running pods are looked up for placement
+var placementKinds = map[string]bool{"Deployment": true, "StatefulSet": true, "DaemonSet": true}
+
+// newNodeMetadataInformer caches node metadata only; the topology labels are all enrichment needs
+func newNodeMetadataInformer(client metadata.Interface) cache.SharedIndexInformer {
+	return metadatainformer.NewFilteredMetadataInformer(client, nodesResource, metav1.NamespaceAll, 0, cache.Indexers{}, nil).Informer()
+}
+
+// topology describes where the pods affected by a Pod or workload event run,
+// e.g. "Node: ip-10-0-1-7 (zone=eu-west-1a, region=eu-west-1)"
+func (w *InformerWatcher) topology(kind string, obj metav1.Object, eventType notifier.EventType) []string {
+	if w.nodeInformer == nil || !w.nodeInformer.HasSynced() {
+		return nil
+	}
+
+	switch {
+	case kind == "Pod":
+		if nodeName := podNodeName(obj); nodeName != "" {
+			return []string{"Node: " + w.describeNode(nodeName)}
+		}
+	case placementKinds[kind] && eventType != notifier.EventDeleted:
+		return w.workloadPlacement(kind, obj)
+	}
+	return nil
+}
+
+// describeNode renders a node name with its configured topology label values
+func (w *InformerWatcher) describeNode(name string) string {
+	nodeLabels := w.nodeLabels(name)
+	var parts []string
+	for _, key := range w.config.Watcher.Topology.GetLabels() {
+		if value, ok := nodeLabels[key]; ok {
+			parts = append(parts, shortLabel(key)+"="+value)
+		}
+	}
+	if len(parts) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(parts, ", "))
+}
+
+// nodeLabels returns the cached labels of a node, or nil when it is unknown
+func (w *InformerWatcher) nodeLabels(name string) map[string]string {
+	item, exists, err := w.nodeInformer.GetIndexer().GetByKey(name)
+	if err != nil || !exists {
+		return nil
+	}
+	node, ok := item.(metav1.Object)
+	if !ok {
+		return nil
+	}
+	return node.GetLabels()
+}
+
+// workloadPlacement summarizes the nodes running a workload's pods, grouped by the first topology label
+func (w *InformerWatcher) workloadPlacement(kind string, obj metav1.Object) []string {
+	selector := workloadSelector(obj)
+	if selector == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, topologyLookupTimeout)
+	defer cancel()
+	// ResourceVersion 0 is served from the API server's cache
+	pods, err := w.k8sClient.CoreV1().Pods(obj.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector:   selector.String(),
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		w.logger.Warn("Failed to look up pods for topology", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName(), "error", err)
+		return nil
+	}
+
+	podsPerNode := make(map[string]int)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			podsPerNode[pod.Spec.NodeName]++
+		}
+	}
+	if len(podsPerNode) == 0 {
+		return nil
+	}
+
+	groupLabel := w.config.Watcher.Topology.GetLabels()[0]
+	podsPerGroup := make(map[string]int)
+	nodes := make([]string, 0, len(podsPerNode))
+	for node, count := range podsPerNode {
+		group := w.nodeLabels(node)[groupLabel]
+		if group == "" {
+			group = "unknown"
+		}
+		podsPerGroup[group] += count
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	groups := make([]string, 0, len(podsPerGroup))
+	for group := range podsPerGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for i, group := range groups {
+		groups[i] = fmt.Sprintf("%s (%s)", group, pluralize(podsPerGroup[group], "pod"))
+	}
+
+	listed := nodes
+	if len(listed) > maxNodesListed {
+		listed = listed[:maxNodesListed]
+	}
+	nodeLine := "Nodes: " + strings.Join(listed, ", ")
+	if len(nodes) > len(listed) {
+		nodeLine += fmt.Sprintf(" and %d more", len(nodes)-len(listed))
+	}
+
+	return []string{
+		fmt.Sprintf("Pods on %s, by %s: %s", pluralize(len(nodes), "node"), shortLabel(groupLabel), strings.Join(groups, ", ")),
+		nodeLine,
+	}
+}
+
+// workloadSelector returns a workload's pod selector, or nil when it selects nothing
+func workloadSelector(obj metav1.Object) labels.Selector {
+	var labelSelector *metav1.LabelSelector
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		labelSelector = workload.Spec.Selector
+	case *unstructured.Unstructured:
+		raw, found, err := unstructured.NestedMap(workload.Object, "spec", "selector")
+		if err != nil || !found {
+			return nil
+		}
+		labelSelector = &metav1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, labelSelector); err != nil {
+			return nil
+		}
+	}
+	if labelSelector == nil {
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil || selector.Empty() {
+		return nil
+	}
+	return selector
+}
+
+// podNodeName returns the node a pod is scheduled to, or "" when it is not scheduled yet
+func podNodeName(obj metav1.Object) string {
+	switch pod := obj.(type) {
+	case *corev1.Pod:
+		return pod.Spec.NodeName
+	case *unstructured.Unstructured:
+		nodeName, _, _ := unstructured.NestedString(pod.Object, "spec", "nodeName")
+		return nodeName
+	}
+	return ""
+}
+
+// shortLabel drops the prefix of a label key, e.g. topology.kubernetes.io/zone becomes zone
+func shortLabel(key string) string {
+	return key[strings.LastIndex(key, "/")+1:]
+}
+
+// pluralize renders a count with its noun, e.g. "1 pod" or "3 pods"
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,181 @@
+package watcher
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// Trace stages, in the order an event normally moves through them
+const (
+	StageReceived     = "received"
+	StageFiltered     = "filtered"
+	StageDiffed       = "diffed"
+	StageDeduplicated = "deduplicated"
+	StageQueued       = "queued"
+	StageSent         = "sent"
+)
+
+// TraceStep is one decision point in an event's processing
+type TraceStep struct {
+	Stage    string        `json:"stage"`
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"durationNs"` // Time spent since the previous step
+	Decision string        `json:"decision"`
+}
+
+// EventTrace is the processing timeline of a single informer event. Its ID also serves
+// as the event's correlation ID in logs and notifications.
+type EventTrace struct {
+	ID         string             `json:"id"`
+	Watcher    string             `json:"watcher,omitempty"` // Informer key of the resource entry, e.g. Secret/production
+	Kind       string             `json:"kind"`
+	Namespace  string             `json:"namespace"`
+	Name       string             `json:"name"`
+	EventType  notifier.EventType `json:"eventType"`
+	ReceivedAt time.Time          `json:"receivedAt"`
+	Outcome    string             `json:"outcome"`
+	Steps      []TraceStep        `json:"steps"`
+
+	logger *slog.Logger
+}
+
+// Logger returns a logger that adds the event's correlation ID, watcher key and object to each record
+func (t *EventTrace) Logger() *slog.Logger {
+	return t.logger
+}
+
+// Step appends a stage with its decision to the trace
+func (t *EventTrace) Step(stage, decision string) {
+	now := time.Now()
+	last := t.ReceivedAt
+	if len(t.Steps) > 0 {
+		last = t.Steps[len(t.Steps)-1].At
+	}
+	t.Steps = append(t.Steps, TraceStep{
+		Stage:    stage,
+		At:       now,
+		Duration: now.Sub(last),
+		Decision: decision,
+	})
+}
+
+// String renders the trace as a single timeline log line
+func (t *EventTrace) String() string {
+	return fmt.Sprintf("trace=%s %s %s/%s %s outcome=%s timeline=%s",
+		t.ID, t.Kind, t.Namespace, t.Name, t.EventType, t.Outcome, t.timeline())
+}
+
+// timeline renders the steps, e.g. "received(+0s: ...) → filtered(+12µs: ...)"
+func (t *EventTrace) timeline() string {
+	parts := make([]string, 0, len(t.Steps))
+	for _, step := range t.Steps {
+		parts = append(parts, fmt.Sprintf("%s(+%s: %s)", step.Stage, step.Duration.Round(time.Microsecond), step.Decision))
+	}
+	return strings.Join(parts, " → ")
+}
+
+// TraceRecorder keeps the traces of the last N events in a ring buffer
+type TraceRecorder struct {
+	mu       sync.RWMutex
+	capacity int
+	traces   []*EventTrace
+	next     int
+	byID     map[string]*EventTrace
+	logger   *slog.Logger
+}
+
+// NewTraceRecorder creates a recorder keeping up to capacity traces. Trace loggers
+// derive from logger, which also logs every finished trace at debug level.
+func NewTraceRecorder(capacity int, logger *slog.Logger) *TraceRecorder {
+	return &TraceRecorder{
+		capacity: capacity,
+		traces:   make([]*EventTrace, capacity),
+		byID:     make(map[string]*EventTrace, capacity),
+		logger:   logger,
+	}
+}
+
+// Start begins a trace for an event that was just received from the informer with the given key
+func (r *TraceRecorder) Start(watcher, kind string, eventType notifier.EventType, namespace, name string) *EventTrace {
+	trace := &EventTrace{
+		ID:         newTraceID(),
+		Watcher:    watcher,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+		EventType:  eventType,
+		ReceivedAt: time.Now(),
+	}
+	trace.logger = r.logger.With("eventID", trace.ID, "watcher", watcher, "kind", kind,
+		"namespace", namespace, "name", name, "eventType", string(eventType))
+	trace.Step(StageReceived, "event received from informer")
+	return trace
+}
+
+// Finish records the outcome of a trace and stores it, evicting the oldest trace when full
+func (r *TraceRecorder) Finish(trace *EventTrace, outcome string) {
+	trace.Outcome = outcome
+	trace.logger.Debug("Event processed", "outcome", outcome, "timeline", trace.timeline())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if evicted := r.traces[r.next]; evicted != nil {
+		delete(r.byID, evicted.ID)
+	}
+	r.traces[r.next] = trace
+	r.byID[trace.ID] = trace
+	r.next = (r.next + 1) % r.capacity
+}
+
+// Get returns a copy of the trace with the given ID, if it is still retained
+func (r *TraceRecorder) Get(id string) (EventTrace, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trace, ok := r.byID[id]
+	if !ok {
+		return EventTrace{}, false
+	}
+
+	copied := *trace
+	copied.Steps = append([]TraceStep(nil), trace.Steps...)
+	return copied, true
+}
+
+// Recent returns copies of up to limit retained traces, newest first
+func (r *TraceRecorder) Recent(limit int) []EventTrace {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if limit <= 0 || limit > r.capacity {
+		limit = r.capacity
+	}
+	recent := make([]EventTrace, 0, limit)
+	for i := 1; i <= r.capacity && len(recent) < limit; i++ {
+		trace := r.traces[(r.next-i+r.capacity)%r.capacity]
+		if trace == nil {
+			break
+		}
+		copied := *trace
+		copied.Steps = append([]TraceStep(nil), trace.Steps...)
+		recent = append(recent, copied)
+	}
+	return recent
+}
+
+// newTraceID returns a random identifier for an event
+func newTraceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,108 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var podsResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+// newPodMetadataInformer watches pod metadata only, which is all the Service
+// selector check needs and far cheaper to cache than full pods
+func newPodMetadataInformer(client metadata.Interface) cache.SharedIndexInformer {
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	return metadatainformer.NewFilteredMetadataInformer(client, podsResource, metav1.NamespaceAll, 0, indexers, nil).Informer()
+}
+
+// validateObject runs basic semantic checks on a changed object and returns
+// human-readable warnings to include in the notification
+func (w *InformerWatcher) validateObject(resourceKind string, obj metav1.Object) []string {
+	switch resourceKind {
+	case "Deployment":
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			return validateDeployment(deployment)
+		}
+	case "Service":
+		if service, ok := obj.(*unstructured.Unstructured); ok {
+			return w.validateService(service)
+		}
+	}
+	return nil
+}
+
+// validateDeployment checks the selector against the pod template and flags unpinned images
+func validateDeployment(deployment *appsv1.Deployment) []string {
+	var warnings []string
+
+	if deployment.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("selector is invalid: %v", err))
+		} else if !selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+			warnings = append(warnings, fmt.Sprintf("selector %q does not match the pod template labels", selector.String()))
+		}
+	}
+
+	podSpec := deployment.Spec.Template.Spec
+	for _, container := range append(append([]corev1.Container(nil), podSpec.InitContainers...), podSpec.Containers...) {
+		if imageUnpinned(container.Image) {
+			warnings = append(warnings, fmt.Sprintf("container %s uses unpinned image %q", container.Name, container.Image))
+		}
+	}
+
+	return warnings
+}
+
+// imageUnpinned reports whether an image has no tag or digest, or uses :latest
+func imageUnpinned(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	// A colon after the last slash is a tag; earlier colons belong to a registry port
+	lastSegment := image[strings.LastIndex(image, "/")+1:]
+	tag := ""
+	if i := strings.LastIndex(lastSegment, ":"); i >= 0 {
+		tag = lastSegment[i+1:]
+	}
+	return tag == "" || tag == "latest"
+}
+
+// validateService warns when a selector-based Service currently selects no pods
+func (w *InformerWatcher) validateService(service *unstructured.Unstructured) []string {
+	serviceType, _, _ := unstructured.NestedString(service.Object, "spec", "type")
+	if serviceType == "ExternalName" {
+		return nil
+	}
+	selector, found, err := unstructured.NestedStringMap(service.Object, "spec", "selector")
+	if err != nil || !found || len(selector) == 0 {
+		return nil
+	}
+
+	if w.podInformer == nil || !w.podInformer.HasSynced() {
+		return nil
+	}
+
+	pods, err := w.podInformer.GetIndexer().ByIndex(cache.NamespaceIndex, service.GetNamespace())
+	if err != nil {
+		w.logger.Warn("Failed to look up pods of Service", "kind", "Service", "namespace", service.GetNamespace(), "name", service.GetName(), "error", err)
+		return nil
+	}
+
+	podSelector := labels.SelectorFromSet(selector)
+	for _, pod := range pods {
+		if accessor, ok := pod.(metav1.Object); ok && podSelector.Matches(labels.Set(accessor.GetLabels())) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("selector %q matches no pods in namespace %s", podSelector.String(), service.GetNamespace())}
+}
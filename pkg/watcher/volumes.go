@@ -0,0 +1,192 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// volumeKinds are the storage kinds whose MODIFIED events are reduced to lifecycle transitions
+var volumeKinds = map[string]bool{"PersistentVolumeClaim": true, "PersistentVolume": true}
+
+// handleVolumeUpdated notifies only lifecycle transitions of PVCs and PVs; controllers
+// update both for finalizers, annotations and resize bookkeeping, so raw MODIFIED events are noise
+func (w *InformerWatcher) handleVolumeUpdated(trace *EventTrace, resourceKind string, oldObj, newObj *unstructured.Unstructured) {
+	var changedFields, diff []string
+	converted := true
+	switch resourceKind {
+	case "PersistentVolumeClaim":
+		var oldClaim, newClaim corev1.PersistentVolumeClaim
+		if converted = convertObject(oldObj, &oldClaim) && convertObject(newObj, &newClaim); converted {
+			changedFields, diff = claimTransitions(&oldClaim, &newClaim)
+		}
+	case "PersistentVolume":
+		var oldVolume, newVolume corev1.PersistentVolume
+		if converted = convertObject(oldObj, &oldVolume) && convertObject(newObj, &newVolume); converted {
+			changedFields, diff = volumeTransitions(&oldVolume, &newVolume)
+		}
+	}
+	if !converted {
+		trace.Logger().Warn("Failed to convert to a typed " + resourceKind)
+		w.traces.Finish(trace, "failed")
+		return
+	}
+	if len(changedFields) == 0 {
+		trace.Step(StageDiffed, "no volume state transitions")
+		w.traces.Finish(trace, "ignored")
+		return
+	}
+
+	trace.Logger().Info("Volume state transitions", "diff", diff)
+	trace.Step(StageDiffed, describeChangedFields(changedFields))
+	w.sendNotification(trace, resourceKind, notifier.EventModified, newObj, changedFields, diff)
+}
+
+// claimTransitions returns the changed PVC state fields and a readable line per transition:
+// phase changes such as Pending -> Bound or Lost, storage class changes, expansion requests,
+// completed expansions, resize conditions and resize failures
+func claimTransitions(oldClaim, newClaim *corev1.PersistentVolumeClaim) ([]string, []string) {
+	var changedFields, diff []string
+
+	if oldPhase, newPhase := oldClaim.Status.Phase, newClaim.Status.Phase; oldPhase != newPhase {
+		changedFields = append(changedFields, "status.phase")
+		line := fmt.Sprintf("~ phase: %s -> %s", oldPhase, newPhase)
+		if newPhase == corev1.ClaimBound && newClaim.Spec.VolumeName != "" {
+			line += " (volume " + newClaim.Spec.VolumeName
+			if capacity, ok := newClaim.Status.Capacity[corev1.ResourceStorage]; ok {
+				line += ", " + capacity.String()
+			}
+			line += ")"
+		}
+		diff = append(diff, line)
+	}
+
+	if oldClass, newClass := storageClassName(oldClaim.Spec.StorageClassName), storageClassName(newClaim.Spec.StorageClassName); oldClass != newClass {
+		changedFields = append(changedFields, "spec.storageClassName")
+		diff = append(diff, fmt.Sprintf("~ storageClassName: %s -> %s", oldClass, newClass))
+	}
+
+	oldRequest, newRequest := oldClaim.Spec.Resources.Requests[corev1.ResourceStorage], newClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+	if oldRequest.Cmp(newRequest) != 0 {
+		changedFields = append(changedFields, "spec.resources.requests.storage")
+		line := fmt.Sprintf("~ requests.storage: %s -> %s", oldRequest.String(), newRequest.String())
+		if newRequest.Cmp(oldRequest) > 0 {
+			line += " (expansion requested)"
+		}
+		diff = append(diff, line)
+	}
+
+	// A claim gets its first capacity when it binds, which the phase line shows
+	oldCapacity, hadCapacity := oldClaim.Status.Capacity[corev1.ResourceStorage]
+	newCapacity := newClaim.Status.Capacity[corev1.ResourceStorage]
+	if hadCapacity && oldCapacity.Cmp(newCapacity) != 0 {
+		changedFields = append(changedFields, "status.capacity.storage")
+		line := fmt.Sprintf("~ capacity.storage: %s -> %s", oldCapacity.String(), newCapacity.String())
+		if newCapacity.Cmp(oldCapacity) > 0 {
+			line += " (expansion completed)"
+		}
+		diff = append(diff, line)
+	}
+
+	if conditionDiff := diffLines("condition", claimConditions(oldClaim), claimConditions(newClaim)); len(conditionDiff) > 0 {
+		changedFields = append(changedFields, "status.conditions")
+		diff = append(diff, conditionDiff...)
+	}
+
+	if resizeDiff := diffLines("resizeStatus", resizeStatuses(oldClaim), resizeStatuses(newClaim)); len(resizeDiff) > 0 {
+		changedFields = append(changedFields, "status.allocatedResourceStatuses")
+		diff = append(diff, resizeDiff...)
+	}
+
+	return changedFields, diff
+}
+
+// volumeTransitions returns the changed PV state fields and a readable line per transition:
+// phase changes such as Bound -> Released or Failed with the reason, claim binding,
+// storage class, capacity and reclaim policy changes
+func volumeTransitions(oldVolume, newVolume *corev1.PersistentVolume) ([]string, []string) {
+	var changedFields, diff []string
+
+	if oldPhase, newPhase := oldVolume.Status.Phase, newVolume.Status.Phase; oldPhase != newPhase {
+		changedFields = append(changedFields, "status.phase")
+		line := fmt.Sprintf("~ phase: %s -> %s", oldPhase, newPhase)
+		switch {
+		case newVolume.Status.Reason != "" && newVolume.Status.Message != "":
+			line += fmt.Sprintf(" (%s: %s)", newVolume.Status.Reason, newVolume.Status.Message)
+		case newVolume.Status.Message != "":
+			line += " (" + newVolume.Status.Message + ")"
+		}
+		diff = append(diff, line)
+	}
+
+	if oldClaim, newClaim := describeClaimRef(oldVolume.Spec.ClaimRef), describeClaimRef(newVolume.Spec.ClaimRef); oldClaim != newClaim {
+		changedFields = append(changedFields, "spec.claimRef")
+		diff = append(diff, fmt.Sprintf("~ claim: %s -> %s", oldClaim, newClaim))
+	}
+
+	if oldClass, newClass := storageClassName(&oldVolume.Spec.StorageClassName), storageClassName(&newVolume.Spec.StorageClassName); oldClass != newClass {
+		changedFields = append(changedFields, "spec.storageClassName")
+		diff = append(diff, fmt.Sprintf("~ storageClassName: %s -> %s", oldClass, newClass))
+	}
+
+	oldCapacity, newCapacity := oldVolume.Spec.Capacity[corev1.ResourceStorage], newVolume.Spec.Capacity[corev1.ResourceStorage]
+	if oldCapacity.Cmp(newCapacity) != 0 {
+		changedFields = append(changedFields, "spec.capacity.storage")
+		diff = append(diff, fmt.Sprintf("~ capacity.storage: %s -> %s", quantityOrNone(oldCapacity), quantityOrNone(newCapacity)))
+	}
+
+	if oldPolicy, newPolicy := oldVolume.Spec.PersistentVolumeReclaimPolicy, newVolume.Spec.PersistentVolumeReclaimPolicy; oldPolicy != newPolicy {
+		changedFields = append(changedFields, "spec.persistentVolumeReclaimPolicy")
+		diff = append(diff, fmt.Sprintf("~ reclaimPolicy: %s -> %s", oldPolicy, newPolicy))
+	}
+
+	return changedFields, diff
+}
+
+// storageClassName shows an unset or empty class as "(none)"
+func storageClassName(name *string) string {
+	if name == nil || *name == "" {
+		return "(none)"
+	}
+	return *name
+}
+
+func quantityOrNone(quantity resource.Quantity) string {
+	if quantity.IsZero() {
+		return "(none)"
+	}
+	return quantity.String()
+}
+
+func describeClaimRef(ref *corev1.ObjectReference) string {
+	if ref == nil {
+		return "(none)"
+	}
+	return ref.Namespace + "/" + ref.Name
+}
+
+// claimConditions lists the PVC conditions that are currently true, e.g. Resizing
+func claimConditions(claim *corev1.PersistentVolumeClaim) []string {
+	var conditions []string
+	for _, condition := range claim.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue {
+			conditions = append(conditions, string(condition.Type))
+		}
+	}
+	return conditions
+}
+
+// resizeStatuses lists the resize state of each resource, e.g. "storage=ControllerResizeFailed"
+func resizeStatuses(claim *corev1.PersistentVolumeClaim) []string {
+	statuses := make([]string, 0, len(claim.Status.AllocatedResourceStatuses))
+	for name, status := range claim.Status.AllocatedResourceStatuses {
+		statuses = append(statuses, string(name)+"="+string(status))
+	}
+	sort.Strings(statuses)
+	return statuses
+}
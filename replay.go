@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// runReplay sends a range of the events recorded by a running watcher to one of its
+// notifiers, and prints the outcome of each
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "Base URL of the running watcher")
+	since := flags.String("since", "", "Oldest events to replay: a duration back from now (24h, 7d) or an RFC 3339 time")
+	until := flags.String("until", "", "Replay only events before this duration back from now or RFC 3339 time")
+	kind := flags.String("kind", "", "Replay only events of this kind")
+	namespace := flags.String("namespace", "", "Replay only events in this namespace")
+	name := flags.String("name", "", "Replay only events of objects with this name")
+	eventType := flags.String("event-type", "", "Replay only events of this type, e.g. MODIFIED")
+	status := flags.String("status", "", "Replay only events with this notification status: sent, failed or recorded")
+	limit := flags.Int("limit", 0, "Replay at most the newest this many matching events (default 100, max 1000)")
+	dryRun := flags.Bool("dry-run", false, "List the events that would be replayed without sending them")
+	token := flags.String("token", os.Getenv("RESOURCE_WATCHER_TOKEN"), "Bearer token of the admin port (default $RESOURCE_WATCHER_TOKEN)")
+	caFile := flags.String("cacert", "", "CA bundle verifying an HTTPS server")
+	certFile := flags.String("cert", "", "Client certificate for an mTLS admin port")
+	keyFile := flags.String("key", "", "Key of the client certificate")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s replay [flags] <notifier>\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Example: %s replay -since 48h -until 24h -namespace production webhook\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 1
+	}
+
+	request, err := json.Marshal(map[string]interface{}{
+		"notifier":  flags.Arg(0),
+		"since":     *since,
+		"until":     *until,
+		"kind":      *kind,
+		"namespace": *namespace,
+		"name":      *name,
+		"eventType": strings.ToUpper(*eventType),
+		"status":    *status,
+		"limit":     *limit,
+		"dryRun":    *dryRun,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	client, err := tailClient(*caFile, *certFile, *keyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// Sending can take long, so only an interrupt ends the request
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	body, err := apiRequest(ctx, client, http.MethodPost, strings.TrimSuffix(*server, "/")+"/api/v1/events/replay", *token, request)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var response struct {
+		Events    []notifier.ReplayResult `json:"events"`
+		Failed    int                     `json:"failed"`
+		Truncated bool                    `json:"truncated"`
+		Error     string                  `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		fmt.Fprintf(os.Stderr, "malformed response: %v\n", err)
+		return 1
+	}
+
+	outcome := "sent"
+	if *dryRun {
+		outcome = "would send"
+	}
+	for _, event := range response.Events {
+		ref := event.Name
+		if event.Namespace != "" {
+			ref = event.Namespace + "/" + event.Name
+		}
+		line := fmt.Sprintf("%s %s %s %s: ", event.Time.Local().Format(time.RFC3339), event.EventType, event.Kind, ref)
+		if event.Error != "" {
+			line += "failed: " + event.Error
+		} else {
+			line += outcome
+		}
+		fmt.Println(line)
+	}
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "%d events would be replayed to %s\n", len(response.Events), flags.Arg(0))
+	} else {
+		fmt.Fprintf(os.Stderr, "%d events replayed to %s, %d failed\n", len(response.Events), flags.Arg(0), response.Failed)
+	}
+	if response.Truncated {
+		fmt.Fprintln(os.Stderr, "more events match: only the newest were replayed; narrow -since or raise -limit")
+	}
+	if response.Error != "" {
+		fmt.Fprintln(os.Stderr, "replay stopped: "+response.Error)
+		return 1
+	}
+	if response.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/archive"
+)
+
+// restoreTimeout bounds each request of the restore subcommand
+const restoreTimeout = 30 * time.Second
+
+// runRestore lists the archived versions of an object, or prints one as a manifest to
+// pipe into kubectl apply
+func runRestore(args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "Base URL of the running watcher")
+	cluster := flags.String("cluster", "", "Cluster of the object; defaults to the first configured")
+	versionID := flags.String("version", "", "Version to print as a manifest, or latest; without it the versions are listed")
+	token := flags.String("token", os.Getenv("RESOURCE_WATCHER_TOKEN"), "Bearer token of the admin port (default $RESOURCE_WATCHER_TOKEN)")
+	caFile := flags.String("cacert", "", "CA bundle verifying an HTTPS server")
+	certFile := flags.String("cert", "", "Client certificate for an mTLS admin port")
+	keyFile := flags.String("key", "", "Key of the client certificate")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s restore [flags] <kind> <[namespace/]name>\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Example: %s restore -version latest ConfigMap payments/app-config | kubectl apply -f -\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 1
+	}
+
+	query := url.Values{"kind": {flags.Arg(0)}}
+	if namespace, name, ok := strings.Cut(flags.Arg(1), "/"); ok {
+		query.Set("namespace", namespace)
+		query.Set("name", name)
+	} else {
+		query.Set("name", namespace)
+	}
+	if *cluster != "" {
+		query.Set("cluster", *cluster)
+	}
+
+	client, err := tailClient(*caFile, *certFile, *keyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+	defer cancel()
+	base := strings.TrimSuffix(*server, "/") + "/api/v1/archive/"
+
+	if *versionID != "" {
+		query.Set("version", *versionID)
+		manifest, err := apiRequest(ctx, client, http.MethodGet, base+"manifest?"+query.Encode(), *token, nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		os.Stdout.Write(manifest)
+		return 0
+	}
+
+	body, err := apiRequest(ctx, client, http.MethodGet, base+"versions?"+query.Encode(), *token, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var response struct {
+		Versions []archive.Version `json:"versions"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		fmt.Fprintf(os.Stderr, "malformed response: %v\n", err)
+		return 1
+	}
+	if len(response.Versions) == 0 {
+		fmt.Fprintf(os.Stderr, "no archived versions of %s %s\n", flags.Arg(0), flags.Arg(1))
+		return 1
+	}
+	for _, version := range response.Versions {
+		fmt.Printf("%-45s %s %s\n", version.ID, version.Time.Local().Format(time.RFC3339), version.EventType)
+	}
+	return 0
+}
+
+// apiRequest sends a request of the restore and replay subcommands, with a JSON body if
+// set, failing with the API's error message on any status but 200
+func apiRequest(ctx context.Context, client *http.Client, method, endpoint, token string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	response, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiError struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(response, &apiError) == nil && apiError.Error != "" {
+			return nil, fmt.Errorf("status %d: %s", resp.StatusCode, apiError.Error)
+		}
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(response)))
+	}
+	return response, nil
+}
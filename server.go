@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/config"
+)
+
+// adminAuth admits requests to the admin port that carry the configured bearer token or a
+// client certificate verified against tls.clientCAFile. Without either configured, it admits all.
+func adminAuth(admin config.AdminServerConfig) gin.HandlerFunc {
+	token := admin.GetBearerToken()
+	mtls := admin.TLS != nil && admin.TLS.ClientCAFile != ""
+	return func(c *gin.Context) {
+		if token == "" && !mtls {
+			return
+		}
+		if mtls && c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			return
+		}
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && token != "" &&
+			subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			return
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="k8s-resource-watcher"`)
+		c.AbortWithStatusJSON(401, gin.H{"error": "authentication required"})
+	}
+}
+
+// serverTLSConfig returns the TLS settings serving the certificate pair, or nil to serve plain HTTP
+func serverTLSConfig(pair *config.ServerTLSConfig) (*tls.Config, error) {
+	if pair == nil {
+		return nil, nil
+	}
+	certificates := &certificateReloader{certFile: pair.CertFile, keyFile: pair.KeyFile}
+	if _, err := certificates.GetCertificate(nil); err != nil {
+		return nil, err
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: certificates.GetCertificate}, nil
+}
+
+// adminTLSConfig returns the TLS settings of the admin port, or nil to serve plain HTTP.
+// With a client CA, clients must present a certificate it signed, unless a bearer token
+// is also configured, in which case either authenticates.
+func adminTLSConfig(admin config.AdminServerConfig) (*tls.Config, error) {
+	if !admin.Enabled || admin.TLS == nil {
+		return nil, nil
+	}
+	tlsConfig, err := serverTLSConfig(&admin.TLS.ServerTLSConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if admin.TLS.ClientCAFile != "" {
+		bundle, err := os.ReadFile(admin.TLS.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("client CA file %s has no PEM certificates", admin.TLS.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		if admin.GetBearerToken() != "" {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+	return tlsConfig, nil
+}
+
+// listenAndServe serves plain HTTP, or HTTPS when the server has TLS settings
+func listenAndServe(server *http.Server) error {
+	if server.TLSConfig != nil {
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
+
+// certificateReloader serves a certificate and key pair from files, loading them again
+// when the certificate file changes, e.g. when cert-manager renews a mounted Secret
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+}
+
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.certFile)
+	if err == nil && (r.certificate == nil || !info.ModTime().Equal(r.modTime)) {
+		var certificate tls.Certificate
+		if certificate, err = tls.LoadX509KeyPair(r.certFile, r.keyFile); err == nil {
+			r.certificate, r.modTime = &certificate, info.ModTime()
+		}
+	}
+	if err != nil {
+		if r.certificate == nil {
+			return nil, fmt.Errorf("failed to load TLS certificate %s: %w", r.certFile, err)
+		}
+		// A renewal may be half written; keep serving the previous pair
+		slog.Warn("Failed to reload TLS certificate", "certFile", r.certFile, "error", err)
+	}
+	return r.certificate, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/expr"
+	"github.com/jimohabdol/k8s-resource-watcher/pkg/notifier"
+)
+
+// tailRetryDelay is how long tail waits before reconnecting to a closed stream
+const tailRetryDelay = 2 * time.Second
+
+// runTail prints the changes streamed by a running watcher, optionally only those
+// matching an expression, until interrupted
+func runTail(args []string) int {
+	flags := flag.NewFlagSet("tail", flag.ExitOnError)
+	server := flags.String("server", "http://localhost:8080", "Base URL of the running watcher")
+	output := flags.String("output", "text", "Output format: text, or json for one event per line")
+	token := flags.String("token", os.Getenv("RESOURCE_WATCHER_TOKEN"), "Bearer token of the admin port (default $RESOURCE_WATCHER_TOKEN)")
+	caFile := flags.String("cacert", "", "CA bundle verifying an HTTPS server")
+	certFile := flags.String("cert", "", "Client certificate for an mTLS admin port")
+	keyFile := flags.String("key", "", "Key of the client certificate")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s tail [flags] [expression]\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Example: %s tail '.kind == \"Deployment\" and .namespace =~ \"^prod-\"'\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var filter *expr.Expr
+	if flags.NArg() > 0 {
+		var err error
+		if filter, err = expr.Parse(strings.Join(flags.Args(), " ")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q (valid formats: text, json)\n", *output)
+		return 1
+	}
+
+	client, err := tailClient(*caFile, *certFile, *keyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	streamURL := strings.TrimSuffix(*server, "/") + "/api/v1/events/stream"
+	for {
+		err := tailStream(ctx, client, streamURL, *token, filter, *output == "json")
+		if ctx.Err() != nil {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "stream %s ended: %v; reconnecting in %s\n", streamURL, err, tailRetryDelay)
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-time.After(tailRetryDelay):
+		}
+	}
+}
+
+// tailClient returns the HTTP client of the stream, and of the restore subcommand, trusting
+// caFile and presenting the client certificate when set. It has no timeout, since the stream
+// stays open.
+func tailClient(caFile, certFile, keyFile string) (*http.Client, error) {
+	if caFile == "" && certFile == "" {
+		return http.DefaultClient, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		bundle, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("CA bundle %s has no PEM certificates", caFile)
+		}
+	}
+	if certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+// tailStream prints the matching events of one stream connection until it ends
+func tailStream(ctx context.Context, client *http.Client, streamURL, token string, filter *expr.Expr, asJSON bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Events are "data:" lines ended by a blank line; comments start with ":"
+	reader := bufio.NewReader(resp.Body)
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("closed by the watcher")
+			}
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				printStreamEvent(data.String(), filter, asJSON)
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+// printStreamEvent prints an event if it matches the filter
+func printStreamEvent(data string, filter *expr.Expr, asJSON bool) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		fmt.Fprintf(os.Stderr, "skipping malformed event: %v\n", err)
+		return
+	}
+	if filter != nil && !filter.Match(doc) {
+		return
+	}
+	if asJSON {
+		fmt.Println(data)
+		return
+	}
+
+	var event notifier.StreamEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		fmt.Fprintf(os.Stderr, "skipping malformed event: %v\n", err)
+		return
+	}
+	ref := event.Name
+	if event.Namespace != "" {
+		ref = event.Namespace + "/" + event.Name
+	}
+	line := fmt.Sprintf("%s %s %s %s %s", event.Time.Local().Format(time.RFC3339), event.Cluster, event.EventType, event.Kind, ref)
+	if len(event.ChangedFields) > 0 {
+		line += " [" + strings.Join(event.ChangedFields, ", ") + "]"
+	}
+	if event.ChangedBy != "" {
+		line += " by " + event.ChangedBy
+	}
+	if event.Error != "" {
+		line += " (notification failed: " + event.Error + ")"
+	}
+	fmt.Println(line)
+	for _, change := range event.ImageChanges {
+		fmt.Printf("    %s %s\n", change.Container, change)
+	}
+	for _, diffLine := range event.Diff {
+		fmt.Println("    " + diffLine)
+	}
+}